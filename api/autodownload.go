@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/autodownload"
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// AutoDownloads lists new episodes of auto-downloaded shows, pending ones first
+func AutoDownloads(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	episodes := database.GetStorm().GetAutoDownloadEpisodes()
+	sort.Slice(episodes, func(i, j int) bool {
+		if episodes[i].State != episodes[j].State {
+			return episodes[i].State < episodes[j].State
+		}
+		return episodes[i].AirDate > episodes[j].AirDate
+	})
+
+	items := make(xbmc.ListItems, 0, len(episodes))
+	for _, episode := range episodes {
+		state := "LOCALIZE[30761]"
+		switch episode.State {
+		case database.AutoDownloadAdded:
+			state = "[COLOR FF00FF00]LOCALIZE[30762][/COLOR]"
+		case database.AutoDownloadFailed:
+			state = "[COLOR FFFF0000]LOCALIZE[30763][/COLOR]"
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     fmt.Sprintf("%s  [%s]  %s", episode.AirDate, state, episode.Title),
+			Path:      URLForXBMC("/show/%d/seasons", episode.ShowID),
+			Thumbnail: config.AddonResource("img", "clock.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: fmt.Sprintf("%s\n%s: %d", episode.Title, episode.LastAttempt.Format("2006-01-02 15:04"), episode.Attempts),
+			},
+			ContextMenu: [][]string{
+				autoDownloadAction(episode.ShowID),
+				{"LOCALIZE[30760]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/autodownload/check"))},
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// ToggleAutoDownload marks or unmarks a show for auto-download of new episodes
+func ToggleAutoDownload(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	enabled := !database.GetStorm().IsAutoDownloadShow(showID)
+	if err := database.GetStorm().SetAutoDownloadShow(showID, enabled); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+
+	xbmc.Refresh()
+	ctx.String(200, "")
+}
+
+// CheckAutoDownloads searches pending episodes immediately
+func CheckAutoDownloads(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		added := autodownload.Check(s)
+		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30766];;%d", added), config.AddonIcon())
+		xbmc.Refresh()
+		ctx.String(200, "")
+	}
+}
+
+// autoDownloadAction returns context menu action to toggle auto-download of a show
+func autoDownloadAction(showID int) []string {
+	if database.GetStorm().IsAutoDownloadShow(showID) {
+		return []string{"LOCALIZE[30758]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/autodownload", showID))}
+	}
+	return []string{"LOCALIZE[30757]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/autodownload", showID))}
+}
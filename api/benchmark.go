@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Standard benchmark queries: well known movie, long running show and anime
+const (
+	benchmarkMovieID = "603"
+	benchmarkShowID  = 456
+	benchmarkAnimeID = 37854
+)
+
+// benchmarkQuery is a single test query, run against each provider
+type benchmarkQuery struct {
+	Label  string
+	Search func(searcher *providers.AddonSearcher) []*bittorrent.TorrentFile
+}
+
+// benchmarkResult keeps results of a single query for a single provider
+type benchmarkResult struct {
+	Count       int
+	Duration    time.Duration
+	MaxSeeds    int64
+	Resolutions map[int]int
+}
+
+// ProvidersBenchmark runs standard set of queries against each enabled provider
+// and shows results count, latency and quality coverage.
+func ProvidersBenchmark(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.String(200, "")
+
+	queries := benchmarkQueries()
+	if len(queries) == 0 {
+		xbmc.Notify("Elementum", "LOCALIZE[30875]", config.AddonIcon())
+		return
+	}
+
+	addons := []Addon{}
+	for _, addon := range getProviders() {
+		if addon.Enabled {
+			addons = append(addons, addon)
+		}
+	}
+	if len(addons) == 0 {
+		xbmc.Notify("Elementum", "LOCALIZE[30204]", config.AddonIcon())
+		return
+	}
+
+	dialog := xbmc.NewDialogProgress("Elementum", "", "", "")
+	defer dialog.Close()
+
+	report := []string{}
+	total := len(addons) * len(queries)
+	step := 0
+
+providersLoop:
+	for _, addon := range addons {
+		searcher := providers.NewAddonSearcher(addon.ID)
+		lines := []string{}
+		found := 0
+
+		for _, query := range queries {
+			if dialog.IsCanceled() {
+				break providersLoop
+			}
+			dialog.Update(step*100/total, addon.Name, query.Label, "")
+			step++
+
+			started := time.Now()
+			torrents := query.Search(searcher)
+			result := newBenchmarkResult(torrents, time.Since(started))
+			found += result.Count
+
+			lines = append(lines, fmt.Sprintf("  %s: %s", query.Label, result))
+		}
+
+		verdict := ""
+		if found == 0 {
+			verdict = " [COLOR red]no results, consider disabling[/COLOR]"
+		}
+		report = append(report, fmt.Sprintf("[B]%s[/B] %s%s", addon.Name, addon.Version, verdict))
+		report = append(report, lines...)
+		report = append(report, "")
+	}
+
+	text := strings.Join(report, "\n")
+	log.Infof("Providers benchmark:\n%s", text)
+	xbmc.DialogText("LOCALIZE[30876]", text)
+}
+
+func benchmarkQueries() []*benchmarkQuery {
+	ret := []*benchmarkQuery{}
+	language := config.Get().Language
+
+	if movie := tmdb.GetMovieByID(benchmarkMovieID, language); movie != nil {
+		ret = append(ret, &benchmarkQuery{
+			Label: fmt.Sprintf("Movie (%s)", movie.Title),
+			Search: func(searcher *providers.AddonSearcher) []*bittorrent.TorrentFile {
+				return searcher.SearchMovieLinks(movie)
+			},
+		})
+	}
+
+	for _, item := range []struct {
+		label  string
+		showID int
+	}{
+		{"Recent episode", benchmarkShowID},
+		{"Anime", benchmarkAnimeID},
+	} {
+		show, episode := latestAiredEpisode(item.showID, language)
+		if show == nil || episode == nil {
+			continue
+		}
+
+		ret = append(ret, &benchmarkQuery{
+			Label: fmt.Sprintf("%s (%s S%02dE%02d)", item.label, show.Name, episode.SeasonNumber, episode.EpisodeNumber),
+			Search: func(searcher *providers.AddonSearcher) []*bittorrent.TorrentFile {
+				return searcher.SearchEpisodeLinks(show, episode)
+			},
+		})
+	}
+
+	return ret
+}
+
+// latestAiredEpisode returns the last episode of a show, that is already aired
+func latestAiredEpisode(showID int, language string) (*tmdb.Show, *tmdb.Episode) {
+	show := tmdb.GetShow(showID, language)
+	if show == nil {
+		return nil, nil
+	}
+
+	today := time.Now().Format("2006-01-02")
+	for seasonNumber := show.NumberOfSeasons; seasonNumber > 0; seasonNumber-- {
+		season := tmdb.GetSeason(showID, seasonNumber, language, len(show.Seasons))
+		if season == nil {
+			continue
+		}
+
+		for i := len(season.Episodes) - 1; i >= 0; i-- {
+			if episode := season.Episodes[i]; episode != nil && episode.AirDate != "" && episode.AirDate <= today {
+				return show, episode
+			}
+		}
+	}
+
+	return show, nil
+}
+
+func newBenchmarkResult(torrents []*bittorrent.TorrentFile, duration time.Duration) *benchmarkResult {
+	ret := &benchmarkResult{
+		Count:       len(torrents),
+		Duration:    duration,
+		Resolutions: map[int]int{},
+	}
+	for _, t := range torrents {
+		ret.Resolutions[t.Resolution]++
+		if t.Seeds > ret.MaxSeeds {
+			ret.MaxSeeds = t.Seeds
+		}
+	}
+	return ret
+}
+
+func (r *benchmarkResult) String() string {
+	if r.Count == 0 {
+		return fmt.Sprintf("no results in %.1fs", r.Duration.Seconds())
+	}
+
+	qualities := []string{}
+	for res := len(bittorrent.Resolutions) - 1; res > bittorrent.ResolutionUnknown; res-- {
+		if r.Resolutions[res] > 0 {
+			qualities = append(qualities, fmt.Sprintf("%s: %d", bittorrent.Resolutions[res], r.Resolutions[res]))
+		}
+	}
+
+	return fmt.Sprintf("%d results in %.1fs, max seeds %d [%s]", r.Count, r.Duration.Seconds(), r.MaxSeeds, strings.Join(qualities, ", "))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/cast"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const castDiscoveryTimeout = 3 * time.Second
+
+// CastTorrent sends HTTP stream of a torrent to Chromecast or AirPlay device in local network.
+// Daemon keeps downloading the torrent while the device renders it.
+func CastTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to cast torrent with index %s", torrentID))
+			return
+		}
+
+		fileURL := ""
+		if player := s.GetActivePlayer(); player != nil && player.GetTorrent() == torrent {
+			fileURL = player.PlayURL()
+		} else if len(torrent.ChosenFiles) > 0 {
+			fileURL = util.EncodeFileURL(torrent.ChosenFiles[0].Path)
+		}
+		if fileURL == "" {
+			xbmc.Notify("Elementum", "LOCALIZE[30928]", config.AddonIcon())
+			return
+		}
+
+		localIP, err := util.LocalIP()
+		if err != nil {
+			xbmc.Notify("Elementum", "LOCALIZE[30930];;"+err.Error(), config.AddonIcon())
+			return
+		}
+
+		devices, err := cast.Discover(castDiscoveryTimeout)
+		if err != nil {
+			torrentsLog.Errorf("Could not discover cast devices: %s", err)
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		} else if len(devices) == 0 {
+			xbmc.Notify("Elementum", "LOCALIZE[30929]", config.AddonIcon())
+			return
+		}
+
+		labels := make([]string, 0, len(devices))
+		for _, d := range devices {
+			labels = append(labels, d.String())
+		}
+		choice := xbmc.ListDialog("LOCALIZE[30811]", labels...)
+		if choice < 0 {
+			return
+		}
+		device := devices[choice]
+
+		// Unsupported containers are passed through ffmpeg, if it is configured
+		endpoint := "files"
+		contentType := cast.ContentType(fileURL)
+		if !device.Supports(fileURL) {
+			if config.Get().FFmpegPath != "" {
+				endpoint = "transcode"
+				contentType = "video/mp4"
+			} else if !xbmc.DialogConfirm("Elementum", "LOCALIZE[30931];;"+device.Name) {
+				return
+			}
+		}
+
+		streamURL := fmt.Sprintf("http://%s:%d/%s/%s", localIP, config.Args.LocalPort, endpoint, fileURL)
+		if err := device.Play(streamURL, contentType, torrent.Name()); err != nil {
+			torrentsLog.Errorf("Could not cast to %s: %s", device, err)
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		xbmc.Notify("Elementum", "LOCALIZE[30932];;"+device.Name, config.AddonIcon())
+		ctx.String(200, "")
+	}
+}
@@ -1,12 +1,17 @@
 package api
 
 import (
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
 	"github.com/gin-gonic/gin"
 	"github.com/op/go-logging"
 
+	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/proxy"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -36,6 +41,14 @@ func ClearCache(ctx *gin.Context) {
 	xbmc.Notify("Elementum", "LOCALIZE[30200]", config.AddonIcon())
 }
 
+// CacheStats returns number of entries and bytes per namespace of the cache database
+func CacheStats(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.Header("Access-Control-Allow-Origin", "*")
+	ctx.JSON(200, cache.GetStats())
+}
+
 // ClearCacheTMDB ...
 func ClearCacheTMDB(ctx *gin.Context) {
 	log.Debug("Removing TMDB cache")
@@ -78,6 +91,36 @@ func ClearTmdbCache(ctx *gin.Context) {
 	library.ClearTmdbCache()
 }
 
+// RefreshMovieMetadata clears cached metadata of a movie and fetches it again
+func RefreshMovieMetadata(ctx *gin.Context) {
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	library.RefreshMovieMetadata(tmdbID)
+
+	xbmc.Notify("Elementum", "LOCALIZE[30933]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// RefreshShowMetadata clears cached metadata of a show, its seasons and episodes, and fetches it again
+func RefreshShowMetadata(ctx *gin.Context) {
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	library.RefreshShowMetadata(showID)
+
+	xbmc.Notify("Elementum", "LOCALIZE[30933]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// ReloadProxyRules reads internal proxy rewriting rules from the rules file
+func ReloadProxyRules(ctx *gin.Context) {
+	if err := proxy.LoadRules(); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	} else {
+		xbmc.Notify("Elementum", "LOCALIZE[30935]", config.AddonIcon())
+	}
+	ctx.String(200, "")
+}
+
 // ResetPath ...
 func ResetPath(ctx *gin.Context) {
 	xbmc.SetSetting("download_path", "")
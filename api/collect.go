@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var (
+	hdrRegex           = regexp.MustCompile(`(?i)\W(dv|dovi|dolby\W?vision)\W`)
+	hdr10PlusRegex     = regexp.MustCompile(`(?i)\Whdr10(\+|plus)\W`)
+	hdrGenericRegex    = regexp.MustCompile(`(?i)\Whdr(10)?\W`)
+	audioChannelsRegex = regexp.MustCompile(`\W([1-7])[\. ]([01])\W`)
+	threeDRegex        = regexp.MustCompile(`(?i)\W(3d|h\W?sbs|h\W?ou)\W`)
+)
+
+var collectionResolutions = map[int]string{
+	bittorrent.Resolution480p:  "sd_480p",
+	bittorrent.Resolution720p:  "hd_720p",
+	bittorrent.Resolution1080p: "hd_1080p",
+	bittorrent.Resolution4k:    "uhd_4k",
+}
+
+var collectionAudio = map[int]string{
+	bittorrent.CodecMp3:     "mp3",
+	bittorrent.CodecAAC:     "aac",
+	bittorrent.CodecAC3:     "dolby_digital",
+	bittorrent.CodecDTS:     "dts",
+	bittorrent.CodecDTSHD:   "dts_hr",
+	bittorrent.CodecDTSHDMA: "dts_ma",
+}
+
+// CollectTorrent adds movie or episode of a downloaded torrent to Trakt collection,
+// with quality metadata, parsed from torrent name.
+func CollectTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		item := database.GetStorm().GetBTItem(torrent.InfoHash())
+		if item == nil || item.ID == 0 {
+			xbmc.Notify("Elementum", "LOCALIZE[30857]", config.AddonIcon())
+			return
+		}
+
+		itemType := "movies"
+		if item.Type == episodeType {
+			itemType = "episodes"
+		}
+
+		tf := &bittorrent.TorrentFile{Name: torrent.Name(), Title: torrent.Name()}
+		tf.Init()
+
+		if err := trakt.CollectItem(itemType, item.ID, item.ShowID, collectionMetadata(tf)); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		xbmc.Notify("Elementum", "LOCALIZE[30856]", config.AddonIcon())
+		library.ClearPageCache()
+		ctx.String(200, "")
+	}
+}
+
+// collectionMetadata converts parsed torrent qualities to Trakt collection metadata
+func collectionMetadata(tf *bittorrent.TorrentFile) *trakt.CollectionMetadata {
+	meta := &trakt.CollectionMetadata{
+		Resolution: collectionResolutions[tf.Resolution],
+		Audio:      collectionAudio[tf.AudioCodec],
+	}
+
+	switch tf.RipType {
+	case bittorrent.RipBluRay:
+		meta.MediaType = "bluray"
+	case bittorrent.RipDVD, bittorrent.RipDVDScr:
+		meta.MediaType = "dvd"
+	default:
+		meta.MediaType = "digital"
+	}
+
+	name := " " + tf.Name + " "
+	if hdrRegex.MatchString(name) {
+		meta.HDR = "dolby_vision"
+	} else if hdr10PlusRegex.MatchString(name) {
+		meta.HDR = "hdr10_plus"
+	} else if hdrGenericRegex.MatchString(name) {
+		meta.HDR = "hdr10"
+	}
+
+	if m := audioChannelsRegex.FindStringSubmatch(name); len(m) == 3 {
+		meta.AudioChannels = m[1] + "." + m[2]
+	}
+	meta.ThreeD = threeDRegex.MatchString(name)
+
+	return meta
+}
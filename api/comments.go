@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var spoilerRegex = regexp.MustCompile(`(?is)\[spoiler\](.*?)\[/spoiler\]`)
+
+// MovieComments lists Trakt comments of a movie
+func MovieComments(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID := ctx.Params.ByName("tmdbId")
+	movie := trakt.GetMovieByTMDB(tmdbID)
+	if movie == nil || movie.IDs == nil {
+		xbmc.Notify("Elementum", "LOCALIZE[30885]", config.AddonIcon())
+		return
+	}
+
+	renderComments(ctx, "movie", strconv.Itoa(movie.IDs.Trakt), URLForXBMC("/movie/%s/comments", tmdbID))
+}
+
+// ShowComments lists Trakt comments of a show
+func ShowComments(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID := ctx.Params.ByName("showId")
+	show := trakt.GetShowByTMDB(showID)
+	if show == nil || show.IDs == nil {
+		xbmc.Notify("Elementum", "LOCALIZE[30886]", config.AddonIcon())
+		return
+	}
+
+	renderComments(ctx, "show", strconv.Itoa(show.IDs.Trakt), URLForXBMC("/show/%s/comments", showID))
+}
+
+// EpisodeComments lists Trakt comments of an episode
+func EpisodeComments(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID := ctx.Params.ByName("showId")
+	seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+	episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+
+	show := trakt.GetShowByTMDB(showID)
+	if show == nil || show.IDs == nil {
+		xbmc.Notify("Elementum", "LOCALIZE[30886]", config.AddonIcon())
+		return
+	}
+
+	id := fmt.Sprintf("%d/seasons/%d/episodes/%d", show.IDs.Trakt, seasonNumber, episodeNumber)
+	renderComments(ctx, "episode", id, URLForXBMC("/show/%s/season/%d/episode/%d/comments", showID, seasonNumber, episodeNumber))
+}
+
+// TraktComment shows full text of a comment, including spoilers
+func TraktComment(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	commentID, _ := strconv.Atoi(ctx.Params.ByName("commentId"))
+	comment, err := trakt.GetComment(commentID)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.DialogText(commentAuthor(comment), spoilerRegex.ReplaceAllString(comment.Comment, "$1"))
+	ctx.String(200, "")
+}
+
+func renderComments(ctx *gin.Context, mediaType string, id string, pageURL string) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+
+	comments, hasNext, err := trakt.GetComments(mediaType, id, page)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+
+	items := make(xbmc.ListItems, 0, len(comments)+1)
+	for _, comment := range comments {
+		label := commentAuthor(comment)
+		if comment.UserStats.Rating > 0 {
+			label += fmt.Sprintf(" [COLOR gold]%d/10[/COLOR]", comment.UserStats.Rating)
+		}
+		if comment.Review {
+			label += " [COLOR skyblue]Review[/COLOR]"
+		}
+		label += fmt.Sprintf(" [COLOR gray](%d likes, %d replies)[/COLOR]", comment.Likes, comment.Replies)
+
+		items = append(items, &xbmc.ListItem{
+			Label:     label,
+			Label2:    comment.CreatedAt.Format("2006-01-02"),
+			Path:      URLForXBMC("/trakt/comment/%d", comment.ID),
+			Thumbnail: config.AddonResource("img", "trakt.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: maskSpoilers(comment),
+				Date: comment.CreatedAt.Format("02.01.2006"),
+			},
+		})
+	}
+
+	if hasNext {
+		items = append(items, &xbmc.ListItem{
+			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
+			Path:      URLQuery(pageURL, "page", strconv.Itoa(page+1)),
+			Thumbnail: config.AddonResource("img", "nextpage.png"),
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+func commentAuthor(comment *trakt.Comment) string {
+	if comment.User == nil {
+		return "Trakt"
+	} else if comment.User.Name != "" && !comment.User.Private {
+		return comment.User.Name
+	}
+	return comment.User.Username
+}
+
+// maskSpoilers hides whole comment, marked as spoiler, or spoiler tags inside of it
+func maskSpoilers(comment *trakt.Comment) string {
+	if comment.Spoiler {
+		return "[COLOR gray][I]This comment contains spoilers, open it to read[/I][/COLOR]"
+	}
+	return spoilerRegex.ReplaceAllString(comment.Comment, "[COLOR gray][I]spoiler[/I][/COLOR]")
+}
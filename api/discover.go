@@ -0,0 +1,284 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// DiscoverPresets lists saved discover presets of movies or shows
+func DiscoverPresets(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	thumbnail := config.AddonResource("img", "movies.png")
+	if media == showType {
+		thumbnail = config.AddonResource("img", "genre_tv.png")
+	}
+
+	items := xbmc.ListItems{}
+	for _, preset := range database.GetStorm().GetDiscoverPresets(media) {
+		items = append(items, &xbmc.ListItem{
+			Label:     preset.Name,
+			Path:      URLForXBMC("/discover/%s/preset/%d", media, preset.ID),
+			Thumbnail: thumbnail,
+			ContextMenu: [][]string{
+				{"LOCALIZE[30722]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/discover/%s/edit/%d", media, preset.ID))},
+				{"LOCALIZE[30723]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/discover/%s/remove/%d", media, preset.ID))},
+			},
+		})
+	}
+	items = append(items, &xbmc.ListItem{
+		Label:     "LOCALIZE[30720]",
+		Path:      URLForXBMC("/discover/%s/add", media),
+		Thumbnail: config.AddonResource("img", "search.png"),
+	})
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// DiscoverPreset lists movies or shows, matching filters of a preset
+func DiscoverPreset(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	id, _ := strconv.Atoi(ctx.Params.ByName("presetId"))
+	preset := database.GetStorm().GetDiscoverPreset(id)
+	if preset == nil {
+		ctx.String(404, "Preset not found")
+		return
+	}
+
+	values, _ := url.ParseQuery(preset.Filters)
+	filters := tmdb.ParseDiscoverFilters(values)
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+
+	if media == showType {
+		shows, total := tmdb.DiscoverShows(filters, config.Get().Language, page)
+		renderShows(ctx, filterNotInterestedShows(shows), page, total, "")
+		return
+	}
+
+	movies, total := tmdb.DiscoverMovies(filters, config.Get().Language, page)
+	renderMovies(ctx, filterNotInterestedMovies(movies), page, total, "")
+}
+
+// AddDiscoverPreset asks for filters and a name of new discover preset
+func AddDiscoverPreset(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	preset := &database.DiscoverPreset{MediaType: ctx.Params.ByName("media")}
+	if !editDiscoverPreset(preset) {
+		ctx.String(200, "")
+		return
+	}
+
+	if err := database.GetStorm().SaveDiscoverPreset(preset); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	ctx.String(200, "")
+	xbmc.Refresh()
+}
+
+// EditDiscoverPreset changes filters or a name of discover preset
+func EditDiscoverPreset(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	id, _ := strconv.Atoi(ctx.Params.ByName("presetId"))
+	preset := database.GetStorm().GetDiscoverPreset(id)
+	if preset == nil || !editDiscoverPreset(preset) {
+		ctx.String(200, "")
+		return
+	}
+
+	if err := database.GetStorm().SaveDiscoverPreset(preset); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	ctx.String(200, "")
+	library.ClearPageCache()
+	xbmc.Refresh()
+}
+
+// RemoveDiscoverPreset removes discover preset
+func RemoveDiscoverPreset(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	id, _ := strconv.Atoi(ctx.Params.ByName("presetId"))
+	if err := database.GetStorm().DeleteDiscoverPreset(id); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	ctx.String(200, "")
+	xbmc.Refresh()
+}
+
+// editDiscoverPreset shows filters of a preset in a dialog, until user saves or cancels changes
+func editDiscoverPreset(preset *database.DiscoverPreset) bool {
+	values, _ := url.ParseQuery(preset.Filters)
+	f := tmdb.ParseDiscoverFilters(values)
+	isMovie := preset.MediaType != showType
+
+	for {
+		options := []string{
+			"LOCALIZE[30724]: " + discoverGenreName(f.Genre, isMovie),
+			"LOCALIZE[30725]: " + f.Country,
+			"LOCALIZE[30726]: " + f.Language,
+			"LOCALIZE[30727]: " + intFilter(f.YearFrom),
+			"LOCALIZE[30728]: " + intFilter(f.YearTo),
+			"LOCALIZE[30729]: " + floatFilter(f.VoteAverageMin),
+			"LOCALIZE[30730]: " + intFilter(f.VoteCountMin),
+			"LOCALIZE[30731]: " + intFilter(f.RuntimeMin),
+			"LOCALIZE[30732]: " + intFilter(f.RuntimeMax),
+			"LOCALIZE[30733]: " + watchProviderName(f.WatchProvider, isMovie),
+			"LOCALIZE[30735]: " + f.Keywords,
+		}
+		if isMovie {
+			options = append(options, "LOCALIZE[30734]: "+f.Certification)
+		}
+		options = append(options, "LOCALIZE[30736]")
+
+		choice := xbmc.ListDialog("LOCALIZE[30719]", options...)
+		switch {
+		case choice < 0:
+			return false
+		case choice == len(options)-1:
+			name := strings.TrimSpace(xbmc.Keyboard(preset.Name, "LOCALIZE[30721]"))
+			if name == "" {
+				return false
+			}
+			preset.Name = name
+			preset.Filters = f.Values().Encode()
+			return true
+		case choice == 0:
+			f.Genre = chooseDiscoverGenre(f.Genre, isMovie)
+		case choice == 1:
+			f.Country = strings.ToUpper(strings.TrimSpace(xbmc.Keyboard(f.Country, "LOCALIZE[30725]")))
+		case choice == 2:
+			f.Language = strings.ToLower(strings.TrimSpace(xbmc.Keyboard(f.Language, "LOCALIZE[30726]")))
+		case choice == 3:
+			f.YearFrom = parseIntFilter(xbmc.Keyboard(intFilter(f.YearFrom), "LOCALIZE[30727]"))
+		case choice == 4:
+			f.YearTo = parseIntFilter(xbmc.Keyboard(intFilter(f.YearTo), "LOCALIZE[30728]"))
+		case choice == 5:
+			f.VoteAverageMin, _ = strconv.ParseFloat(strings.TrimSpace(xbmc.Keyboard(floatFilter(f.VoteAverageMin), "LOCALIZE[30729]")), 64)
+		case choice == 6:
+			f.VoteCountMin = parseIntFilter(xbmc.Keyboard(intFilter(f.VoteCountMin), "LOCALIZE[30730]"))
+		case choice == 7:
+			f.RuntimeMin = parseIntFilter(xbmc.Keyboard(intFilter(f.RuntimeMin), "LOCALIZE[30731]"))
+		case choice == 8:
+			f.RuntimeMax = parseIntFilter(xbmc.Keyboard(intFilter(f.RuntimeMax), "LOCALIZE[30732]"))
+		case choice == 9:
+			f.WatchProvider = chooseWatchProvider(f.WatchProvider, isMovie)
+		case choice == 10:
+			f.Keywords = strings.TrimSpace(xbmc.Keyboard(f.Keywords, "LOCALIZE[30735]"))
+		case choice == 11:
+			f.Certification = strings.TrimSpace(xbmc.Keyboard(f.Certification, "LOCALIZE[30734]"))
+		}
+	}
+}
+
+func discoverGenres(isMovie bool) []*tmdb.Genre {
+	if isMovie {
+		return tmdb.GetMovieGenres(config.Get().Language)
+	}
+	return tmdb.GetTVGenres(config.Get().Language)
+}
+
+func discoverGenreName(id string, isMovie bool) string {
+	if id == "" {
+		return ""
+	}
+	for _, genre := range discoverGenres(isMovie) {
+		if strconv.Itoa(genre.ID) == id {
+			return genre.Name
+		}
+	}
+	return id
+}
+
+func chooseDiscoverGenre(current string, isMovie bool) string {
+	genres := discoverGenres(isMovie)
+	names := make([]string, 0, len(genres)+1)
+	names = append(names, "LOCALIZE[30737]")
+	for _, genre := range genres {
+		names = append(names, genre.Name)
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30724]", names...)
+	if choice < 0 {
+		return current
+	} else if choice == 0 {
+		return ""
+	}
+	return strconv.Itoa(genres[choice-1].ID)
+}
+
+func discoverWatchProviders(isMovie bool) []*tmdb.WatchProvider {
+	mediaType := showType
+	if isMovie {
+		mediaType = movieType
+	}
+	return tmdb.GetWatchProvidersList(mediaType, config.Get().Region)
+}
+
+func watchProviderName(id string, isMovie bool) string {
+	if id == "" {
+		return ""
+	}
+	for _, provider := range discoverWatchProviders(isMovie) {
+		if strconv.Itoa(provider.ProviderID) == id {
+			return provider.ProviderName
+		}
+	}
+	return id
+}
+
+// chooseWatchProvider offers streaming services, available in user region
+func chooseWatchProvider(current string, isMovie bool) string {
+	providers := discoverWatchProviders(isMovie)
+	if len(providers) == 0 {
+		return strings.TrimSpace(xbmc.Keyboard(current, "LOCALIZE[30733]"))
+	}
+
+	names := make([]string, 0, len(providers)+1)
+	names = append(names, "LOCALIZE[30737]")
+	for _, provider := range providers {
+		names = append(names, provider.ProviderName)
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30733]", names...)
+	if choice < 0 {
+		return current
+	} else if choice == 0 {
+		return ""
+	}
+	return strconv.Itoa(providers[choice-1].ProviderID)
+}
+
+func intFilter(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+func floatFilter(value float64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func parseIntFilter(value string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(value))
+	return i
+}
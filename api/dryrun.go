@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// How many ranked torrents to describe in dry-run dialog
+const dryRunTorrentsLimit = 5
+
+// MovieDryRun searches and ranks torrents for a movie and shows which one would be picked,
+// without starting any download.
+func MovieDryRun(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID := ctx.Params.ByName("tmdbId")
+	movie := tmdb.GetMovieByID(tmdbID, config.Get().Language)
+	if movie == nil {
+		ctx.String(404, "")
+		return
+	}
+
+	var torrents []*bittorrent.TorrentFile
+	var err error
+	if torrents, err = GetCachedTorrents(tmdbID); err != nil || len(torrents) == 0 {
+		torrents = movieLinks(tmdbID)
+
+		SetCachedTorrents(tmdbID, torrents)
+	}
+
+	showDryRun(fmt.Sprintf("%s (%d)", movie.Title, movie.Year), torrents, providers.SortMovies)
+	ctx.String(200, "")
+}
+
+// ShowEpisodeDryRun searches and ranks torrents for an episode and shows which one would be picked,
+// without starting any download.
+func ShowEpisodeDryRun(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+	episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+
+	var torrents []*bittorrent.TorrentFile
+	var err error
+
+	fakeTmdbID := strconv.Itoa(showID) + "_" + strconv.Itoa(seasonNumber) + "_" + strconv.Itoa(episodeNumber)
+	if torrents, err = GetCachedTorrents(fakeTmdbID); err != nil || len(torrents) == 0 {
+		if torrents, err = showEpisodeLinks(showID, seasonNumber, episodeNumber); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			ctx.String(404, "")
+			return
+		}
+
+		SetCachedTorrents(fakeTmdbID, torrents)
+	}
+
+	title := fmt.Sprintf("S%02dE%02d", seasonNumber, episodeNumber)
+	if show := tmdb.GetShow(showID, config.Get().Language); show != nil {
+		title = show.Name + " " + title
+	}
+
+	showDryRun(title, torrents, providers.SortShows)
+	ctx.String(200, "")
+}
+
+func showDryRun(title string, torrents []*bittorrent.TorrentFile, sortType int) {
+	text := providers.ExplainRanking(torrents, sortType, dryRunTorrentsLimit)
+	log.Infof("Dry-run play for %s:\n%s", title, text)
+	xbmc.DialogText(fmt.Sprintf("LOCALIZE[30809] %s", title), text)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var (
+	errNoProviders      = errors.New("No providers installed or enabled")
+	errProvidersTimeout = errors.New("All providers timed out")
+)
+
+// failureGuide explains classified failure to the user, suggests a fix
+// and points to diagnostics action, related to the failure.
+type failureGuide struct {
+	Message string
+	Fix     string
+	// Action is a plugin route, opened as a folder, or run in background
+	Action string
+	Folder bool
+}
+
+var failureGuides = []struct {
+	err   error
+	guide failureGuide
+}{
+	{errNoProviders, failureGuide{"LOCALIZE[30700]", "LOCALIZE[30701]", "/provider/", true}},
+	{errProvidersTimeout, failureGuide{"LOCALIZE[30702]", "LOCALIZE[30703]", "/providers/benchmark", false}},
+	{bittorrent.ErrNoSeeds, failureGuide{"LOCALIZE[30704]", "LOCALIZE[30705]", "/status/playback", false}},
+	{bittorrent.ErrNotEnoughSpace, failureGuide{"LOCALIZE[30706]", "LOCALIZE[30707]", "/cmd/open_path/download", false}},
+	{bittorrent.ErrPortBlocked, failureGuide{"LOCALIZE[30708]", "LOCALIZE[30709]", "/status", false}},
+	{trakt.ErrAuthExpired, failureGuide{"LOCALIZE[30710]", "LOCALIZE[30711]", "/trakt/authorize", false}},
+}
+
+// classifyFailure returns guide for known failure, or nil
+func classifyFailure(err error) *failureGuide {
+	if err == nil {
+		return nil
+	}
+
+	for _, f := range failureGuides {
+		if errors.Is(err, f.err) {
+			return &f.guide
+		}
+	}
+	return nil
+}
+
+// notifyFailure shows guidance for classified failure and offers to run diagnostics action.
+// Returns false for unclassified errors, so that caller can show generic notification.
+func notifyFailure(err error) bool {
+	guide := classifyFailure(err)
+	if guide == nil {
+		return false
+	}
+
+	log.Warningf("Classified failure: %s", err)
+	if !xbmc.DialogConfirmFocused("Elementum", guide.Message+";;"+guide.Fix) {
+		return true
+	}
+
+	if guide.Folder {
+		xbmc.UpdatePath(URLForXBMC(guide.Action))
+	} else {
+		go func() {
+			resp, err := http.Get(URLForHTTP(guide.Action))
+			if err != nil {
+				log.Warningf("Could not run diagnostics action %s: %s", guide.Action, err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	return true
+}
+
+// notifyError shows guidance for classified failure, or error text for others
+func notifyError(err error) {
+	if !notifyFailure(err) {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+}
+
+// notifyNoTorrents tells why search has not found any torrent
+func notifyNoTorrents() {
+	if !providers.AllTimedOut() || !notifyFailure(errProvidersTimeout) {
+		xbmc.Notify("Elementum", "LOCALIZE[30205]", config.AddonIcon())
+	}
+}
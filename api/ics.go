@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/util"
+)
+
+// Default length of an episode event, when Trakt does not know the runtime
+const icsDefaultRuntime = 30
+
+// Longest calendar feed, to keep number of Trakt requests reasonable
+const icsMaxDays = 180
+
+// Trakt calendars, that can be exported as a feed
+var icsCalendars = map[string]string{
+	"shows":     "my/shows",
+	"newshows":  "my/shows/new",
+	"premieres": "my/shows/premieres",
+}
+
+// TraktCalendarICS renders Trakt shows calendar of the user as an iCalendar feed,
+// that can be subscribed to from calendar applications
+func TraktCalendarICS(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	endPoint, ok := icsCalendars[ctx.DefaultQuery("calendar", "shows")]
+	if !ok {
+		ctx.String(404, "Unknown calendar")
+		return
+	}
+
+	days := config.Get().TraktCalendarsICSDays
+	if d, err := strconv.Atoi(ctx.Query("days")); err == nil {
+		days = d
+	}
+	if days <= 0 {
+		days = 30
+	} else if days > icsMaxDays {
+		days = icsMaxDays
+	}
+
+	shows, err := trakt.CalendarShowsRange(endPoint, util.UTCBod(), days)
+	if err != nil {
+		log.Warningf("Could not get Trakt calendar for ICS feed: %s", err)
+		if len(shows) == 0 {
+			ctx.String(500, err.Error())
+			return
+		}
+	}
+
+	ctx.Header("Content-Disposition", "inline; filename=elementum.ics")
+	ctx.Data(200, "text/calendar; charset=utf-8", renderICS(shows))
+}
+
+func renderICS(shows []*trakt.CalendarShow) []byte {
+	now := time.Now().UTC().Format("20060102T150405Z")
+
+	buf := &bytes.Buffer{}
+	writeICSLine(buf, "BEGIN:VCALENDAR")
+	writeICSLine(buf, "VERSION:2.0")
+	writeICSLine(buf, "PRODID:-//Elementum//Trakt calendar//EN")
+	writeICSLine(buf, "CALSCALE:GREGORIAN")
+	writeICSLine(buf, "METHOD:PUBLISH")
+	writeICSLine(buf, "X-WR-CALNAME:Elementum")
+
+	for _, s := range shows {
+		if s == nil || s.Show == nil || s.Show.IDs == nil || s.Episode == nil {
+			continue
+		}
+
+		aired, err := time.Parse(time.RFC3339, s.FirstAired)
+		if err != nil {
+			continue
+		}
+		runtime := s.Episode.Runtime
+		if runtime == 0 {
+			runtime = s.Show.Runtime
+		}
+		if runtime == 0 {
+			runtime = icsDefaultRuntime
+		}
+
+		uid := fmt.Sprintf("%d-%d-%d", s.Show.IDs.Trakt, s.Episode.Season, s.Episode.Number)
+		if s.Episode.IDs != nil && s.Episode.IDs.Trakt != 0 {
+			uid = strconv.Itoa(s.Episode.IDs.Trakt)
+		}
+
+		summary := fmt.Sprintf("%s %dx%02d", s.Show.Title, s.Episode.Season, s.Episode.Number)
+		if s.Episode.Title != "" {
+			summary += " " + s.Episode.Title
+		}
+		description := s.Episode.Overview
+		if s.Show.Network != "" {
+			description = strings.TrimSpace(s.Show.Network + "\n\n" + description)
+		}
+
+		writeICSLine(buf, "BEGIN:VEVENT")
+		writeICSLine(buf, "UID:"+uid+"@elementum.trakt")
+		writeICSLine(buf, "DTSTAMP:"+now)
+		writeICSLine(buf, "DTSTART:"+aired.UTC().Format("20060102T150405Z"))
+		writeICSLine(buf, "DTEND:"+aired.UTC().Add(time.Duration(runtime)*time.Minute).Format("20060102T150405Z"))
+		writeICSLine(buf, "SUMMARY:"+escapeICS(summary))
+		if description != "" {
+			writeICSLine(buf, "DESCRIPTION:"+escapeICS(description))
+		}
+		if s.Show.IDs.Slug != "" {
+			writeICSLine(buf, "URL:https://trakt.tv/shows/"+s.Show.IDs.Slug)
+		}
+		writeICSLine(buf, "END:VEVENT")
+	}
+
+	writeICSLine(buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeICSLine writes a content line, folded to 75 octets, as required by RFC 5545
+func writeICSLine(buf *bytes.Buffer, line string) {
+	// Continuation lines start with a space, that counts towards the limit
+	limit := 75
+
+	for len(line) > limit {
+		cut := limit
+		// Folding should not split multi-byte characters
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func escapeICS(s string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	).Replace(s)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/anacrolix/missinggo/perf"
@@ -26,27 +27,47 @@ func Index(s *bittorrent.Service) gin.HandlerFunc {
 
 		defer perf.ScopeTimer()()
 
-		li := xbmc.ListItems{
-			{Label: "LOCALIZE[30214]", Path: URLForXBMC("/movies/"), Thumbnail: config.AddonResource("img", "movies.png")},
-			{Label: "LOCALIZE[30215]", Path: URLForXBMC("/shows/"), Thumbnail: config.AddonResource("img", "tv.png")},
-			{Label: "LOCALIZE[30209]", Path: URLForXBMC("/search"), Thumbnail: config.AddonResource("img", "search.png")},
-			{Label: "LOCALIZE[30229]", Path: URLForXBMC("/torrents/"), Thumbnail: config.AddonResource("img", "cloud.png")},
-			{Label: "LOCALIZE[30216]", Path: URLForXBMC("/playtorrent"), Thumbnail: config.AddonResource("img", "magnet.png")},
-			{Label: "LOCALIZE[30537]", Path: URLForXBMC("/history"), Thumbnail: config.AddonResource("img", "clock.png")},
-			{Label: "LOCALIZE[30239]", Path: URLForXBMC("/provider/"), Thumbnail: config.AddonResource("img", "shield.png")},
-			{Label: "LOCALIZE[30355]", Path: URLForXBMC("/changelog"), Thumbnail: config.AddonResource("img", "faq8.png")},
-			{Label: "LOCALIZE[30393]", Path: URLForXBMC("/status"), Thumbnail: config.AddonResource("img", "clock.png")},
-			{Label: "LOCALIZE[30527]", Path: URLForXBMC("/donate"), Thumbnail: config.AddonResource("img", "faq8.png")},
-			{Label: "LOCALIZE[30579]", Path: URLForXBMC("/settings/plugin.video.elementum"), Thumbnail: config.AddonResource("img", "settings.png")},
+		if config.Get().KidsMode && !isKidsUnlocked() {
+			KidsIndex(ctx)
+			return
 		}
 
-		// Adding Settings urls for each search provider found locally.
-		for _, addon := range getProviders() {
-			name := strings.Title(strings.ReplaceAll(addon.Name, "script.elementum.", ""))
+		renderIndex(ctx)
+	}
+}
 
-			li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30582];;" + name, Path: URLForXBMC("/settings/" + addon.ID), Thumbnail: config.AddonResource("img", "settings.png")})
-		}
+func renderIndex(ctx *gin.Context) {
+	li := xbmc.ListItems{
+		{Label: "LOCALIZE[30214]", Path: URLForXBMC("/movies/"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "LOCALIZE[30215]", Path: URLForXBMC("/shows/"), Thumbnail: config.AddonResource("img", "tv.png")},
+		{Label: "LOCALIZE[30209]", Path: URLForXBMC("/search"), Thumbnail: config.AddonResource("img", "search.png")},
+		{Label: "LOCALIZE[30821]", Path: URLForXBMC("/everywhere/search"), Thumbnail: config.AddonResource("img", "search.png")},
+		{Label: "LOCALIZE[30229]", Path: URLForXBMC("/torrents/"), Thumbnail: config.AddonResource("img", "cloud.png")},
+		{Label: "LOCALIZE[30216]", Path: URLForXBMC("/playtorrent"), Thumbnail: config.AddonResource("img", "magnet.png")},
+		{Label: "LOCALIZE[30738]", Path: URLForXBMC("/rss/"), Thumbnail: config.AddonResource("img", "cloud.png")},
+		{Label: "LOCALIZE[30537]", Path: URLForXBMC("/history"), Thumbnail: config.AddonResource("img", "clock.png")},
+		{Label: "LOCALIZE[30822]", Path: URLForXBMC("/library/journal"), Thumbnail: config.AddonResource("img", "clock.png")},
+		{Label: "Trakt > LOCALIZE[30770]", Path: URLForXBMC("/streaming/changes"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30361]", Path: URLForXBMC("/trakt/history"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "LOCALIZE[30239]", Path: URLForXBMC("/provider/"), Thumbnail: config.AddonResource("img", "shield.png")},
+		{Label: "LOCALIZE[30355]", Path: URLForXBMC("/changelog"), Thumbnail: config.AddonResource("img", "faq8.png")},
+		{Label: "LOCALIZE[30393]", Path: URLForXBMC("/status"), Thumbnail: config.AddonResource("img", "clock.png"), ContextMenu: [][]string{
+			{"LOCALIZE[30823]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/status/playback"))},
+		}},
+		{Label: "LOCALIZE[30527]", Path: URLForXBMC("/donate"), Thumbnail: config.AddonResource("img", "faq8.png")},
+		{Label: "LOCALIZE[30783]", Path: URLForXBMC("/notifications/"), Thumbnail: config.AddonResource("img", "settings.png")},
+		{Label: "LOCALIZE[30579]", Path: URLForXBMC("/settings/plugin.video.elementum"), Thumbnail: config.AddonResource("img", "settings.png")},
+	}
+
+	// Seasonal collections are shown right after main sections, only during their dates
+	li = append(li[:2], append(seasonalMenuItems(), li[2:]...)...)
 
-		ctx.JSON(200, xbmc.NewView("", li))
+	// Adding Settings urls for each search provider found locally.
+	for _, addon := range getProviders() {
+		name := strings.Title(strings.ReplaceAll(addon.Name, "script.elementum.", ""))
+
+		li = append(li, &xbmc.ListItem{Label: "LOCALIZE[30582];;" + name, Path: URLForXBMC("/settings/" + addon.ID), Thumbnail: config.AddonResource("img", "settings.png")})
 	}
+
+	ctx.JSON(200, xbmc.NewView("", filterListItems(li)))
 }
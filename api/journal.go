@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// LibraryJournal lists recorded library mutations with their reasons, newest first.
+// Entries of a single movie or show are listed, if "tmdb" query parameter is set.
+func LibraryJournal(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, _ := strconv.Atoi(ctx.Query("tmdb"))
+
+	entries := library.JournalEntries(tmdbID)
+	items := make(xbmc.ListItems, 0, len(entries))
+	for _, e := range entries {
+		label := e.Action
+		if e.Title != "" {
+			label += ": " + e.Title
+		}
+
+		path := URLForXBMC("/library/journal")
+		if e.TMDBID != 0 && tmdbID == 0 {
+			path = URLQuery(path, "tmdb", strconv.Itoa(e.TMDBID))
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     fmt.Sprintf("[%s] %s", e.Time.Format("2006-01-02 15:04"), label),
+			Label2:    e.Reason,
+			Path:      path,
+			Thumbnail: config.AddonResource("img", "clock.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: fmt.Sprintf("%s\n%s", label, e.Reason),
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("", items))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// How long restricted routes stay available after entering the PIN
+const kidsUnlockDuration = 15 * time.Minute
+
+// Default genres for kids mode: Animation, Family
+const kidsDefaultGenres = "16,10751"
+
+// Routes, requiring PIN in kids mode
+var kidsLockedPrefixes = []string{
+	"/search",
+	"/everywhere/",
+	"/settings/",
+	"/playtorrent",
+	"/provider/",
+	"/providers/",
+	"/history",
+	"/movies/",
+	"/shows/",
+	"/tags/",
+	"/selection/",
+	"/trakt/",
+	"/library/",
+}
+
+var kidsUnlocked = struct {
+	sync.Mutex
+	until time.Time
+}{}
+
+// KidsModeFilter protects search, settings and general browsing routes with a PIN,
+// when kids mode is enabled. Allowed Trakt lists are available without PIN.
+func KidsModeFilter() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !config.Get().KidsMode || !isKidsLocked(ctx.Request.URL.Path) || isKidsUnlocked() {
+			return
+		}
+
+		if !unlockKidsMode() {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+}
+
+func isKidsLocked(path string) bool {
+	for _, list := range config.Get().KidsLists {
+		if hasPathPrefix(path, "/movies/trakt/lists/"+list) || hasPathPrefix(path, "/shows/trakt/lists/"+list) {
+			return false
+		}
+	}
+
+	for _, prefix := range kidsLockedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix checks that path starts with prefix, that ends at a path segment boundary,
+// so that allowed list "123" does not allow list "1234"
+func hasPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func isKidsUnlocked() bool {
+	kidsUnlocked.Lock()
+	defer kidsUnlocked.Unlock()
+
+	return time.Now().Before(kidsUnlocked.until)
+}
+
+// unlockKidsMode asks for a PIN and unlocks restricted routes for a while
+func unlockKidsMode() bool {
+	pin := config.Get().KidsModePIN
+	if pin == "" || xbmc.Keyboard("", "LOCALIZE[30801]", true) != pin {
+		xbmc.Notify("Elementum", "LOCALIZE[30802]", config.AddonIcon())
+		return false
+	}
+
+	kidsUnlocked.Lock()
+	kidsUnlocked.until = time.Now().Add(kidsUnlockDuration)
+	kidsUnlocked.Unlock()
+	return true
+}
+
+// KidsIndex is a root menu for kids mode
+func KidsIndex(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{
+		{Label: "LOCALIZE[30214]", Path: URLForXBMC("/kids/movies"), Thumbnail: config.AddonResource("img", "genre_animation.png")},
+		{Label: "LOCALIZE[30215]", Path: URLForXBMC("/kids/shows"), Thumbnail: config.AddonResource("img", "genre_family.png")},
+	}
+
+	for _, list := range config.Get().KidsLists {
+		name := list
+		if i := strings.Index(list, "/"); i >= 0 {
+			name = list[:i]
+		}
+		items = append(items, &xbmc.ListItem{
+			Label:     "Trakt > " + name,
+			Path:      URLForXBMC("/movies/trakt/lists/%s", list),
+			Thumbnail: config.AddonResource("img", "trakt.png"),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30215]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/shows/trakt/lists/%s", list))},
+			},
+		})
+	}
+
+	items = append(items, &xbmc.ListItem{Label: "LOCALIZE[30803]", Path: URLForXBMC("/kids/unlock"), Thumbnail: config.AddonResource("img", "settings.png")})
+
+	ctx.JSON(200, xbmc.NewView("", filterListItems(items)))
+}
+
+// KidsUnlock shows full root menu after entering the PIN
+func KidsUnlock(ctx *gin.Context) {
+	if !isKidsUnlocked() && !unlockKidsMode() {
+		return
+	}
+
+	renderIndex(ctx)
+}
+
+// KidsMovies lists popular movies of allowed genres and certification
+func KidsMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.KidsMovies(kidsGenres(), config.Get().KidsCertification, config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
+
+// KidsShows lists popular shows of allowed genres
+func KidsShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.KidsShows(kidsGenres(), config.Get().Language, page)
+	renderShows(ctx, shows, page, total, "")
+}
+
+func kidsGenres() string {
+	if genres := strings.TrimSpace(config.Get().KidsGenres); genres != "" {
+		return genres
+	}
+	return kidsDefaultGenres
+}
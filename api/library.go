@@ -42,7 +42,7 @@ func AddMovie(ctx *gin.Context) {
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
 
-	movie, err := library.AddMovie(tmdbID, force)
+	movie, err := library.AddMovie(tmdbID, force, library.ReasonUser)
 	if err != nil {
 		ctx.String(200, err.Error())
 		return
@@ -60,7 +60,7 @@ func AddMovie(ctx *gin.Context) {
 
 	log.Noticef(logMsg, movie.Title, tmdbID)
 	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("%s;;%s", label, movie.Title))) {
-		xbmc.VideoLibraryScanDirectory(library.MoviesLibraryPath(), true)
+		library.PlanKodiUpdate()
 	} else {
 		if ctx != nil {
 			ctx.Abort()
@@ -90,7 +90,7 @@ func RemoveMovie(ctx *gin.Context) {
 
 	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
 	tmdbStr := ctx.Params.ByName("tmdbId")
-	movie, err := library.RemoveMovie(tmdbID)
+	movie, err := library.RemoveMovie(tmdbID, library.ReasonUser)
 	if err != nil {
 		ctx.String(200, err.Error())
 	}
@@ -100,6 +100,7 @@ func RemoveMovie(ctx *gin.Context) {
 
 	if ctx != nil {
 		if movie != nil && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", movie.Title)) {
+			library.RecordKodiUpdate(library.JournalKodiClean, library.ReasonUser)
 			xbmc.VideoLibraryClean()
 		} else {
 			ctx.Abort()
@@ -120,7 +121,7 @@ func AddShow(ctx *gin.Context) {
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
 
-	show, err := library.AddShow(tmdbID, force)
+	show, err := library.AddShow(tmdbID, force, library.ReasonUser)
 	if err != nil {
 		ctx.String(200, err.Error())
 		return
@@ -138,7 +139,7 @@ func AddShow(ctx *gin.Context) {
 
 	log.Noticef(logMsg, show.Name, tmdbID)
 	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("%s;;%s", label, show.Name))) {
-		xbmc.VideoLibraryScanDirectory(library.ShowsLibraryPath(), true)
+		library.PlanKodiUpdate()
 	} else {
 		library.ClearPageCache()
 	}
@@ -164,7 +165,7 @@ func RemoveShow(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	tmdbID := ctx.Params.ByName("tmdbId")
-	show, err := library.RemoveShow(tmdbID)
+	show, err := library.RemoveShow(tmdbID, library.ReasonUser)
 	if err != nil {
 		ctx.String(200, err.Error())
 	}
@@ -174,6 +175,7 @@ func RemoveShow(ctx *gin.Context) {
 
 	if ctx != nil {
 		if show != nil && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", show.Name)) {
+			library.RecordKodiUpdate(library.JournalKodiClean, library.ReasonUser)
 			xbmc.VideoLibraryClean()
 		} else {
 			ctx.Abort()
@@ -189,6 +191,7 @@ func UpdateLibrary(ctx *gin.Context) {
 		ctx.String(200, err.Error())
 	}
 	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", "LOCALIZE[30288]")) {
+		library.RecordKodiUpdate(library.JournalKodiScan, library.ReasonUser)
 		xbmc.VideoLibraryScan()
 	}
 }
@@ -201,6 +204,7 @@ func UpdateTrakt(ctx *gin.Context) {
 		library.IsTraktInitialized = false
 		library.RefreshTrakt()
 		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", "LOCALIZE[30288]")) {
+			library.RecordKodiUpdate(library.JournalKodiScan, "Trakt sync requested by user")
 			xbmc.VideoLibraryScan()
 		}
 	}()
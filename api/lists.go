@@ -0,0 +1,347 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var listPrivacies = []string{trakt.ListPrivate, trakt.ListFriends, trakt.ListPublic}
+
+// CreateTraktList asks for a name and privacy and creates new Trakt list
+func CreateTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	name := strings.TrimSpace(xbmc.Keyboard("", "LOCALIZE[30827]"))
+	if name == "" {
+		return
+	}
+
+	privacy := chooseListPrivacy()
+	if privacy == "" {
+		return
+	}
+
+	if _, err := trakt.CreateList(name, privacy); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30828]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// RenameTraktList asks for a new name of Trakt list
+func RenameTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+	name := strings.TrimSpace(xbmc.Keyboard(ctx.Query("name"), "LOCALIZE[30827]"))
+	if name == "" || name == ctx.Query("name") {
+		return
+	}
+
+	if err := trakt.UpdateList(listID, name, ""); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30829]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// ChangeTraktListPrivacy asks for a new privacy of Trakt list
+func ChangeTraktListPrivacy(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+	privacy := chooseListPrivacy()
+	if privacy == "" {
+		return
+	}
+
+	if err := trakt.UpdateList(listID, "", privacy); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30839];;%s", privacy), config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// DeleteTraktList removes Trakt list after confirmation
+func DeleteTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+	if !xbmc.DialogConfirm("Elementum", fmt.Sprintf("LOCALIZE[30840];;%s", ctx.Query("name"))) {
+		return
+	}
+
+	if err := trakt.DeleteList(listID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30830]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// ReorderTraktList asks for an item of Trakt list and moves it to a new position
+func ReorderTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+	items, err := trakt.ListItems(listID)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	} else if len(items) < 2 {
+		return
+	}
+
+	labels := make([]string, 0, len(items))
+	for i, item := range items {
+		title := ""
+		if item.Movie != nil {
+			title = fmt.Sprintf("%s (%d)", item.Movie.Title, item.Movie.Year)
+		} else if item.Show != nil {
+			title = fmt.Sprintf("%s (%d)", item.Show.Title, item.Show.Year)
+		}
+		labels = append(labels, fmt.Sprintf("%d. %s", i+1, title))
+	}
+
+	from := xbmc.ListDialog("LOCALIZE[30831]", labels...)
+	if from < 0 {
+		return
+	}
+
+	to := xbmc.ListDialog("LOCALIZE[30832]", labels...)
+	if to < 0 || to == from {
+		return
+	}
+
+	moved := items[from]
+	items = append(items[:from], items[from+1:]...)
+	items = append(items[:to], append([]*trakt.ListItem{moved}, items[to:]...)...)
+
+	rank := make([]int, 0, len(items))
+	for _, item := range items {
+		rank = append(rank, item.ID)
+	}
+
+	if err := trakt.ReorderListItems(listID, rank); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30833]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// SortTraktList overrides sort order of Trakt list, or returns sort order, configured in Trakt
+func SortTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+
+	labels := []string{"LOCALIZE[30794]"}
+	for _, field := range trakt.ListSortFields {
+		labels = append(labels, fmt.Sprintf("LOCALIZE[30841];;%s", field), fmt.Sprintf("LOCALIZE[30842];;%s", field))
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30835]", labels...)
+	if choice < 0 {
+		return
+	}
+
+	sortBy, sortHow := "", ""
+	if choice > 0 {
+		sortBy = trakt.ListSortFields[(choice-1)/2]
+		sortHow = "asc"
+		if (choice-1)%2 == 1 {
+			sortHow = "desc"
+		}
+	}
+
+	if err := database.GetStorm().SetListSort(listID, sortBy, sortHow); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	trakt.ClearListCache(listID)
+	xbmc.Notify("Elementum", "LOCALIZE[30836]", config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+func chooseListPrivacy() string {
+	choice := xbmc.ListDialog("LOCALIZE[30834]", listPrivacies...)
+	if choice < 0 {
+		return ""
+	}
+	return listPrivacies[choice]
+}
+
+// traktListActions returns context menu entries for managing Trakt list,
+// editing is only allowed for lists of current user.
+func traktListActions(list *trakt.List) [][]string {
+	ret := [][]string{
+		{"LOCALIZE[30838]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/lists/create"))},
+		{"LOCALIZE[30837]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/list/%d/sort", list.IDs.Trakt))},
+	}
+	if list.User == nil || (!strings.EqualFold(list.User.Username, config.Get().TraktUsername) && !strings.EqualFold(list.User.Ids.Slug, config.Get().TraktUsername)) {
+		return ret
+	}
+
+	return append(ret,
+		[]string{"LOCALIZE[30790]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/rename", list.IDs.Trakt), "name", list.Name))},
+		[]string{"LOCALIZE[30791]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/list/%d/privacy", list.IDs.Trakt))},
+		[]string{"LOCALIZE[30792]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/list/%d/reorder", list.IDs.Trakt))},
+		[]string{"LOCALIZE[30793]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/delete", list.IDs.Trakt), "name", list.Name))},
+	)
+}
+
+// SearchTraktLists searches public Trakt lists and shows found lists
+func SearchTraktLists(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	query := ctx.Query("q")
+	if query == "" {
+		if query = xbmc.Keyboard("", "LOCALIZE[30206]"); len(query) == 0 {
+			return
+		}
+	}
+
+	pageParam := ctx.DefaultQuery("page", "1")
+	page, _ := strconv.Atoi(pageParam)
+
+	lists, hasNextPage, err := trakt.SearchLists(query, pageParam)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+
+	liked := map[int]bool{}
+	if config.Get().TraktToken != "" {
+		for _, l := range trakt.Likedlists() {
+			liked[l.IDs.Trakt] = true
+		}
+	}
+
+	items := xbmc.ListItems{}
+	for _, l := range lists {
+		if l.List == nil || l.List.User == nil || l.List.IDs == nil {
+			continue
+		}
+
+		list := l.List
+		user := list.User.Ids.Slug
+		item := &xbmc.ListItem{
+			Label:     fmt.Sprintf("%s [COLOR gray](%s, %d)[/COLOR]", list.Name, list.User.Username, list.ItemCount),
+			Path:      URLForXBMC("/movies/trakt/lists/%s/%d", user, list.IDs.Trakt),
+			Thumbnail: config.AddonResource("img", "trakt.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: list.Description,
+			},
+			ContextMenu: [][]string{
+				{"LOCALIZE[30799]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/shows/trakt/lists/%s/%d", user, list.IDs.Trakt))},
+				{"LOCALIZE[30837]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/list/%d/sort", list.IDs.Trakt))},
+			},
+		}
+
+		if config.Get().TraktToken != "" {
+			likeAction := []string{"LOCALIZE[30795]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/like", list.IDs.Trakt), "user", user))}
+			if liked[list.IDs.Trakt] {
+				likeAction = []string{"LOCALIZE[30796]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/unlike", list.IDs.Trakt), "user", user))}
+			}
+
+			syncAction := []string{"LOCALIZE[30797]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/sync", list.IDs.Trakt), "user", user, "name", list.Name))}
+			if database.GetStorm().IsSyncedList(list.IDs.Trakt) {
+				syncAction = []string{"LOCALIZE[30798]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/trakt/list/%d/sync", list.IDs.Trakt), "user", user, "name", list.Name))}
+			}
+
+			item.ContextMenu = append(item.ContextMenu, likeAction, syncAction)
+		}
+		items = append(items, item)
+	}
+
+	if hasNextPage {
+		next := &xbmc.ListItem{
+			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
+			Path:      URLQuery(URLForXBMC("/trakt/lists/search"), "q", query, "page", strconv.Itoa(page+1)),
+			Thumbnail: config.AddonResource("img", "nextpage.png"),
+		}
+		items = append(items, next)
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// LikeTraktList likes or unlikes Trakt list of any user
+func LikeTraktList(like bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+		user := ctx.Query("user")
+
+		var err error
+		message := "LOCALIZE[30843]"
+		if like {
+			err = trakt.LikeList(user, listID)
+		} else {
+			err = trakt.UnlikeList(user, listID)
+			message = "LOCALIZE[30844]"
+		}
+
+		if err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		xbmc.Notify("Elementum", message, config.AddonIcon())
+		ctx.Abort()
+		library.ClearPageCache()
+	}
+}
+
+// SyncTraktList enrolls Trakt list of any user into library sync, or removes it from sync
+func SyncTraktList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	listID, _ := strconv.Atoi(ctx.Params.ByName("listId"))
+	user := ctx.Query("user")
+	enabled := !database.GetStorm().IsSyncedList(listID)
+
+	if err := database.GetStorm().SetSyncedList(listID, user, ctx.Query("name"), enabled); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if enabled {
+		xbmc.Notify("Elementum", "LOCALIZE[30845]", config.AddonIcon())
+
+		id := fmt.Sprintf("%s/%d", user, listID)
+		library.SyncMoviesList(id, false, true)
+		library.SyncShowsList(id, false, true)
+	} else {
+		xbmc.Notify("Elementum", "LOCALIZE[30846]", config.AddonIcon())
+	}
+
+	ctx.Abort()
+	library.ClearPageCache()
+}
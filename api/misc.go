@@ -6,15 +6,18 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/asdine/storm/q"
 	"github.com/dustin/go-humanize"
 	"github.com/gin-gonic/gin"
 
+	"github.com/elgatito/elementum/bittorrent"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/placeholder"
 	"github.com/elgatito/elementum/proxy"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/util"
@@ -59,6 +62,55 @@ func Settings(ctx *gin.Context) {
 	ctx.String(200, "")
 }
 
+// MeteredMode shows or changes metered network mode, is suitable for automation scripts
+func MeteredMode(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		enabled := config.Get().MeteredMode
+		switch ctx.Params.ByName("state") {
+		case "":
+		case "on":
+			enabled = true
+		case "off":
+			enabled = false
+		case "toggle":
+			enabled = !enabled
+		default:
+			ctx.String(404, "Unknown metered mode state")
+			return
+		}
+
+		if enabled != config.Get().MeteredMode {
+			log.Infof("Setting metered mode to %t", enabled)
+			s.SetMeteredMode(enabled)
+			xbmc.SetSetting("metered_mode", strconv.FormatBool(enabled))
+		}
+
+		ctx.JSON(200, map[string]bool{"metered": enabled})
+	}
+}
+
+// Placeholder serves generated artwork for items without images
+func Placeholder(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	title := ctx.Query("title")
+	if title == "" {
+		ctx.String(404, "Title is required")
+		return
+	}
+
+	b, err := placeholder.Get(ctx.Params.ByName("kind"), title)
+	if err != nil {
+		ctx.String(404, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=604800")
+	ctx.Data(200, "image/png", b)
+}
+
 // Status display
 func Status(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -131,6 +183,26 @@ func Status(ctx *gin.Context) {
 	ctx.String(200, "")
 }
 
+// PlaybackTiming shows timing of playback steps for the last started item
+func PlaybackTiming(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	p := bittorrent.LastPlaybackTiming()
+	if p == nil {
+		xbmc.Notify("Elementum", "No playback started yet", config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	text := p.String()
+	if slowest := p.Slowest(); slowest != nil {
+		text += fmt.Sprintf("\n\nSlowest step: [B]%s[/B] (%.2fs)", slowest.Name, slowest.Duration.Seconds())
+	}
+
+	xbmc.DialogText("LOCALIZE[30823]", text)
+	ctx.String(200, "")
+}
+
 func fileSize(path string) string {
 	fi, err := os.Stat(path)
 	if err != nil {
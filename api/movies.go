@@ -17,6 +17,7 @@ import (
 	"github.com/elgatito/elementum/scrape"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -57,35 +58,45 @@ var genreSlugs = map[int]string{
 func MoviesIndex(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
+	country := countryName()
 	items := xbmc.ListItems{
 		{Label: "LOCALIZE[30209]", Path: URLForXBMC("/movies/search"), Thumbnail: config.AddonResource("img", "search.png")},
-		{Label: "Trakt > LOCALIZE[30263]", Path: URLForXBMC("/movies/trakt/lists/"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30263]", Path: URLForXBMC("/movies/trakt/lists/"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30838]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/lists/create"))}}, TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30800]", Path: URLForXBMC("/trakt/lists/search"), Thumbnail: config.AddonResource("img", "trakt.png")},
 		{Label: "Trakt > LOCALIZE[30254]", Path: URLForXBMC("/movies/trakt/watchlist"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30252]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/movie/list/add/watchlist"))}}, TraktAuth: true},
 		{Label: "Trakt > LOCALIZE[30257]", Path: URLForXBMC("/movies/trakt/collection"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30252]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/movie/list/add/collection"))}}, TraktAuth: true},
-		{Label: "Trakt > LOCALIZE[30290]", Path: URLForXBMC("/movies/trakt/calendars/"), Thumbnail: config.AddonResource("img", "most_anticipated.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30882]", Path: URLForXBMC("/movies/trakt/ratings"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30290]", Path: URLForXBMC("/movies/trakt/calendars/"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
 		{Label: "Trakt > LOCALIZE[30423]", Path: URLForXBMC("/movies/trakt/recommendations"), Thumbnail: config.AddonResource("img", "movies.png"), TraktAuth: true},
 		{Label: "LOCALIZE[30558]", Path: URLForXBMC("/movies/autoscraped"), Thumbnail: config.AddonResource("img", "trending.png")},
 		{Label: "Trakt > LOCALIZE[30422]", Path: URLForXBMC("/movies/trakt/toplists"), Thumbnail: config.AddonResource("img", "most_collected.png")},
 		{Label: "Trakt > LOCALIZE[30246]", Path: URLForXBMC("/movies/trakt/trending"), Thumbnail: config.AddonResource("img", "trending.png")},
 		{Label: "Trakt > LOCALIZE[30210]", Path: URLForXBMC("/movies/trakt/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
-		{Label: "Trakt > LOCALIZE[30247]", Path: URLForXBMC("/movies/trakt/played"), Thumbnail: config.AddonResource("img", "most_played.png")},
-		{Label: "Trakt > LOCALIZE[30248]", Path: URLForXBMC("/movies/trakt/watched"), Thumbnail: config.AddonResource("img", "most_watched.png")},
-		{Label: "Trakt > LOCALIZE[30249]", Path: URLForXBMC("/movies/trakt/collected"), Thumbnail: config.AddonResource("img", "most_collected.png")},
+		{Label: fmt.Sprintf("Trakt > LOCALIZE[30787] %s", country), Path: URLForXBMC("/movies/trakt/popular/local"), Thumbnail: config.AddonResource("img", "popular.png")},
+		{Label: "Trakt > LOCALIZE[30289]", Path: URLForXBMC("/movies/trakt/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
+		{Label: "Trakt > LOCALIZE[30247]", Path: URLForXBMC("/movies/trakt/periods/played"), Thumbnail: config.AddonResource("img", "most_played.png")},
+		{Label: "Trakt > LOCALIZE[30248]", Path: URLForXBMC("/movies/trakt/periods/watched"), Thumbnail: config.AddonResource("img", "most_watched.png")},
+		{Label: "Trakt > LOCALIZE[30249]", Path: URLForXBMC("/movies/trakt/periods/collected"), Thumbnail: config.AddonResource("img", "most_collected.png")},
 		{Label: "Trakt > LOCALIZE[30250]", Path: URLForXBMC("/movies/trakt/anticipated"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
+		{Label: "Trakt > LOCALIZE[30250] LOCALIZE[30936]", Path: URLForXBMC("/movies/trakt/anticipated/release"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
 		{Label: "Trakt > LOCALIZE[30251]", Path: URLForXBMC("/movies/trakt/boxoffice"), Thumbnail: config.AddonResource("img", "box_office.png")},
 
 		{Label: "TMDB > LOCALIZE[30210]", Path: URLForXBMC("/movies/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
+		{Label: fmt.Sprintf("TMDB > LOCALIZE[30787] %s", country), Path: URLForXBMC("/movies/popular/local"), Thumbnail: config.AddonResource("img", "popular.png")},
 		{Label: "TMDB > LOCALIZE[30211]", Path: URLForXBMC("/movies/top"), Thumbnail: config.AddonResource("img", "top_rated.png")},
 		{Label: "TMDB > LOCALIZE[30212]", Path: URLForXBMC("/movies/mostvoted"), Thumbnail: config.AddonResource("img", "most_voted.png")},
 		{Label: "TMDB > LOCALIZE[30236]", Path: URLForXBMC("/movies/recent"), Thumbnail: config.AddonResource("img", "clock.png")},
 		{Label: "TMDB > LOCALIZE[30213]", Path: URLForXBMC("/movies/imdb250"), Thumbnail: config.AddonResource("img", "imdb.png")},
 		{Label: "TMDB > LOCALIZE[30289]", Path: URLForXBMC("/movies/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
+		{Label: "TMDB > LOCALIZE[30784]", Path: URLForXBMC("/movies/keywords"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
 		{Label: "TMDB > LOCALIZE[30373]", Path: URLForXBMC("/movies/languages"), Thumbnail: config.AddonResource("img", "movies.png")},
 		{Label: "TMDB > LOCALIZE[30374]", Path: URLForXBMC("/movies/countries"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "TMDB > LOCALIZE[30719]", Path: URLForXBMC("/discover/movie"), Thumbnail: config.AddonResource("img", "movies.png")},
 
 		{Label: "Trakt > LOCALIZE[30361]", Path: URLForXBMC("/movies/trakt/history"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
 
 		{Label: "LOCALIZE[30517]", Path: URLForXBMC("/movies/library"), Thumbnail: config.AddonResource("img", "movies.png")},
+		{Label: "LOCALIZE[30912]", Path: URLForXBMC("/tags/movie"), Thumbnail: config.AddonResource("img", "movies.png")},
 	}
 	for _, item := range items {
 		item.ContextMenu = append([][]string{
@@ -130,6 +141,31 @@ func MovieGenres(ctx *gin.Context) {
 	ctx.JSON(200, xbmc.NewView("menus_movies_genres", filterListItems(items)))
 }
 
+// MovieKeywords lists popular TMDB keywords to browse movies by
+func MovieKeywords(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0, len(tmdb.PopularKeywords))
+	for _, keyword := range tmdb.PopularKeywords {
+		items = append(items, &xbmc.ListItem{
+			Label:     keyword.Name,
+			Path:      URLForXBMC("/movies/keyword/%d", keyword.ID),
+			Thumbnail: config.AddonResource("img", "genre_comedy.png"),
+		})
+	}
+	ctx.JSON(200, xbmc.NewView("menus_movies_genres", filterListItems(items)))
+}
+
+// KeywordMovies shows popular movies of a TMDB keyword
+func KeywordMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	keywordID, _ := strconv.Atoi(ctx.Params.ByName("keywordId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.MoviesByKeyword(keywordID, config.Get().Language, page)
+	renderMovies(ctx, filterNotInterestedMovies(movies), page, total, "")
+}
+
 // MovieLanguages ...
 func MovieLanguages(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -256,9 +292,9 @@ func MoviesTraktLists(ctx *gin.Context) {
 			Label:     list.Name,
 			Path:      link,
 			Thumbnail: config.AddonResource("img", "trakt.png"),
-			ContextMenu: [][]string{
+			ContextMenu: append([][]string{
 				menuItem,
-			},
+			}, traktListActions(list)...),
 		}
 		items = append(items, item)
 	}
@@ -270,8 +306,8 @@ func CalendarMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	items := xbmc.ListItems{
-		{Label: "LOCALIZE[30291]", Path: URLForXBMC("/movies/trakt/calendars/movies"), Thumbnail: config.AddonResource("img", "box_office.png")},
-		{Label: "LOCALIZE[30292]", Path: URLForXBMC("/movies/trakt/calendars/releases"), Thumbnail: config.AddonResource("img", "tv.png")},
+		{Label: "LOCALIZE[30291]", Path: URLForXBMC("/movies/trakt/calendars/movies"), Thumbnail: config.AddonResource("img", "box_office.png"), TraktAuth: true},
+		{Label: "LOCALIZE[30292]", Path: URLForXBMC("/movies/trakt/calendars/releases"), Thumbnail: config.AddonResource("img", "tv.png"), TraktAuth: true},
 		{Label: "LOCALIZE[30293]", Path: URLForXBMC("/movies/trakt/calendars/allmovies"), Thumbnail: config.AddonResource("img", "box_office.png")},
 		{Label: "LOCALIZE[30294]", Path: URLForXBMC("/movies/trakt/calendars/allreleases"), Thumbnail: config.AddonResource("img", "tv.png")},
 	}
@@ -291,7 +327,7 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 	items := make(xbmc.ListItems, 0, len(movies)+hasNextPage)
 
 	for _, movie := range movies {
-		if movie == nil {
+		if movie == nil || !util.IsContentAllowed(movie.OriginalLanguage, movie.VoteCount) {
 			continue
 		}
 		item := movie.ToListItem()
@@ -335,6 +371,19 @@ func renderMovies(ctx *gin.Context, movies tmdb.Movies, page int, total int, que
 			{"LOCALIZE[30034]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/movies"))},
 		}
 		item.ContextMenu = append(libraryActions, item.ContextMenu...)
+		if action := notInterestedAction(ctx, movieType, movie.ID); action != nil {
+			item.ContextMenu = append(item.ContextMenu, action)
+		}
+		if config.Get().TraktToken != "" {
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30887]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/trakt/watched/note", movie.ID))})
+		}
+		item.ContextMenu = append(item.ContextMenu, tagsActions(movieType, movie.ID)...)
+		item.ContextMenu = append(item.ContextMenu, selectionActions(movieType, movie.ID)...)
+		item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30809]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/dryrun", movie.ID))})
+		item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/refresh", movie.ID))})
+		item.ContextMenu = append(item.ContextMenu, watchProvidersAction(movieType, movie.ID))
+		item.ContextMenu = append(item.ContextMenu, personsAction(movieType, movie.ID))
+		applyTags(item, movieType, movie.ID)
 
 		if config.Get().Platform.Kodi < 17 {
 			item.ContextMenu = append(item.ContextMenu,
@@ -399,7 +448,32 @@ func PopularMovies(ctx *gin.Context) {
 
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	movies, total := tmdb.PopularMovies(p, config.Get().Language, page)
-	renderMovies(ctx, movies, page, total, "")
+	renderMovies(ctx, filterNotInterestedMovies(movies), page, total, "")
+}
+
+// CountryPopularMovies lists popular movies, released in configured country
+func CountryPopularMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	movies, total := tmdb.CountryPopularMovies(config.Get().Region, config.Get().Language, page)
+	renderMovies(ctx, filterNotInterestedMovies(movies), page, total, "")
+}
+
+// countryName returns name of configured country, or its code, if TMDB does not know it
+func countryName() string {
+	region := config.Get().Region
+	for _, country := range tmdb.GetCountries(config.Get().Language) {
+		if country == nil || !strings.EqualFold(country.Iso31661, region) {
+			continue
+		}
+
+		if country.Name != "" {
+			return country.Name
+		}
+		return country.EnglishName
+	}
+	return region
 }
 
 // RecentMovies ...
@@ -481,12 +555,23 @@ func movieLinks(tmdbID string) []*bittorrent.TorrentFile {
 
 	searchers := providers.GetMovieSearchers()
 	if len(searchers) == 0 {
-		xbmc.Notify("Elementum", "LOCALIZE[30204]", config.AddonIcon())
+		notifyFailure(errNoProviders)
 	}
 
 	return providers.SearchMovie(searchers, movie)
 }
 
+func movieLinksStream(movie *tmdb.Movie) <-chan *providers.SearchResults {
+	log.Info("Streaming links for:", movie.ID)
+
+	searchers := providers.GetMovieSearchers()
+	if len(searchers) == 0 {
+		notifyFailure(errNoProviders)
+	}
+
+	return providers.SearchMovieStream(searchers, movie)
+}
+
 // MovieRun ...
 func MovieRun(action string, s *bittorrent.Service) gin.HandlerFunc {
 	defer perf.ScopeTimer()()
@@ -508,8 +593,14 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			runAction = "/download"
 		}
 
+		if action != "download" {
+			bittorrent.StartPlaybackTiming("Movie " + tmdbID)
+			bittorrent.PlaybackStep(bittorrent.StepMetadataResolve)
+		}
+
 		movie := tmdb.GetMovieByID(tmdbID, config.Get().Language)
 		if movie == nil {
+			bittorrent.FinishPlaybackTiming("Movie not found")
 			return
 		}
 
@@ -528,6 +619,10 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		if action != "download" && playLocalFile(ctx, movieType, movie.ID, external) {
+			return
+		}
+
 		if torrent := InTorrentsMap(tmdbID); torrent != nil {
 			rURL := URLQuery(URLForXBMC(runAction),
 				"doresume", doresume,
@@ -544,67 +639,40 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 
 		var torrents []*bittorrent.TorrentFile
 		var err error
+		choice := -1
+		streamed := false
 
+		bittorrent.PlaybackStep(bittorrent.StepProviderSearch)
 		if torrents, err = GetCachedTorrents(tmdbID); err != nil || len(torrents) == 0 {
-			torrents = movieLinks(tmdbID)
+			if action != "play" && config.Get().StreamingSearch {
+				torrents, choice = chooseStreamedLinks(movieLinksStream(movie), tmdbID, movie.Title)
+				streamed = true
+			} else {
+				torrents = movieLinks(tmdbID)
 
-			SetCachedTorrents(tmdbID, torrents)
+				SetCachedTorrents(tmdbID, torrents)
+			}
 		}
 
 		if len(torrents) == 0 {
-			xbmc.Notify("Elementum", "LOCALIZE[30205]", config.AddonIcon())
+			bittorrent.FinishPlaybackTiming("No torrents found")
+			notifyNoTorrents()
 			return
 		}
 
-		choices := make([]string, 0, len(torrents))
-		for _, torrent := range torrents {
-			resolution := ""
-			if torrent.Resolution > 0 {
-				resolution = fmt.Sprintf("[B][COLOR %s]%s[/COLOR][/B] ", bittorrent.Colors[torrent.Resolution], bittorrent.Resolutions[torrent.Resolution])
-			}
-
-			info := make([]string, 0)
-			if torrent.Size != "" {
-				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
-			}
-			if torrent.RipType > 0 {
-				info = append(info, bittorrent.Rips[torrent.RipType])
-			}
-			if torrent.VideoCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.VideoCodec])
-			}
-			if torrent.AudioCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
-			}
-			if torrent.Provider != "" {
-				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
-			}
-
-			multi := ""
-			if torrent.Multi {
-				multi = multiType
+		bittorrent.PlaybackStep(bittorrent.StepStreamChoice)
+		// Streamed torrents are already chosen, while results were arriving
+		if !streamed {
+			if action == "play" {
+				choice = 0
+			} else {
+				choice = xbmc.ListDialogLarge("LOCALIZE[30228]", movie.Title, torrentChoices(torrents)...)
 			}
-
-			label := fmt.Sprintf("%s(%d / %d) %s\n%s\n%s%s",
-				resolution,
-				torrent.Seeds,
-				torrent.Peers,
-				strings.Join(info, " "),
-				torrent.Name,
-				torrent.Icon,
-				multi,
-			)
-			choices = append(choices, label)
 		}
 
-		choice := -1
-		if action == "play" {
-			choice = 0
+		if choice < 0 {
+			bittorrent.FinishPlaybackTiming("Cancelled")
 		} else {
-			choice = xbmc.ListDialogLarge("LOCALIZE[30228]", movie.Title, choices...)
-		}
-
-		if choice >= 0 {
 			AddToTorrentsMap(tmdbID, torrents[choice])
 
 			rURL := URLQuery(URLForXBMC(runAction),
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/notifications"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// NotificationPreferences lists notification events with channels, they are sent to
+func NotificationPreferences(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0, len(notifications.Events))
+	for _, e := range notifications.Events {
+		items = append(items, &xbmc.ListItem{
+			Label:     e.Label,
+			Label2:    notificationChannelsLabel(notifications.GetChannels(e.Event)),
+			Path:      URLForXBMC("/notifications/edit/%s", e.Event),
+			Thumbnail: config.AddonResource("img", "settings.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: notificationChannelsLabel(notifications.GetChannels(e.Event)),
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// EditNotificationPreference toggles channels of an event, until dialog is cancelled
+func EditNotificationPreference(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	event := notifications.Event(ctx.Params.ByName("event"))
+	channels := notifications.GetChannels(event)
+	initial := channels
+
+	for {
+		choices := make([]string, 0, len(notifications.Channels))
+		for _, c := range notifications.Channels {
+			mark := "[ ]"
+			if channels&c.Channel != 0 {
+				mark = "[X]"
+			}
+			choices = append(choices, mark+" "+c.Label)
+		}
+
+		choice := xbmc.ListDialog("LOCALIZE[30781]", choices...)
+		if choice < 0 || choice >= len(notifications.Channels) {
+			break
+		}
+		channels ^= notifications.Channels[choice].Channel
+	}
+
+	if channels != initial {
+		if err := notifications.SetChannels(event, channels); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		xbmc.Refresh()
+	}
+	ctx.String(200, "")
+}
+
+func notificationChannelsLabel(channels int) string {
+	labels := []string{}
+	for _, c := range notifications.Channels {
+		if channels&c.Channel != 0 {
+			labels = append(labels, c.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return "LOCALIZE[30782]"
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Listings, where "Not interested" is offered and items, marked so, are not shown
+var discoveryListingRegex = regexp.MustCompile(`/(popular|trending|recommendations)(/|$)`)
+
+// NotInterested hides movie or show from discovery listings and dismisses it from Trakt recommendations
+func NotInterested(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	if media != movieType && media != showType {
+		xbmc.Notify("Elementum", fmt.Sprintf("Unknown media type: %s", media), config.AddonIcon())
+		return
+	}
+
+	if err := database.GetStorm().AddNotInterested(media, tmdbID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if config.Get().TraktToken != "" {
+		go dismissTraktRecommendation(media, tmdbID)
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30816]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+// dismissTraktRecommendation resolves Trakt ID of an item and dismisses it, so that Trakt learns from it
+func dismissTraktRecommendation(media string, tmdbID int) {
+	var ids *trakt.IDs
+	if media == movieType {
+		if m := trakt.GetMovieByTMDB(strconv.Itoa(tmdbID)); m != nil {
+			ids = m.IDs
+		}
+	} else if s := trakt.GetShowByTMDB(strconv.Itoa(tmdbID)); s != nil {
+		ids = s.IDs
+	}
+
+	if ids == nil {
+		log.Warningf("Could not find %s %d on Trakt to dismiss recommendation", media, tmdbID)
+		return
+	}
+	if err := trakt.DismissRecommendation(media+"s", ids.Trakt); err != nil {
+		log.Warningf("Could not dismiss Trakt recommendation of %s %d: %s", media, tmdbID, err)
+	}
+}
+
+// notInterestedAction returns context menu entry for hiding item,
+// if current listing is a discovery listing, or nil otherwise
+func notInterestedAction(ctx *gin.Context, media string, tmdbID int) []string {
+	if ctx == nil || !discoveryListingRegex.MatchString(ctx.Request.URL.Path) {
+		return nil
+	}
+	return []string{"LOCALIZE[30815]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/notinterested/%s/%d", media, tmdbID))}
+}
+
+func filterNotInterestedMovies(movies tmdb.Movies) tmdb.Movies {
+	hidden := database.GetStorm().GetNotInterested(movieType)
+	for i, m := range movies {
+		if m != nil && hidden[m.ID] {
+			movies[i] = nil
+		}
+	}
+	return movies
+}
+
+func filterNotInterestedShows(shows tmdb.Shows) tmdb.Shows {
+	hidden := database.GetStorm().GetNotInterested(showType)
+	for i, s := range shows {
+		if s != nil && hidden[s.ID] {
+			shows[i] = nil
+		}
+	}
+	return shows
+}
+
+func filterNotInterestedTraktMovies(movies []*trakt.Movies) []*trakt.Movies {
+	hidden := database.GetStorm().GetNotInterested(movieType)
+	for i, m := range movies {
+		if m != nil && m.Movie != nil && m.Movie.IDs != nil && hidden[m.Movie.IDs.TMDB] {
+			movies[i] = nil
+		}
+	}
+	return movies
+}
+
+func filterNotInterestedTraktShows(shows []*trakt.Shows) []*trakt.Shows {
+	hidden := database.GetStorm().GetNotInterested(showType)
+	for i, s := range shows {
+		if s != nil && s.Show != nil && s.Show.IDs != nil && hidden[s.Show.IDs.TMDB] {
+			shows[i] = nil
+		}
+	}
+	return shows
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Crew jobs, shown together with the cast
+var personJobs = map[string]bool{
+	"Director":   true,
+	"Screenplay": true,
+	"Writer":     true,
+	"Creator":    true,
+	"Novel":      true,
+}
+
+// ItemPersons lists cast and main crew of a movie or a show, each opening person's filmography
+func ItemPersons(mediaType string, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		id, _ := strconv.Atoi(ctx.Params.ByName(param))
+
+		var credits *tmdb.Credits
+		if mediaType == movieType {
+			if movie := tmdb.GetMovie(id, config.Get().Language); movie != nil {
+				credits = movie.Credits
+			}
+		} else if show := tmdb.GetShow(id, config.Get().Language); show != nil {
+			credits = show.Credits
+		}
+		if credits == nil {
+			ctx.Error(fmt.Errorf("Unable to get credits of %s %d", mediaType, id))
+			return
+		}
+
+		items := xbmc.ListItems{}
+		seen := map[int]bool{}
+		for _, crew := range credits.Crew {
+			if crew == nil || !personJobs[crew.Job] || seen[crew.ID] {
+				continue
+			}
+			seen[crew.ID] = true
+			items = append(items, personListItem(crew.ID, crew.Name, crew.Job, crew.ProfilePath))
+		}
+		for _, cast := range credits.Cast {
+			if cast == nil || seen[cast.ID] {
+				continue
+			}
+			seen[cast.ID] = true
+			items = append(items, personListItem(cast.ID, cast.Name, cast.Character, cast.ProfilePath))
+		}
+
+		ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+	}
+}
+
+// PersonIndex shows biography of a person, with links to movies and shows
+func PersonIndex(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	personID, _ := strconv.Atoi(ctx.Params.ByName("personId"))
+	person := tmdb.GetPerson(personID, config.Get().Language)
+	if person == nil {
+		ctx.Error(fmt.Errorf("Unable to get person %d", personID))
+		return
+	}
+
+	plot := person.Biography
+	if born := strings.TrimSpace(person.Birthday + " " + person.PlaceOfBirth); born != "" {
+		plot = born + "\n\n" + plot
+	}
+	credits := tmdb.GetPersonCredits(personID, config.Get().Language)
+	image := tmdb.ImageURL(person.ProfilePath, "w500")
+
+	items := xbmc.ListItems{
+		{
+			Label:     fmt.Sprintf("LOCALIZE[30214] (%d)", len(credits.IDs("movie"))),
+			Path:      URLForXBMC("/person/%d/movies", personID),
+			Thumbnail: image,
+			Info:      &xbmc.ListItemInfo{Plot: plot},
+		},
+		{
+			Label:     fmt.Sprintf("LOCALIZE[30215] (%d)", len(credits.IDs("tv"))),
+			Path:      URLForXBMC("/person/%d/shows", personID),
+			Thumbnail: image,
+			Info:      &xbmc.ListItemInfo{Plot: plot},
+		},
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// PersonMovies lists movies of a person
+func PersonMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	personID, _ := strconv.Atoi(ctx.Params.ByName("personId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+
+	ids := tmdb.GetPersonCredits(personID, config.Get().Language).IDs("movie")
+	renderMovies(ctx, tmdb.GetMovies(personPage(ids, page), config.Get().Language), page, len(ids), "")
+}
+
+// PersonShows lists shows of a person
+func PersonShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	personID, _ := strconv.Atoi(ctx.Params.ByName("personId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+
+	ids := tmdb.GetPersonCredits(personID, config.Get().Language).IDs("tv")
+	renderShows(ctx, tmdb.GetShows(personPage(ids, page), config.Get().Language), page, len(ids), "")
+}
+
+// personPage returns IDs of a requested page
+func personPage(ids []int, page int) []int {
+	if page < 1 {
+		page = 1
+	}
+	from := (page - 1) * config.Get().ResultsPerPage
+	if from >= len(ids) {
+		return []int{}
+	}
+	to := from + config.Get().ResultsPerPage
+	if to > len(ids) {
+		to = len(ids)
+	}
+	return ids[from:to]
+}
+
+func personListItem(id int, name string, role string, profilePath string) *xbmc.ListItem {
+	label := name
+	if role != "" {
+		label = fmt.Sprintf("%s [COLOR FF999999](%s)[/COLOR]", name, role)
+	}
+
+	return &xbmc.ListItem{
+		Label:     label,
+		Path:      URLForXBMC("/person/%d", id),
+		Thumbnail: tmdb.ImageURL(profilePath, "w500"),
+		Art: &xbmc.ListItemArt{
+			Thumbnail: tmdb.ImageURL(profilePath, "w500"),
+			Poster:    tmdb.ImageURL(profilePath, "w500"),
+		},
+	}
+}
+
+// personsAction returns context menu action to open cast and crew of an item
+func personsAction(mediaType string, tmdbID int) []string {
+	if mediaType == movieType {
+		return []string{"LOCALIZE[30767]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movie/%d/persons", tmdbID))}
+	}
+	return []string{"LOCALIZE[30767]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/show/%d/persons", tmdbID))}
+}
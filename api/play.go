@@ -3,8 +3,10 @@ package api
 import (
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/gin-gonic/gin"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/elgatito/elementum/bittorrent"
 	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 )
@@ -90,6 +93,15 @@ func Play(s *bittorrent.Service) gin.HandlerFunc {
 			Background:        background == "true",
 		}
 
+		if !params.Background {
+			if resume != "" {
+				bittorrent.EnsurePlaybackTiming("Torrent " + resume)
+			} else {
+				bittorrent.EnsurePlaybackTiming("Torrent " + uri)
+			}
+			bittorrent.PlaybackStep(bittorrent.StepTorrentAdd)
+		}
+
 		player := bittorrent.NewPlayer(s, params)
 		log.Infof("Playing item: %s", litter.Sdump(params))
 		if t := s.GetTorrentByHash(resume); resume != "" && t != nil {
@@ -99,11 +111,19 @@ func Play(s *bittorrent.Service) gin.HandlerFunc {
 			player.SetTorrent(t)
 		} 
 		
-		if player.Buffer() != nil || !player.HasChosenFile() || player.Params().Background {
+		if err := player.Buffer(); err != nil || !player.HasChosenFile() || player.Params().Background {
+			if err != nil {
+				bittorrent.FinishPlaybackTiming(err.Error())
+				go notifyFailure(err)
+			} else {
+				bittorrent.FinishPlaybackTiming("")
+			}
 			player.Close()
 			return
 		}
 
+		player.InitVideoInfo()
+
 		rURL, _ := url.Parse(fmt.Sprintf("%s/files/%s", util.GetContextHTTPHost(ctx), player.PlayURL()))
 		ctx.Redirect(302, rURL.String())
 	}
@@ -187,6 +207,45 @@ func PlayURI(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// ResumeCrashedPlayback offers to resume playback, that was not properly stopped,
+// which happens when Kodi crashes while playing.
+func ResumeCrashedPlayback(s *bittorrent.Service) {
+	p := bittorrent.GetActivePlayback()
+	if p == nil {
+		return
+	}
+	bittorrent.ClearActivePlayback()
+
+	// Give time to Kodi to start its JSON-RPC service
+	time.Sleep(10 * time.Second)
+	if xbmc.PlayerIsPlaying() {
+		return
+	}
+
+	position := &library.Resume{Position: p.Position, Total: p.Total}
+	if !xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30713];;[COLOR gold]%s[/COLOR] (%s)", p.Title, position.ToString())) {
+		return
+	}
+
+	resume := ""
+	if s.GetTorrentByHash(p.InfoHash) != nil {
+		resume = p.InfoHash
+	}
+
+	log.Infof("Resuming interrupted playback of %s at %s", p.Title, position.ToString())
+	xbmc.PlayURL(URLQuery(URLForXBMC("/play"),
+		"uri", p.URI,
+		"resume", resume,
+		"oindex", strconv.Itoa(p.OriginalIndex),
+		"doresume", "true",
+		"type", p.ContentType,
+		"tmdb", strconv.Itoa(p.TMDBId),
+		"show", strconv.Itoa(p.ShowID),
+		"season", strconv.Itoa(p.Season),
+		"episode", strconv.Itoa(p.Episode),
+		"query", p.Query))
+}
+
 // strToInt parses string to int, and returning default value is no int found
 func strToInt(str string, def int) int {
 	if str != "" {
@@ -197,3 +256,22 @@ func strToInt(str string, def int) int {
 
 	return def
 }
+
+// playLocalFile offers to play already downloaded file of media item from disk,
+// instead of searching and downloading it again
+func playLocalFile(ctx *gin.Context, contentType string, tmdbID int, external string) bool {
+	f := bittorrent.FindLocalFile(contentType, tmdbID)
+	if f == nil || !xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30718];;[COLOR gold]%s[/COLOR]", filepath.Base(f.Path))) {
+		return false
+	}
+
+	path := bittorrent.LocalFilePath(f)
+	log.Infof("Playing downloaded file %s", path)
+	bittorrent.FinishPlaybackTiming("Local file")
+	if external != "" {
+		xbmc.PlayURL(path)
+	} else {
+		ctx.Redirect(302, path)
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const profileRequestTimeout = 15 * time.Second
+
+// profileSettings are settings, that are shared in a profile: filters, quality and providers.
+// Paths, credentials and device specific settings are never exported.
+var profileSettings = []string{
+	// Filters
+	"allowed_languages",
+	"min_vote_count",
+	"min_candidate_size",
+	"min_candidate_show_size",
+
+	// Quality
+	"resolution_preference_movies",
+	"resolution_preference_shows",
+	"sorting_mode_movies",
+	"sorting_mode_shows",
+	"preferred_audio_languages",
+	"preferred_subtitle_languages",
+	"choose_stream_auto_movie",
+	"choose_stream_auto_show",
+	"choose_stream_auto_search",
+	"force_link_type",
+	"smart_episode_choose",
+	"smart_episode_match",
+
+	// Providers
+	"skip_burst_search",
+	"custom_provider_timeout_enabled",
+	"custom_provider_timeout",
+	"streaming_search",
+	"use_original_title",
+	"use_anime_en_title",
+	"use_cache_search",
+	"cache_search_duration",
+	"jackett_movie_categories",
+	"jackett_show_categories",
+	"jackett_anime_categories",
+}
+
+// ProfileExport saves current settings and enabled providers as a named profile,
+// which can be pulled by other devices.
+func ProfileExport(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	name := strings.TrimSpace(xbmc.Keyboard("", "LOCALIZE[30847]"))
+	if name == "" {
+		return
+	}
+
+	p := &database.SettingsProfile{
+		Name:     name,
+		Settings: map[string]string{},
+	}
+	for _, s := range xbmc.GetAllSettings() {
+		if util.StringSliceContains(profileSettings, s.Key) {
+			p.Settings[s.Key] = s.Value
+		}
+	}
+	for _, addon := range getProviders() {
+		if addon.Enabled {
+			p.Providers = append(p.Providers, addon.ID)
+		}
+	}
+
+	if err := database.GetStorm().SaveSettingsProfile(p); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	log.Infof("Exported settings profile %s with %d settings and %d providers", name, len(p.Settings), len(p.Providers))
+	xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30848];;%s", name), config.AddonIcon())
+	ctx.String(200, "")
+}
+
+// ProfileDelete removes saved profile
+func ProfileDelete(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	name := ctx.Params.ByName("name")
+	if err := database.GetStorm().DeleteSettingsProfile(name); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30849];;%s", name), config.AddonIcon())
+	ctx.String(200, "")
+}
+
+// ProfilesList returns names of saved profiles, for other devices to choose from
+func ProfilesList(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	names := []string{}
+	for _, p := range database.GetStorm().GetSettingsProfiles() {
+		names = append(names, p.Name)
+	}
+	ctx.JSON(200, names)
+}
+
+// ProfileGet returns saved profile as JSON
+func ProfileGet(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	p := database.GetStorm().GetSettingsProfile(ctx.Params.ByName("name"))
+	if p == nil {
+		ctx.String(404, "Profile not found")
+		return
+	}
+	ctx.JSON(200, p)
+}
+
+// ProfilePull lets user choose a profile of primary instance, or a local one, if primary is not set,
+// and applies it to this device.
+func ProfilePull(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	primary := config.Get().ProfilesPrimaryURL
+
+	var names []string
+	if primary == "" {
+		for _, p := range database.GetStorm().GetSettingsProfiles() {
+			names = append(names, p.Name)
+		}
+	} else if err := fetchProfileJSON(primary+"/profiles/list", &names); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if len(names) == 0 {
+		xbmc.Notify("Elementum", "LOCALIZE[30850]", config.AddonIcon())
+		return
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30851]", names...)
+	if choice < 0 {
+		return
+	}
+
+	var p *database.SettingsProfile
+	if primary == "" {
+		p = database.GetStorm().GetSettingsProfile(names[choice])
+	} else if err := fetchProfileJSON(primary+"/profiles/get/"+url.PathEscape(names[choice]), &p); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+	if p == nil {
+		xbmc.Notify("Elementum", "LOCALIZE[30852]", config.AddonIcon())
+		return
+	}
+
+	if !xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30853];;%s;;%d", p.Name, len(p.Settings))) {
+		return
+	}
+
+	applyProfile(p)
+	xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30854];;%s", p.Name), config.AddonIcon())
+	ctx.String(200, "")
+}
+
+// applyProfile writes profile settings to Kodi, which notifies daemon to reload configuration,
+// and enables only providers of the profile, that are installed on this device.
+func applyProfile(p *database.SettingsProfile) {
+	for key, value := range p.Settings {
+		if util.StringSliceContains(profileSettings, key) {
+			xbmc.SetSetting(key, value)
+		}
+	}
+
+	if len(p.Providers) == 0 {
+		return
+	}
+	for _, addon := range getProviders() {
+		if enabled := util.StringSliceContains(p.Providers, addon.ID); enabled != addon.Enabled {
+			xbmc.SetAddonEnabled(addon.ID, enabled)
+		}
+	}
+	log.Infof("Applied settings profile %s", p.Name)
+}
+
+func fetchProfileJSON(u string, ret interface{}) error {
+	client := &http.Client{Timeout: profileRequestTimeout}
+	resp, err := client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Primary instance responded with %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
@@ -3,12 +3,16 @@ package api
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/gin-gonic/gin"
 
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -85,6 +89,7 @@ func ProviderList(ctx *gin.Context) {
 			item.ContextMenu = append(item.ContextMenu,
 				[]string{"LOCALIZE[30241]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/provider/%s/disable", provider.ID))},
 				[]string{"LOCALIZE[30244]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/provider/%s/settings", provider.ID))},
+				[]string{"LOCALIZE[30859]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/provider/%s/limits", provider.ID))},
 			)
 		} else {
 			item.ContextMenu = append(item.ContextMenu,
@@ -94,6 +99,8 @@ func ProviderList(ctx *gin.Context) {
 		item.ContextMenu = append(item.ContextMenu,
 			[]string{"LOCALIZE[30274]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/providers/enable"))},
 			[]string{"LOCALIZE[30275]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/providers/disable"))},
+			[]string{"LOCALIZE[30874]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/providers/benchmark"))},
+			[]string{"LOCALIZE[30877]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLQuery(URLForXBMC("/provider/status"), "dialog", "true"))},
 		)
 		items = append(items, item)
 	}
@@ -108,6 +115,136 @@ func ProviderSettings(ctx *gin.Context) {
 	ctx.String(200, "")
 }
 
+// ProviderLimits edits search timeout, concurrency and content types of a provider.
+// Values are taken from "timeout", "concurrency" and "disabled" query parameters, if any is given,
+// otherwise they are edited with dialogs.
+func ProviderLimits(ctx *gin.Context) {
+	addonID := ctx.Params.ByName("provider")
+	settings := database.GetStorm().GetProviderSettings(addonID)
+
+	_, hasTimeout := ctx.GetQuery("timeout")
+	_, hasConcurrency := ctx.GetQuery("concurrency")
+	_, hasDisabled := ctx.GetQuery("disabled")
+	if hasTimeout || hasConcurrency || hasDisabled {
+		if hasTimeout {
+			settings.Timeout = parseLimit(ctx.Query("timeout"), settings.Timeout)
+		}
+		if hasConcurrency {
+			settings.MaxConcurrent = parseLimit(ctx.Query("concurrency"), settings.MaxConcurrent)
+		}
+		if hasDisabled {
+			settings.DisabledTypes = []string{}
+			for _, content := range strings.Split(ctx.Query("disabled"), ",") {
+				if content = strings.TrimSpace(content); util.StringSliceContains(providers.ContentTypes, content) {
+					settings.DisabledTypes = append(settings.DisabledTypes, content)
+				}
+			}
+		}
+
+		if err := database.GetStorm().SetProviderSettings(settings); err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(200, settings)
+		return
+	}
+
+	for {
+		labels := []string{
+			"LOCALIZE[30860];;" + limitLabel(settings.Timeout, "s", "LOCALIZE[30866]"),
+			"LOCALIZE[30861];;" + limitLabel(settings.MaxConcurrent, "", "LOCALIZE[30867]"),
+		}
+		for _, content := range providers.ContentTypes {
+			label := "LOCALIZE[30862]"
+			if util.StringSliceContains(settings.DisabledTypes, content) {
+				label = "LOCALIZE[30863]"
+			}
+			labels = append(labels, label+";;"+content)
+		}
+
+		choice := xbmc.ListDialog("LOCALIZE[30859]", labels...)
+		if choice < 0 {
+			break
+		} else if choice == 0 {
+			settings.Timeout = parseLimit(xbmc.Keyboard(strconv.Itoa(settings.Timeout), "LOCALIZE[30864]"), settings.Timeout)
+		} else if choice == 1 {
+			settings.MaxConcurrent = parseLimit(xbmc.Keyboard(strconv.Itoa(settings.MaxConcurrent), "LOCALIZE[30865]"), settings.MaxConcurrent)
+		} else {
+			content := providers.ContentTypes[choice-2]
+			if util.StringSliceContains(settings.DisabledTypes, content) {
+				disabled := []string{}
+				for _, c := range settings.DisabledTypes {
+					if c != content {
+						disabled = append(disabled, c)
+					}
+				}
+				settings.DisabledTypes = disabled
+			} else {
+				settings.DisabledTypes = append(settings.DisabledTypes, content)
+			}
+		}
+
+		if err := database.GetStorm().SetProviderSettings(settings); err != nil {
+			log.Warningf("Could not save search limits of %s: %s", addonID, err)
+		}
+	}
+
+	ctx.String(200, "")
+}
+
+// parseLimit parses non-negative limit value, keeping current value on invalid input
+func parseLimit(value string, current int) int {
+	if limit, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && limit >= 0 {
+		return limit
+	}
+	return current
+}
+
+func limitLabel(value int, unit string, zero string) string {
+	if value <= 0 {
+		return zero
+	}
+	return strconv.Itoa(value) + unit
+}
+
+// ProviderStatus responds with search statistics of providers, or shows them in a dialog if "dialog" is set.
+// It is registered on /provider/:provider, since router does not allow static /provider/status
+// next to provider routes, and shows all providers for "status" or a single provider otherwise.
+func ProviderStatus(ctx *gin.Context) {
+	addonID := ctx.Params.ByName("provider")
+
+	stats := []providers.ProviderStats{}
+	if addonID == "status" {
+		for _, addon := range getProviders() {
+			stats = append(stats, providers.GetProviderStats(addon.ID))
+		}
+	} else {
+		stats = append(stats, providers.GetProviderStats(addonID))
+	}
+
+	if ctx.Query("dialog") == "" {
+		ctx.JSON(200, stats)
+		return
+	}
+
+	text := ""
+	for _, s := range stats {
+		state := "[COLOR FF009900]OK[/COLOR]"
+		if s.IsFailing() {
+			state = "[COLOR FF990000]Failing[/COLOR]"
+		} else if s.Calls == 0 {
+			state = "No searches yet"
+		}
+
+		text += fmt.Sprintf("[B]%s[/B] - %s\n", s.ID, state)
+		text += fmt.Sprintf("    Calls: %d, successful: %d (%.0f%%), timeouts: %d\n", s.Calls, s.Successes, s.SuccessRate(), s.Timeouts)
+		text += fmt.Sprintf("    Average latency: %.2fs, average results: %.1f\n\n", s.AverageLatency().Seconds(), s.AverageResults())
+	}
+
+	xbmc.DialogText("LOCALIZE[30877]", text)
+	ctx.String(200, "")
+}
+
 // ProviderCheck ...
 func ProviderCheck(ctx *gin.Context) {
 	addonID := ctx.Params.ByName("provider")
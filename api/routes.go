@@ -34,6 +34,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	r.Use(gin.Recovery())
 	r.Use(gin.LoggerWithWriter(gin.DefaultWriter, "/torrents/list", "/notification"))
 	r.Use(IPLogger())
+	r.Use(KidsModeFilter())
 
 	gin.SetMode(gin.ReleaseMode)
 
@@ -44,6 +45,21 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	r.GET("/donate", Donate)
 	r.GET("/settings/:addon", Settings)
 	r.GET("/status", Status)
+	r.GET("/watchdog", WatchdogStatus)
+	r.GET("/watchdog/goroutines", WatchdogGoroutines)
+	r.GET("/watchdog/restart/:name", WatchdogRestart)
+	r.GET("/cache/stats", CacheStats)
+	r.GET("/status/playback", PlaybackTiming)
+	r.GET("/metered", MeteredMode(s))
+	r.GET("/metered/:state", MeteredMode(s))
+	r.GET("/placeholder/:kind", Placeholder)
+
+	kids := r.Group("/kids")
+	{
+		kids.GET("/movies", KidsMovies)
+		kids.GET("/shows", KidsShows)
+		kids.GET("/unlock", KidsUnlock)
+	}
 
 	history := r.Group("/history")
 	{
@@ -58,6 +74,64 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		search.GET("/remove", SearchRemove)
 		search.GET("/clear", SearchClear)
 		search.GET("/infolabels/:tmdbId", InfoLabelsSearch(s))
+
+		search.GET("/silent/movie/:tmdbId", SilentSearchMovie)
+		search.GET("/silent/show/:showId/season/:season", SilentSearchSeason)
+		search.GET("/silent/show/:showId/season/:season/episode/:episode", SilentSearchEpisode)
+	}
+	r.GET("/everywhere/search", SearchEverywhere)
+	r.GET("/torznab/api", TorznabAPI)
+
+	tags := r.Group("/tags")
+	{
+		tags.GET("/:media", TagsIndex)
+		tags.GET("/:media/:tag", TaggedItems)
+	}
+
+	rssFeeds := r.Group("/rss")
+	{
+		rssFeeds.GET("/", RSSFeeds)
+		rssFeeds.GET("/add", AddRSSFeed)
+		rssFeeds.GET("/edit/:feedId", EditRSSFeed)
+		rssFeeds.GET("/toggle/:feedId", ToggleRSSFeed)
+		rssFeeds.GET("/remove/:feedId", RemoveRSSFeed)
+		rssFeeds.GET("/check", CheckRSSFeeds(s))
+	}
+
+	person := r.Group("/person")
+	{
+		person.GET("/:personId", PersonIndex)
+		person.GET("/:personId/movies", PersonMovies)
+		person.GET("/:personId/shows", PersonShows)
+	}
+
+	r.GET("/streaming/changes", StreamingChanges)
+
+	notificationPreferences := r.Group("/notifications")
+	{
+		notificationPreferences.GET("/", NotificationPreferences)
+		notificationPreferences.GET("/edit/:event", EditNotificationPreference)
+	}
+
+	autoDownloads := r.Group("/autodownload")
+	{
+		autoDownloads.GET("/", AutoDownloads)
+		autoDownloads.GET("/check", CheckAutoDownloads(s))
+	}
+
+	discover := r.Group("/discover")
+	{
+		discover.GET("/:media", DiscoverPresets)
+		discover.GET("/:media/preset/:presetId", DiscoverPreset)
+		discover.GET("/:media/add", AddDiscoverPreset)
+		discover.GET("/:media/edit/:presetId", EditDiscoverPreset)
+		discover.GET("/:media/remove/:presetId", RemoveDiscoverPreset)
+	}
+
+	selection := r.Group("/selection")
+	{
+		selection.GET("/:media", SelectionActions(s))
+		selection.GET("/:media/toggle/:tmdbId", ToggleSelection)
 	}
 
 	r.LoadHTMLGlob(filepath.Join(config.Get().Info.Path, "resources", "web", "*.html"))
@@ -84,6 +158,17 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		torrents.GET("/undownloadall/:torrentId", UnDownloadAllTorrent(s))
 		torrents.GET("/selectfile/:torrentId", SelectFileTorrent(s, true))
 		torrents.GET("/downloadfile/:torrentId", SelectFileTorrent(s, false))
+		torrents.GET("/audio/:torrentId", AudioPlaylistTorrent(s))
+		torrents.GET("/export/:torrentId", ExportTorrent(s))
+		torrents.GET("/cast/:torrentId", CastTorrent(s))
+		torrents.GET("/collect/:torrentId", CollectTorrent(s))
+		torrents.GET("/magnet/:torrentId", TorrentMagnet(s))
+		torrents.GET("/trackers/:torrentId", TorrentTrackers(s))
+		torrents.GET("/peers/:torrentId", TorrentPeers(s))
+		torrents.GET("/inspect/:torrentId", InspectTorrent(s))
+		torrents.GET("/queue", DownloadQueue(s))
+		torrents.GET("/queue/:torrentId/up", MoveInQueue(s, -1))
+		torrents.GET("/queue/:torrentId/down", MoveInQueue(s, 1))
 
 		// Web UI json
 		torrents.GET("/list", ListTorrentsWeb(s))
@@ -95,6 +180,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movies.GET("/search", SearchMovies)
 		movies.GET("/autoscraped", AutoscrapedMovies)
 		movies.GET("/popular", PopularMovies)
+		movies.GET("/popular/local", CountryPopularMovies)
 		movies.GET("/popular/genre/:genre", PopularMovies)
 		movies.GET("/popular/language/:language", PopularMovies)
 		movies.GET("/popular/country/:country", PopularMovies)
@@ -106,23 +192,36 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movies.GET("/imdb250", IMDBTop250)
 		movies.GET("/mostvoted", MoviesMostVoted)
 		movies.GET("/genres", MovieGenres)
+		movies.GET("/keywords", MovieKeywords)
+		movies.GET("/keyword/:keywordId", KeywordMovies)
 		movies.GET("/languages", MovieLanguages)
 		movies.GET("/countries", MovieCountries)
 		movies.GET("/library", MovieLibrary)
+		movies.GET("/seasonal/:collection", SeasonalMovies)
 
 		trakt := movies.Group("/trakt")
 		{
 			trakt.GET("/watchlist", WatchlistMovies)
 			trakt.GET("/collection", CollectionMovies)
+			trakt.GET("/ratings", RatedMovies)
 			trakt.GET("/popular", TraktPopularMovies)
+			trakt.GET("/popular/local", TraktCountryPopularMovies)
+			trakt.GET("/popular/genre/:genre", TraktPopularMovies)
 			trakt.GET("/recommendations", TraktRecommendationsMovies)
 			trakt.GET("/trending", TraktTrendingMovies)
+			trakt.GET("/trending/genre/:genre", TraktTrendingMovies)
+			trakt.GET("/genres", TraktGenresMovies)
 			trakt.GET("/toplists", TopTraktLists)
 			trakt.GET("/played", TraktMostPlayedMovies)
+			trakt.GET("/played/:period", TraktMostPlayedMovies)
 			trakt.GET("/watched", TraktMostWatchedMovies)
+			trakt.GET("/watched/:period", TraktMostWatchedMovies)
 			trakt.GET("/collected", TraktMostCollectedMovies)
+			trakt.GET("/collected/:period", TraktMostCollectedMovies)
 			trakt.GET("/anticipated", TraktMostAnticipatedMovies)
+			trakt.GET("/anticipated/:sort", TraktMostAnticipatedMovies)
 			trakt.GET("/boxoffice", TraktBoxOffice)
+			trakt.GET("/periods/:category", TraktPeriodsMovies)
 			trakt.GET("/history", TraktHistoryMovies)
 
 			lists := trakt.Group("/lists")
@@ -156,8 +255,18 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		movie.GET("/:tmdbId/forceplay/*ident", MovieRun("forceplay", s))
 		movie.GET("/:tmdbId/watchlist/add", AddMovieToWatchlist)
 		movie.GET("/:tmdbId/watchlist/remove", RemoveMovieFromWatchlist)
+		movie.GET("/:tmdbId/rate", RateMovie)
+		movie.GET("/:tmdbId/trakt/watched", MarkMovieWatched(false))
+		movie.GET("/:tmdbId/trakt/watched/note", MarkMovieWatched(true))
+		movie.GET("/:tmdbId/comments", MovieComments)
 		movie.GET("/:tmdbId/collection/add", AddMovieToCollection)
 		movie.GET("/:tmdbId/collection/remove", RemoveMovieFromCollection)
+		movie.GET("/:tmdbId/tags", EditItemTags(movieType, "tmdbId"))
+		movie.GET("/:tmdbId/note", EditItemNote(movieType, "tmdbId"))
+		movie.GET("/:tmdbId/dryrun", MovieDryRun)
+		movie.GET("/:tmdbId/refresh", RefreshMovieMetadata)
+		movie.GET("/:tmdbId/providers", WatchProvidersInfo(movieType, "tmdbId"))
+		movie.GET("/:tmdbId/persons", ItemPersons(movieType, "tmdbId"))
 	}
 
 	shows := r.Group("/shows")
@@ -165,6 +274,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		shows.GET("/", TVIndex)
 		shows.GET("/search", SearchShows)
 		shows.GET("/popular", PopularShows)
+		shows.GET("/popular/local", CountryPopularShows)
 		shows.GET("/popular/genre/:genre", PopularShows)
 		shows.GET("/popular/language/:language", PopularShows)
 		shows.GET("/popular/country/:country", PopularShows)
@@ -179,6 +289,8 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		shows.GET("/top", TopRatedShows)
 		shows.GET("/mostvoted", TVMostVoted)
 		shows.GET("/genres", TVGenres)
+		shows.GET("/networks", TVNetworks)
+		shows.GET("/network/:networkId", NetworkShows)
 		shows.GET("/languages", TVLanguages)
 		shows.GET("/countries", TVCountries)
 		shows.GET("/library", TVLibrary)
@@ -187,13 +299,23 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		{
 			trakt.GET("/watchlist", WatchlistShows)
 			trakt.GET("/collection", CollectionShows)
+			trakt.GET("/ratings", RatedShows)
 			trakt.GET("/popular", TraktPopularShows)
+			trakt.GET("/popular/local", TraktCountryPopularShows)
+			trakt.GET("/popular/genre/:genre", TraktPopularShows)
 			trakt.GET("/recommendations", TraktRecommendationsShows)
 			trakt.GET("/trending", TraktTrendingShows)
+			trakt.GET("/trending/genre/:genre", TraktTrendingShows)
+			trakt.GET("/genres", TraktGenresShows)
 			trakt.GET("/played", TraktMostPlayedShows)
+			trakt.GET("/played/:period", TraktMostPlayedShows)
 			trakt.GET("/watched", TraktMostWatchedShows)
+			trakt.GET("/watched/:period", TraktMostWatchedShows)
 			trakt.GET("/collected", TraktMostCollectedShows)
+			trakt.GET("/collected/:period", TraktMostCollectedShows)
 			trakt.GET("/anticipated", TraktMostAnticipatedShows)
+			trakt.GET("/anticipated/:sort", TraktMostAnticipatedShows)
+			trakt.GET("/periods/:category", TraktPeriodsShows)
 			trakt.GET("/progress", TraktProgressShows)
 			trakt.GET("/history", TraktHistoryShows)
 
@@ -212,6 +334,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 				calendars.GET("/allshows", TraktAllShows)
 				calendars.GET("/allnewshows", TraktAllNewShows)
 				calendars.GET("/allpremieres", TraktAllPremieres)
+				calendars.GET("/ics", TraktCalendarICS)
 			}
 		}
 	}
@@ -240,10 +363,30 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		show.GET("/:showId/season/:season/episode/:episode/links/*ident", ShowEpisodeRun("links", s))
 		show.GET("/:showId/season/:season/episode/:episode/forcelinks", ShowEpisodeRun("forcelinks", s))
 		show.GET("/:showId/season/:season/episode/:episode/forcelinks/*ident", ShowEpisodeRun("forcelinks", s))
+		show.GET("/:showId/season/:season/episode/:episode/dryrun", ShowEpisodeDryRun)
 		show.GET("/:showId/watchlist/add", AddShowToWatchlist)
 		show.GET("/:showId/watchlist/remove", RemoveShowFromWatchlist)
 		show.GET("/:showId/collection/add", AddShowToCollection)
 		show.GET("/:showId/collection/remove", RemoveShowFromCollection)
+		show.GET("/:showId/rewatch/start", StartShowRewatch)
+		show.GET("/:showId/rewatch/stop", StopShowRewatch)
+		show.GET("/:showId/progress/hide", HideShowProgress)
+		show.GET("/:showId/progress/dismiss/:season/:episode", DismissShowProgress)
+		show.GET("/:showId/progress/unhide", UnhideShowProgress)
+		show.GET("/:showId/rate", RateShow)
+		show.GET("/:showId/comments", ShowComments)
+		show.GET("/:showId/season/:season/episode/:episode/rate", RateEpisode)
+		show.GET("/:showId/season/:season/episode/:episode/trakt/watched", MarkEpisodeWatched(false))
+		show.GET("/:showId/season/:season/episode/:episode/trakt/watched/note", MarkEpisodeWatched(true))
+		show.GET("/:showId/season/:season/episode/:episode/comments", EpisodeComments)
+		show.GET("/:showId/intro", ShowIntroOffset)
+		show.GET("/:showId/spoilers", ToggleShowSpoilers)
+		show.GET("/:showId/autodownload", ToggleAutoDownload)
+		show.GET("/:showId/tags", EditItemTags(showType, "showId"))
+		show.GET("/:showId/note", EditItemNote(showType, "showId"))
+		show.GET("/:showId/refresh", RefreshShowMetadata)
+		show.GET("/:showId/providers", WatchProvidersInfo(showType, "showId"))
+		show.GET("/:showId/persons", ItemPersons(showType, "showId"))
 	}
 	// TODO
 	// episode := r.Group("/episode")
@@ -263,6 +406,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		library.GET("/show/play/:showId/:season/:episode", PlayShow(s))
 
 		library.GET("/update", UpdateLibrary)
+		library.GET("/journal", LibraryJournal)
 
 		// DEPRECATED
 		library.GET("/play/movie/:tmdbId", PlayMovie(s))
@@ -277,11 +421,13 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	provider := r.Group("/provider")
 	{
 		provider.GET("/", ProviderList)
+		provider.GET("/:provider", ProviderStatus)
 		provider.GET("/:provider/check", ProviderCheck)
 		provider.GET("/:provider/enable", ProviderEnable)
 		provider.GET("/:provider/disable", ProviderDisable)
 		provider.GET("/:provider/failure", ProviderFailure)
 		provider.GET("/:provider/settings", ProviderSettings)
+		provider.GET("/:provider/limits", ProviderLimits)
 
 		provider.GET("/:provider/movie/:tmdbId", ProviderGetMovie)
 		provider.GET("/:provider/show/:showId/season/:season/episode/:episode", ProviderGetEpisode)
@@ -291,6 +437,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	{
 		allproviders.GET("/enable", ProvidersEnableAll)
 		allproviders.GET("/disable", ProvidersDisableAll)
+		allproviders.GET("/benchmark", ProvidersBenchmark)
 	}
 
 	repo := r.Group("/repository")
@@ -303,8 +450,35 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 	{
 		trakt.GET("/authorize", AuthorizeTrakt)
 		trakt.GET("/deauthorize", DeauthorizeTrakt)
+		trakt.GET("/profile/switch", SwitchTraktProfile)
 		trakt.GET("/select_list/:action/:media", SelectTraktUserList)
 		trakt.GET("/update", UpdateTrakt)
+		trakt.GET("/history", TraktMyHistory)
+		trakt.GET("/history/remove/:historyId", TraktHistoryRemove)
+		trakt.GET("/history/note/:historyId", TraktHistoryNote)
+		trakt.GET("/recommendations/dismiss/:media/:traktId", DismissRecommendation)
+		trakt.GET("/comment/:commentId", TraktComment)
+		trakt.GET("/lists/create", CreateTraktList)
+		trakt.GET("/lists/search", SearchTraktLists)
+		trakt.GET("/list/:listId/rename", RenameTraktList)
+		trakt.GET("/list/:listId/privacy", ChangeTraktListPrivacy)
+		trakt.GET("/list/:listId/reorder", ReorderTraktList)
+		trakt.GET("/list/:listId/sort", SortTraktList)
+		trakt.GET("/list/:listId/delete", DeleteTraktList)
+		trakt.GET("/list/:listId/like", LikeTraktList(true))
+		trakt.GET("/list/:listId/unlike", LikeTraktList(false))
+		trakt.GET("/list/:listId/sync", SyncTraktList)
+	}
+
+	r.GET("/notinterested/:media/:tmdbId", NotInterested)
+
+	profiles := r.Group("/profiles")
+	{
+		profiles.GET("/list", ProfilesList)
+		profiles.GET("/get/:name", ProfileGet)
+		profiles.GET("/export", ProfileExport)
+		profiles.GET("/pull", ProfilePull)
+		profiles.GET("/delete/:name", ProfileDelete)
 	}
 
 	r.GET("/setviewmode/:content_type", SetViewMode)
@@ -338,6 +512,8 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 
 		cmd.GET("/paste/:type", Pastebin)
 
+		cmd.GET("/reload_proxy_rules", ReloadProxyRules)
+
 		cmd.GET("/select_interface/:type", SelectNetworkInterface)
 		cmd.GET("/select_strm_language", SelectStrmLanguage)
 
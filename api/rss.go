@@ -0,0 +1,149 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/rss"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// RSSFeeds lists configured RSS feeds
+func RSSFeeds(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{}
+	for _, feed := range database.GetStorm().GetRSSFeeds() {
+		label := feed.Name
+		toggleAction := []string{"LOCALIZE[30747]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/rss/toggle/%d", feed.ID))}
+		if !feed.Enabled {
+			label = fmt.Sprintf("[COLOR FF999999]%s[/COLOR]", feed.Name)
+			toggleAction = []string{"LOCALIZE[30746]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/rss/toggle/%d", feed.ID))}
+		}
+
+		plot := feed.URL
+		if feed.TitleRegex != "" {
+			plot += "\n" + feed.TitleRegex
+		}
+		for _, item := range database.GetStorm().GetRSSItems(feed.ID) {
+			plot += fmt.Sprintf("\n%s  %s", item.Added.Format("2006-01-02"), item.Title)
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     label,
+			Path:      URLForXBMC("/rss/edit/%d", feed.ID),
+			Thumbnail: config.AddonResource("img", "cloud.png"),
+			Info:      &xbmc.ListItemInfo{Plot: plot},
+			ContextMenu: [][]string{
+				toggleAction,
+				{"LOCALIZE[30748]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/rss/remove/%d", feed.ID))},
+				{"LOCALIZE[30749]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/rss/check"))},
+			},
+		})
+	}
+	items = append(items, &xbmc.ListItem{
+		Label:     "LOCALIZE[30739]",
+		Path:      URLForXBMC("/rss/add"),
+		Thumbnail: config.AddonResource("img", "cloud.png"),
+	})
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// AddRSSFeed asks for a feed and its match rules
+func AddRSSFeed(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	feed := &database.RSSFeed{Enabled: true}
+	if editRSSFeed(feed) {
+		if err := database.GetStorm().SaveRSSFeed(feed); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		xbmc.Refresh()
+	}
+	ctx.String(200, "")
+}
+
+// EditRSSFeed changes a feed and its match rules
+func EditRSSFeed(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	id, _ := strconv.Atoi(ctx.Params.ByName("feedId"))
+	if feed := database.GetStorm().GetRSSFeed(id); feed != nil && editRSSFeed(feed) {
+		if err := database.GetStorm().SaveRSSFeed(feed); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		xbmc.Refresh()
+	}
+	ctx.String(200, "")
+}
+
+// ToggleRSSFeed enables or disables a feed
+func ToggleRSSFeed(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	id, _ := strconv.Atoi(ctx.Params.ByName("feedId"))
+	if feed := database.GetStorm().GetRSSFeed(id); feed != nil {
+		feed.Enabled = !feed.Enabled
+		if err := database.GetStorm().SaveRSSFeed(feed); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		xbmc.Refresh()
+	}
+	ctx.String(200, "")
+}
+
+// RemoveRSSFeed removes a feed
+func RemoveRSSFeed(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	id, _ := strconv.Atoi(ctx.Params.ByName("feedId"))
+	if err := database.GetStorm().DeleteRSSFeed(id); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	xbmc.Refresh()
+	ctx.String(200, "")
+}
+
+// CheckRSSFeeds checks all enabled feeds right away
+func CheckRSSFeeds(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		added := rss.Check(s)
+		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30750];;%d", added), config.AddonIcon())
+		xbmc.Refresh()
+		ctx.String(200, "")
+	}
+}
+
+// editRSSFeed asks for feed fields one by one, returns false if required field is cancelled
+func editRSSFeed(feed *database.RSSFeed) bool {
+	if feed.Name = strings.TrimSpace(xbmc.Keyboard(feed.Name, "LOCALIZE[30740]")); feed.Name == "" {
+		return false
+	}
+	feedURL := strings.TrimSpace(xbmc.Keyboard(feed.URL, "LOCALIZE[30741]"))
+	if u, err := url.Parse(feedURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		xbmc.Notify("Elementum", "LOCALIZE[30741]", config.AddonIcon())
+		return false
+	}
+	feed.URL = feedURL
+	feed.TitleRegex = strings.TrimSpace(xbmc.Keyboard(feed.TitleRegex, "LOCALIZE[30742]"))
+
+	resolutions := append([]string{"LOCALIZE[30737]"}, bittorrent.Resolutions[1:]...)
+	if choice := xbmc.ListDialog("LOCALIZE[30743]", resolutions...); choice >= 0 {
+		feed.MinResolution = choice
+	}
+
+	feed.MaxSize = parseIntFilter(xbmc.Keyboard(intFilter(feed.MaxSize), "LOCALIZE[30744]"))
+	feed.ShowID = parseIntFilter(xbmc.Keyboard(intFilter(feed.ShowID), "LOCALIZE[30745]"))
+	return true
+}
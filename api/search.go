@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/asdine/storm/q"
@@ -15,6 +16,7 @@ import (
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -82,7 +84,7 @@ func Search(s *bittorrent.Service) gin.HandlerFunc {
 		}
 
 		if len(torrents) == 0 {
-			xbmc.Notify("Elementum", "LOCALIZE[30205]", config.AddonIcon())
+			notifyNoTorrents()
 			return
 		}
 
@@ -149,6 +151,120 @@ func Search(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// SearchEverywhere runs query through metadata search and through all providers,
+// showing found movies, shows and raw torrents in one listing
+func SearchEverywhere(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+	query := ctx.Query("q")
+	keyboard := ctx.Query("keyboard")
+	historyType := "everywhere"
+
+	if len(query) == 0 {
+		searchHistoryProcess(ctx, historyType, keyboard)
+		return
+	}
+
+	// Update query last use date to show it on the top
+	database.GetStorm().AddSearchHistory(historyType, query)
+
+	var movies tmdb.Movies
+	var shows tmdb.Shows
+	var torrents []*bittorrent.TorrentFile
+	fakeTmdbID := strconv.Itoa(int(xxhash.Sum64String(query)))
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		movies, _ = tmdb.SearchMovies(query, config.Get().Language, 1)
+	}()
+	go func() {
+		defer wg.Done()
+		shows, _ = tmdb.SearchShows(query, config.Get().Language, 1)
+	}()
+	go func() {
+		defer wg.Done()
+
+		var err error
+		if torrents, err = GetCachedTorrents(fakeTmdbID); err != nil || len(torrents) == 0 {
+			searchLog.Infof("Searching providers for: %s", query)
+
+			torrents = providers.Search(providers.GetSearchers(), query)
+			SetCachedTorrents(fakeTmdbID, torrents)
+		}
+	}()
+	wg.Wait()
+
+	items := make(xbmc.ListItems, 0, len(movies)+len(shows)+len(torrents))
+	for _, movie := range movies {
+		if movie == nil {
+			continue
+		}
+
+		item := movie.ToListItem()
+		item.Label = "[B]LOCALIZE[30214][/B]: " + item.Label
+		thisURL := URLForXBMC("/movie/%d/", movie.ID) + "%s/%s"
+		contextTitle := fmt.Sprintf("%s (%d)", item.Info.OriginalTitle, item.Info.Year)
+		item.Path = contextPlayURL(thisURL, contextTitle, false)
+		item.IsPlayable = true
+		items = append(items, item)
+	}
+	for _, show := range shows {
+		if show == nil {
+			continue
+		}
+
+		item := show.ToListItem()
+		item.Label = "[B]LOCALIZE[30215][/B]: " + item.Label
+		item.Path = URLForXBMC("/show/%d/seasons", show.ID)
+		items = append(items, item)
+	}
+	for _, torrent := range torrents {
+		items = append(items, &xbmc.ListItem{
+			Label:     searchTorrentLabel(torrent),
+			Label2:    torrent.Name,
+			Icon:      torrent.Icon,
+			Thumbnail: torrent.Icon,
+			Info: &xbmc.ListItemInfo{
+				Title: torrent.Name,
+			},
+			Path: URLQuery(
+				URLForXBMC("/play"),
+				"uri", torrent.URI,
+				"query", query,
+				"tmdb", fakeTmdbID,
+				"type", "search"),
+			IsPlayable: true,
+		})
+	}
+
+	if len(items) == 0 {
+		notifyNoTorrents()
+	}
+
+	ctx.JSON(200, xbmc.NewView("", items))
+}
+
+// searchTorrentLabel formats one line label for a torrent, found by providers
+func searchTorrentLabel(torrent *bittorrent.TorrentFile) string {
+	info := make([]string, 0)
+	if torrent.Resolution > 0 {
+		info = append(info, fmt.Sprintf("[B][COLOR %s]%s[/COLOR][/B]", bittorrent.Colors[torrent.Resolution], bittorrent.Resolutions[torrent.Resolution]))
+	}
+	info = append(info, fmt.Sprintf("(%d / %d)", torrent.Seeds, torrent.Peers))
+	if torrent.Size != "" {
+		info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
+	}
+	if torrent.Provider != "" {
+		info = append(info, fmt.Sprintf("[B]%s[/B]", torrent.Provider))
+	}
+	info = append(info, torrent.Name)
+
+	return strings.Join(info, " ")
+}
+
 func searchHistoryProcess(ctx *gin.Context, historyType string, keyboard string) {
 	if len(keyboard) > 0 {
 		query := ""
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// seasonalCollection is a curated movies section, shown only during its date range
+type seasonalCollection struct {
+	ID         string
+	Label      string
+	Thumbnail  string
+	StartMonth time.Month
+	StartDay   int
+	EndMonth   time.Month
+	EndDay     int
+
+	// Trakt list as "user/listId", preferred over keywords if set
+	List func() string
+	// Comma separated TMDB keyword IDs
+	Keywords func() string
+}
+
+var seasonalCollections = []*seasonalCollection{
+	{
+		ID:         "halloween",
+		Label:      "LOCALIZE[30813]",
+		Thumbnail:  "genre_horror.png",
+		StartMonth: time.October,
+		StartDay:   1,
+		EndMonth:   time.November,
+		EndDay:     2,
+		List:       func() string { return config.Get().SeasonalHalloweenList },
+		Keywords:   func() string { return config.Get().SeasonalHalloweenKeywords },
+	},
+	{
+		ID:         "christmas",
+		Label:      "LOCALIZE[30814]",
+		Thumbnail:  "genre_family.png",
+		StartMonth: time.November,
+		StartDay:   25,
+		EndMonth:   time.January,
+		EndDay:     6,
+		List:       func() string { return config.Get().SeasonalChristmasList },
+		Keywords:   func() string { return config.Get().SeasonalChristmasKeywords },
+	},
+}
+
+// IsActive returns true if date is within collection range, ranges can wrap over the new year
+func (c *seasonalCollection) IsActive(now time.Time) bool {
+	day := int(now.Month())*100 + now.Day()
+	start := int(c.StartMonth)*100 + c.StartDay
+	end := int(c.EndMonth)*100 + c.EndDay
+
+	if start <= end {
+		return day >= start && day <= end
+	}
+	return day >= start || day <= end
+}
+
+func activeSeasonalCollections() []*seasonalCollection {
+	ret := []*seasonalCollection{}
+	if !config.Get().SeasonalCollections {
+		return ret
+	}
+
+	now := time.Now()
+	for _, c := range seasonalCollections {
+		if c.IsActive(now) && (c.List() != "" || c.Keywords() != "") {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func getSeasonalCollection(id string) *seasonalCollection {
+	for _, c := range seasonalCollections {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// seasonalMenuItems returns menu items for currently active seasonal collections
+func seasonalMenuItems() xbmc.ListItems {
+	items := xbmc.ListItems{}
+	for _, c := range activeSeasonalCollections() {
+		items = append(items, &xbmc.ListItem{
+			Label:     c.Label,
+			Path:      URLForXBMC("/movies/seasonal/%s", c.ID),
+			Thumbnail: config.AddonResource("img", c.Thumbnail),
+		})
+	}
+	return items
+}
+
+// SeasonalMovies shows movies of a seasonal collection
+func SeasonalMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	c := getSeasonalCollection(ctx.Params.ByName("collection"))
+	if c == nil {
+		ctx.String(404, "")
+		return
+	}
+
+	pageParam := ctx.DefaultQuery("page", "1")
+	page, _ := strconv.Atoi(pageParam)
+
+	if list := c.List(); list != "" {
+		user, listID := "", list
+		if i := strings.Index(list, "/"); i >= 0 {
+			user, listID = list[:i], list[i+1:]
+		}
+
+		movies, err := trakt.ListItemsMovies(user, listID, false)
+		if err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		renderTraktMovies(ctx, movies, -1, page)
+		return
+	}
+
+	movies, total := tmdb.KeywordMovies(c.Keywords(), config.Get().Language, page)
+	renderMovies(ctx, movies, page, total, "")
+}
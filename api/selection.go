@@ -0,0 +1,240 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const (
+	selectionLibrary = iota
+	selectionWatchlist
+	selectionCollection
+	selectionDownload
+	selectionClear
+)
+
+// selection keeps TMDB ids, selected in listings, per media type
+var selection = struct {
+	sync.Mutex
+	items map[string][]int
+}{
+	items: map[string][]int{},
+}
+
+func selectedItems(media string) []int {
+	selection.Lock()
+	defer selection.Unlock()
+
+	return append([]int{}, selection.items[media]...)
+}
+
+func clearSelection(media string) {
+	selection.Lock()
+	defer selection.Unlock()
+
+	delete(selection.items, media)
+}
+
+// ToggleSelection adds an item to selection, or removes it, if it is already selected
+func ToggleSelection(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+
+	selection.Lock()
+	items := selection.items[media]
+	found := false
+	for i, id := range items {
+		if id == tmdbID {
+			items = append(items[:i], items[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		items = append(items, tmdbID)
+	}
+	selection.items[media] = items
+	selection.Unlock()
+
+	xbmc.Notify("Elementum", fmt.Sprintf("%d items selected", len(items)), config.AddonIcon())
+	ctx.Abort()
+	library.ClearPageCache()
+}
+
+// SelectionActions asks for an action and runs it for all selected items at once
+func SelectionActions(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		media := ctx.Params.ByName("media")
+		ids := selectedItems(media)
+		if len(ids) == 0 {
+			return
+		}
+
+		labels := []string{"Add to library", "Add to Trakt watchlist", "LOCALIZE[30855]"}
+		actions := []int{selectionLibrary, selectionWatchlist, selectionCollection}
+		if media == movieType {
+			labels = append(labels, "Download")
+			actions = append(actions, selectionDownload)
+		}
+		labels = append(labels, "Clear selection")
+		actions = append(actions, selectionClear)
+
+		choice := xbmc.ListDialog(fmt.Sprintf("%d selected items", len(ids)), labels...)
+		if choice < 0 || choice >= len(actions) {
+			return
+		}
+
+		var err error
+		switch actions[choice] {
+		case selectionLibrary:
+			err = selectionAddToLibrary(media, ids)
+		case selectionWatchlist:
+			err = selectionSyncTrakt(media, ids, 1)
+		case selectionCollection:
+			err = selectionSyncTrakt(media, ids, 0)
+		case selectionDownload:
+			err = downloadSelection(s, ids)
+		}
+
+		if err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		if actions[choice] != selectionClear {
+			xbmc.Notify("Elementum", fmt.Sprintf("%s: %d items done", labels[choice], len(ids)), config.AddonIcon())
+		}
+		clearSelection(media)
+		ctx.Abort()
+		library.ClearPageCache()
+	}
+}
+
+// selectionActions returns context menu entries for selecting an item and acting on selected items
+func selectionActions(media string, tmdbID int) [][]string {
+	ids := selectedItems(media)
+
+	label := "Select"
+	if util.IntSliceContains(ids, tmdbID) {
+		label = "Unselect"
+	}
+	ret := [][]string{
+		{label, fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/selection/%s/toggle/%d", media, tmdbID))},
+	}
+	if len(ids) > 0 {
+		ret = append(ret, []string{fmt.Sprintf("Selected items (%d)", len(ids)), fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/selection/%s", media))})
+	}
+
+	return ret
+}
+
+func selectionAddToLibrary(media string, ids []int) error {
+	added := []int{}
+	for _, id := range ids {
+		var err error
+		if media == movieType {
+			_, err = library.AddMovie(strconv.Itoa(id), false, library.ReasonSelection)
+		} else {
+			_, err = library.AddShow(strconv.Itoa(id), false, library.ReasonSelection)
+		}
+
+		if err != nil {
+			log.Warningf("Could not add %s %d to library: %s", media, id, err)
+			continue
+		}
+		added = append(added, id)
+	}
+
+	if len(added) == 0 {
+		return fmt.Errorf("No items were added to library")
+	}
+
+	if config.Get().TraktToken != "" {
+		if media == movieType && config.Get().TraktSyncAddedMovies {
+			go trakt.SyncAddedItems("movies", added, config.Get().TraktSyncAddedMoviesLocation)
+		} else if media == showType && config.Get().TraktSyncAddedShows {
+			go trakt.SyncAddedItems("shows", added, config.Get().TraktSyncAddedShowsLocation)
+		}
+	}
+
+	if config.Get().LibraryUpdate == 0 {
+		library.PlanKodiUpdate()
+	}
+
+	return nil
+}
+
+// selectionSyncTrakt adds all items to Trakt list with one request, to avoid rate limits
+func selectionSyncTrakt(media string, ids []int, location int) error {
+	itemType := "movies"
+	if media == showType {
+		itemType = "shows"
+	}
+
+	resp, err := trakt.SyncAddedItems(itemType, ids, location)
+	if err != nil {
+		return err
+	} else if resp != nil && resp.Status() != 201 {
+		return fmt.Errorf("Failed with %d status code", resp.Status())
+	}
+
+	listType := "watchlist"
+	if location == 0 {
+		listType = "collection"
+	}
+	database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey(fmt.Sprintf("com.trakt.%s.%s", listType, itemType))))
+	database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey(fmt.Sprintf("com.trakt.%s.%s", itemType, listType))))
+	return nil
+}
+
+// downloadSelection adds best found torrent of each movie to downloads
+func downloadSelection(s *bittorrent.Service, ids []int) error {
+	dialog := xbmc.NewDialogProgressBG("Elementum", "Download", "Download")
+	if dialog != nil {
+		defer dialog.Close()
+	}
+
+	for i, id := range ids {
+		tmdbID := strconv.Itoa(id)
+		if dialog != nil {
+			dialog.Update(i*100/len(ids), "Elementum", fmt.Sprintf("Download: %d/%d", i+1, len(ids)))
+		}
+
+		torrents := movieLinks(tmdbID)
+		if len(torrents) == 0 {
+			log.Warningf("No links found for movie %s", tmdbID)
+			continue
+		}
+
+		t, err := s.AddTorrent(torrents[0].URI, false, config.Get().DownloadStorage)
+		if err != nil {
+			log.Warningf("Could not add torrent for movie %s: %s", tmdbID, err)
+			continue
+		}
+
+		database.GetStorm().UpdateBTItem(t.InfoHash(), id, movieType, []string{}, t.Name(), 0, 0, 0)
+		if file, _, err := t.ChooseFile(nil); err == nil && file != nil {
+			t.DownloadFile(file)
+			t.SaveDBFiles()
+		}
+	}
+
+	return nil
+}
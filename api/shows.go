@@ -26,29 +26,38 @@ import (
 func TVIndex(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
+	country := countryName()
 	items := xbmc.ListItems{
 		{Label: "LOCALIZE[30209]", Path: URLForXBMC("/shows/search"), Thumbnail: config.AddonResource("img", "search.png")},
 
 		{Label: "Trakt > LOCALIZE[30360]", Path: URLForXBMC("/shows/trakt/progress"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
-		{Label: "Trakt > LOCALIZE[30263]", Path: URLForXBMC("/shows/trakt/lists/"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30263]", Path: URLForXBMC("/shows/trakt/lists/"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30838]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/lists/create"))}}, TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30800]", Path: URLForXBMC("/trakt/lists/search"), Thumbnail: config.AddonResource("img", "trakt.png")},
 		{Label: "Trakt > LOCALIZE[30254]", Path: URLForXBMC("/shows/trakt/watchlist"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30252]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/show/list/add/watchlist"))}}, TraktAuth: true},
 		{Label: "Trakt > LOCALIZE[30257]", Path: URLForXBMC("/shows/trakt/collection"), Thumbnail: config.AddonResource("img", "trakt.png"), ContextMenu: [][]string{{"LOCALIZE[30252]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/library/show/list/add/collection"))}}, TraktAuth: true},
-		{Label: "Trakt > LOCALIZE[30290]", Path: URLForXBMC("/shows/trakt/calendars/"), Thumbnail: config.AddonResource("img", "most_anticipated.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30882]", Path: URLForXBMC("/shows/trakt/ratings"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
+		{Label: "Trakt > LOCALIZE[30290]", Path: URLForXBMC("/shows/trakt/calendars/"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
 		{Label: "Trakt > LOCALIZE[30423]", Path: URLForXBMC("/shows/trakt/recommendations"), Thumbnail: config.AddonResource("img", "tv.png"), TraktAuth: true},
 		{Label: "Trakt > LOCALIZE[30246]", Path: URLForXBMC("/shows/trakt/trending"), Thumbnail: config.AddonResource("img", "trending.png")},
 		{Label: "Trakt > LOCALIZE[30210]", Path: URLForXBMC("/shows/trakt/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
-		{Label: "Trakt > LOCALIZE[30247]", Path: URLForXBMC("/shows/trakt/played"), Thumbnail: config.AddonResource("img", "most_played.png")},
-		{Label: "Trakt > LOCALIZE[30248]", Path: URLForXBMC("/shows/trakt/watched"), Thumbnail: config.AddonResource("img", "most_watched.png")},
-		{Label: "Trakt > LOCALIZE[30249]", Path: URLForXBMC("/shows/trakt/collected"), Thumbnail: config.AddonResource("img", "most_collected.png")},
+		{Label: fmt.Sprintf("Trakt > LOCALIZE[30787] %s", country), Path: URLForXBMC("/shows/trakt/popular/local"), Thumbnail: config.AddonResource("img", "popular.png")},
+		{Label: "Trakt > LOCALIZE[30289]", Path: URLForXBMC("/shows/trakt/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
+		{Label: "Trakt > LOCALIZE[30247]", Path: URLForXBMC("/shows/trakt/periods/played"), Thumbnail: config.AddonResource("img", "most_played.png")},
+		{Label: "Trakt > LOCALIZE[30248]", Path: URLForXBMC("/shows/trakt/periods/watched"), Thumbnail: config.AddonResource("img", "most_watched.png")},
+		{Label: "Trakt > LOCALIZE[30249]", Path: URLForXBMC("/shows/trakt/periods/collected"), Thumbnail: config.AddonResource("img", "most_collected.png")},
 		{Label: "Trakt > LOCALIZE[30250]", Path: URLForXBMC("/shows/trakt/anticipated"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
+		{Label: "Trakt > LOCALIZE[30250] LOCALIZE[30936]", Path: URLForXBMC("/shows/trakt/anticipated/release"), Thumbnail: config.AddonResource("img", "most_anticipated.png")},
 
 		{Label: "TMDB > LOCALIZE[30238]", Path: URLForXBMC("/shows/recent/episodes"), Thumbnail: config.AddonResource("img", "fresh.png")},
 		{Label: "TMDB > LOCALIZE[30237]", Path: URLForXBMC("/shows/recent/shows"), Thumbnail: config.AddonResource("img", "clock.png")},
 		{Label: "TMDB > LOCALIZE[30210]", Path: URLForXBMC("/shows/popular"), Thumbnail: config.AddonResource("img", "popular.png")},
+		{Label: fmt.Sprintf("TMDB > LOCALIZE[30787] %s", country), Path: URLForXBMC("/shows/popular/local"), Thumbnail: config.AddonResource("img", "popular.png")},
 		{Label: "TMDB > LOCALIZE[30211]", Path: URLForXBMC("/shows/top"), Thumbnail: config.AddonResource("img", "top_rated.png")},
 		{Label: "TMDB > LOCALIZE[30212]", Path: URLForXBMC("/shows/mostvoted"), Thumbnail: config.AddonResource("img", "most_voted.png")},
 		{Label: "TMDB > LOCALIZE[30289]", Path: URLForXBMC("/shows/genres"), Thumbnail: config.AddonResource("img", "genre_comedy.png")},
+		{Label: "TMDB > LOCALIZE[30785]", Path: URLForXBMC("/shows/networks"), Thumbnail: config.AddonResource("img", "tv.png")},
 		{Label: "TMDB > LOCALIZE[30373]", Path: URLForXBMC("/shows/languages"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
+		{Label: "TMDB > LOCALIZE[30719]", Path: URLForXBMC("/discover/show"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
 		// Note: Search by countries is implemented, but TMDB does not support it yet,
 		// so we are not showing this. When there is an endpoint - we can enable
 		// and modify the URL params to /discover endpoint
@@ -56,7 +65,9 @@ func TVIndex(ctx *gin.Context) {
 
 		{Label: "Trakt > LOCALIZE[30361]", Path: URLForXBMC("/shows/trakt/history"), Thumbnail: config.AddonResource("img", "trakt.png"), TraktAuth: true},
 
+		{Label: "LOCALIZE[30759]", Path: URLForXBMC("/autodownload/"), Thumbnail: config.AddonResource("img", "clock.png")},
 		{Label: "LOCALIZE[30517]", Path: URLForXBMC("/shows/library"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
+		{Label: "LOCALIZE[30912]", Path: URLForXBMC("/tags/show"), Thumbnail: config.AddonResource("img", "genre_tv.png")},
 	}
 	for _, item := range items {
 		item.ContextMenu = append([][]string{
@@ -102,6 +113,31 @@ func TVGenres(ctx *gin.Context) {
 	ctx.JSON(200, xbmc.NewView("menus_tvshows_genres", filterListItems(items)))
 }
 
+// TVNetworks lists popular TV networks and streaming services to browse shows by
+func TVNetworks(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := make(xbmc.ListItems, 0, len(tmdb.PopularNetworks))
+	for _, network := range tmdb.PopularNetworks {
+		items = append(items, &xbmc.ListItem{
+			Label:     network.Name,
+			Path:      URLForXBMC("/shows/network/%d", network.ID),
+			Thumbnail: config.AddonResource("img", "tv.png"),
+		})
+	}
+	ctx.JSON(200, xbmc.NewView("menus_tvshows_genres", filterListItems(items)))
+}
+
+// NetworkShows shows popular shows of a TV network
+func NetworkShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	networkID, _ := strconv.Atoi(ctx.Params.ByName("networkId"))
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.ShowsByNetwork(networkID, config.Get().Language, page)
+	renderShows(ctx, filterNotInterestedShows(shows), page, total, "")
+}
+
 // TVLanguages ...
 func TVLanguages(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -192,9 +228,9 @@ func TVTraktLists(ctx *gin.Context) {
 			Label:     list.Name,
 			Path:      link,
 			Thumbnail: config.AddonResource("img", "trakt.png"),
-			ContextMenu: [][]string{
+			ContextMenu: append([][]string{
 				menuItem,
-			},
+			}, traktListActions(list)...),
 		}
 		items = append(items, item)
 	}
@@ -207,9 +243,9 @@ func CalendarShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	items := xbmc.ListItems{
-		{Label: "LOCALIZE[30295]", Path: URLForXBMC("/shows/trakt/calendars/shows"), Thumbnail: config.AddonResource("img", "tv.png")},
-		{Label: "LOCALIZE[30296]", Path: URLForXBMC("/shows/trakt/calendars/newshows"), Thumbnail: config.AddonResource("img", "fresh.png")},
-		{Label: "LOCALIZE[30297]", Path: URLForXBMC("/shows/trakt/calendars/premieres"), Thumbnail: config.AddonResource("img", "box_office.png")},
+		{Label: "LOCALIZE[30295]", Path: URLForXBMC("/shows/trakt/calendars/shows"), Thumbnail: config.AddonResource("img", "tv.png"), TraktAuth: true},
+		{Label: "LOCALIZE[30296]", Path: URLForXBMC("/shows/trakt/calendars/newshows"), Thumbnail: config.AddonResource("img", "fresh.png"), TraktAuth: true},
+		{Label: "LOCALIZE[30297]", Path: URLForXBMC("/shows/trakt/calendars/premieres"), Thumbnail: config.AddonResource("img", "box_office.png"), TraktAuth: true},
 		{Label: "LOCALIZE[30298]", Path: URLForXBMC("/shows/trakt/calendars/allshows"), Thumbnail: config.AddonResource("img", "tv.png")},
 		{Label: "LOCALIZE[30299]", Path: URLForXBMC("/shows/trakt/calendars/allnewshows"), Thumbnail: config.AddonResource("img", "fresh.png")},
 		{Label: "LOCALIZE[30300]", Path: URLForXBMC("/shows/trakt/calendars/allpremieres"), Thumbnail: config.AddonResource("img", "box_office.png")},
@@ -228,7 +264,7 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 	items := make(xbmc.ListItems, 0, len(shows)+hasNextPage)
 
 	for _, show := range shows {
-		if show == nil {
+		if show == nil || !util.IsContentAllowed(show.OriginalLanguage, show.VoteCount) {
 			continue
 		}
 		item := show.ToListItem()
@@ -260,6 +296,20 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 			{"LOCALIZE[30035]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/tvshows"))},
 		}
 		item.ContextMenu = append(libraryActions, item.ContextMenu...)
+		if action := notInterestedAction(ctx, showType, show.ID); action != nil {
+			item.ContextMenu = append(item.ContextMenu, action)
+		}
+		if config.Get().IntroOffsetEnabled {
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30818]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/intro", show.ID))})
+		}
+		item.ContextMenu = append(item.ContextMenu, spoilersAction(show.ID))
+		item.ContextMenu = append(item.ContextMenu, autoDownloadAction(show.ID))
+		item.ContextMenu = append(item.ContextMenu, tagsActions(showType, show.ID)...)
+		item.ContextMenu = append(item.ContextMenu, selectionActions(showType, show.ID)...)
+		item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/refresh", show.ID))})
+		item.ContextMenu = append(item.ContextMenu, watchProvidersAction(showType, show.ID))
+		item.ContextMenu = append(item.ContextMenu, personsAction(showType, show.ID))
+		applyTags(item, showType, show.ID)
 
 		if config.Get().Platform.Kodi < 17 {
 			item.ContextMenu = append(item.ContextMenu,
@@ -285,6 +335,66 @@ func renderShows(ctx *gin.Context, shows tmdb.Shows, page int, total int, query
 	ctx.JSON(200, xbmc.NewView("tvshows", filterListItems(items)))
 }
 
+// ShowIntroOffset asks for a start offset, used to skip intro of show episodes
+func ShowIntroOffset(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+
+	current := ""
+	if intro := database.GetStorm().GetShowIntro(showID); intro != nil {
+		current = strconv.Itoa(intro.Offset)
+	}
+
+	value := xbmc.Keyboard(current, "LOCALIZE[30921]")
+	if value == "" {
+		return
+	}
+
+	offset, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || offset < database.IntroOffsetDisabled {
+		xbmc.Notify("Elementum", "LOCALIZE[30922]", config.AddonIcon())
+		return
+	}
+
+	if err := database.GetStorm().SetShowIntroOffset(showID, offset); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30923]", config.AddonIcon())
+	ctx.String(200, "")
+}
+
+// ToggleShowSpoilers enables or disables hiding of unwatched episode details for a show
+func ToggleShowSpoilers(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	enabled := !database.GetStorm().IsSpoilerProtected(showID)
+	if err := database.GetStorm().SetSpoilerProtection(showID, enabled); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if enabled {
+		xbmc.Notify("Elementum", "LOCALIZE[30924]", config.AddonIcon())
+	} else {
+		xbmc.Notify("Elementum", "LOCALIZE[30925]", config.AddonIcon())
+	}
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+func spoilersAction(showID int) []string {
+	if database.GetStorm().IsSpoilerProtected(showID) {
+		return []string{"LOCALIZE[30820]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/spoilers", showID))}
+	}
+	return []string{"LOCALIZE[30819]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/spoilers", showID))}
+}
+
 // PopularShows ...
 func PopularShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -299,7 +409,16 @@ func PopularShows(ctx *gin.Context) {
 
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	shows, total := tmdb.PopularShows(p, config.Get().Language, page)
-	renderShows(ctx, shows, page, total, "")
+	renderShows(ctx, filterNotInterestedShows(shows), page, total, "")
+}
+
+// CountryPopularShows lists popular shows, available to stream in configured country
+func CountryPopularShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	shows, total := tmdb.CountryPopularShows(config.Get().Region, config.Get().Language, page)
+	renderShows(ctx, filterNotInterestedShows(shows), page, total, "")
 }
 
 // RecentShows ...
@@ -409,6 +528,7 @@ func ShowSeasons(ctx *gin.Context) {
 			{contextLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextURL)},
 			{contextOppositeLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextOppositeURL)},
 			{"LOCALIZE[30036]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/seasons"))},
+			{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/refresh", show.ID))},
 		}
 
 		reversedItems = append(reversedItems, item)
@@ -514,6 +634,8 @@ func ShowEpisodes(ctx *gin.Context) {
 					{"LOCALIZE[30037]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/episodes"))},
 				}
 			}
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30809]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/season/%d/episode/%d/dryrun", show.ID, seasonNumber, item.Info.Episode))})
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/refresh", show.ID))})
 			item.IsPlayable = true
 		}
 
@@ -540,12 +662,23 @@ func showSeasonLinks(showID int, seasonNumber int) ([]*bittorrent.TorrentFile, e
 
 	searchers := providers.GetSeasonSearchers()
 	if len(searchers) == 0 {
-		xbmc.Notify("Elementum", "LOCALIZE[30204]", config.AddonIcon())
+		notifyFailure(errNoProviders)
 	}
 
 	return providers.SearchSeason(searchers, show, season), nil
 }
 
+func showSeasonLinksStream(show *tmdb.Show, season *tmdb.Season) <-chan *providers.SearchResults {
+	log.Infof("Streaming links for %s season %d", show.Name, season.Season)
+
+	searchers := providers.GetSeasonSearchers()
+	if len(searchers) == 0 {
+		notifyFailure(errNoProviders)
+	}
+
+	return providers.SearchSeasonStream(searchers, show, season)
+}
+
 // ShowSeasonRun ...
 func ShowSeasonRun(action string, s *bittorrent.Service) gin.HandlerFunc {
 	defer perf.ScopeTimer()()
@@ -630,70 +763,40 @@ func ShowSeasonLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 
 		var torrents []*bittorrent.TorrentFile
 		var err error
+		choice := -1
+		streamed := false
 
 		fakeTmdbID := strconv.Itoa(showID) + "_" + strconv.Itoa(seasonNumber)
 		if torrents, err = GetCachedTorrents(fakeTmdbID); err != nil || len(torrents) == 0 {
-			torrents, err = showSeasonLinks(showID, seasonNumber)
+			if action != "play" && config.Get().StreamingSearch {
+				torrents, choice = chooseStreamedLinks(showSeasonLinksStream(show, season), fakeTmdbID, longName)
+				streamed, err = true, nil
+			} else {
+				torrents, err = showSeasonLinks(showID, seasonNumber)
 
-			SetCachedTorrents(fakeTmdbID, torrents)
+				SetCachedTorrents(fakeTmdbID, torrents)
+			}
 		}
 
 		if err != nil {
+			bittorrent.FinishPlaybackTiming(err.Error())
 			ctx.Error(err)
 			return
 		}
 
 		if len(torrents) == 0 {
-			xbmc.Notify("Elementum", "LOCALIZE[30205]", config.AddonIcon())
+			bittorrent.FinishPlaybackTiming("No torrents found")
+			notifyNoTorrents()
 			return
 		}
 
-		choices := make([]string, 0, len(torrents))
-		for _, torrent := range torrents {
-			resolution := ""
-			if torrent.Resolution > 0 {
-				resolution = fmt.Sprintf("[B][COLOR %s]%s[/COLOR][/B] ", bittorrent.Colors[torrent.Resolution], bittorrent.Resolutions[torrent.Resolution])
-			}
-
-			info := make([]string, 0)
-			if torrent.Size != "" {
-				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
-			}
-			if torrent.RipType > 0 {
-				info = append(info, bittorrent.Rips[torrent.RipType])
-			}
-			if torrent.VideoCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.VideoCodec])
-			}
-			if torrent.AudioCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
-			}
-			if torrent.Provider != "" {
-				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
-			}
-
-			multi := ""
-			if torrent.Multi {
-				multi = multiType
+		// Streamed torrents are already chosen, while results were arriving
+		if !streamed {
+			if action == "play" {
+				choice = 0
+			} else {
+				choice = xbmc.ListDialogLarge("LOCALIZE[30228]", longName, torrentChoices(torrents)...)
 			}
-
-			label := fmt.Sprintf("%s(%d / %d) %s\n%s\n%s%s",
-				resolution,
-				torrent.Seeds,
-				torrent.Peers,
-				strings.Join(info, " "),
-				torrent.Name,
-				torrent.Icon,
-				multi,
-			)
-			choices = append(choices, label)
-		}
-
-		choice := -1
-		if action == "play" {
-			choice = 0
-		} else {
-			choice = xbmc.ListDialogLarge("LOCALIZE[30228]", longName, choices...)
 		}
 
 		if choice >= 0 {
@@ -735,12 +838,23 @@ func showEpisodeLinks(showID int, seasonNumber int, episodeNumber int) ([]*bitto
 
 	searchers := providers.GetEpisodeSearchers()
 	if len(searchers) == 0 {
-		xbmc.Notify("Elementum", "LOCALIZE[30204]", config.AddonIcon())
+		notifyFailure(errNoProviders)
 	}
 
 	return providers.SearchEpisode(searchers, show, episode), nil
 }
 
+func showEpisodeLinksStream(show *tmdb.Show, episode *tmdb.Episode) <-chan *providers.SearchResults {
+	log.Infof("Streaming links for %s S%02dE%02d", show.Name, episode.SeasonNumber, episode.EpisodeNumber)
+
+	searchers := providers.GetEpisodeSearchers()
+	if len(searchers) == 0 {
+		notifyFailure(errNoProviders)
+	}
+
+	return providers.SearchEpisodeStream(searchers, show, episode)
+}
+
 // ShowEpisodeRun ...
 func ShowEpisodeRun(action string, s *bittorrent.Service) gin.HandlerFunc {
 	defer perf.ScopeTimer()()
@@ -766,14 +880,21 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			runAction = "/download"
 		}
 
+		if action != "download" {
+			bittorrent.StartPlaybackTiming(fmt.Sprintf("Show %d S%02dE%02d", showID, seasonNumber, episodeNumber))
+			bittorrent.PlaybackStep(bittorrent.StepMetadataResolve)
+		}
+
 		show := tmdb.GetShow(showID, config.Get().Language)
 		if show == nil {
+			bittorrent.FinishPlaybackTiming("Show not found")
 			ctx.Error(errors.New("Unable to find show"))
 			return
 		}
 
 		episode := tmdb.GetEpisode(showID, seasonNumber, episodeNumber, config.Get().Language)
 		if episode == nil {
+			bittorrent.FinishPlaybackTiming("Episode not found")
 			ctx.Error(errors.New("Unable to find episode"))
 			return
 		}
@@ -798,6 +919,10 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		if action != "download" && playLocalFile(ctx, episodeType, episode.ID, external) {
+			return
+		}
+
 		if torrent := InTorrentsMap(strconv.Itoa(episode.ID)); torrent != nil {
 			rURL := URLQuery(URLForXBMC(runAction),
 				"doresume", doresume,
@@ -817,12 +942,20 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 
 		var torrents []*bittorrent.TorrentFile
 		var err error
+		choice := -1
+		streamed := false
 
+		bittorrent.PlaybackStep(bittorrent.StepProviderSearch)
 		fakeTmdbID := strconv.Itoa(showID) + "_" + strconv.Itoa(seasonNumber) + "_" + strconv.Itoa(episodeNumber)
 		if torrents, err = GetCachedTorrents(fakeTmdbID); err != nil || len(torrents) == 0 {
-			torrents, err = showEpisodeLinks(showID, seasonNumber, episodeNumber)
+			if action != "play" && config.Get().StreamingSearch {
+				torrents, choice = chooseStreamedLinks(showEpisodeLinksStream(show, episode), fakeTmdbID, longName)
+				streamed, err = true, nil
+			} else {
+				torrents, err = showEpisodeLinks(showID, seasonNumber, episodeNumber)
 
-			SetCachedTorrents(fakeTmdbID, torrents)
+				SetCachedTorrents(fakeTmdbID, torrents)
+			}
 		}
 
 		if err != nil {
@@ -831,59 +964,23 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		}
 
 		if len(torrents) == 0 {
-			xbmc.Notify("Elementum", "LOCALIZE[30205]", config.AddonIcon())
+			notifyNoTorrents()
 			return
 		}
 
-		choices := make([]string, 0, len(torrents))
-		for _, torrent := range torrents {
-			resolution := ""
-			if torrent.Resolution > 0 {
-				resolution = fmt.Sprintf("[B][COLOR %s]%s[/COLOR][/B] ", bittorrent.Colors[torrent.Resolution], bittorrent.Resolutions[torrent.Resolution])
-			}
-
-			info := make([]string, 0)
-			if torrent.Size != "" {
-				info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
-			}
-			if torrent.RipType > 0 {
-				info = append(info, bittorrent.Rips[torrent.RipType])
-			}
-			if torrent.VideoCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.VideoCodec])
-			}
-			if torrent.AudioCodec > 0 {
-				info = append(info, bittorrent.Codecs[torrent.AudioCodec])
-			}
-			if torrent.Provider != "" {
-				info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
-			}
-
-			multi := ""
-			if torrent.Multi {
-				multi = multiType
+		bittorrent.PlaybackStep(bittorrent.StepStreamChoice)
+		// Streamed torrents are already chosen, while results were arriving
+		if !streamed {
+			if action == "play" {
+				choice = 0
+			} else {
+				choice = xbmc.ListDialogLarge("LOCALIZE[30228]", longName, torrentChoices(torrents)...)
 			}
-
-			label := fmt.Sprintf("%s(%d / %d) %s\n%s\n%s%s",
-				resolution,
-				torrent.Seeds,
-				torrent.Peers,
-				strings.Join(info, " "),
-				torrent.Name,
-				torrent.Icon,
-				multi,
-			)
-			choices = append(choices, label)
 		}
 
-		choice := -1
-		if action == "play" {
-			choice = 0
+		if choice < 0 {
+			bittorrent.FinishPlaybackTiming("Cancelled")
 		} else {
-			choice = xbmc.ListDialogLarge("LOCALIZE[30228]", longName, choices...)
-		}
-
-		if choice >= 0 {
 			AddToTorrentsMap(strconv.Itoa(episode.ID), torrents[choice])
 
 			rURL := URLQuery(URLForXBMC(runAction),
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// Silent search endpoints do not show any dialogs and return ranked torrents as JSON,
+// so that they can be used by external schedulers. Providers, that require authorization,
+// are called only with "auth=1" query parameter.
+
+// SilentSearchMovie ...
+func SilentSearchMovie(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	movie := tmdb.GetMovieByID(ctx.Params.ByName("tmdbId"), config.Get().Language)
+	if movie == nil {
+		silentSearchError(ctx, 404, "Unable to find movie")
+		return
+	}
+
+	searchers := providers.GetMovieSearchers()
+	if len(searchers) == 0 {
+		silentSearchError(ctx, 503, errNoProviders.Error())
+		return
+	}
+
+	log.Infof("Silent search for movie %d: %s", movie.ID, movie.Title)
+	ctx.JSON(200, providers.SearchMovieSilent(searchers, movie, silentSearchAuth(ctx)))
+}
+
+// SilentSearchSeason ...
+func SilentSearchSeason(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+
+	show := tmdb.GetShow(showID, config.Get().Language)
+	if show == nil {
+		silentSearchError(ctx, 404, "Unable to find show")
+		return
+	}
+
+	season := tmdb.GetSeason(showID, seasonNumber, config.Get().Language, len(show.Seasons))
+	if season == nil {
+		silentSearchError(ctx, 404, "Unable to find season")
+		return
+	}
+
+	searchers := providers.GetSeasonSearchers()
+	if len(searchers) == 0 {
+		silentSearchError(ctx, 503, errNoProviders.Error())
+		return
+	}
+
+	log.Infof("Silent search for %s season %d", show.Name, seasonNumber)
+	ctx.JSON(200, providers.SearchSeasonSilent(searchers, show, season, silentSearchAuth(ctx)))
+}
+
+// SilentSearchEpisode ...
+func SilentSearchEpisode(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+	episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+
+	show := tmdb.GetShow(showID, config.Get().Language)
+	if show == nil {
+		silentSearchError(ctx, 404, "Unable to find show")
+		return
+	}
+
+	season := tmdb.GetSeason(showID, seasonNumber, config.Get().Language, len(show.Seasons))
+	if season == nil || episodeNumber < 1 || len(season.Episodes) < episodeNumber {
+		silentSearchError(ctx, 404, "Unable to find episode")
+		return
+	}
+
+	searchers := providers.GetEpisodeSearchers()
+	if len(searchers) == 0 {
+		silentSearchError(ctx, 503, errNoProviders.Error())
+		return
+	}
+
+	log.Infof("Silent search for %s S%02dE%02d", show.Name, seasonNumber, episodeNumber)
+	ctx.JSON(200, providers.SearchEpisodeSilent(searchers, show, season.Episodes[episodeNumber-1], silentSearchAuth(ctx)))
+}
+
+func silentSearchAuth(ctx *gin.Context) bool {
+	auth, _ := strconv.ParseBool(ctx.DefaultQuery("auth", "false"))
+	return auth
+}
+
+func silentSearchError(ctx *gin.Context, code int, message string) {
+	ctx.JSON(code, gin.H{"error": message})
+}
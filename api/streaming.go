@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// torrentChoices returns labels of torrents for the stream choice dialog
+func torrentChoices(torrents []*bittorrent.TorrentFile) []string {
+	choices := make([]string, 0, len(torrents))
+	for _, torrent := range torrents {
+		resolution := ""
+		if torrent.Resolution > 0 {
+			resolution = fmt.Sprintf("[B][COLOR %s]%s[/COLOR][/B] ", bittorrent.Colors[torrent.Resolution], bittorrent.Resolutions[torrent.Resolution])
+		}
+
+		info := make([]string, 0)
+		if torrent.Size != "" {
+			info = append(info, fmt.Sprintf("[B][%s][/B]", torrent.Size))
+		}
+		if torrent.RipType > 0 {
+			info = append(info, bittorrent.Rips[torrent.RipType])
+		}
+		if torrent.VideoCodec > 0 {
+			info = append(info, bittorrent.Codecs[torrent.VideoCodec])
+		}
+		if torrent.AudioCodec > 0 {
+			info = append(info, bittorrent.Codecs[torrent.AudioCodec])
+		}
+		if torrent.Provider != "" {
+			info = append(info, fmt.Sprintf(" - [B]%s[/B]", torrent.Provider))
+		}
+
+		multi := ""
+		if torrent.Multi {
+			multi = multiType
+		}
+
+		label := fmt.Sprintf("%s(%d / %d) %s\n%s\n%s%s",
+			resolution,
+			torrent.Seeds,
+			torrent.Peers,
+			strings.Join(info, " "),
+			torrent.Name,
+			torrent.Icon,
+			multi,
+		)
+		choices = append(choices, label)
+	}
+
+	return choices
+}
+
+// chooseStreamedLinks shows torrents of the fastest providers right away,
+// with an entry to wait for remaining providers, which reopens the dialog with merged results.
+// Complete results are cached under cacheKey, even if user has chosen before search end.
+func chooseStreamedLinks(results <-chan *providers.SearchResults, cacheKey string, title string) ([]*bittorrent.TorrentFile, int) {
+	current := &providers.SearchResults{}
+	for {
+		current = nextStreamedLinks(results, current)
+		if len(current.Torrents) == 0 {
+			SetCachedTorrents(cacheKey, current.Torrents)
+			return current.Torrents, -1
+		}
+
+		choices := torrentChoices(current.Torrents)
+		if current.Pending > 0 {
+			choices = append(choices, fmt.Sprintf("[B]Wait for more results[/B]\n%d of %d providers answered\n%s", current.Total-current.Pending, current.Total, config.AddonIcon()))
+		}
+
+		choice := xbmc.ListDialogLarge("LOCALIZE[30228]", title, choices...)
+		if choice == len(current.Torrents) {
+			continue
+		}
+
+		if current.Pending == 0 {
+			SetCachedTorrents(cacheKey, current.Torrents)
+		} else {
+			go func(last *providers.SearchResults) {
+				for r := range results {
+					last = r
+				}
+				SetCachedTorrents(cacheKey, last.Torrents)
+			}(current)
+		}
+
+		return current.Torrents, choice
+	}
+}
+
+// nextStreamedLinks waits for results with new torrents, or for search end
+func nextStreamedLinks(results <-chan *providers.SearchResults, current *providers.SearchResults) *providers.SearchResults {
+	dialogProgressBG := xbmc.NewDialogProgressBG("Elementum", "LOCALIZE[30117]", "LOCALIZE[30117]")
+	if dialogProgressBG != nil {
+		defer dialogProgressBG.Close()
+	}
+
+	for r := range results {
+		if len(r.Torrents) > len(current.Torrents) || r.Pending == 0 {
+			return r
+		}
+
+		current = r
+		if dialogProgressBG != nil {
+			dialogProgressBG.Update((r.Total-r.Pending)*100/r.Total, "Elementum", "LOCALIZE[30117]")
+		}
+	}
+
+	// Search has ended, or there were no providers
+	return &providers.SearchResults{Torrents: current.Torrents, Total: current.Total}
+}
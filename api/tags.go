@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// TagsIndex lists local tags, used for movies or shows
+func TagsIndex(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	listPath := "/movies/trakt"
+	if media == showType {
+		listPath = "/shows/trakt"
+	}
+
+	items := xbmc.ListItems{}
+	for _, tag := range database.GetStorm().GetTags(media) {
+		items = append(items, &xbmc.ListItem{
+			Label:     tag,
+			Path:      URLForXBMC("/tags/%s/%s", media, url.PathEscape(tag)),
+			Thumbnail: config.AddonResource("img", "movies.png"),
+			ContextMenu: [][]string{
+				{"LOCALIZE[30904]", fmt.Sprintf("Container.Update(%s)", URLQuery(URLForXBMC(listPath+"/watchlist"), "tag", tag))},
+				{"LOCALIZE[30905]", fmt.Sprintf("Container.Update(%s)", URLQuery(URLForXBMC(listPath+"/collection"), "tag", tag))},
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
+
+// TaggedItems lists movies or shows, having the tag
+func TaggedItems(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	tag := ctx.Params.ByName("tag")
+	ids := database.GetStorm().GetTaggedItems(media, tag)
+
+	if media == showType {
+		shows := tmdb.Shows{}
+		for _, id := range ids {
+			if show := tmdb.GetShow(id, config.Get().Language); show != nil {
+				shows = append(shows, show)
+			}
+		}
+		renderShows(ctx, shows, -1, len(shows), "")
+		return
+	}
+
+	movies := tmdb.Movies{}
+	for _, id := range ids {
+		if movie := tmdb.GetMovie(id, config.Get().Language); movie != nil {
+			movies = append(movies, movie)
+		}
+	}
+	renderMovies(ctx, movies, -1, len(movies), "")
+}
+
+// EditItemTags asks for comma separated tags of a movie or a show
+func EditItemTags(mediaType, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		tmdbID, _ := strconv.Atoi(ctx.Params.ByName(param))
+
+		current := ""
+		if item := database.GetStorm().GetItemTags(mediaType, tmdbID); item != nil {
+			current = strings.Join(item.Tags, ", ")
+		}
+
+		value := xbmc.Keyboard(current, "LOCALIZE[30906]")
+		if value == current {
+			return
+		}
+
+		tags := []string{}
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" && !util.StringSliceContains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		if err := database.GetStorm().SetItemTags(mediaType, tmdbID, tags); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		xbmc.Notify("Elementum", "LOCALIZE[30907]", config.AddonIcon())
+		ctx.Abort()
+		library.ClearPageCache()
+	}
+}
+
+// EditItemNote asks for a personal note of a movie or a show
+func EditItemNote(mediaType, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		tmdbID, _ := strconv.Atoi(ctx.Params.ByName(param))
+
+		current := ""
+		if item := database.GetStorm().GetItemTags(mediaType, tmdbID); item != nil {
+			current = item.Note
+		}
+
+		value := xbmc.Keyboard(current, "LOCALIZE[30908]")
+		if value == current {
+			return
+		}
+
+		if err := database.GetStorm().SetItemNote(mediaType, tmdbID, strings.TrimSpace(value)); err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		xbmc.Notify("Elementum", "LOCALIZE[30909]", config.AddonIcon())
+		ctx.Abort()
+		library.ClearPageCache()
+	}
+}
+
+// tagsActions returns context menu entries for editing tags and note of an item
+func tagsActions(mediaType string, tmdbID int) [][]string {
+	return [][]string{
+		{"LOCALIZE[30910]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/%s/%d/tags", mediaType, tmdbID))},
+		{"LOCALIZE[30911]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/%s/%d/note", mediaType, tmdbID))},
+	}
+}
+
+// applyTags shows stored tags and note of an item at the top of its plot
+func applyTags(item *xbmc.ListItem, mediaType string, tmdbID int) {
+	tags := database.GetStorm().GetItemTags(mediaType, tmdbID)
+	if tags == nil || item.Info == nil {
+		return
+	}
+
+	header := ""
+	if len(tags.Tags) > 0 {
+		header += fmt.Sprintf("[B]Tags:[/B] %s\n", strings.Join(tags.Tags, ", "))
+	}
+	if tags.Note != "" {
+		header += fmt.Sprintf("[B]Note:[/B] %s\n", tags.Note)
+	}
+	item.Info.Plot = header + "\n" + item.Info.Plot
+}
+
+// filterTaggedMovies keeps only movies, having the tag, if the tag is set
+func filterTaggedMovies(movies []*trakt.Movies, tag string) []*trakt.Movies {
+	if tag == "" {
+		return movies
+	}
+
+	ids := database.GetStorm().GetTaggedItems(movieType, tag)
+	ret := make([]*trakt.Movies, 0, len(ids))
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && util.IntSliceContains(ids, m.Movie.IDs.TMDB) {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// filterTaggedShows keeps only shows, having the tag, if the tag is set
+func filterTaggedShows(shows []*trakt.Shows, tag string) []*trakt.Shows {
+	if tag == "" {
+		return shows
+	}
+
+	ids := database.GetStorm().GetTaggedItems(showType, tag)
+	ret := make([]*trakt.Shows, 0, len(ids))
+	for _, s := range shows {
+		if s != nil && s.Show != nil && util.IntSliceContains(ids, s.Show.IDs.TMDB) {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +27,9 @@ import (
 	"github.com/elgatito/elementum/xbmc"
 )
 
+// Service, generating QR code image for exported magnet link
+const qrCodeURL = "https://api.qrserver.com/v1/create-qr-code/?size=400x400&data=%s"
+
 var (
 	torrentsLog    = logging.MustGetLogger("torrents")
 	cachedTorrents = map[int]string{}
@@ -235,6 +241,7 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 				{"LOCALIZE[30276]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/delete/%s?files=true", t.InfoHash()))},
 				{"LOCALIZE[30308]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/move/%s", t.InfoHash()))},
 				sessionAction,
+				{"LOCALIZE[30913]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/torrents/queue"))},
 			}
 
 			if !t.IsMemoryStorage() {
@@ -248,6 +255,17 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 				}
 			}
 
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30786]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/inspect/%s", t.InfoHash()))})
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30810]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/export/%s", t.InfoHash()))})
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30811]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/cast/%s", t.InfoHash()))})
+			if config.Get().TraktToken != "" {
+				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30855]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/collect/%s", t.InfoHash()))})
+			}
+
+			if t.IsAudioTorrent() {
+				item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30812]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/torrents/audio/%s", t.InfoHash()))})
+			}
+
 			item.IsPlayable = true
 			items = append(items, &item)
 		}
@@ -256,6 +274,46 @@ func ListTorrents(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// AudioPlaylistTorrent lists audio files of a torrent as a playlist, downloading them in order
+func AudioPlaylistTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to list audio files for torrent with index %s", torrentID))
+			return
+		}
+
+		files := torrent.AudioFiles()
+		if len(files) == 0 {
+			xbmc.Notify("Elementum", "LOCALIZE[30934]", config.AddonIcon())
+			ctx.String(200, "")
+			return
+		}
+
+		torrent.StartAudioPlaylist()
+
+		items := make(xbmc.ListItems, 0, len(files))
+		for i, f := range files {
+			items = append(items, &xbmc.ListItem{
+				Label: f.Name,
+				Path:  util.GetHTTPHost() + "/files/" + util.EncodeFileURL(f.Path),
+				Info: &xbmc.ListItemInfo{
+					Title:       strings.TrimSuffix(f.Name, filepath.Ext(f.Name)),
+					Album:       torrent.Name(),
+					TrackNumber: i + 1,
+					Size:        int(f.Size),
+				},
+				IsPlayable: true,
+			})
+		}
+
+		ctx.JSON(200, xbmc.NewView("songs", items))
+	}
+}
+
 // ListTorrentsWeb ...
 func ListTorrentsWeb(s *bittorrent.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -589,6 +647,220 @@ func Versions(s *bittorrent.Service) gin.HandlerFunc {
 	}
 }
 
+// DownloadQueue lists unfinished torrents in order of download queue
+func DownloadQueue(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		queueItems := database.GetStorm().GetQueueItems()
+		downloads := s.GetDownloads()
+		items := make(xbmc.ListItems, 0, len(downloads))
+		for i, t := range downloads {
+			infoHash := t.InfoHash()
+
+			status := xbmc.Translate(t.GetStateString())
+			if item, ok := queueItems[infoHash]; ok && item.Queued {
+				status = "[COLOR orange]Queued[/COLOR]"
+			}
+
+			item := &xbmc.ListItem{
+				Label: fmt.Sprintf("%d. %.2f%% - %s - %s", i+1, t.GetProgress(), status, t.Name()),
+				Path:  t.GetPlayURL(""),
+				Info: &xbmc.ListItemInfo{
+					Title: t.Name(),
+				},
+				ContextMenu: [][]string{
+					{"LOCALIZE[30914]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/queue/%s/up", infoHash))},
+					{"LOCALIZE[30915]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/torrents/queue/%s/down", infoHash))},
+				},
+				IsPlayable: true,
+			}
+			items = append(items, item)
+		}
+
+		ctx.JSON(200, xbmc.NewView("", items))
+	}
+}
+
+// MoveInQueue moves torrent up or down in download queue
+func MoveInQueue(s *bittorrent.Service, offset int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to move torrent with index %s", torrentID))
+			return
+		}
+
+		if s.MoveDownload(torrent, offset) {
+			xbmc.Refresh()
+		}
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.String(200, "")
+	}
+}
+
+// ExportTorrent saves magnet link or torrent file of an active torrent into export folder,
+// or shows magnet link as QR code.
+func ExportTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to export torrent with index %s", torrentID))
+			return
+		}
+
+		choice := xbmc.ListDialog("LOCALIZE[30868]", "LOCALIZE[30869]", "LOCALIZE[30870]", "LOCALIZE[30871]")
+		if choice < 0 {
+			return
+		} else if choice == 2 {
+			xbmc.ShowPicture(fmt.Sprintf(qrCodeURL, url.QueryEscape(torrent.Magnet())))
+			ctx.String(200, "")
+			return
+		}
+
+		exportPath := config.Get().ExportPath
+		if exportPath == "" {
+			xbmc.Notify("Elementum", "LOCALIZE[30872]", config.AddonIcon())
+			return
+		}
+
+		fileName := filepath.Join(exportPath, util.ToFileName(torrent.Name()))
+		var content []byte
+		if choice == 0 {
+			fileName += ".magnet"
+			content = []byte(torrent.Magnet())
+		} else {
+			fileName += ".torrent"
+			content = torrent.GetMetadata()
+		}
+
+		if err := ioutil.WriteFile(fileName, content, 0666); err != nil {
+			torrentsLog.Errorf("Could not export torrent to %s: %s", fileName, err)
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+			return
+		}
+
+		torrentsLog.Infof("Exported torrent to %s", fileName)
+		xbmc.Notify("Elementum", "LOCALIZE[30873];;"+fileName, config.AddonIcon())
+		ctx.String(200, "")
+	}
+}
+
+// TorrentMagnet returns magnet link of an active torrent, used by Web UI to copy it to clipboard
+func TorrentMagnet(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.JSON(200, gin.H{
+			"id":     torrent.InfoHash(),
+			"name":   torrent.Name(),
+			"magnet": torrent.Magnet(),
+		})
+	}
+}
+
+// TorrentTrackers returns state of trackers of a torrent
+func TorrentTrackers(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.JSON(200, torrent.Trackers())
+	}
+}
+
+// TorrentPeers returns summary of peers of a torrent
+func TorrentPeers(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		ctx.JSON(200, torrent.Peers())
+	}
+}
+
+// InspectTorrent shows peers and trackers of a torrent in a text dialog,
+// to find out why it does not download
+func InspectTorrent(s *bittorrent.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		torrentID := ctx.Params.ByName("torrentId")
+		torrent, err := GetTorrentFromParam(s, torrentID)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to find torrent with index %s", torrentID))
+			return
+		}
+
+		text := bytes.Buffer{}
+		peers := torrent.Peers()
+		fmt.Fprintf(&text, "[B]Peers[/B]\n")
+		fmt.Fprintf(&text, "Connected: %d seeds, %d peers\n", peers.ConnectedSeeds, peers.ConnectedPeers)
+		fmt.Fprintf(&text, "Known: %d seeds, %d peers, %d connect candidates\n", peers.ListSeeds, peers.ListPeers, peers.ConnectCandidates)
+		fmt.Fprintf(&text, "Swarm: %d seeds, %d peers\n", peers.Complete, peers.Incomplete)
+		fmt.Fprintf(&text, "Speed: %s/s down, %s/s up\n", humanize.Bytes(uint64(peers.DownloadRate)), humanize.Bytes(uint64(peers.UploadRate)))
+
+		sources := make([]string, 0, len(peers.Sources))
+		for source := range peers.Sources {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			fmt.Fprintf(&text, "    %s: %d\n", source, peers.Sources[source])
+		}
+
+		fmt.Fprintf(&text, "\n[B]Trackers[/B]\n")
+		for _, tracker := range torrent.Trackers() {
+			state := "[COLOR red]not working[/COLOR]"
+			if tracker.Updating {
+				state = "updating"
+			} else if tracker.Working {
+				state = "[COLOR green]working[/COLOR]"
+			}
+
+			fmt.Fprintf(&text, "%s\n    %s, %d seeds, %d peers, next announce in %ds", tracker.URL, state, tracker.Seeds, tracker.Peers, tracker.NextAnnounce)
+			if tracker.Fails > 0 {
+				fmt.Fprintf(&text, ", %d fails", tracker.Fails)
+			}
+			if tracker.Message != "" {
+				fmt.Fprintf(&text, ", %s", tracker.Message)
+			}
+			text.WriteString("\n")
+		}
+
+		xbmc.DialogText(torrent.Name(), text.String())
+		ctx.String(200, "")
+	}
+}
+
 // GetTorrentFromParam ...
 func GetTorrentFromParam(s *bittorrent.Service, param string) (*bittorrent.Torrent, error) {
 	if len(param) == 0 {
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// Torznab categories, reported for results
+const (
+	torznabCategoryMovies = 2000
+	torznabCategoryTV     = 5000
+	torznabCategoryAnime  = 5070
+	torznabCategoryOther  = 8000
+
+	torznabNamespace  = "http://torznab.com/schemas/2015/feed"
+	torznabLimitMax   = 100
+	torznabDateLayout = "Mon, 02 Jan 2006 15:04:05 -0700"
+)
+
+// Torznab error codes
+const (
+	torznabErrorCredentials    = 100
+	torznabErrorMissingParam   = 200
+	torznabErrorNoSuchFunction = 202
+)
+
+type torznabError struct {
+	XMLName     xml.Name `xml:"error"`
+	Code        int      `xml:"code,attr"`
+	Description string   `xml:"description,attr"`
+}
+
+type torznabCaps struct {
+	XMLName xml.Name `xml:"caps"`
+	Server  struct {
+		Title string `xml:"title,attr"`
+	} `xml:"server"`
+	Limits struct {
+		Max     int `xml:"max,attr"`
+		Default int `xml:"default,attr"`
+	} `xml:"limits"`
+	Searching struct {
+		Search      torznabCapsSearch `xml:"search"`
+		TVSearch    torznabCapsSearch `xml:"tv-search"`
+		MovieSearch torznabCapsSearch `xml:"movie-search"`
+	} `xml:"searching"`
+	Categories []torznabCapsCategory `xml:"categories>category"`
+}
+
+type torznabCapsSearch struct {
+	Available       string `xml:"available,attr"`
+	SupportedParams string `xml:"supportedParams,attr"`
+}
+
+type torznabCapsCategory struct {
+	ID      int                   `xml:"id,attr"`
+	Name    string                `xml:"name,attr"`
+	Subcats []torznabCapsCategory `xml:"subcat"`
+}
+
+type torznabFeed struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	Namespace string   `xml:"xmlns:torznab,attr"`
+	Channel   struct {
+		Title       string        `xml:"title"`
+		Description string        `xml:"description"`
+		Items       []torznabItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type torznabItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	Link      string `xml:"link"`
+	PubDate   string `xml:"pubDate"`
+	Size      uint64 `xml:"size"`
+	Category  int    `xml:"category"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length uint64 `xml:"length,attr"`
+		Type   string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	Attrs []torznabAttr `xml:"torznab:attr"`
+}
+
+type torznabAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// TorznabAPI exposes aggregated search of enabled providers as a Torznab indexer,
+// so that Sonarr/Radarr can use the same providers. Disabled until API key is set in settings.
+func TorznabAPI(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	apiKey := config.Get().TorznabServerAPIKey
+	if apiKey == "" {
+		torznabFail(ctx, torznabErrorCredentials, "Torznab endpoint is disabled in settings")
+		return
+	} else if ctx.Query("apikey") != apiKey {
+		torznabFail(ctx, torznabErrorCredentials, "Incorrect API key")
+		return
+	}
+
+	var torrents []*bittorrent.TorrentFile
+	category := torznabCategoryOther
+
+	switch ctx.Query("t") {
+	case "caps":
+		ctx.XML(200, torznabCapabilities())
+		return
+	case "search":
+		torrents = torznabSearchQuery(ctx.Query("q"))
+	case "movie":
+		category = torznabCategoryMovies
+		torrents = torznabSearchMovie(ctx)
+	case "tvsearch":
+		torrents, category = torznabSearchShow(ctx)
+	case "":
+		torznabFail(ctx, torznabErrorMissingParam, "Missing parameter (t)")
+		return
+	default:
+		torznabFail(ctx, torznabErrorNoSuchFunction, "No such function")
+		return
+	}
+
+	offset, _ := strconv.Atoi(ctx.Query("offset"))
+	limit, err := strconv.Atoi(ctx.Query("limit"))
+	if err != nil || limit <= 0 || limit > torznabLimitMax {
+		limit = torznabLimitMax
+	}
+	if offset < 0 || offset > len(torrents) {
+		offset = len(torrents)
+	}
+	torrents = torrents[offset:]
+	if len(torrents) > limit {
+		torrents = torrents[:limit]
+	}
+
+	ctx.XML(200, torznabResults(torrents, category))
+}
+
+func torznabFail(ctx *gin.Context, code int, description string) {
+	ctx.XML(200, torznabError{Code: code, Description: description})
+}
+
+func torznabCapabilities() *torznabCaps {
+	caps := &torznabCaps{}
+	caps.Server.Title = "Elementum"
+	caps.Limits.Max = torznabLimitMax
+	caps.Limits.Default = torznabLimitMax
+	caps.Searching.Search = torznabCapsSearch{"yes", "q"}
+	caps.Searching.TVSearch = torznabCapsSearch{"yes", "q,season,ep,tvdbid,tmdbid,imdbid"}
+	caps.Searching.MovieSearch = torznabCapsSearch{"yes", "q,imdbid,tmdbid"}
+	caps.Categories = []torznabCapsCategory{
+		{ID: torznabCategoryMovies, Name: "Movies"},
+		{ID: torznabCategoryTV, Name: "TV", Subcats: []torznabCapsCategory{
+			{ID: torznabCategoryAnime, Name: "TV/Anime"},
+		}},
+		{ID: torznabCategoryOther, Name: "Other"},
+	}
+	return caps
+}
+
+func torznabResults(torrents []*bittorrent.TorrentFile, category int) *torznabFeed {
+	feed := &torznabFeed{
+		Version:   "2.0",
+		Namespace: torznabNamespace,
+	}
+	feed.Channel.Title = "Elementum"
+	feed.Channel.Description = "Elementum providers search"
+	feed.Channel.Items = make([]torznabItem, 0, len(torrents))
+
+	pubDate := time.Now().Format(torznabDateLayout)
+	for _, t := range torrents {
+		item := torznabItem{
+			Title:    t.Title,
+			GUID:     t.URI,
+			Link:     t.URI,
+			PubDate:  pubDate,
+			Size:     t.SizeParsed,
+			Category: category,
+		}
+		if item.Title == "" {
+			item.Title = t.Name
+		}
+		if t.InfoHash != "" {
+			item.GUID = t.InfoHash
+		}
+		item.Enclosure.URL = t.URI
+		item.Enclosure.Length = t.SizeParsed
+		item.Enclosure.Type = "application/x-bittorrent"
+
+		item.Attrs = []torznabAttr{
+			{"category", strconv.Itoa(category)},
+			{"seeders", strconv.FormatInt(t.Seeds, 10)},
+			{"peers", strconv.FormatInt(t.Seeds+t.Peers, 10)},
+		}
+		if t.InfoHash != "" {
+			item.Attrs = append(item.Attrs, torznabAttr{"infohash", t.InfoHash})
+		}
+		if t.IsMagnet() {
+			item.Attrs = append(item.Attrs, torznabAttr{"magneturl", t.URI})
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return feed
+}
+
+func torznabSearchQuery(query string) []*bittorrent.TorrentFile {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	log.Infof("Torznab search for query: %s", query)
+	return providers.SearchSilent(providers.GetSearchers(), query)
+}
+
+func torznabSearchMovie(ctx *gin.Context) []*bittorrent.TorrentFile {
+	tmdbID, _ := strconv.Atoi(ctx.Query("tmdbid"))
+	if tmdbID == 0 {
+		tmdbID = torznabFindID(torznabIMDBId(ctx.Query("imdbid")), "imdb_id", false)
+	}
+	if tmdbID == 0 {
+		return torznabSearchQuery(ctx.Query("q"))
+	}
+
+	movie := tmdb.GetMovie(tmdbID, config.Get().Language)
+	if movie == nil {
+		return nil
+	}
+
+	log.Infof("Torznab search for movie %d: %s", movie.ID, movie.Title)
+	return providers.SearchMovieSilent(providers.GetMovieSearchers(), movie, false)
+}
+
+// torznabSearchShow searches show, season or episode, depending on given params,
+// and returns category of found show.
+func torznabSearchShow(ctx *gin.Context) ([]*bittorrent.TorrentFile, int) {
+	seasonNumber, seasonErr := strconv.Atoi(ctx.Query("season"))
+	episodeNumber, episodeErr := strconv.Atoi(ctx.Query("ep"))
+
+	showID, _ := strconv.Atoi(ctx.Query("tmdbid"))
+	if showID == 0 {
+		showID = torznabFindID(ctx.Query("tvdbid"), "tvdb_id", true)
+	}
+	if showID == 0 {
+		showID = torznabFindID(torznabIMDBId(ctx.Query("imdbid")), "imdb_id", true)
+	}
+
+	if showID == 0 {
+		query := strings.TrimSpace(ctx.Query("q"))
+		if query != "" && seasonErr == nil && episodeErr == nil {
+			query = fmt.Sprintf("%s S%02dE%02d", query, seasonNumber, episodeNumber)
+		} else if query != "" && seasonErr == nil {
+			query = fmt.Sprintf("%s S%02d", query, seasonNumber)
+		}
+		return torznabSearchQuery(query), torznabCategoryTV
+	}
+
+	show := tmdb.GetShow(showID, config.Get().Language)
+	if show == nil {
+		return nil, torznabCategoryTV
+	}
+
+	category := torznabCategoryTV
+	if show.IsAnime() {
+		category = torznabCategoryAnime
+	}
+
+	if seasonErr != nil {
+		return torznabSearchQuery(show.Name), category
+	}
+
+	season := tmdb.GetSeason(showID, seasonNumber, config.Get().Language, len(show.Seasons))
+	if season == nil {
+		return nil, category
+	}
+
+	if episodeErr != nil {
+		log.Infof("Torznab search for %s season %d", show.Name, seasonNumber)
+		return providers.SearchSeasonSilent(providers.GetSeasonSearchers(), show, season, false), category
+	} else if episodeNumber < 1 || len(season.Episodes) < episodeNumber {
+		return nil, category
+	}
+
+	log.Infof("Torznab search for %s S%02dE%02d", show.Name, seasonNumber, episodeNumber)
+	return providers.SearchEpisodeSilent(providers.GetEpisodeSearchers(), show, season.Episodes[episodeNumber-1], false), category
+}
+
+// torznabFindID resolves external ID into TMDB ID of a show or a movie
+func torznabFindID(externalID string, externalSource string, isShow bool) int {
+	if externalID == "" {
+		return 0
+	}
+
+	result := tmdb.Find(externalID, externalSource)
+	if result == nil {
+		return 0
+	} else if isShow && len(result.TVResults) > 0 {
+		return result.TVResults[0].ID
+	} else if !isShow && len(result.MovieResults) > 0 {
+		return result.MovieResults[0].ID
+	}
+	return 0
+}
+
+// torznabIMDBId adds "tt" prefix, which is omitted by Sonarr/Radarr
+func torznabIMDBId(id string) string {
+	if id == "" || strings.HasPrefix(id, "tt") {
+		return id
+	}
+	return "tt" + id
+}
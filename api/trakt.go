@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -137,6 +138,17 @@ func DeauthorizeTrakt(ctx *gin.Context) {
 	}
 }
 
+// SwitchTraktProfile switches Trakt authorization to the Kodi profile, passed in query, or to the active one
+func SwitchTraktProfile(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	if err := trakt.SwitchProfile(ctx.Query("profile")); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	library.ClearPageCache()
+	ctx.String(200, "")
+}
+
 //
 // Main lists
 //
@@ -145,46 +157,138 @@ func DeauthorizeTrakt(ctx *gin.Context) {
 func WatchlistMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	movies, err := trakt.WatchlistMovies(false)
+	movies, err := trakt.WatchlistMovies(isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
-	renderTraktMovies(ctx, movies, -1, 0)
+	setListingUpdated(ctx, cache.TraktMoviesWatchlistKey, cache.TraktMoviesWatchlistExpire)
+	renderTraktMovies(ctx, filterTaggedMovies(movies, ctx.Query("tag")), -1, 0)
 }
 
 // WatchlistShows ...
 func WatchlistShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	shows, err := trakt.WatchlistShows(false)
+	shows, err := trakt.WatchlistShows(isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
-	renderTraktShows(ctx, shows, -1, 0)
+	setListingUpdated(ctx, cache.TraktShowsWatchlistKey, cache.TraktShowsWatchlistExpire)
+	renderTraktShows(ctx, sortShowsByCompletion(ctx, filterTaggedShows(shows, ctx.Query("tag"))), -1, 0)
 }
 
 // CollectionMovies ...
 func CollectionMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	movies, err := trakt.CollectionMovies(false)
+	movies, err := trakt.CollectionMovies(isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
-	renderTraktMovies(ctx, movies, -1, 0)
+	setListingUpdated(ctx, cache.TraktMoviesCollectionKey, cache.TraktMoviesCollectionExpire)
+	renderTraktMovies(ctx, filterTaggedMovies(movies, ctx.Query("tag")), -1, 0)
 }
 
 // CollectionShows ...
 func CollectionShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	shows, err := trakt.CollectionShows(false)
+	shows, err := trakt.CollectionShows(isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
+	setListingUpdated(ctx, cache.TraktShowsCollectionKey, cache.TraktShowsCollectionExpire)
+	renderTraktShows(ctx, sortShowsByCompletion(ctx, filterTaggedShows(shows, ctx.Query("tag"))), -1, 0)
+}
+
+// RatedMovies ...
+func RatedMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	movies, err := trakt.RatedMovies(isRefreshRequested(ctx))
+	if err != nil {
+		notifyError(err)
+	}
+	setListingUpdated(ctx, cache.TraktMoviesRatingsKey, cache.TraktMoviesRatingsExpire)
+	renderTraktMovies(ctx, movies, -1, 0)
+}
+
+// RatedShows ...
+func RatedShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	shows, err := trakt.RatedShows(isRefreshRequested(ctx))
+	if err != nil {
+		notifyError(err)
+	}
+	setListingUpdated(ctx, cache.TraktShowsRatingsKey, cache.TraktShowsRatingsExpire)
 	renderTraktShows(ctx, shows, -1, 0)
 }
 
+// RateMovie ...
+func RateMovie(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+	rateItem(ctx, "movies", tmdbID)
+}
+
+// RateShow ...
+func RateShow(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	rateItem(ctx, "shows", showID)
+}
+
+// RateEpisode ...
+func RateEpisode(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+	episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+
+	episode := tmdb.GetEpisode(showID, seasonNumber, episodeNumber, config.Get().Language)
+	if episode == nil {
+		xbmc.Notify("Elementum", "LOCALIZE[30881]", config.AddonIcon())
+		return
+	}
+	rateItem(ctx, "episodes", episode.ID)
+}
+
+// rateItem asks user for a rating and sends it to Trakt
+func rateItem(ctx *gin.Context, itemType string, tmdbID int) {
+	choices := []string{"LOCALIZE[30880]"}
+	for r := 10; r >= 1; r-- {
+		choices = append(choices, strconv.Itoa(r))
+	}
+
+	choice := xbmc.ListDialog("LOCALIZE[30878]", choices...)
+	if choice < 0 {
+		return
+	}
+
+	rating := 0
+	if choice > 0 {
+		rating = 11 - choice
+	}
+	if err := trakt.RateItem(itemType, tmdbID, rating); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	if rating == 0 {
+		xbmc.Notify("Elementum", "LOCALIZE[30879]", config.AddonIcon())
+	} else {
+		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30883];;%d", rating), config.AddonIcon())
+	}
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
 // UserlistMovies ...
 func UserlistMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -193,11 +297,12 @@ func UserlistMovies(ctx *gin.Context) {
 	listID := ctx.Params.ByName("listId")
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, err := trakt.ListItemsMovies(user, listID, false)
+	movies, err := trakt.ListItemsMovies(user, listID, isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
-	renderTraktMovies(ctx, movies, -1, page)
+	setListingUpdated(ctx, fmt.Sprintf(cache.TraktMoviesListKey, listID), cache.TraktMoviesListExpire)
+	renderTraktMovies(ctx, trakt.FilterMovies(movies), -1, page)
 }
 
 // UserlistShows ...
@@ -208,11 +313,120 @@ func UserlistShows(ctx *gin.Context) {
 	listID := ctx.Params.ByName("listId")
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, err := trakt.ListItemsShows(user, listID, false)
+	shows, err := trakt.ListItemsShows(user, listID, isRefreshRequested(ctx))
 	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyError(err)
 	}
-	renderTraktShows(ctx, shows, -1, page)
+	setListingUpdated(ctx, fmt.Sprintf(cache.TraktShowsListKey, listID), cache.TraktShowsListExpire)
+	renderTraktShows(ctx, sortShowsByCompletion(ctx, trakt.FilterShows(shows)), -1, page)
+}
+
+// Key of context value with time, when rendered listing was fetched from Trakt
+const listingUpdatedKey = "listingUpdated"
+
+// isRefreshRequested checks whether listing should bypass the cache
+func isRefreshRequested(ctx *gin.Context) bool {
+	return ctx.Query("refresh") == "1"
+}
+
+// setListingUpdated remembers, when cached listing was fetched, to show it on top of the listing
+func setListingUpdated(ctx *gin.Context, key string, expires time.Duration) {
+	if updated, err := cache.NewDBStore().Updated(key, expires); err == nil {
+		ctx.Set(listingUpdatedKey, updated)
+	}
+}
+
+// withListingUpdated adds "Last updated" item on top of cached listing and "Refresh" action to its items
+func withListingUpdated(ctx *gin.Context, items xbmc.ListItems) xbmc.ListItems {
+	value, ok := ctx.Get(listingUpdatedKey)
+	if !ok {
+		return items
+	}
+	updated := value.(time.Time)
+
+	query := ctx.Request.URL.Query()
+	query.Set("refresh", "1")
+	refreshURL := URLForXBMC("%s?%s", ctx.Request.URL.Path, query.Encode())
+	refreshAction := []string{"LOCALIZE[30916]", fmt.Sprintf("Container.Update(%s,replace)", refreshURL)}
+
+	for _, item := range items {
+		item.ContextMenu = append(item.ContextMenu, refreshAction)
+	}
+
+	info := &xbmc.ListItem{
+		Label:       listingUpdatedLabel(time.Since(updated)),
+		Path:        refreshURL,
+		ContextMenu: [][]string{refreshAction},
+	}
+	return append(xbmc.ListItems{info}, items...)
+}
+
+// Key of context value, set for listings, that can be sorted by watched progress
+const completionSortableKey = "completionSortable"
+
+// sortShowsByCompletion sorts shows by watched progress, when it is requested for the listing
+func sortShowsByCompletion(ctx *gin.Context, shows []*trakt.Shows) []*trakt.Shows {
+	if config.Get().TraktToken == "" {
+		return shows
+	}
+
+	ctx.Set(completionSortableKey, true)
+	if ctx.Query("sort") == "completion" {
+		trakt.SortShowsByCompletion(shows)
+	}
+	return shows
+}
+
+// completionSortAction returns context menu action to sort the listing by watched progress
+func completionSortAction(ctx *gin.Context) []string {
+	if _, ok := ctx.Get(completionSortableKey); !ok || ctx.Query("sort") == "completion" {
+		return nil
+	}
+
+	query := ctx.Request.URL.Query()
+	query.Set("sort", "completion")
+	query.Del("page")
+	return []string{"LOCALIZE[30817]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("%s?%s", ctx.Request.URL.Path, query.Encode()))}
+}
+
+// applyShowCompletion shows watched progress of a show in the label and sets properties, used by skins for progress bars
+func applyShowCompletion(item *xbmc.ListItem, completion *trakt.ShowCompletion) {
+	if completion == nil {
+		return
+	}
+
+	if item.Properties == nil {
+		item.Properties = map[string]string{}
+	}
+	item.Properties["TotalEpisodes"] = strconv.Itoa(completion.Aired)
+	item.Properties["WatchedEpisodes"] = strconv.Itoa(completion.Watched)
+	item.Properties["UnWatchedEpisodes"] = strconv.Itoa(completion.Aired - util.Min(completion.Watched, completion.Aired))
+	item.Properties["Completion"] = strconv.Itoa(completion.Percent())
+
+	if completion.Watched > 0 {
+		item.Label += fmt.Sprintf(" [COLOR FF999999](%d%%)[/COLOR]", completion.Percent())
+	}
+}
+
+// sortQuery keeps requested sorting of a listing for the next page
+func sortQuery(ctx *gin.Context) string {
+	if order := ctx.Query("sort"); order != "" {
+		return "&sort=" + url.QueryEscape(order)
+	}
+	return ""
+}
+
+// listingUpdatedLabel returns label with age of cached listing
+func listingUpdatedLabel(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "LOCALIZE[30917]"
+	case age < time.Hour:
+		return fmt.Sprintf("LOCALIZE[30918];;%d", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("LOCALIZE[30919];;%d", int(age.Hours()))
+	}
+	return fmt.Sprintf("LOCALIZE[30920];;%d", int(age.Hours()/24))
 }
 
 // func WatchlistSeasons(ctx *gin.Context) {
@@ -239,8 +453,7 @@ func AddMovieToWatchlist(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Movie added to watchlist", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.movies"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.movies.watchlist"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.watchlist.movies")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -260,8 +473,7 @@ func RemoveMovieFromWatchlist(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Movie removed from watchlist", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.movies"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.movies.watchlist"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.watchlist.movies")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -281,8 +493,7 @@ func AddShowToWatchlist(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed %d", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Show added to watchlist", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.shows"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.shows.watchlist"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.watchlist.shows")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -290,6 +501,135 @@ func AddShowToWatchlist(ctx *gin.Context) {
 	}
 }
 
+// StartShowRewatch starts rewatch of a show, showId is a Trakt ID
+func StartShowRewatch(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	if err := database.GetStorm().StartRewatch(showID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30937]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+// StopShowRewatch stops rewatch of a show, showId is a Trakt ID
+func StopShowRewatch(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	if err := database.GetStorm().StopRewatch(showID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30938]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+func rewatchAction(showID int) []string {
+	if database.GetStorm().GetRewatch(showID) != nil {
+		return []string{"LOCALIZE[30940]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/rewatch/stop", showID))}
+	}
+	return []string{"LOCALIZE[30939]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/rewatch/start", showID))}
+}
+
+// DismissRecommendation removes movie or show from Trakt recommendations
+func DismissRecommendation(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	media := ctx.Params.ByName("media")
+	traktID, _ := strconv.Atoi(ctx.Params.ByName("traktId"))
+	if err := trakt.DismissRecommendation(media, traktID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30902]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+// dismissAction returns context menu entry for dismissing recommendation,
+// if current listing shows recommendations, or nil otherwise
+func dismissAction(ctx *gin.Context, media string, ids *trakt.IDs) []string {
+	if ctx == nil || ids == nil || !strings.HasSuffix(ctx.Request.URL.Path, "/trakt/recommendations") {
+		return nil
+	}
+	return []string{"LOCALIZE[30901]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/recommendations/dismiss/%s/%d", media, ids.Trakt))}
+}
+
+// HideShowProgress hides show from Trakt progress
+func HideShowProgress(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	if err := trakt.HideShow(trakt.HiddenProgressWatched, showID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30899]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+// UnhideShowProgress returns show back to Trakt progress
+func UnhideShowProgress(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	if err := trakt.UnhideShow(trakt.HiddenProgressWatched, showID); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30900]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+// DismissShowProgress hides show from continue watching locally, without changing Trakt history,
+// until its next episode changes
+func DismissShowProgress(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+	season, _ := strconv.Atoi(ctx.Params.ByName("season"))
+	episode, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+	if err := database.GetStorm().DismissProgress(showID, season, episode); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		return
+	}
+
+	xbmc.Notify("Elementum", "LOCALIZE[30903]", config.AddonIcon())
+	if ctx != nil {
+		ctx.Abort()
+	}
+	library.ClearPageCache()
+}
+
+func progressAction(showID int) []string {
+	if trakt.IsShowHidden(trakt.HiddenProgressWatched, showID) {
+		return []string{"LOCALIZE[30898]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/progress/unhide", showID))}
+	}
+	return []string{"LOCALIZE[30897]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/progress/hide", showID))}
+}
+
 // RemoveShowFromWatchlist ...
 func RemoveShowFromWatchlist(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -302,8 +642,7 @@ func RemoveShowFromWatchlist(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Show removed from watchlist", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.watchlist.shows"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.shows.watchlist"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.watchlist.shows")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -323,8 +662,7 @@ func AddMovieToCollection(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Movie added to collection", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.collection.movies"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.movies.collection"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.collection.movies")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -344,8 +682,7 @@ func RemoveMovieFromCollection(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Movie removed from collection", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.collection.movies"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.movies.collection"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.collection.movies")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -365,8 +702,7 @@ func AddShowToCollection(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Show added to collection", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.collection.shows"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.shows.collection"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.collection.shows")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -386,8 +722,7 @@ func RemoveShowFromCollection(ctx *gin.Context) {
 		xbmc.Notify("Elementum", fmt.Sprintf("Failed with %d status code", resp.Status()), config.AddonIcon())
 	} else {
 		xbmc.Notify("Elementum", "Show removed from collection", config.AddonIcon())
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.collection.shows"))
-		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte("com.trakt.shows.collection"))
+		database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey("com.trakt.collection.shows")))
 		if ctx != nil {
 			ctx.Abort()
 		}
@@ -443,6 +778,10 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 			}
 
 			item := movieListing.Movie.ToListItem()
+			if countdown := releaseCountdown(movieListing.ReleaseDate); countdown != "" {
+				item.Label += countdown
+				item.Info.Title += countdown
+			}
 
 			// Example of adding UTF8 char into title,
 			// list: https://www.utf8-chartable.de/unicode-utf8-table.pl?start=9728&number=1024&names=2&utf8=string-literal
@@ -486,9 +825,24 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 				{"LOCALIZE[30619];;LOCALIZE[30214]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movies/"))},
 				watchlistAction,
 				collectionAction,
+				{"LOCALIZE[30878]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/rate", movieListing.Movie.IDs.TMDB))},
+				{"LOCALIZE[30884]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movie/%d/comments", movieListing.Movie.IDs.TMDB))},
 				{"LOCALIZE[30034]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/movies"))},
 			}
 			item.ContextMenu = append(libraryActions, item.ContextMenu...)
+			if action := dismissAction(ctx, "movies", movieListing.Movie.IDs); action != nil {
+				item.ContextMenu = append(item.ContextMenu, action)
+			}
+			if action := notInterestedAction(ctx, movieType, movieListing.Movie.IDs.TMDB); action != nil {
+				item.ContextMenu = append(item.ContextMenu, action)
+			}
+			item.ContextMenu = append(item.ContextMenu, tagsActions(movieType, movieListing.Movie.IDs.TMDB)...)
+			item.ContextMenu = append(item.ContextMenu, selectionActions(movieType, movieListing.Movie.IDs.TMDB)...)
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30809]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/dryrun", movieListing.Movie.IDs.TMDB))})
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/refresh", movieListing.Movie.IDs.TMDB))})
+			item.ContextMenu = append(item.ContextMenu, watchProvidersAction(movieType, movieListing.Movie.IDs.TMDB))
+			item.ContextMenu = append(item.ContextMenu, personsAction(movieType, movieListing.Movie.IDs.TMDB))
+			applyTags(item, movieType, movieListing.Movie.IDs.TMDB)
 
 			if config.Get().Platform.Kodi < 17 {
 				item.ContextMenu = append(item.ContextMenu,
@@ -504,6 +858,15 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 	}
 	wg.Wait()
 
+	// Drop empty items, left by filtered out or broken listings
+	ret := make(xbmc.ListItems, 0, len(items)+hasNextPage)
+	for _, item := range items {
+		if item != nil {
+			ret = append(ret, item)
+		}
+	}
+	items = ret
+
 	if page >= 0 && hasNextPage > 0 {
 		path := ctx.Request.URL.Path
 		nextpage := &xbmc.ListItem{
@@ -513,20 +876,45 @@ func renderTraktMovies(ctx *gin.Context, movies []*trakt.Movies, total int, page
 		}
 		items = append(items, nextpage)
 	}
+	items = withListingUpdated(ctx, items)
 	ctx.JSON(200, xbmc.NewView("movies", items))
 }
 
+// filterTraktMovies empties movies, not allowed by content filters.
+// Positions are kept, since listings are paginated by index.
+func filterTraktMovies(movies []*trakt.Movies) []*trakt.Movies {
+	for i, m := range movies {
+		if m != nil && m.Movie != nil && !util.IsContentAllowed(m.Movie.Language, m.Movie.Votes) {
+			movies[i] = nil
+		}
+	}
+	return movies
+}
+
 // TraktPopularMovies ...
 func TraktPopularMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.TopMovies("popular", pageParam)
+	movies, total, err := trakt.TopMoviesByGenre("popular", ctx.Params.ByName("genre"), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterNotInterestedTraktMovies(filterTraktMovies(movies)), total, page)
+}
+
+// TraktCountryPopularMovies lists popular movies, filtered by configured country
+func TraktCountryPopularMovies(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	pageParam := ctx.DefaultQuery("page", "1")
+	page, _ := strconv.Atoi(pageParam)
+	movies, total, err := trakt.TopMoviesByCountry("popular", config.Get().Region, pageParam)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	renderTraktMovies(ctx, filterNotInterestedTraktMovies(filterTraktMovies(movies)), total, page)
 }
 
 // TraktRecommendationsMovies ...
@@ -539,7 +927,7 @@ func TraktRecommendationsMovies(ctx *gin.Context) {
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterNotInterestedTraktMovies(filterTraktMovies(movies)), total, page)
 }
 
 // TraktTrendingMovies ...
@@ -548,11 +936,11 @@ func TraktTrendingMovies(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.TopMovies("trending", pageParam)
+	movies, total, err := trakt.TopMoviesByGenre("trending", ctx.Params.ByName("genre"), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterNotInterestedTraktMovies(filterTraktMovies(movies)), total, page)
 }
 
 // TraktMostPlayedMovies ...
@@ -561,11 +949,11 @@ func TraktMostPlayedMovies(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.TopMovies("played", pageParam)
+	movies, total, err := trakt.TopMovies(topPeriodCategory("played", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterTraktMovies(movies), total, page)
 }
 
 // TraktMostWatchedMovies ...
@@ -574,11 +962,11 @@ func TraktMostWatchedMovies(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.TopMovies("watched", pageParam)
+	movies, total, err := trakt.TopMovies(topPeriodCategory("watched", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterTraktMovies(movies), total, page)
 }
 
 // TraktMostCollectedMovies ...
@@ -587,11 +975,11 @@ func TraktMostCollectedMovies(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.TopMovies("collected", pageParam)
+	movies, total, err := trakt.TopMovies(topPeriodCategory("collected", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	renderTraktMovies(ctx, filterTraktMovies(movies), total, page)
 }
 
 // TraktMostAnticipatedMovies ...
@@ -604,7 +992,10 @@ func TraktMostAnticipatedMovies(ctx *gin.Context) {
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, total, page)
+	if ctx.Params.ByName("sort") == "release" {
+		trakt.SortMoviesByRelease(movies)
+	}
+	renderTraktMovies(ctx, filterTraktMovies(movies), total, page)
 }
 
 // TraktBoxOffice ...
@@ -615,7 +1006,7 @@ func TraktBoxOffice(ctx *gin.Context) {
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktMovies(ctx, movies, -1, 0)
+	renderTraktMovies(ctx, filterTraktMovies(movies), -1, 0)
 }
 
 // TraktHistoryMovies ...
@@ -662,6 +1053,280 @@ func TraktHistoryShows(ctx *gin.Context) {
 	renderTraktShows(ctx, shows, -1, page)
 }
 
+// TraktMyHistory lists watched movies and episodes from Trakt history, grouped by day
+func TraktMyHistory(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	pageParam := ctx.DefaultQuery("page", "1")
+	page, _ := strconv.Atoi(pageParam)
+
+	history, hasNext, err := trakt.History(pageParam)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+
+	// Notes are available only for Trakt VIP, so history is shown without them for other accounts
+	notes, err := trakt.HistoryNotes()
+	if err != nil && err != trakt.ErrVIPRequired {
+		log.Warningf("Could not get Trakt notes: %s", err)
+	}
+	colorNote := config.Get().TraktCalendarsColorUnaired
+
+	language := config.Get().Language
+	colorDate := config.Get().TraktCalendarsColorDate
+	colorShow := config.Get().TraktCalendarsColorShow
+	colorEpisode := config.Get().TraktCalendarsColorEpisode
+	dateFormat := getCalendarsDateFormat()
+
+	rendered := make(xbmc.ListItems, len(history))
+	wg := sync.WaitGroup{}
+	wg.Add(len(history))
+	for i, h := range history {
+		go func(i int, entry *trakt.HistoryItem) {
+			defer wg.Done()
+			if entry == nil {
+				return
+			}
+
+			var item *xbmc.ListItem
+			var removeURL string
+			watchedAt := entry.WatchedAt.Local().Format("15:04")
+
+			if entry.Movie != nil && entry.Movie.IDs != nil {
+				item = entry.Movie.ToListItem()
+				item.Label = fmt.Sprintf(`[COLOR %s]%s[/COLOR] | [B][COLOR %s]%s[/COLOR][/B]`,
+					colorDate, watchedAt, colorShow, item.Info.Title)
+
+				thisURL := URLForXBMC("/movie/%d/", entry.Movie.IDs.TMDB) + "%s/%s"
+				contextTitle := fmt.Sprintf("%s (%d)", item.Info.OriginalTitle, entry.Movie.Year)
+				item.Path = contextPlayURL(thisURL, contextTitle, false)
+				removeURL = URLForXBMC("/trakt/history/remove/%d?type=movie", entry.ID)
+			} else if entry.Episode != nil && entry.Show != nil && entry.Show.IDs != nil {
+				epi := entry.Episode
+				showName := tmdb.DisplayTitle(entry.Show.Title, entry.Show.OriginalTitle)
+
+				var show *tmdb.Show
+				var season *tmdb.Season
+				var episode *tmdb.Episode
+				if !config.Get().ForceUseTrakt && entry.Show.IDs.TMDB != 0 {
+					show = tmdb.GetShow(entry.Show.IDs.TMDB, language)
+					if show != nil {
+						showName = tmdb.DisplayTitle(show.Name, show.OriginalName)
+						season = tmdb.GetSeason(entry.Show.IDs.TMDB, epi.Season, language, len(show.Seasons))
+						episode = tmdb.GetEpisode(entry.Show.IDs.TMDB, epi.Season, epi.Number, language)
+					}
+				}
+
+				if show != nil && season != nil && episode != nil {
+					item = episode.ToListItem(show, season)
+				} else {
+					item = epi.ToListItem(entry.Show)
+				}
+				item.Label = fmt.Sprintf(`[COLOR %s]%s[/COLOR] | [B][COLOR %s]%s[/COLOR][/B] - [I][COLOR %s]%dx%02d %s[/COLOR][/I]`,
+					colorDate, watchedAt, colorShow, showName, colorEpisode, epi.Season, epi.Number, epi.Title)
+
+				thisURL := URLForXBMC("/show/%d/season/%d/episode/%d/", entry.Show.IDs.TMDB, epi.Season, epi.Number) + "%s/%s"
+				contextTitle := fmt.Sprintf("%s S%02dE%02d", entry.Show.Title, epi.Season, epi.Number)
+				item.Path = contextPlayURL(thisURL, contextTitle, false)
+				removeURL = URLForXBMC("/trakt/history/remove/%d?type=episode", entry.ID)
+			} else {
+				return
+			}
+
+			noteLabel := "LOCALIZE[30888]"
+			if note, ok := notes[entry.ID]; ok && note != nil && note.Notes != "" {
+				noteLabel = "LOCALIZE[30889]"
+				item.Label += fmt.Sprintf(` [I][COLOR %s](%s)[/COLOR][/I]`, colorNote, note.Notes)
+				item.Info.Plot = note.Notes + "\n\n" + item.Info.Plot
+			}
+
+			item.Info.Title = item.Label
+			item.Info.LastPlayed = entry.WatchedAt.Local().Format("2006-01-02 15:04:05")
+			item.ContextMenu = [][]string{
+				{"LOCALIZE[30824]", fmt.Sprintf("XBMC.RunPlugin(%s)", removeURL)},
+				{noteLabel, fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/trakt/history/note/%d", entry.ID))},
+			}
+			item.IsPlayable = true
+			rendered[i] = item
+		}(i, h)
+	}
+	wg.Wait()
+
+	// Trakt returns history sorted by watched date, so we only need to put day headers in between
+	items := make(xbmc.ListItems, 0, len(rendered))
+	lastDay := ""
+	for i, item := range rendered {
+		if item == nil {
+			continue
+		}
+
+		day := history[i].WatchedAt.Local().Format(dateFormat)
+		if day != lastDay {
+			lastDay = day
+			items = append(items, &xbmc.ListItem{
+				Label:     fmt.Sprintf(`[B][COLOR %s]%s[/COLOR][/B]`, colorDate, history[i].WatchedAt.Local().Format("Monday, "+dateFormat)),
+				Path:      URLForXBMC("/trakt/history?page=%d", page),
+				Thumbnail: config.AddonResource("img", "clock.png"),
+			})
+		}
+		items = append(items, item)
+	}
+
+	if hasNext {
+		nextpage := &xbmc.ListItem{
+			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
+			Path:      URLForXBMC("/trakt/history?page=%d", page+1),
+			Thumbnail: config.AddonResource("img", "nextpage.png"),
+		}
+		items = append(items, nextpage)
+	}
+	ctx.JSON(200, xbmc.NewView("episodes", items))
+}
+
+// TraktHistoryRemove removes single entry from Trakt history and refreshes local watched state
+func TraktHistoryRemove(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	historyID, _ := strconv.ParseInt(ctx.Params.ByName("historyId"), 10, 64)
+	itemType := library.MovieType
+	if ctx.Query("type") == "episode" {
+		itemType = library.EpisodeType
+	}
+
+	stats, err := trakt.RemoveFromHistory(historyID)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	} else if stats.Deleted.Movies == 0 && stats.Deleted.Episodes == 0 {
+		xbmc.Notify("Elementum", "LOCALIZE[30825]", config.AddonIcon())
+	} else {
+		xbmc.Notify("Elementum", "LOCALIZE[30826]", config.AddonIcon())
+
+		// Re-read watched items from Trakt, so that local playcount is corrected
+		go func() {
+			if err := library.RefreshTraktWatched(itemType, true); err != nil {
+				log.Warningf("Could not refresh Trakt watched items: %s", err)
+			}
+			library.ClearPageCache()
+		}()
+	}
+
+	ctx.String(200, "")
+}
+
+// TraktHistoryNote asks for a note and attaches it to a Trakt history entry
+func TraktHistoryNote(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	historyID, _ := strconv.ParseInt(ctx.Params.ByName("historyId"), 10, 64)
+
+	notes, err := trakt.HistoryNotes()
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	current := ""
+	if note, ok := notes[historyID]; ok && note != nil {
+		current = note.Notes
+	}
+	text := strings.TrimSpace(xbmc.Keyboard(current, "LOCALIZE[30890]"))
+	if text == "" || text == current {
+		ctx.String(200, "")
+		return
+	}
+
+	if err := trakt.SetHistoryNote(historyID, text); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	} else {
+		xbmc.Notify("Elementum", "LOCALIZE[30891]", config.AddonIcon())
+		ctx.Abort()
+		library.ClearPageCache()
+		return
+	}
+	ctx.String(200, "")
+}
+
+// MarkMovieWatched adds movie to Trakt history, optionally asking for a note to attach to the history entry
+func MarkMovieWatched(withNote bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		tmdbID, _ := strconv.Atoi(ctx.Params.ByName("tmdbId"))
+		markWatched(ctx, library.MovieType, &trakt.WatchedItem{
+			MediaType: "movie",
+			Movie:     tmdbID,
+			Watched:   true,
+		}, withNote)
+	}
+}
+
+// MarkEpisodeWatched adds episode to Trakt history, optionally asking for a note to attach to the history entry
+func MarkEpisodeWatched(withNote bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		showID, _ := strconv.Atoi(ctx.Params.ByName("showId"))
+		seasonNumber, _ := strconv.Atoi(ctx.Params.ByName("season"))
+		episodeNumber, _ := strconv.Atoi(ctx.Params.ByName("episode"))
+		markWatched(ctx, library.EpisodeType, &trakt.WatchedItem{
+			MediaType: "episode",
+			Show:      showID,
+			Season:    seasonNumber,
+			Episode:   episodeNumber,
+			Watched:   true,
+		}, withNote)
+	}
+}
+
+// markWatched sends watched item to Trakt history. Note is asked before marking,
+// so that cancelled dialog leaves item unwatched. For accounts without Trakt VIP item is marked without a note.
+func markWatched(ctx *gin.Context, itemType int, item *trakt.WatchedItem, withNote bool) {
+	text := ""
+	if withNote {
+		if text = strings.TrimSpace(xbmc.Keyboard("", "LOCALIZE[30892]")); text == "" {
+			ctx.String(200, "")
+			return
+		}
+	}
+
+	resp, err := trakt.SetWatched(item)
+	if err == nil && resp.Status() != 201 {
+		err = fmt.Errorf("Bad status marking Trakt %s as watched: %d", item.MediaType, resp.Status())
+	}
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		ctx.String(200, "")
+		return
+	}
+
+	message := "LOCALIZE[30893]"
+	if text != "" {
+		historyID, err := trakt.LastHistoryID(item)
+		if err == nil {
+			err = trakt.SetHistoryNote(historyID, text)
+		}
+		if err == trakt.ErrVIPRequired {
+			message = "LOCALIZE[30894]"
+		} else if err != nil {
+			log.Warningf("Could not attach note to Trakt history: %s", err)
+			message = "LOCALIZE[30895]"
+		} else {
+			message = "LOCALIZE[30896]"
+		}
+	}
+	xbmc.Notify("Elementum", message, config.AddonIcon())
+
+	go func() {
+		if err := library.RefreshTraktWatched(itemType, true); err != nil {
+			log.Warningf("Could not refresh Trakt watched items: %s", err)
+		}
+		library.ClearPageCache()
+	}()
+
+	ctx.String(200, "")
+}
+
 // TraktProgressShows ...
 func TraktProgressShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
@@ -699,7 +1364,12 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 		}
 	}
 
+	// Merged within a page, to keep paging positions of the listing
+	shows = trakt.MergeDuplicateShows(shows)
+
 	items := make(xbmc.ListItems, 0, len(shows)+hasNextPage)
+	completion := trakt.ShowsCompletion()
+	sortAction := completionSortAction(ctx)
 
 	for _, showListing := range shows {
 		if showListing == nil || showListing.Show == nil {
@@ -707,6 +1377,13 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 		}
 
 		item := showListing.Show.ToListItem()
+		if countdown := releaseCountdown(showListing.ReleaseDate); countdown != "" {
+			item.Label += countdown
+			item.Info.Title += countdown
+		}
+		if showListing.Show.IDs != nil {
+			applyShowCompletion(item, completion[showListing.Show.IDs.Trakt])
+		}
 		tmdbID := strconv.Itoa(showListing.Show.IDs.TMDB)
 
 		item.Path = URLForXBMC("/show/%d/seasons", showListing.Show.IDs.TMDB)
@@ -733,9 +1410,34 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 			{"LOCALIZE[30619];;LOCALIZE[30215]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/shows/"))},
 			watchlistAction,
 			collectionAction,
+			{"LOCALIZE[30878]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/rate", showListing.Show.IDs.TMDB))},
+			{"LOCALIZE[30884]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/show/%d/comments", showListing.Show.IDs.TMDB))},
 			{"LOCALIZE[30035]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/tvshows"))},
 		}
 		item.ContextMenu = append(libraryActions, item.ContextMenu...)
+		if config.Get().TraktToken != "" {
+			item.ContextMenu = append(item.ContextMenu, rewatchAction(showListing.Show.IDs.Trakt), progressAction(showListing.Show.IDs.TMDB))
+		}
+		if config.Get().IntroOffsetEnabled {
+			item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30818]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/intro", showListing.Show.IDs.TMDB))})
+		}
+		if action := dismissAction(ctx, "shows", showListing.Show.IDs); action != nil {
+			item.ContextMenu = append(item.ContextMenu, action)
+		}
+		if action := notInterestedAction(ctx, showType, showListing.Show.IDs.TMDB); action != nil {
+			item.ContextMenu = append(item.ContextMenu, action)
+		}
+		item.ContextMenu = append(item.ContextMenu, spoilersAction(showListing.Show.IDs.TMDB))
+		item.ContextMenu = append(item.ContextMenu, autoDownloadAction(showListing.Show.IDs.TMDB))
+		item.ContextMenu = append(item.ContextMenu, tagsActions(showType, showListing.Show.IDs.TMDB)...)
+		item.ContextMenu = append(item.ContextMenu, selectionActions(showType, showListing.Show.IDs.TMDB)...)
+		item.ContextMenu = append(item.ContextMenu, []string{"LOCALIZE[30808]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/refresh", showListing.Show.IDs.TMDB))})
+		item.ContextMenu = append(item.ContextMenu, watchProvidersAction(showType, showListing.Show.IDs.TMDB))
+		item.ContextMenu = append(item.ContextMenu, personsAction(showType, showListing.Show.IDs.TMDB))
+		if sortAction != nil {
+			item.ContextMenu = append(item.ContextMenu, sortAction)
+		}
+		applyTags(item, showType, showListing.Show.IDs.TMDB)
 
 		if config.Get().Platform.Kodi < 17 {
 			item.ContextMenu = append(item.ContextMenu,
@@ -750,25 +1452,50 @@ func renderTraktShows(ctx *gin.Context, shows []*trakt.Shows, total int, page in
 		path := ctx.Request.URL.Path
 		nextpage := &xbmc.ListItem{
 			Label:     "LOCALIZE[30415];;" + strconv.Itoa(page+1),
-			Path:      URLForXBMC(fmt.Sprintf("%s?page=%d", path, page+1)),
+			Path:      URLForXBMC(fmt.Sprintf("%s?page=%d", path, page+1) + sortQuery(ctx)),
 			Thumbnail: config.AddonResource("img", "nextpage.png"),
 		}
 		items = append(items, nextpage)
 	}
+	items = withListingUpdated(ctx, items)
 	ctx.JSON(200, xbmc.NewView("tvshows", items))
 }
 
+// filterTraktShows empties shows, not allowed by content filters.
+// Positions are kept, since listings are paginated by index.
+func filterTraktShows(shows []*trakt.Shows) []*trakt.Shows {
+	for i, s := range shows {
+		if s != nil && s.Show != nil && !util.IsContentAllowed(s.Show.Language, s.Show.Votes) {
+			shows[i] = nil
+		}
+	}
+	return shows
+}
+
 // TraktPopularShows ...
 func TraktPopularShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.TopShows("popular", pageParam)
+	shows, total, err := trakt.TopShowsByGenre("popular", ctx.Params.ByName("genre"), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterNotInterestedTraktShows(filterTraktShows(shows)), total, page)
+}
+
+// TraktCountryPopularShows lists popular shows, filtered by configured country
+func TraktCountryPopularShows(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	pageParam := ctx.DefaultQuery("page", "1")
+	page, _ := strconv.Atoi(pageParam)
+	shows, total, err := trakt.TopShowsByCountry("popular", config.Get().Region, pageParam)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+	renderTraktShows(ctx, filterNotInterestedTraktShows(filterTraktShows(shows)), total, page)
 }
 
 // TraktRecommendationsShows ...
@@ -781,7 +1508,7 @@ func TraktRecommendationsShows(ctx *gin.Context) {
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterNotInterestedTraktShows(filterTraktShows(shows)), total, page)
 }
 
 // TraktTrendingShows ...
@@ -790,11 +1517,11 @@ func TraktTrendingShows(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.TopShows("trending", pageParam)
+	shows, total, err := trakt.TopShowsByGenre("trending", ctx.Params.ByName("genre"), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterNotInterestedTraktShows(filterTraktShows(shows)), total, page)
 }
 
 // TraktMostPlayedShows ...
@@ -803,11 +1530,11 @@ func TraktMostPlayedShows(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.TopShows("played", pageParam)
+	shows, total, err := trakt.TopShows(topPeriodCategory("played", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterTraktShows(shows), total, page)
 }
 
 // TraktMostWatchedShows ...
@@ -816,11 +1543,11 @@ func TraktMostWatchedShows(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.TopShows("watched", pageParam)
+	shows, total, err := trakt.TopShows(topPeriodCategory("watched", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterTraktShows(shows), total, page)
 }
 
 // TraktMostCollectedShows ...
@@ -829,11 +1556,11 @@ func TraktMostCollectedShows(ctx *gin.Context) {
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.TopShows("collected", pageParam)
+	shows, total, err := trakt.TopShows(topPeriodCategory("collected", ctx.Params.ByName("period")), pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	renderTraktShows(ctx, filterTraktShows(shows), total, page)
 }
 
 // TraktMostAnticipatedShows ...
@@ -846,7 +1573,127 @@ func TraktMostAnticipatedShows(ctx *gin.Context) {
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderTraktShows(ctx, shows, total, page)
+	if ctx.Params.ByName("sort") == "release" {
+		trakt.SortShowsByRelease(shows)
+	}
+	renderTraktShows(ctx, filterTraktShows(shows), total, page)
+}
+
+// traktGenreSlugs maps Trakt genre slugs to slugs for images
+var traktGenreSlugs = map[string]string{
+	"action":          "action",
+	"adventure":       "adventure",
+	"animation":       "animation",
+	"anime":           "animation",
+	"children":        "kids",
+	"comedy":          "comedy",
+	"crime":           "crime",
+	"documentary":     "documentary",
+	"drama":           "drama",
+	"family":          "family",
+	"fantasy":         "fantasy",
+	"foreign":         "foreign",
+	"history":         "history",
+	"horror":          "horror",
+	"music":           "music",
+	"musical":         "music",
+	"mystery":         "mystery",
+	"news":            "news",
+	"reality":         "reality",
+	"romance":         "romance",
+	"science-fiction": "scifi",
+	"soap":            "soap",
+	"suspense":        "thriller",
+	"talk-show":       "talk",
+	"thriller":        "thriller",
+	"tv-movie":        "tv",
+	"war":             "war",
+	"western":         "western",
+}
+
+// TraktGenresMovies ...
+func TraktGenresMovies(ctx *gin.Context) {
+	renderTraktGenres(ctx, "movies", "menus_movies_genres")
+}
+
+// TraktGenresShows ...
+func TraktGenresShows(ctx *gin.Context) {
+	renderTraktGenres(ctx, "shows", "menus_tvshows_genres")
+}
+
+func renderTraktGenres(ctx *gin.Context, media string, view string) {
+	defer perf.ScopeTimer()()
+
+	genres, err := trakt.GetGenres(media)
+	if err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+	}
+
+	items := make(xbmc.ListItems, 0, len(genres))
+	for _, genre := range genres {
+		if genre == nil || genre.Slug == "" || genre.Slug == "none" {
+			continue
+		}
+
+		thumbnail := config.AddonResource("img", "trakt.png")
+		if slug, ok := traktGenreSlugs[genre.Slug]; ok {
+			thumbnail = config.AddonResource("img", fmt.Sprintf("genre_%s.png", slug))
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     genre.Name,
+			Path:      URLForXBMC("/%s/trakt/popular/genre/%s", media, genre.Slug),
+			Thumbnail: thumbnail,
+			ContextMenu: [][]string{
+				{"LOCALIZE[30246]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/%s/trakt/trending/genre/%s", media, genre.Slug))},
+				{"LOCALIZE[30144]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/%s", view))},
+			},
+		})
+	}
+	ctx.JSON(200, xbmc.NewView(view, filterListItems(items)))
+}
+
+// TraktPeriodsMovies ...
+func TraktPeriodsMovies(ctx *gin.Context) {
+	renderTopPeriods(ctx, "movies", "menus_movies")
+}
+
+// TraktPeriodsShows ...
+func TraktPeriodsShows(ctx *gin.Context) {
+	renderTopPeriods(ctx, "shows", "menus_tvshows")
+}
+
+func renderTopPeriods(ctx *gin.Context, media string, view string) {
+	defer perf.ScopeTimer()()
+
+	category := ctx.Params.ByName("category")
+	labels := map[string]string{
+		"weekly":  "LOCALIZE[30804]",
+		"monthly": "LOCALIZE[30805]",
+		"yearly":  "LOCALIZE[30806]",
+		"all":     "LOCALIZE[30807]",
+	}
+
+	items := make(xbmc.ListItems, 0, len(trakt.TopPeriods))
+	for _, period := range trakt.TopPeriods {
+		items = append(items, &xbmc.ListItem{
+			Label:     labels[period],
+			Path:      URLForXBMC("/%s/trakt/%s/%s", media, category, period),
+			Thumbnail: config.AddonResource("img", fmt.Sprintf("most_%s.png", category)),
+		})
+	}
+	ctx.JSON(200, xbmc.NewView(view, filterListItems(items)))
+}
+
+// topPeriodCategory appends chart period to Trakt category, if it is supported
+func topPeriodCategory(category string, period string) string {
+	for _, p := range trakt.TopPeriods {
+		if p == period {
+			return category + "/" + period
+		}
+	}
+
+	return category
 }
 
 //
@@ -857,133 +1704,154 @@ func TraktMostAnticipatedShows(ctx *gin.Context) {
 func TraktMyShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("my/shows", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "my/shows")
 }
 
 // TraktMyNewShows ...
 func TraktMyNewShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("my/shows/new", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "my/shows/new")
 }
 
 // TraktMyPremieres ...
 func TraktMyPremieres(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("my/shows/premieres", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "my/shows/premieres")
 }
 
 // TraktMyMovies ...
 func TraktMyMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.CalendarMovies("my/movies", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarMovies(ctx, movies, total, page)
+	calendarMovies(ctx, "my/movies")
 }
 
 // TraktMyReleases ...
 func TraktMyReleases(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.CalendarMovies("my/dvd", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarMovies(ctx, movies, total, page)
+	calendarMovies(ctx, "my/dvd")
 }
 
 // TraktAllShows ...
 func TraktAllShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("all/shows", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "all/shows")
 }
 
 // TraktAllNewShows ...
 func TraktAllNewShows(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("all/shows/new", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "all/shows/new")
 }
 
 // TraktAllPremieres ...
 func TraktAllPremieres(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
-	pageParam := ctx.DefaultQuery("page", "1")
-	page, _ := strconv.Atoi(pageParam)
-	shows, total, err := trakt.CalendarShows("all/shows/premieres", pageParam)
-	if err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
-	}
-	renderCalendarShows(ctx, shows, total, page)
+	calendarShows(ctx, "all/shows/premieres")
 }
 
 // TraktAllMovies ...
 func TraktAllMovies(ctx *gin.Context) {
 	defer perf.ScopeTimer()()
 
+	calendarMovies(ctx, "all/movies")
+}
+
+// TraktAllReleases ...
+func TraktAllReleases(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	calendarMovies(ctx, "all/dvd")
+}
+
+// calendarShows renders shows calendar of an endpoint. If date range is set in settings,
+// or with "start" and "days" query parameters, whole range is shown, grouped by dates.
+func calendarShows(ctx *gin.Context, endPoint string) {
+	if start, days, ok := calendarRange(ctx); ok {
+		shows, err := trakt.CalendarShowsRange(endPoint, start, days)
+		if err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		renderCalendarShows(ctx, shows, -1, 0, true)
+		return
+	}
+
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.CalendarMovies("all/movies", pageParam)
+	shows, total, err := trakt.CalendarShows(endPoint, pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderCalendarMovies(ctx, movies, total, page)
+	renderCalendarShows(ctx, shows, total, page, false)
 }
 
-// TraktAllReleases ...
-func TraktAllReleases(ctx *gin.Context) {
-	defer perf.ScopeTimer()()
+// calendarMovies renders movies calendar of an endpoint, see calendarShows
+func calendarMovies(ctx *gin.Context, endPoint string) {
+	if start, days, ok := calendarRange(ctx); ok {
+		movies, err := trakt.CalendarMoviesRange(endPoint, start, days)
+		if err != nil {
+			xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		}
+		renderCalendarMovies(ctx, movies, -1, 0, true)
+		return
+	}
 
 	pageParam := ctx.DefaultQuery("page", "1")
 	page, _ := strconv.Atoi(pageParam)
-	movies, total, err := trakt.CalendarMovies("all/dvd", pageParam)
+	movies, total, err := trakt.CalendarMovies(endPoint, pageParam)
 	if err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
 	}
-	renderCalendarMovies(ctx, movies, total, page)
+	renderCalendarMovies(ctx, movies, total, page, false)
+}
+
+// calendarRange returns date range of a calendar from query parameters, or from settings.
+// Zero number of days means default Trakt calendar.
+func calendarRange(ctx *gin.Context) (start time.Time, days int, ok bool) {
+	start = util.UTCBod().AddDate(0, 0, config.Get().TraktCalendarsStartDays)
+	days = config.Get().TraktCalendarsDays
+
+	if t, err := time.Parse("2006-01-02", ctx.Query("start")); err == nil {
+		start = t
+	}
+	if d, err := strconv.Atoi(ctx.Query("days")); err == nil {
+		days = d
+	}
+
+	return start, days, days > 0
 }
 
-func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total int, page int) {
+// groupCalendarItems puts a date label before items of each day
+func groupCalendarItems(ctx *gin.Context, items xbmc.ListItems, dates []time.Time) xbmc.ListItems {
+	colorDate := config.Get().TraktCalendarsColorDate
+	dateFormat := getCalendarsDateFormat()
+	path := URLForXBMC(ctx.Request.URL.Path)
+
+	ret := make(xbmc.ListItems, 0, len(items))
+	var last time.Time
+	for i, item := range items {
+		if item == nil || i >= len(dates) {
+			continue
+		}
+		if len(ret) == 0 || !dates[i].Equal(last) {
+			last = dates[i]
+			ret = append(ret, &xbmc.ListItem{
+				Label: fmt.Sprintf("[B][COLOR %s]%s, %s[/COLOR][/B]", colorDate, last.Weekday(), last.Format(dateFormat)),
+				Path:  URLQuery(path, "start", last.Format("2006-01-02"), "days", "1"),
+			})
+		}
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total int, page int, grouped bool) {
 	hasNextPage := 0
 	if page > 0 {
 		resultsPerPage := config.Get().ResultsPerPage
@@ -1014,6 +1882,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 	dateFormat := getCalendarsDateFormat()
 
 	items := make(xbmc.ListItems, len(movies)+hasNextPage)
+	dates := make([]time.Time, len(movies))
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(movies))
@@ -1027,7 +1896,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 			}
 
 			var movie *tmdb.Movie
-			movieName := movieListing.Movie.Title
+			movieName := tmdb.DisplayTitle(movieListing.Movie.Title, movieListing.Movie.OriginalTitle)
 			airDate := movieListing.Movie.Released
 			if len(airDate) > 10 && strings.Contains(airDate, "T") {
 				airDate = airDate[0:strings.Index(airDate, "T")]
@@ -1037,7 +1906,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 				movie = tmdb.GetMovie(movieListing.Movie.IDs.TMDB, language)
 
 				if movie != nil {
-					movieName = movie.Title
+					movieName = tmdb.DisplayTitle(movie.Title, movie.OriginalTitle)
 				}
 			}
 
@@ -1090,6 +1959,8 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 				{"LOCALIZE[30619];;LOCALIZE[30214]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movies/"))},
 				watchlistAction,
 				collectionAction,
+				{"LOCALIZE[30878]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/movie/%d/rate", movieListing.Movie.IDs.TMDB))},
+				{"LOCALIZE[30884]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/movie/%d/comments", movieListing.Movie.IDs.TMDB))},
 				{"LOCALIZE[30034]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/movies"))},
 			}
 			item.ContextMenu = append(libraryActions, item.ContextMenu...)
@@ -1102,11 +1973,16 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 			}
 
 			item.IsPlayable = true
-			items = append(items, item)
+			items[i] = item
+			dates[i] = aired
 		}(i, m)
 	}
 	wg.Wait()
 
+	if grouped {
+		items = groupCalendarItems(ctx, items, dates)
+	}
+
 	for i := len(items) - 1; i >= 0; i-- {
 		if items[i] == nil {
 			items = append(items[:i], items[i+1:]...)
@@ -1125,7 +2001,7 @@ func renderCalendarMovies(ctx *gin.Context, movies []*trakt.CalendarMovie, total
 	ctx.JSON(200, xbmc.NewView("movies", items))
 }
 
-func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total int, page int) {
+func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total int, page int, grouped bool) {
 	hasNextPage := 0
 	if page > 0 {
 		resultsPerPage := config.Get().ResultsPerPage
@@ -1159,6 +2035,7 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 
 	now := util.UTCBod()
 	items := make(xbmc.ListItems, len(shows)+hasNextPage)
+	dates := make([]time.Time, len(shows))
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(shows))
@@ -1176,7 +2053,7 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 			seasonNumber := epi.Season
 			episodeNumber := epi.Number
 			episodeName := epi.Title
-			showName := showListing.Show.Title
+			showName := tmdb.DisplayTitle(showListing.Show.Title, showListing.Show.OriginalTitle)
 			showOriginalName := showListing.Show.Title
 
 			var episode *tmdb.Episode
@@ -1195,7 +2072,7 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 					episodeName = episode.Name
 				}
 				if show != nil {
-					showName = show.Name
+					showName = tmdb.DisplayTitle(show.Name, show.OriginalName)
 					showOriginalName = show.OriginalName
 				}
 			}
@@ -1280,10 +2157,15 @@ func renderCalendarShows(ctx *gin.Context, shows []*trakt.CalendarShow, total in
 			item.IsPlayable = true
 
 			items[i] = item
+			dates[i] = aired
 		}(i, s)
 	}
 	wg.Wait()
 
+	if grouped {
+		items = groupCalendarItems(ctx, items, dates)
+	}
+
 	for i := len(items) - 1; i >= 0; i-- {
 		if items[i] == nil {
 			items = append(items[:i], items[i+1:]...)
@@ -1328,7 +2210,7 @@ func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total in
 			seasonNumber := epi.Season
 			episodeNumber := epi.Number
 			episodeName := epi.Title
-			showName := showListing.Show.Title
+			showName := tmdb.DisplayTitle(showListing.Show.Title, showListing.Show.OriginalTitle)
 
 			var episode *tmdb.Episode
 			var season *tmdb.Season
@@ -1346,7 +2228,7 @@ func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total in
 					episodeName = episode.Name
 				}
 				if show != nil {
-					showName = show.Name
+					showName = tmdb.DisplayTitle(show.Name, show.OriginalName)
 				}
 			}
 			if airDate == "" {
@@ -1411,6 +2293,13 @@ func renderProgressShows(ctx *gin.Context, shows []*trakt.ProgressShow, total in
 				{contextLabel, fmt.Sprintf("XBMC.PlayMedia(%s)", contextURL)},
 				{"LOCALIZE[30037]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/setviewmode/episodes"))},
 				{markWatchedLabel, fmt.Sprintf("XBMC.RunPlugin(%s)", markWatchedURL)},
+				{"LOCALIZE[30887]", fmt.Sprintf("XBMC.RunPlugin(%s)", markWatchedURL+"/note")},
+				{"LOCALIZE[30878]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/season/%d/episode/%d/rate", showListing.Show.IDs.TMDB, seasonNumber, episodeNumber))},
+				{"LOCALIZE[30884]", fmt.Sprintf("Container.Update(%s)", URLForXBMC("/show/%d/season/%d/episode/%d/comments", showListing.Show.IDs.TMDB, seasonNumber, episodeNumber))},
+				rewatchAction(showListing.Show.IDs.Trakt),
+				{"LOCALIZE[30897]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/progress/hide", showListing.Show.IDs.TMDB))},
+				{"LOCALIZE[30788]", fmt.Sprintf("XBMC.RunPlugin(%s)", URLForXBMC("/show/%d/progress/dismiss/%d/%d", showListing.Show.IDs.TMDB, epi.Season, epi.Number))},
+				spoilersAction(showListing.Show.IDs.TMDB),
 			}
 			if config.Get().Platform.Kodi < 17 {
 				item.ContextMenu = append(item.ContextMenu,
@@ -1477,6 +2366,25 @@ func getProgressDateFormat() string {
 	return prepareDateFormat(config.Get().TraktProgressDateFormat)
 }
 
+// releaseCountdown returns label suffix with days left until release, or empty string for released items
+func releaseCountdown(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	days := int(date.Sub(util.UTCBod()).Hours() / 24)
+	switch {
+	case days < 0:
+		return ""
+	case days == 0:
+		return " [COLOR gold](releases today)[/COLOR]"
+	case days == 1:
+		return " [COLOR gold](releases tomorrow)[/COLOR]"
+	default:
+		return fmt.Sprintf(" [COLOR gold](releases in %d days)[/COLOR]", days)
+	}
+}
+
 func getCalendarsDateFormat() string {
 	return prepareDateFormat(config.Get().TraktCalendarsDateFormat)
 }
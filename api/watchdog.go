@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/watchdog"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// WatchdogStatus returns monitored tasks, which are running now
+func WatchdogStatus(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.JSON(200, gin.H{
+		"tasks": watchdog.Running(),
+	})
+}
+
+// WatchdogGoroutines returns stacks of all goroutines, to look for a deadlock
+func WatchdogGoroutines(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	ctx.Data(200, "text/plain; charset=utf-8", watchdog.Stacks())
+}
+
+// WatchdogRestart aborts running tasks of a subsystem and starts it again
+func WatchdogRestart(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	name := ctx.Params.ByName("name")
+	if err := watchdog.Restart(name); err != nil {
+		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		ctx.String(404, err.Error())
+		return
+	}
+
+	xbmc.Notify("Elementum", fmt.Sprintf("Restarted %s", name), config.AddonIcon())
+	ctx.String(200, "")
+}
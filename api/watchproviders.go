@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/missinggo/perf"
+	"github.com/gin-gonic/gin"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// WatchProvidersInfo shows streaming services and stores, offering a movie or a show in user region
+func WatchProvidersInfo(mediaType, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer perf.ScopeTimer()()
+
+		tmdbID, _ := strconv.Atoi(ctx.Params.ByName(param))
+		providers := tmdb.GetWatchProviders(mediaType, tmdbID, config.Get().Region)
+
+		lines := []string{}
+		if providers != nil {
+			free := append(append([]*tmdb.WatchProvider{}, providers.Free...), providers.Ads...)
+			for _, group := range []struct {
+				label     int
+				providers []*tmdb.WatchProvider
+			}{
+				{30752, providers.Flatrate},
+				{30755, free},
+				{30753, providers.Rent},
+				{30754, providers.Buy},
+			} {
+				if names := tmdb.WatchProviderNames(group.providers); len(names) > 0 {
+					lines = append(lines, "[B]"+xbmc.GetLocalizedString(group.label)+"[/B]: "+strings.Join(names, ", "))
+				}
+			}
+		}
+		if len(lines) == 0 {
+			lines = append(lines, xbmc.GetLocalizedString(30756))
+		}
+		lines = append(lines, "", "JustWatch / TMDB, "+config.Get().Region)
+
+		xbmc.DialogText(xbmc.GetLocalizedString(30751), strings.Join(lines, "\n"))
+		ctx.String(200, "")
+	}
+}
+
+// watchProvidersAction returns context menu action to show, where an item can be watched
+func watchProvidersAction(mediaType string, tmdbID int) []string {
+	path := "/movie/%d/providers"
+	if mediaType == showType {
+		path = "/show/%d/providers"
+	}
+	return []string{"LOCALIZE[30751]", "XBMC.RunPlugin(" + URLForXBMC(path, tmdbID) + ")"}
+}
+
+// StreamingChanges lists watchlist items, which recently arrived on, or left streaming services
+func StreamingChanges(ctx *gin.Context) {
+	defer perf.ScopeTimer()()
+
+	items := xbmc.ListItems{}
+	for _, state := range database.GetStorm().GetStreamingChanges(time.Now().AddDate(0, 0, -30)) {
+		changes := []string{}
+		if len(state.Arrived) > 0 {
+			changes = append(changes, "[COLOR FF00FF00]+ "+strings.Join(state.Arrived, ", ")+"[/COLOR]")
+		}
+		if len(state.Left) > 0 {
+			changes = append(changes, "[COLOR FFFF0000]- "+strings.Join(state.Left, ", ")+"[/COLOR]")
+		}
+
+		path := URLForXBMC("/show/%d/seasons", state.TMDBID)
+		if state.MediaType == movieType {
+			path = contextPlayURL(URLForXBMC("/movie/%d/", state.TMDBID)+"%s/%s", state.Title, false)
+		}
+
+		items = append(items, &xbmc.ListItem{
+			Label:     fmt.Sprintf("%s  %s  %s", state.Changed.Format("2006-01-02"), state.Title, strings.Join(changes, " ")),
+			Path:      path,
+			Thumbnail: config.AddonResource("img", "trakt.png"),
+			Info: &xbmc.ListItemInfo{
+				Plot: strings.Join(state.Providers, ", "),
+			},
+			ContextMenu: [][]string{
+				watchProvidersAction(state.MediaType, state.TMDBID),
+			},
+		})
+	}
+
+	ctx.JSON(200, xbmc.NewView("menus", filterListItems(items)))
+}
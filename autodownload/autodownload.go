@@ -0,0 +1,206 @@
+package autodownload
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+const (
+	checkInterval = time.Hour
+	// Episodes, which are not found, are searched again not more often, than this
+	retryInterval = 3 * time.Hour
+	// Number of latest seasons, checked for new episodes
+	checkSeasons = 2
+)
+
+var (
+	log = logging.MustGetLogger("autodownload")
+
+	// Prevents parallel checks from the loop and from manual refresh
+	checkMu sync.Mutex
+)
+
+// Run checks auto-downloaded shows periodically, until closing is signalled
+func Run(s *bittorrent.Service, closing <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var lastCheck time.Time
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			if !config.Get().AutoDownloadEnabled || time.Since(lastCheck) < checkInterval {
+				continue
+			}
+
+			lastCheck = time.Now()
+			Check(s)
+		}
+	}
+}
+
+// Check collects new episodes of auto-downloaded shows and downloads aired ones,
+// returns number of added downloads
+func Check(s *bittorrent.Service) (added int) {
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	for _, item := range database.GetStorm().GetAutoDownloadShows() {
+		show := tmdb.GetShow(item.ShowID, config.Get().Language)
+		if show == nil {
+			continue
+		}
+
+		collect(show, item.Added)
+	}
+
+	for _, episode := range database.GetStorm().GetAutoDownloadEpisodes() {
+		if episode.State != database.AutoDownloadPending || !isReady(&episode) {
+			continue
+		}
+
+		episode := episode
+		if process(s, &episode) {
+			added++
+		}
+		database.GetStorm().SaveAutoDownloadEpisode(&episode)
+	}
+
+	return
+}
+
+// collect adds episodes of latest seasons, aired after the show was marked, to the queue
+func collect(show *tmdb.Show, since time.Time) {
+	sinceDay := since.UTC().Truncate(24 * time.Hour)
+
+	seasons := 0
+	for i := len(show.Seasons) - 1; i >= 0 && seasons < checkSeasons; i-- {
+		if show.Seasons[i] == nil || show.Seasons[i].Season == 0 {
+			continue
+		}
+		seasons++
+
+		season := tmdb.GetSeason(show.ID, show.Seasons[i].Season, config.Get().Language, len(show.Seasons))
+		if season == nil {
+			continue
+		}
+
+		for _, e := range season.Episodes {
+			if e == nil || e.AirDate == "" {
+				continue
+			}
+			if aired, err := time.Parse("2006-01-02", e.AirDate); err != nil || aired.Before(sinceDay) {
+				continue
+			}
+			if database.GetStorm().GetAutoDownloadEpisode(show.ID, e.SeasonNumber, e.EpisodeNumber) != nil {
+				continue
+			}
+
+			database.GetStorm().SaveAutoDownloadEpisode(&database.AutoDownloadEpisode{
+				ShowID:  show.ID,
+				Season:  e.SeasonNumber,
+				Episode: e.EpisodeNumber,
+				AirDate: e.AirDate,
+				State:   database.AutoDownloadPending,
+				Title:   fmt.Sprintf("%s S%02dE%02d", show.Name, e.SeasonNumber, e.EpisodeNumber),
+			})
+		}
+	}
+}
+
+// isReady checks that episode is aired, configured delay has passed
+// and it was not searched recently
+func isReady(episode *database.AutoDownloadEpisode) bool {
+	aired, err := time.Parse("2006-01-02", episode.AirDate)
+	if err != nil || !aired.Before(util.UTCBod()) {
+		return false
+	}
+	if time.Since(aired) < time.Duration(config.Get().AutoDownloadDelay)*time.Hour {
+		return false
+	}
+
+	return time.Since(episode.LastAttempt) >= retryInterval
+}
+
+// process searches an episode and adds the best found torrent, returns true if torrent is added
+func process(s *bittorrent.Service, item *database.AutoDownloadEpisode) bool {
+	item.Attempts++
+	item.LastAttempt = time.Now()
+
+	infoHash, err := download(s, item)
+	if err == nil {
+		item.State = database.AutoDownloadAdded
+		item.InfoHash = infoHash
+		notifications.Notify(notifications.AutoDownload, "LOCALIZE[30764];;"+item.Title)
+		return true
+	}
+
+	log.Warningf("Could not auto-download '%s': %s", item.Title, err)
+	if attempts := config.Get().AutoDownloadAttempts; attempts > 0 && item.Attempts >= attempts {
+		item.State = database.AutoDownloadFailed
+		notifications.Notify(notifications.AutoDownload, "LOCALIZE[30765];;"+item.Title)
+	}
+	return false
+}
+
+// download adds the best search result and selects episode file in it
+func download(s *bittorrent.Service, item *database.AutoDownloadEpisode) (string, error) {
+	show := tmdb.GetShow(item.ShowID, config.Get().Language)
+	if show == nil {
+		return "", fmt.Errorf("Unable to get show")
+	}
+	episode := tmdb.GetEpisode(item.ShowID, item.Season, item.Episode, config.Get().Language)
+	if episode == nil {
+		return "", fmt.Errorf("Unable to get episode")
+	}
+
+	// Results are sorted by configured quality rules, so the first one is the best
+	torrents := providers.SearchEpisodeSilent(providers.GetEpisodeSearchers(), show, episode, true)
+	if len(torrents) == 0 {
+		return "", fmt.Errorf("Nothing found")
+	}
+
+	log.Infof("Adding '%s' for auto-downloaded episode '%s'", torrents[0].Name, item.Title)
+	t, err := s.AddTorrent(torrents[0].URI, false, config.Get().DownloadStorage)
+	if err != nil {
+		return "", err
+	}
+
+	database.GetStorm().UpdateBTItem(t.InfoHash(), episode.ID, "episode", []string{}, t.Name(), show.ID, item.Season, item.Episode)
+
+	if !t.HasMetadata() {
+		if err := t.WaitForMetadata(t.InfoHash()); err != nil {
+			return "", err
+		}
+	}
+
+	choices, _, err := t.GetCandidateFiles(nil)
+	if err != nil {
+		return "", err
+	}
+
+	index, found := bittorrent.MatchEpisodeFilename(item.Season, item.Episode, false, item.Season, show, episode, nil, choices)
+	if found == 1 {
+		t.DownloadFile(t.GetFileByIndex(choices[index].Index))
+	} else if len(choices) == 1 {
+		t.DownloadFile(t.GetFileByIndex(choices[0].Index))
+	} else {
+		t.DownloadAllFiles()
+	}
+	t.SaveDBFiles()
+
+	return t.InfoHash(), nil
+}
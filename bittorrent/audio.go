@@ -0,0 +1,113 @@
+package bittorrent
+
+import (
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Amount of data at the start of next audio file, fetched in advance for gapless playback
+const audioPrefetchSize = 1024 * 1024
+
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".m4b":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".wma":  "audio/x-ms-wma",
+	".ape":  "audio/x-ape",
+	".wv":   "audio/x-wavpack",
+	".dsf":  "audio/x-dsf",
+}
+
+func init() {
+	// Registering audio types, as system mime database does not always know them
+	for ext, t := range audioMimeTypes {
+		mime.AddExtensionType(ext, t)
+	}
+}
+
+// IsAudioFile returns true if file has known audio extension
+func IsAudioFile(path string) bool {
+	_, ok := audioMimeTypes[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// AudioFiles returns audio files of a torrent, sorted by path to keep album order
+func (t *Torrent) AudioFiles() []*File {
+	ret := []*File{}
+	for _, f := range t.files {
+		if IsAudioFile(f.Path) {
+			ret = append(ret, f)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Path < ret[j].Path
+	})
+	return ret
+}
+
+// IsAudioTorrent returns true if most of the torrent size is taken by audio files
+func (t *Torrent) IsAudioTorrent() bool {
+	var total, audio int64
+	for _, f := range t.files {
+		total += f.Size
+		if IsAudioFile(f.Path) {
+			audio += f.Size
+		}
+	}
+
+	return total > 0 && audio*2 > total
+}
+
+// StartAudioPlaylist selects all audio files for download in sequential mode,
+// so pieces are fetched in playlist order, across file boundaries.
+func (t *Torrent) StartAudioPlaylist() {
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return
+	}
+
+	if !t.IsMemoryStorage() {
+		t.DownloadFiles(t.AudioFiles())
+		t.SaveDBFiles()
+	}
+
+	log.Infof("Starting audio playlist for %s", t.Name())
+	t.th.SetSequentialDownload(true)
+}
+
+// prefetchNextAudio requests first pieces of the audio file, following the current one,
+// so switching tracks in a playlist does not wait for the download.
+func (t *Torrent) prefetchNextAudio(current *File) {
+	if t.IsMemoryStorage() || t.ti == nil || t.ti.Swigcptr() == 0 || !IsAudioFile(current.Path) {
+		return
+	}
+
+	files := t.AudioFiles()
+	for i, f := range files {
+		if f.Index != current.Index || i+1 >= len(files) {
+			continue
+		}
+
+		next := files[i+1]
+		pieceLength := int64(t.ti.PieceLength())
+		end := int((next.Offset + audioPrefetchSize) / pieceLength)
+		if end > next.PieceEnd {
+			end = next.PieceEnd
+		}
+
+		log.Debugf("Prefetching pieces %d-%d of next audio file: %s", next.PieceStart, end, next.Path)
+		for piece := next.PieceStart; piece <= end; piece++ {
+			t.th.PiecePriority(piece, 6)
+			t.th.SetPieceDeadline(piece, 0, 0)
+		}
+		return
+	}
+}
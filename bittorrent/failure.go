@@ -0,0 +1,36 @@
+package bittorrent
+
+import (
+	"errors"
+)
+
+// Buffering failures, classified to show user a specific guidance
+var (
+	// ErrNotEnoughSpace is returned when download destination has no space for chosen file
+	ErrNotEnoughSpace = errors.New("Not enough space on download destination")
+	// ErrNoSeeds is returned when buffering is stopped, while torrent has no known seeds or peers
+	ErrNoSeeds = errors.New("No seeds or peers available for this torrent")
+	// ErrPortBlocked is returned when buffering is stopped, while swarm is known,
+	// but no connections could be made, which usually means that listen port is blocked
+	ErrPortBlocked = errors.New("Unable to connect to peers, listen port may be blocked")
+	// ErrBufferingCancelled is returned when user stops buffering without a known reason
+	ErrBufferingCancelled = errors.New("User cancelled the buffering")
+)
+
+// stalledReason explains why buffering did not progress, judging by torrent connections
+func (btp *Player) stalledReason() error {
+	if btp.t == nil || btp.t.Closer.IsSet() || btp.t.th == nil || btp.t.th.Swigcptr() == 0 {
+		return ErrBufferingCancelled
+	}
+
+	seeds, seedsTotal, peers, peersTotal := btp.t.GetConnections()
+	if seeds+peers > 0 {
+		return ErrBufferingCancelled
+	} else if seedsTotal+peersTotal == 0 {
+		return ErrNoSeeds
+	} else if status := btp.t.GetLastStatus(false); status != nil && status.Swigcptr() != 0 && !status.GetHasIncoming() {
+		return ErrPortBlocked
+	}
+
+	return ErrBufferingCancelled
+}
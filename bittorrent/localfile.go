@@ -0,0 +1,57 @@
+package bittorrent
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+)
+
+// FindLocalFile returns completely downloaded file of media item, which still exists on disk, or nil
+func FindLocalFile(contentType string, tmdbID int) *database.LocalFile {
+	if tmdbID == 0 {
+		return nil
+	}
+
+	f := database.GetStorm().GetLocalFile(contentType, tmdbID)
+	if f == nil {
+		return nil
+	}
+
+	if fi, err := os.Stat(LocalFilePath(f)); err != nil || fi.Size() != f.Size {
+		log.Debugf("Downloaded file %s is not available anymore", f.Path)
+		database.GetStorm().DeleteLocalFile(f)
+		return nil
+	}
+	return f
+}
+
+// LocalFilePath returns absolute path of downloaded file
+func LocalFilePath(f *database.LocalFile) string {
+	return filepath.Join(config.Get().DownloadPath, f.Path)
+}
+
+// saveLocalFile remembers chosen file, if it is completely downloaded,
+// so that it can be played from disk without searching for it again
+func (btp *Player) saveLocalFile() {
+	if btp.t == nil || btp.chosenFile == nil || btp.t.IsMemoryStorage() || btp.p.TMDBId == 0 {
+		return
+	} else if btp.p.ContentType != movieType && btp.p.ContentType != episodeType {
+		return
+	} else if !btp.t.IsFileDownloaded(btp.chosenFile) {
+		return
+	}
+
+	f := &database.LocalFile{
+		InfoHash:      btp.t.InfoHash(),
+		OriginalIndex: btp.chosenFile.Index,
+		Path:          btp.chosenFile.Path,
+		Size:          btp.chosenFile.Size,
+		Added:         time.Now(),
+	}
+	if err := database.GetStorm().SetLocalFile(btp.p.ContentType, btp.p.TMDBId, f); err != nil {
+		log.Warningf("Could not save downloaded file %s: %s", f.Path, err)
+	}
+}
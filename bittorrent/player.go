@@ -42,8 +42,21 @@ const (
 	singleEpisodeMatchRegex = `(?i)(^|\W|_)(Ep?0*%[1]d|0*%[1]d)(\W|_)`
 )
 
+const (
+	// autoPlayNextLead is a number of seconds to reserve for starting next episode
+	autoPlayNextLead = 5
+
+	// Seeks, that start within introSeekFrom seconds from the beginning and end
+	// between introMinLength and introMaxLength, are considered as intro skips.
+	introSeekFrom  = 60
+	introMinLength = 10
+	introMaxLength = 300
+)
+
 var (
 	errNoCandidates = fmt.Errorf("No candidates left")
+
+	autoPlayBinge = &autoPlayCounter{}
 )
 
 const (
@@ -69,6 +82,7 @@ type Player struct {
 	chosenFile               *File
 	subtitlesFile            *File
 	subtitlesLoaded          []string
+	introSampled             bool
 	fileSize                 int64
 	fileName                 string
 	extracted                string
@@ -93,6 +107,7 @@ type PlayerParams struct {
 	WasSeeked         bool
 	DoneAudio         bool
 	DoneSubtitles     bool
+	DoneStreams       bool
 	Background        bool
 	KodiPosition      int
 	WatchedProgress   float64
@@ -116,6 +131,8 @@ type PlayerParams struct {
 	AbsoluteNumber    int
 	Query             string
 	UpNextSent        bool
+	ReusedTorrent     bool
+	IntroOffset       float64
 	UIDs              *library.UniqueIDs
 	Resume            *library.Resume
 	StoredResume      *library.Resume
@@ -130,6 +147,13 @@ type NextEpisode struct {
 	bufferSize int64
 }
 
+// autoPlayCounter keeps number of consecutive auto-played episodes of a show
+type autoPlayCounter struct {
+	mu     sync.Mutex
+	showID int
+	count  int
+}
+
 // CandidateFile ...
 type CandidateFile struct {
 	Index       int
@@ -174,6 +198,9 @@ func (btp *Player) SetTorrent(t *Torrent) {
 
 	btp.t = t
 
+	// Torrent, awaiting for next file playback, is reused for next episode
+	btp.p.ReusedTorrent = btp.t.IsNextFile
+
 	btp.t.IsBuffering = false
 	btp.t.IsBufferingFinished = false
 	btp.t.IsNextFile = false
@@ -260,6 +287,7 @@ func (btp *Player) Buffer() error {
 		btp.t.IsNeedFinishNotification = true
 	} else {
 		btp.t.IsBuffering = true
+		PlaybackStep(StepBuffer)
 	}
 
 	buffered, done := btp.bufferEvents.Listen()
@@ -282,11 +310,45 @@ func (btp *Player) Buffer() error {
 		return err.(error)
 	} else if !btp.HasChosenFile() {
 		return errors.New("File not chosen")
+	} else if err := btp.probeChosenFile(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// probeChosenFile checks header of chosen file, to warn about or skip formats, that Kodi can not play
+func (btp *Player) probeChosenFile() error {
+	if config.Get().ProbeFileAction == ProbeFileDisabled || btp.p.Background || btp.t.IsRarArchive {
+		return nil
+	}
+
+	f, err := NewTorrentFS(btp.s, "GET").Open("/" + filepath.ToSlash(btp.chosenFile.Path))
+	if err != nil {
+		log.Warningf("Could not open file for probing: %s", err)
+		return nil
+	}
+	defer f.Close()
+
+	format, playable, err := probeFile(f)
+	if err != nil {
+		log.Warningf("Could not probe file %s: %s", btp.chosenFile.Path, err)
+		return nil
+	} else if playable || (format == formatISO && strings.EqualFold(filepath.Ext(btp.chosenFile.Path), ".iso")) {
+		// Disc images are played by Kodi, if they are not disguised as video files
+		log.Debugf("Probed file %s, detected format: %s", btp.chosenFile.Path, format)
+		return nil
+	}
+
+	log.Warningf("File %s is detected as %s, which is not playable", btp.chosenFile.Path, format)
+	if config.Get().ProbeFileAction == ProbeFileWarn && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30926];;%s", format)) {
+		return nil
+	}
+
+	xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30927];;%s", format), config.AddonIcon())
+	return fmt.Errorf("Unplayable file format: %s", format)
+}
+
 func (btp *Player) waitCheckAvailableSpace() {
 	if btp.t.IsMemoryStorage() {
 		return
@@ -300,7 +362,7 @@ func (btp *Player) waitCheckAvailableSpace() {
 		case <-ticker.C:
 			if btp.hasChosenFile {
 				if !btp.s.checkAvailableSpace(btp.t) {
-					btp.bufferEvents.Broadcast(errors.New("Not enough space on download destination"))
+					btp.bufferEvents.Broadcast(ErrNotEnoughSpace)
 					btp.notEnoughSpace = true
 				}
 
@@ -340,10 +402,11 @@ func (btp *Player) processMetadata() {
 		resume = nil
 	}
 
+	requestedResume := btp.p.ResumePlayback
 	btp.p.ResumePlayback = ResumeNo
 	if resume != nil && !btp.p.Background && config.Get().PlayResumeAction != 0 {
 		if !(config.Get().SilentStreamStart ||
-			btp.p.ResumePlayback == ResumeYes ||
+			requestedResume == ResumeYes ||
 			config.Get().PlayResumeAction == 2 ||
 			xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30535];;%s", btp.p.StoredResume.ToString()))) {
 			log.Infof("Resetting stored resume")
@@ -355,6 +418,10 @@ func (btp *Player) processMetadata() {
 		}
 	}
 
+	if btp.p.ResumePlayback == ResumeNo && btp.p.ReusedTorrent {
+		btp.p.IntroOffset = btp.getIntroOffset()
+	}
+
 	files := []string{}
 	if btp.chosenFile != nil {
 		btp.t.DownloadFileWithPriority(btp.chosenFile, 2)
@@ -399,7 +466,12 @@ func (btp *Player) processMetadata() {
 	log.Info("Setting piece priorities")
 
 	if !btp.p.Background {
-		go btp.t.Buffer(btp.chosenFile, btp.p.ResumeHash == "")
+		resumeOffset := int64(0)
+		if btp.p.ResumePlayback == ResumeYes && resume != nil && resume.Total > 0 {
+			resumeOffset = int64(float64(btp.chosenFile.Size) * resume.Position / resume.Total)
+		}
+
+		go btp.t.Buffer(btp.chosenFile, btp.p.ResumeHash == "", btp.startBufferSize(btp.chosenFile), resumeOffset)
 	}
 }
 
@@ -421,7 +493,7 @@ func (btp *Player) statusStrings(progress float64, status lt.TorrentStatus) (str
 		query := btp.t.BufferLength
 		done := int64(float64(progress/100) * float64(query))
 
-		line1 = fmt.Sprintf("%s (%.2f%%) | (%s / %s)", statusName, progress, humanize.Bytes(uint64(done)), humanize.Bytes(uint64(query)))
+		line1 = fmt.Sprintf("%s (%.2f%%) | (%s / %s) | ETA %s", statusName, progress, humanize.Bytes(uint64(done)), humanize.Bytes(uint64(query)), btp.bufferETA(progress, status))
 	} else if btp.t.IsMemoryStorage() {
 		// For memory storage show also memory size near percents.
 		line1 = fmt.Sprintf("%s (%.2f%% / %s)", statusName, progress, humanize.Bytes(uint64(btp.t.MemorySize)))
@@ -457,6 +529,76 @@ func (btp *Player) statusStrings(progress float64, status lt.TorrentStatus) (str
 	return line1, line2, line3
 }
 
+// startBufferSize returns size to buffer before playback, when buffer is set in seconds,
+// estimating file bitrate with item runtime. Zero means buffer size from settings.
+func (btp *Player) startBufferSize(file *File) int64 {
+	seconds := config.Get().BufferSeconds
+	if seconds <= 0 || file == nil || file.Size <= 0 {
+		return 0
+	}
+
+	runtime := btp.runtime()
+	if runtime <= 0 {
+		log.Infof("Runtime is unknown, using buffer size from settings")
+		return 0
+	}
+
+	size := file.Size / int64(runtime) * int64(seconds)
+	if size < int64(config.Get().EndBufferSize) {
+		size = int64(config.Get().EndBufferSize)
+	} else if size > file.Size {
+		size = file.Size
+	}
+
+	log.Infof("Buffering %d seconds of %d seconds runtime: %s", seconds, runtime, humanize.Bytes(uint64(size)))
+	return size
+}
+
+// runtime returns duration of played item in seconds, taken from TMDB
+func (btp *Player) runtime() int {
+	if btp.p.ContentType == movieType && btp.p.TMDBId != 0 {
+		if movie := tmdb.GetMovie(btp.p.TMDBId, config.Get().Language); movie != nil {
+			return movie.Runtime * 60
+		}
+	} else if btp.p.ShowID != 0 {
+		if show := tmdb.GetShow(btp.p.ShowID, config.Get().Language); show != nil && len(show.EpisodeRunTime) > 0 {
+			return show.EpisodeRunTime[0] * 60
+		}
+	}
+
+	return 0
+}
+
+// bufferETA returns estimated time left for buffering, based on current download speed
+func (btp *Player) bufferETA(progress float64, status lt.TorrentStatus) string {
+	rate := int64(status.GetDownloadPayloadRate())
+	if rate <= 0 || btp.t.BufferLength <= 0 {
+		return "--:--"
+	}
+
+	left := int64(float64(btp.t.BufferLength) * (100 - progress) / 100)
+	eta := time.Duration(left/rate) * time.Second
+	return fmt.Sprintf("%d:%02d", int(eta.Minutes()), int(eta.Seconds())%60)
+}
+
+// startAnyway offers to start playback before buffer is complete,
+// returns true if user agreed and buffering is finished.
+func (btp *Player) startAnyway() bool {
+	progress := btp.t.GetBufferProgress()
+	if progress <= 0 || !btp.t.IsBuffering {
+		return false
+	}
+
+	if !xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30712];;%.2f%%", progress)) {
+		return false
+	}
+
+	btp.t.ForceBufferFinished()
+	btp.setRateLimiting(true)
+	btp.bufferEvents.Signal()
+	return true
+}
+
 // HasChosenFile ...
 func (btp *Player) HasChosenFile() bool {
 	return btp.hasChosenFile && btp.chosenFile != nil
@@ -524,6 +666,8 @@ func (btp *Player) Close() {
 		go btp.s.PlayerStop()
 	}()
 
+	btp.saveLocalFile()
+
 	if btp.t.HasNextFile && btp.IsWatched() {
 		log.Infof("Leaving torrent '%s' awaiting for next file playback", btp.t.Name())
 		btp.t.startNextTimer()
@@ -531,7 +675,7 @@ func (btp *Player) Close() {
 	}
 
 	// Remove torrent only if this torrent is not needed for background download or other players are using it.
-	if !btp.p.Background && btp.t.PlayerAttached <= 1 {
+	if !btp.p.Background && btp.t.PlayerAttached <= 1 && !btp.applyPostPlayback() {
 		// If there is no chosen file - we stop the torrent and remove everything
 		btp.s.RemoveTorrent(btp.t, false, btp.notEnoughSpace, btp.IsWatched())
 	}
@@ -558,9 +702,18 @@ func (btp *Player) bufferDialog() {
 			}
 
 			if btp.closer.IsSet() || btp.dialogProgress.IsCanceled() || btp.notEnoughSpace {
-				errMsg := "User cancelled the buffering"
-				log.Info(errMsg)
-				btp.bufferEvents.Broadcast(errors.New(errMsg))
+				if !btp.closer.IsSet() && !btp.notEnoughSpace && btp.startAnyway() {
+					return
+				}
+
+				err := ErrBufferingCancelled
+				if btp.notEnoughSpace {
+					err = ErrNotEnoughSpace
+				} else if !btp.closer.IsSet() {
+					err = btp.stalledReason()
+				}
+				log.Infof("Buffering stopped: %s", err)
+				btp.bufferEvents.Broadcast(err)
 				btp.t.ResetBuffering()
 				return
 			}
@@ -717,6 +870,7 @@ func (btp *Player) playerLoop() {
 	}
 
 	log.Info("Waiting for playback...")
+	PlaybackStep(StepPlayerStart)
 	oneSecond := time.NewTicker(1 * time.Second)
 	defer oneSecond.Stop()
 	playbackTimeout := time.After(time.Duration(config.Get().BufferTimeout) * time.Second)
@@ -730,12 +884,14 @@ playbackWaitLoop:
 		case <-playbackTimeout:
 			log.Warningf("Playback was unable to start after %d seconds. Aborting...", config.Get().BufferTimeout)
 			btp.bufferEvents.Broadcast(errors.New("Playback was unable to start before timeout"))
+			FinishPlaybackTiming("Playback was unable to start before timeout")
 			return
 		case <-oneSecond.C:
 		}
 	}
 
 	log.Info("Playback loop")
+	FinishPlaybackTiming("")
 	overlayStatusActive := false
 	playing := true
 
@@ -749,6 +905,7 @@ playbackWaitLoop:
 	}
 
 	btp.t.IsPlaying = true
+	playbackTicks := 0
 
 playbackLoop:
 	for {
@@ -758,15 +915,27 @@ playbackLoop:
 		}
 		select {
 		case <-oneSecond.C:
+			lastWatchedTime := btp.p.WatchedTime
 			btp.updateWatchTimes()
 
+			if playbackTicks++; playbackTicks%activePlaybackSaveInterval == 0 {
+				go btp.saveActivePlayback()
+			}
+
 			// Trigger UpNext notification if Player is done with initialization
 			if btp.p.VideoDuration > 0 && !btp.p.UpNextSent {
-				go btp.processUpNextPayload()
+				if !config.Get().AutoPlayNext {
+					go btp.processUpNextPayload()
+				} else if btp.p.VideoDuration-btp.p.WatchedTime <= float64(config.Get().AutoPlayNextDelay+autoPlayNextLead) {
+					// Marked before starting, so that next tick does not start it again
+					btp.p.UpNextSent = true
+					go btp.processAutoPlayNext()
+				}
 			}
 
 			if btp.p.Seeked {
 				btp.p.Seeked = false
+				btp.processIntroSeek(lastWatchedTime)
 				if btp.scrobble {
 					go trakt.Scrobble("start", btp.p.ContentType, btp.p.TMDBId, btp.p.WatchedTime, btp.p.VideoDuration)
 				}
@@ -808,6 +977,7 @@ playbackLoop:
 
 	log.Info("Stopped playback")
 	btp.SaveStoredResume()
+	ClearActivePlayback()
 	btp.setRateLimiting(false)
 	go func() {
 		btp.GetIdent()
@@ -1057,9 +1227,12 @@ func (btp *Player) startNextFile() {
 	if (btp.p.ShowID == 0 && btp.p.Query == "") || !btp.t.HasNextFile || !btp.next.done || btp.next.f == nil || btp.t.IsBuffering || btp.next.started {
 		return
 	}
+	if config.Get().MeteredMode {
+		return
+	}
 
 	btp.next.started = true
-	go btp.t.Buffer(btp.next.f, false)
+	go btp.t.Buffer(btp.next.f, false, btp.startBufferSize(btp.next.f), 0)
 }
 
 func (btp *Player) findNextFile() {
@@ -1114,7 +1287,10 @@ func (btp *Player) findNextFile() {
 
 	btp.t.HasNextFile = true
 
-	startBufferSize := btp.s.GetBufferSize()
+	startBufferSize := btp.startBufferSize(btp.next.f)
+	if startBufferSize <= 0 {
+		startBufferSize = btp.s.GetBufferSize()
+	}
 	_, _, _, preBufferSize := btp.t.getBufferSize(btp.next.f.Offset, 0, startBufferSize)
 	_, _, _, postBufferSize := btp.t.getBufferSize(btp.next.f.Offset, btp.next.f.Size-int64(config.Get().EndBufferSize), int64(config.Get().EndBufferSize))
 
@@ -1280,6 +1456,122 @@ func (btp *Player) processUpNextPayload() {
 	xbmc.UpNextNotify(xbmc.Args{payload})
 }
 
+// getIntroOffset returns start offset for current show, either configured for the show,
+// or learned from previously skipped intros.
+func (btp *Player) getIntroOffset() float64 {
+	if btp.p.ShowID == 0 || !config.Get().IntroOffsetEnabled {
+		return 0
+	}
+
+	intro := database.GetStorm().GetShowIntro(btp.p.ShowID)
+	if intro == nil || intro.Offset == database.IntroOffsetDisabled {
+		return 0
+	} else if intro.Offset > 0 {
+		return float64(intro.Offset)
+	}
+
+	return intro.AverageLength
+}
+
+// processIntroSeek remembers intro length if user has skipped it by seeking from the beginning
+func (btp *Player) processIntroSeek(from float64) {
+	if btp.introSampled || btp.p.ShowID == 0 || !config.Get().IntroOffsetEnabled {
+		return
+	}
+	// Ignore automatic seeks, made for resume or intro offset
+	if btp.p.ResumePlayback == ResumeYes || btp.p.IntroOffset > 0 {
+		return
+	}
+
+	length := btp.p.WatchedTime
+	if from > introSeekFrom || length <= from || length < introMinLength || length > introMaxLength {
+		return
+	}
+
+	btp.introSampled = true
+	log.Infof("Saving intro length of %.0f seconds for show %d", length, btp.p.ShowID)
+	if err := database.GetStorm().AddShowIntroSample(btp.p.ShowID, length); err != nil {
+		log.Warningf("Could not save intro length: %s", err)
+	}
+}
+
+func (btp *Player) processAutoPlayNext() {
+	// Only episodes, resolved to a show, have enough information for the dialog
+	if btp.p.ShowID == 0 {
+		return
+	}
+
+	show, season, episode, err := getNextShowSeasonEpisode(btp.p.ShowID, btp.p.Season, btp.p.Episode)
+	if err != nil {
+		log.Warningf("Cannot prepare next episode for auto-play: %s", err)
+		return
+	}
+
+	playURL := contextPlayURL(
+		URLForXBMC("/show/%d/season/%d/episode/%d/",
+			show.ID,
+			episode.SeasonNumber,
+			episode.EpisodeNumber,
+		)+"%s/%s?silent=true",
+		fmt.Sprintf("%s S%02dE%02d", show.OriginalName, episode.SeasonNumber, episode.EpisodeNumber),
+		false,
+	)
+
+	if autoPlayBinge.isBinge(show.ID) {
+		// Binge mode: wait for current episode to end without asking
+		remaining := btp.p.VideoDuration - btp.p.WatchedTime
+		for !btp.closed && xbmc.PlayerIsPlaying() {
+			remaining = btp.p.VideoDuration - btp.p.WatchedTime
+			time.Sleep(1 * time.Second)
+		}
+		if remaining > float64(autoPlayNextLead*2) {
+			log.Infof("Playback was stopped before the end, not starting next episode")
+			autoPlayBinge.reset()
+			return
+		}
+	} else {
+		li := episode.ToListItem(show, season)
+		title := fmt.Sprintf("%s - %dx%02d %s", show.Name, episode.SeasonNumber, episode.EpisodeNumber, episode.Name)
+		if !xbmc.DialogCountdown("LOCALIZE[30789]", title, episode.Overview, li.Art.Thumbnail, li.Art.FanArt, config.Get().AutoPlayNextDelay) {
+			log.Infof("Auto-play of the next episode was cancelled")
+			autoPlayBinge.reset()
+			return
+		}
+	}
+
+	autoPlayBinge.add(show.ID)
+	log.Infof("Auto-playing next episode: %s", playURL)
+	xbmc.PlayURL(playURL)
+}
+
+// isBinge returns true if configured number of episodes was auto-played in a row
+func (c *autoPlayCounter) isBinge(showID int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	binge := config.Get().AutoPlayNextBinge
+	return binge > 0 && c.showID == showID && c.count >= binge
+}
+
+func (c *autoPlayCounter) add(showID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.showID != showID {
+		c.showID = showID
+		c.count = 0
+	}
+	c.count++
+}
+
+func (c *autoPlayCounter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.showID = 0
+	c.count = 0
+}
+
 func (btp *Player) processUpNextShow() (upnext.Payload, error) {
 	res := upnext.Payload{}
 
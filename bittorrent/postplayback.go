@@ -0,0 +1,55 @@
+package bittorrent
+
+import (
+	"fmt"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// postPlaybackPolicy returns configured action for the torrent of finished playback,
+// or -1 if playback is not bound to a media type and global keep settings should be used.
+func (btp *Player) postPlaybackPolicy() int {
+	if !btp.IsWatched() {
+		return config.Get().PostPlaybackPartial
+	}
+
+	switch btp.p.ContentType {
+	case movieType:
+		return config.Get().PostPlaybackMovies
+	case episodeType:
+		return config.Get().PostPlaybackEpisodes
+	}
+	return -1
+}
+
+// applyPostPlayback keeps, pauses or removes torrent of finished playback, according to the policy.
+// Returns false if policy is not defined and torrent should be removed with global keep settings.
+func (btp *Player) applyPostPlayback() bool {
+	if btp.t.IsMemoryStorage() || btp.notEnoughSpace {
+		return false
+	}
+
+	action := btp.postPlaybackPolicy()
+	if action == config.PostPlaybackAsk {
+		choice := xbmc.ListDialog(fmt.Sprintf("LOCALIZE[30714]: %s", btp.t.Name()), "LOCALIZE[30715]", "LOCALIZE[30716]", "LOCALIZE[30717]")
+		if choice < 0 {
+			choice = config.PostPlaybackKeep
+		}
+		action = choice
+	}
+
+	switch action {
+	case config.PostPlaybackKeep:
+		log.Infof("Keeping torrent '%s' seeding after playback", btp.t.Name())
+	case config.PostPlaybackPause:
+		log.Infof("Pausing torrent '%s' after playback", btp.t.Name())
+		btp.t.Pause()
+	case config.PostPlaybackDelete:
+		log.Infof("Deleting torrent '%s' with data after playback", btp.t.Name())
+		btp.s.RemoveTorrent(btp.t, true, true, btp.IsWatched())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package bittorrent
+
+import (
+	"bytes"
+	"io"
+)
+
+// formatISO is a name of disc image format
+const formatISO = "ISO image"
+
+// Size of file header, read for probing. Should cover ISO9660/UDF descriptors at 32K offset.
+const probeHeaderSize = 0x8000 + 0x800
+
+type formatSignature struct {
+	name     string
+	offset   int
+	magic    []byte
+	playable bool
+}
+
+// Known signatures, checked in order
+var formatSignatures = []formatSignature{
+	{"Matroska", 0, []byte{0x1A, 0x45, 0xDF, 0xA3}, true},
+	{"MP4", 4, []byte("ftyp"), true},
+	{"MP4", 4, []byte("moov"), true},
+	{"MP4", 4, []byte("mdat"), true},
+	{"MP4", 4, []byte("free"), true},
+	{"AVI", 8, []byte("AVI "), true},
+	{"MPEG-PS", 0, []byte{0x00, 0x00, 0x01, 0xBA}, true},
+	{"FLV", 0, []byte("FLV"), true},
+	{"ASF", 0, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}, true},
+	{"Ogg", 0, []byte("OggS"), true},
+
+	{"executable", 0, []byte("MZ"), false},
+	{"executable", 0, []byte{0x7F, 'E', 'L', 'F'}, false},
+	{"ZIP archive", 0, []byte{'P', 'K', 0x03, 0x04}, false},
+	{"RAR archive", 0, []byte("Rar!"), false},
+	{"7z archive", 0, []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, false},
+	{"PDF document", 0, []byte("%PDF"), false},
+	{formatISO, 0x8001, []byte("CD001"), false},
+	{formatISO, 0x8001, []byte("BEA01"), false},
+}
+
+// probeFormat detects container format by file header.
+// Returns empty name for unknown formats, which are considered playable.
+func probeFormat(header []byte) (name string, playable bool) {
+	// MPEG-TS has sync byte at the start of each 188 bytes packet
+	if len(header) > 188*2 && header[0] == 0x47 && header[188] == 0x47 && header[188*2] == 0x47 {
+		return "MPEG-TS", true
+	}
+	// Blu-ray M2TS has 4 bytes timestamp before each 188 bytes packet
+	if len(header) > 4+192*2 && header[4] == 0x47 && header[4+192] == 0x47 && header[4+192*2] == 0x47 {
+		return "M2TS", true
+	}
+
+	for _, s := range formatSignatures {
+		if len(header) < s.offset+len(s.magic) {
+			continue
+		}
+		if bytes.Equal(header[s.offset:s.offset+len(s.magic)], s.magic) {
+			return s.name, s.playable
+		}
+	}
+
+	return "", true
+}
+
+// probeFile reads file header and detects its format
+func probeFile(r io.Reader) (name string, playable bool, err error) {
+	header := make([]byte, probeHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	} else if err != nil {
+		return "", true, err
+	}
+
+	name, playable = probeFormat(header[:n])
+	return
+}
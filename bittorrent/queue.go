@@ -1,5 +1,15 @@
 package bittorrent
 
+import (
+	"sort"
+	"time"
+
+	"github.com/elgatito/elementum/database"
+)
+
+// How often active downloads are checked against the limit
+const downloadQueueInterval = 10 * time.Second
+
 // Queue represents list of torrents inside of a session
 type Queue struct {
 	s        *Service
@@ -74,3 +84,63 @@ func (q *Queue) FindByURI(uri string) *Torrent {
 func (q *Queue) Clean() {
 	q.torrents = []*Torrent{}
 }
+
+// Downloads returns unfinished torrents, ordered by their position in download queue.
+// Torrents, that were never moved in the queue, follow in order of adding.
+func (q *Queue) Downloads() []*Torrent {
+	return q.downloads(database.GetStorm().GetQueueItems())
+}
+
+// downloads orders unfinished torrents by already read queue state, so that it is read once per queue check
+func (q *Queue) downloads(items map[string]*database.QueueItem) []*Torrent {
+	priority := func(t *Torrent) int {
+		if item, ok := items[t.InfoHash()]; ok && item.Priority >= 0 {
+			return item.Priority
+		}
+		return len(items)
+	}
+
+	ret := []*Torrent{}
+	for _, t := range q.torrents {
+		if t.th == nil || t.Closer.IsSet() || t.IsMemoryStorage() || !t.HasMetadata() || t.GetProgress() >= 100 {
+			continue
+		}
+		ret = append(ret, t)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return priority(ret[i]) < priority(ret[j])
+	})
+	return ret
+}
+
+// MoveDownload moves torrent up (negative offset) or down in download queue
+func (q *Queue) MoveDownload(hash string, offset int) bool {
+	downloads := q.Downloads()
+
+	idx := -1
+	for i, t := range downloads {
+		if t.InfoHash() == hash {
+			idx = i
+			break
+		}
+	}
+
+	to := idx + offset
+	if idx < 0 || to < 0 || to >= len(downloads) {
+		return false
+	}
+
+	downloads[idx], downloads[to] = downloads[to], downloads[idx]
+
+	hashes := make([]string, 0, len(downloads))
+	for _, t := range downloads {
+		hashes = append(hashes, t.InfoHash())
+	}
+	if err := database.GetStorm().SetQueuePriorities(hashes); err != nil {
+		log.Warningf("Could not save download queue: %s", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package bittorrent
+
+import (
+	"time"
+
+	"github.com/elgatito/elementum/database"
+)
+
+const (
+	activePlaybackKey = "playback.active"
+	// Playback, that was not updated for this time, is not offered to resume
+	activePlaybackExpiration = 3 * 24 * 60 * 60
+	// How often active playback state is persisted
+	activePlaybackSaveInterval = 30
+)
+
+// ActivePlayback describes playback in progress, persisted periodically,
+// so that it can be resumed after Kodi crash.
+type ActivePlayback struct {
+	InfoHash      string    `json:"infohash"`
+	URI           string    `json:"uri"`
+	Title         string    `json:"title"`
+	OriginalIndex int       `json:"oindex"`
+	ContentType   string    `json:"type"`
+	TMDBId        int       `json:"tmdb"`
+	ShowID        int       `json:"show"`
+	Season        int       `json:"season"`
+	Episode       int       `json:"episode"`
+	Query         string    `json:"query"`
+	Position      float64   `json:"position"`
+	Total         float64   `json:"total"`
+	Updated       time.Time `json:"updated"`
+}
+
+// GetActivePlayback returns playback, that was not properly stopped, or nil
+func GetActivePlayback() *ActivePlayback {
+	p := &ActivePlayback{}
+	if err := database.GetCache().GetCachedObject(database.CommonBucket, activePlaybackKey, p); err != nil || p.InfoHash == "" {
+		return nil
+	}
+	return p
+}
+
+// ClearActivePlayback forgets saved playback
+func ClearActivePlayback() {
+	database.GetCache().Delete(database.CommonBucket, activePlaybackKey)
+}
+
+// saveActivePlayback persists current playback state, along with stored resume position
+func (btp *Player) saveActivePlayback() {
+	if btp.p.Background || btp.chosenFile == nil || btp.p.WatchedTime <= 0 {
+		return
+	}
+
+	btp.SaveStoredResume()
+
+	uri := btp.p.URI
+	if uri == "" {
+		uri = btp.t.Magnet()
+	}
+
+	p := &ActivePlayback{
+		InfoHash:      btp.t.InfoHash(),
+		URI:           uri,
+		Title:         btp.fileName,
+		OriginalIndex: btp.chosenFile.Index,
+		ContentType:   btp.p.ContentType,
+		TMDBId:        btp.p.TMDBId,
+		ShowID:        btp.p.ShowID,
+		Season:        btp.p.Season,
+		Episode:       btp.p.Episode,
+		Query:         btp.p.Query,
+		Position:      btp.p.WatchedTime,
+		Total:         btp.p.VideoDuration,
+		Updated:       time.Now(),
+	}
+	if err := database.GetCache().SetCachedObject(database.CommonBucket, activePlaybackExpiration, activePlaybackKey, p); err != nil {
+		log.Warningf("Could not save active playback: %s", err)
+	}
+}
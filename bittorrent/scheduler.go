@@ -0,0 +1,153 @@
+package bittorrent
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// How often scheduler checks, whether night mode window has started or ended
+const schedulerInterval = time.Minute
+
+// Night mode actions
+const (
+	NightModePause = iota
+	NightModeThrottle
+)
+
+// schedulerLoop applies night mode, while current time is inside of configured window
+func (s *Service) schedulerLoop() {
+	closing := s.Closer.C()
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			s.applyNightMode()
+		}
+	}
+}
+
+// applyNightMode pauses or throttles torrents inside of night mode window,
+// and resumes torrents, paused by night mode, and restores limits, when window ends.
+// If playback is allowed, playing torrents are not paused and limits are lifted while playing.
+func (s *Service) applyNightMode() {
+	if s.Closer.IsSet() || s.Session == nil || s.Session.Swigcptr() == 0 {
+		return
+	}
+
+	c := config.Get()
+	active := c.NightModeEnabled && isNightTime(time.Now(), c.NightModeStart, c.NightModeEnd)
+
+	s.nightMu.Lock()
+
+	if active != s.nightMode {
+		if active {
+			log.Infof("Night mode started, until %s", c.NightModeEnd)
+		} else {
+			log.Infof("Night mode ended")
+		}
+		s.nightMode = active
+	}
+
+	// Torrents are paused one by one, so that torrents, added during the night, are paused as well
+	if active && c.NightModeAction == NightModePause {
+		for _, t := range s.q.All() {
+			if t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 || t.GetPaused() {
+				continue
+			} else if c.NightModePlayback && (t.IsPlaying || t.IsBuffering) {
+				continue
+			}
+
+			log.Infof("Night mode, pausing %s", t.Name())
+			t.Pause()
+			s.nightPaused[t.InfoHash()] = true
+		}
+	} else if len(s.nightPaused) > 0 {
+		for infoHash := range s.nightPaused {
+			if t := s.q.FindByHash(infoHash); t != nil && !t.Closer.IsSet() {
+				log.Infof("Night mode ended, resuming %s", t.Name())
+				t.Resume()
+			}
+		}
+		s.nightPaused = map[string]bool{}
+	}
+
+	throttle := active && c.NightModeAction == NightModeThrottle && !(c.NightModePlayback && s.anyPlayerIsPlaying())
+	changed := throttle != s.nightThrottled
+	s.nightThrottled = throttle
+
+	s.nightMu.Unlock()
+
+	if changed {
+		s.RestoreLimits()
+	}
+}
+
+// isNightPaused returns whether torrents should stay paused by night mode
+func (s *Service) isNightPaused() bool {
+	s.nightMu.Lock()
+	defer s.nightMu.Unlock()
+
+	return s.nightMode && config.Get().NightModeAction == NightModePause
+}
+
+// isNightThrottled returns whether night mode limits should be used instead of configured ones
+func (s *Service) isNightThrottled() bool {
+	s.nightMu.Lock()
+	defer s.nightMu.Unlock()
+
+	return s.nightThrottled
+}
+
+// isNightTime checks whether time of a day is inside of a window, given as "HH:MM" strings,
+// window can go over midnight, like 23:00-07:00
+func isNightTime(now time.Time, start, end string) bool {
+	from, ok := minuteOfDay(start)
+	if !ok {
+		return false
+	}
+	to, ok := minuteOfDay(end)
+	if !ok || from == to {
+		return false
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+func minuteOfDay(clock string) (int, bool) {
+	parts := strings.SplitN(clock, ":", 2)
+	if len(parts) != 2 {
+		return 0, false
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, false
+	}
+
+	return hours*60 + minutes, true
+}
+
+// setNightLimits applies night mode download and upload limits
+func (s *Service) setNightLimits() {
+	s.SetDownloadLimit(s.config.NightModeDownloadRate)
+	s.SetUploadLimit(s.config.NightModeUploadRate)
+	log.Infof("Night mode, rate limiting download to %s and upload to %s",
+		humanize.Bytes(uint64(s.config.NightModeDownloadRate)), humanize.Bytes(uint64(s.config.NightModeUploadRate)))
+}
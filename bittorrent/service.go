@@ -12,6 +12,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
@@ -68,6 +69,16 @@ type Service struct {
 
 	MarkedToMove string
 
+	nightMu        sync.Mutex
+	nightMode      bool
+	nightThrottled bool
+	nightPaused    map[string]bool
+
+	meteredMu     sync.Mutex
+	meteredPaused map[string]bool
+
+	downloadQueueMu sync.Mutex
+
 	alertsBroadcaster *broadcast.Broadcaster
 	Closer            util.Event
 	isShutdown        bool
@@ -93,6 +104,9 @@ func NewService() *Service {
 		SpaceChecked: map[string]bool{},
 		Players:      map[string]*Player{},
 
+		nightPaused:   map[string]bool{},
+		meteredPaused: map[string]bool{},
+
 		alertsBroadcaster: broadcast.NewBroadcaster(),
 	}
 
@@ -117,6 +131,8 @@ func NewService() *Service {
 
 	go s.loadTorrentFiles()
 	go s.downloadProgress()
+	go s.downloadQueueLoop()
+	go s.schedulerLoop()
 
 	return s
 }
@@ -156,11 +172,27 @@ func (s *Service) CloseSession() {
 // Should reassemble Service configuration and restart everything.
 // For non-memory storage it should also load old torrent files.
 func (s *Service) Reconfigure() {
-	s.stopServices()
+	previous := *s.config
 
 	config.Reload()
 	proxy.Reload()
 
+	// Kodi profile could have been changed, then Trakt authorization of that profile is restored
+	if err := trakt.SwitchProfile(""); err != nil {
+		log.Warningf("Could not switch Trakt profile: %s", err)
+	}
+
+	// Privacy settings are applied to running session, without restarting it
+	if isPrivacyChangeOnly(&previous, config.Get()) {
+		log.Info("Applying changed privacy settings to running session...")
+		s.config = config.Get()
+		s.applyPrivacySettings(s.PackSettings)
+		s.Session.ApplySettings(s.PackSettings)
+		return
+	}
+
+	s.stopServices()
+
 	s.config = config.Get()
 	s.configure()
 
@@ -191,10 +223,6 @@ func (s *Service) configure() {
 
 	log.Info("Applying session settings...")
 
-	s.PeerID, s.UserAgent = util.GetUserAndPeer()
-	log.Infof("UserAgent: %s, PeerID: %s", s.UserAgent, s.PeerID)
-	settings.SetStr("user_agent", s.UserAgent)
-
 	// Bools
 	settings.SetBool("announce_to_all_tiers", true)
 	settings.SetBool("announce_to_all_trackers", true)
@@ -314,26 +342,7 @@ func (s *Service) configure() {
 		}
 	}
 
-	log.Info("Applying encryption settings...")
-	settings.SetInt("allowed_enc_level", int(lt.SettingsPackPeRc4))
-	settings.SetBool("prefer_rc4", true)
-
-	if s.config.EncryptionPolicy > 0 {
-		policy := int(lt.SettingsPackPeDisabled)
-		level := int(lt.SettingsPackPeBoth)
-		preferRc4 := false
-
-		if s.config.EncryptionPolicy == 2 {
-			policy = int(lt.SettingsPackPeForced)
-			level = int(lt.SettingsPackPeRc4)
-			preferRc4 = true
-		}
-
-		settings.SetInt("out_enc_policy", policy)
-		settings.SetInt("in_enc_policy", policy)
-		settings.SetInt("allowed_enc_level", level)
-		settings.SetBool("prefer_rc4", preferRc4)
-	}
+	s.applyPrivacySettings(settings)
 
 	settings.SetInt("proxy_type", ProxyTypeNone)
 	if s.config.ProxyEnabled && s.config.ProxyHost != "" {
@@ -498,6 +507,54 @@ func (s *Service) configure() {
 	s.applyCustomSettings()
 }
 
+// applyPrivacySettings sets user agent, anonymous mode and encryption policy:
+// 0 - enabled, 1 - disabled, 2 - forced
+func (s *Service) applyPrivacySettings(settings lt.SettingsPack) {
+	s.PeerID, s.UserAgent = util.GetUserAndPeer()
+	log.Infof("UserAgent: %s, PeerID: %s", s.UserAgent, s.PeerID)
+	settings.SetStr("user_agent", s.UserAgent)
+
+	log.Infof("Anonymous mode: %v", s.config.AnonymousMode)
+	settings.SetBool("anonymous_mode", s.config.AnonymousMode)
+
+	log.Info("Applying encryption settings...")
+	policy := int(lt.SettingsPackPeEnabled)
+	level := int(lt.SettingsPackPeRc4)
+	preferRc4 := true
+
+	if s.config.EncryptionPolicy == 1 {
+		policy = int(lt.SettingsPackPeDisabled)
+		level = int(lt.SettingsPackPeBoth)
+		preferRc4 = false
+	} else if s.config.EncryptionPolicy == 2 {
+		policy = int(lt.SettingsPackPeForced)
+	}
+
+	settings.SetInt("out_enc_policy", policy)
+	settings.SetInt("in_enc_policy", policy)
+	settings.SetInt("allowed_enc_level", level)
+	settings.SetBool("prefer_rc4", preferRc4)
+}
+
+// isPrivacyChangeOnly checks if only settings, applied by applyPrivacySettings, differ
+func isPrivacyChangeOnly(previous, current *config.Configuration) bool {
+	if previous.EncryptionPolicy == current.EncryptionPolicy &&
+		previous.AnonymousMode == current.AnonymousMode &&
+		previous.CustomUserAgent == current.CustomUserAgent &&
+		previous.SpoofUserAgent == current.SpoofUserAgent {
+		return false
+	}
+
+	a, b := *previous, *current
+	for _, c := range []*config.Configuration{&a, &b} {
+		c.EncryptionPolicy = 0
+		c.AnonymousMode = false
+		c.CustomUserAgent = ""
+		c.SpoofUserAgent = 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
 func (s *Service) startServices() {
 	log.Info("Starting LSD...")
 	s.PackSettings.SetBool("enable_lsd", true)
@@ -614,7 +671,6 @@ func (s *Service) checkAvailableSpace(t *Torrent) bool {
 
 	if availableSpace < sizeLeft {
 		log.Errorf("Unsufficient free space on %s. Has %d, needs %d.", path, diskStatus.Free, sizeLeft)
-		xbmc.Notify("Elementum", "LOCALIZE[30207]", config.AddonIcon())
 
 		log.Infof("Pausing torrent %s", status.GetName())
 		t.Pause()
@@ -781,6 +837,7 @@ func (s *Service) AddTorrent(uri string, paused bool, downloadStorage int) (*Tor
 	s.q.Add(t)
 
 	if !t.HasMetadata() {
+		PlaybackStep(StepMetadataFetch)
 		if err := t.WaitForMetadata(infoHash); err != nil {
 			return nil, err
 		}
@@ -852,6 +909,7 @@ func (s *Service) RemoveTorrent(t *Torrent, forceDrop, forceDelete, isWatched bo
 		}()
 
 		s.q.Delete(t)
+		database.GetStorm().DeleteQueueItem(t.InfoHash())
 
 		t.Drop(deleteTorrentFiles, deleteTorrentData)
 	}
@@ -871,7 +929,9 @@ func (s *Service) onStateChanged(stateAlert lt.StateChangedAlert) {
 		if spaceChecked, exists := s.SpaceChecked[infoHash]; exists {
 			if spaceChecked == false {
 				if t := s.GetTorrentByHash(infoHash); t != nil {
-					s.checkAvailableSpace(t)
+					if !s.checkAvailableSpace(t) {
+						xbmc.Notify("Elementum", "LOCALIZE[30207]", config.AddonIcon())
+					}
 					delete(s.SpaceChecked, infoHash)
 				}
 			}
@@ -1169,6 +1229,68 @@ func (s *Service) cleanStaleFiles(dir string, ext string) {
 	}
 }
 
+// downloadQueueLoop keeps number of active downloads within configured limit
+func (s *Service) downloadQueueLoop() {
+	closing := s.Closer.C()
+	ticker := time.NewTicker(downloadQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			s.manageDownloadQueue()
+		}
+	}
+}
+
+// manageDownloadQueue pauses downloads at the end of the queue, when there are too many of them,
+// and starts next queued ones, when active downloads complete.
+// Torrents, paused by user, and playing torrents are not touched by the queue.
+func (s *Service) manageDownloadQueue() {
+	s.downloadQueueMu.Lock()
+	defer s.downloadQueueMu.Unlock()
+
+	s.updateDownloadQueue()
+}
+
+// updateDownloadQueue applies download queue, downloadQueueMu should be held by the caller
+func (s *Service) updateDownloadQueue() {
+	// Queued downloads are not started in metered mode, as they would be paused right away
+	if s.Closer.IsSet() || s.Session == nil || s.Session.Swigcptr() == 0 || s.Session.IsPaused() || s.isNightPaused() || config.Get().MeteredMode {
+		return
+	}
+
+	limit := config.Get().MaxActiveDownloads
+	items := database.GetStorm().GetQueueItems()
+	active := 0
+
+	for _, t := range s.q.downloads(items) {
+		infoHash := t.InfoHash()
+		queued := items[infoHash] != nil && items[infoHash].Queued
+		if t.GetPaused() && !queued {
+			continue
+		}
+
+		if t.IsPlaying || t.IsBuffering || limit <= 0 || active < limit {
+			active++
+			if queued {
+				log.Infof("Starting queued download: %s", t.Name())
+				database.GetStorm().SetQueued(infoHash, false)
+				t.Resume()
+			}
+			continue
+		}
+
+		if !queued {
+			log.Infof("Active downloads limit reached, queueing: %s", t.Name())
+			database.GetStorm().SetQueued(infoHash, true)
+			t.Pause()
+		}
+	}
+}
+
 func (s *Service) downloadProgress() {
 	closing := s.Closer.C()
 	rotateTicker := time.NewTicker(5 * time.Second)
@@ -1199,6 +1321,11 @@ func (s *Service) downloadProgress() {
 			var totalUploadRate float64
 			var totalProgress int
 
+			// Metered mode could be disabled in settings, not only with SetMeteredMode
+			if !config.Get().MeteredMode {
+				s.resumeMetered()
+			}
+
 			activeTorrents := make([]*activeTorrent, 0)
 			torrentsVector := s.Session.GetTorrents()
 			torrentsVectorSize := int(torrentsVector.Size())
@@ -1238,6 +1365,19 @@ func (s *Service) downloadProgress() {
 				torrentName := ts.GetName()
 				progress := int(float64(ts.GetProgress()) * 100)
 
+				// Metered mode pauses downloads and seeding, except torrents, used by a player
+				if config.Get().MeteredMode && !isPaused && !t.IsPlaying && !t.IsBuffering && t.PlayerAttached <= 0 {
+					log.Warningf("Metered mode enabled, pausing %s", torrentName)
+					torrentHandle.AutoManaged(false)
+					torrentHandle.Pause(1)
+					isPaused = true
+					status = StatusStrings[StatusPaused]
+
+					s.meteredMu.Lock()
+					s.meteredPaused[infoHash] = true
+					s.meteredMu.Unlock()
+				}
+
 				if progress < 100 && !isPaused {
 					activeTorrents = append(activeTorrents, &activeTorrent{
 						torrentName:  torrentName,
@@ -1511,8 +1651,36 @@ func (s *Service) SetUploadLimit(i int) {
 	s.Session.ApplySettings(settings)
 }
 
+// SetMeteredMode enables or disables metered mode, when it is disabled,
+// torrents, paused by metered mode, are resumed, other paused torrents stay paused.
+func (s *Service) SetMeteredMode(enabled bool) {
+	config.SetMeteredMode(enabled)
+	if !enabled {
+		s.resumeMetered()
+	}
+}
+
+// resumeMetered resumes torrents, that were paused by metered mode
+func (s *Service) resumeMetered() {
+	s.meteredMu.Lock()
+	defer s.meteredMu.Unlock()
+
+	for infoHash := range s.meteredPaused {
+		if t := s.q.FindByHash(infoHash); t != nil && !t.Closer.IsSet() {
+			log.Infof("Metered mode disabled, resuming %s", t.Name())
+			t.Resume()
+		}
+	}
+	s.meteredPaused = map[string]bool{}
+}
+
 // RestoreLimits ...
 func (s *Service) RestoreLimits() {
+	if s.isNightThrottled() {
+		s.setNightLimits()
+		return
+	}
+
 	if s.config.DownloadRateLimit > 0 {
 		s.SetDownloadLimit(s.config.DownloadRateLimit)
 		log.Infof("Rate limiting download to %s", humanize.Bytes(uint64(s.config.DownloadRateLimit)))
@@ -1582,6 +1750,8 @@ func (s *Service) ClientInfo(_w io.Writer) {
 	w := bufio.NewWriter(_w)
 	defer w.Flush()
 
+	tmdb.WriteAPIKeysUsage(w)
+
 	for _, t := range s.q.All() {
 		if t == nil || t.th == nil {
 			continue
@@ -1800,6 +1970,24 @@ func (s *Service) GetTorrents() []*Torrent {
 	return s.q.All()
 }
 
+// GetDownloads returns unfinished torrents in order of download queue
+func (s *Service) GetDownloads() []*Torrent {
+	return s.q.Downloads()
+}
+
+// MoveDownload changes position of a torrent in download queue
+func (s *Service) MoveDownload(t *Torrent, offset int) bool {
+	s.downloadQueueMu.Lock()
+	defer s.downloadQueueMu.Unlock()
+
+	if !s.q.MoveDownload(t.InfoHash(), offset) {
+		return false
+	}
+
+	s.updateDownloadQueue()
+	return true
+}
+
 // GetListenIP returns calculated IP for TCP/TCP6
 func (s *Service) GetListenIP(network string) string {
 	if strings.Contains(network, "6") {
@@ -0,0 +1,144 @@
+package bittorrent
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Language tags, used in release names, mapped to ISO 639-2 codes
+var releaseLanguageTags = []struct {
+	re   *regexp.Regexp
+	lang string
+}{
+	{regexp.MustCompile(`(?i)\W(eng|english)\W`), "eng"},
+	{regexp.MustCompile(`(?i)\W(rus|russian)\W`), "rus"},
+	{regexp.MustCompile(`(?i)\W(ukr|ukrainian)\W`), "ukr"},
+	{regexp.MustCompile(`(?i)\W(fre|french|vff|truefrench)\W`), "fre"},
+	{regexp.MustCompile(`(?i)\W(ger|german)\W`), "ger"},
+	{regexp.MustCompile(`(?i)\W(spa|spanish|castellano|latino)\W`), "spa"},
+	{regexp.MustCompile(`(?i)\W(ita|italian)\W`), "ita"},
+	{regexp.MustCompile(`(?i)\W(por|portuguese)\W`), "por"},
+	{regexp.MustCompile(`(?i)\W(pol|polish)\W`), "pol"},
+	{regexp.MustCompile(`(?i)\W(jpn|japanese)\W`), "jpn"},
+	{regexp.MustCompile(`(?i)\W(kor|korean)\W`), "kor"},
+}
+
+// releaseLanguages returns languages, detected in a release name
+func releaseLanguages(name string) []string {
+	name = " " + name + " "
+
+	ret := []string{}
+	for _, tag := range releaseLanguageTags {
+		if tag.re.MatchString(name) {
+			ret = append(ret, tag.lang)
+		}
+	}
+	return ret
+}
+
+// InitStreams switches audio and subtitles streams of playing item to preferred languages
+func (btp *Player) InitStreams() {
+	if btp.p.DoneStreams {
+		return
+	}
+	btp.p.DoneStreams = true
+
+	audioLanguages := normalizeLanguages(config.Get().PreferredAudioLanguages)
+	subtitleLanguages := normalizeLanguages(config.Get().PreferredSubtitleLanguages)
+	if len(audioLanguages) == 0 && len(subtitleLanguages) == 0 {
+		return
+	}
+
+	playerID := xbmc.PlayerGetActive()
+	if playerID < 0 {
+		return
+	}
+
+	streams := xbmc.PlayerGetStreams(playerID)
+	if streams == nil {
+		return
+	}
+
+	// Streams without language tag are considered to be in the release language,
+	// if the release name mentions exactly one language.
+	releaseLanguage := ""
+	if detected := releaseLanguages(btp.t.Name()); len(detected) == 1 {
+		releaseLanguage = detected[0]
+	}
+
+	audio := findStream(streams.AudioStreams, audioLanguages, releaseLanguage)
+	if audio != nil {
+		if streams.CurrentAudioStream == nil || streams.CurrentAudioStream.Index != audio.Index {
+			log.Infof("Switching audio stream to %d (%s)", audio.Index, audio.Language)
+			xbmc.PlayerSetAudioStream(playerID, audio.Index)
+		}
+	}
+
+	if len(subtitleLanguages) == 0 {
+		return
+	}
+
+	// Subtitles are not needed if audio is already in one of subtitles languages
+	if audio != nil && containsLanguage(subtitleLanguages, audio.Language, releaseLanguage) {
+		if streams.SubtitleEnabled {
+			log.Infof("Audio is in preferred subtitles language, disabling subtitles")
+			xbmc.PlayerSetSubtitle(playerID, -1, false)
+		}
+		return
+	}
+
+	if subtitle := findStream(streams.Subtitles, subtitleLanguages, ""); subtitle != nil {
+		if !streams.SubtitleEnabled || streams.CurrentSubtitle == nil || streams.CurrentSubtitle.Index != subtitle.Index {
+			log.Infof("Switching subtitles to %d (%s)", subtitle.Index, subtitle.Language)
+			xbmc.PlayerSetSubtitle(playerID, subtitle.Index, true)
+		}
+	}
+}
+
+// findStream returns first stream, matching languages in order of their preference
+func findStream(streams []*xbmc.PlayerStream, languages []string, fallback string) *xbmc.PlayerStream {
+	for _, lang := range languages {
+		for _, s := range streams {
+			if containsLanguage([]string{lang}, s.Language, fallback) {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func containsLanguage(languages []string, lang string, fallback string) bool {
+	lang = strings.ToLower(lang)
+	if lang == "" || lang == "und" {
+		lang = fallback
+	}
+	if lang == "" {
+		return false
+	}
+
+	if len(lang) != 3 {
+		lang = xbmc.ConvertLanguage(lang, xbmc.Iso639_2)
+	}
+
+	for _, l := range languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLanguages converts configured languages into ISO 639-2 codes, used by Kodi for streams
+func normalizeLanguages(languages []string) []string {
+	ret := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		if converted := xbmc.ConvertLanguage(lang, xbmc.Iso639_2); converted != "" {
+			lang = converted
+		}
+		ret = append(ret, strings.ToLower(lang))
+	}
+	return ret
+}
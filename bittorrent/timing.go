@@ -0,0 +1,155 @@
+package bittorrent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Playback steps, measured for diagnostics
+const (
+	StepMetadataResolve = "Metadata resolve"
+	StepProviderSearch  = "Provider search"
+	StepStreamChoice    = "Stream choice"
+	StepTorrentAdd      = "Torrent add"
+	StepMetadataFetch   = "Metadata fetch"
+	StepBuffer          = "Buffer"
+	StepPlayerStart     = "Player start"
+)
+
+// Session, not finished in this time, is considered abandoned and is replaced by a new one
+const playbackTimingTimeout = 10 * time.Minute
+
+// TimingSpan is a single measured step of playback
+type TimingSpan struct {
+	Name     string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// PlaybackTiming keeps timing of playback steps, from item resolve till player start
+type PlaybackTiming struct {
+	Title    string
+	Started  time.Time
+	Finished bool
+	Failed   string
+	Spans    []*TimingSpan
+}
+
+var playbackTiming = struct {
+	sync.Mutex
+	current *PlaybackTiming
+	last    *PlaybackTiming
+}{}
+
+// StartPlaybackTiming begins new timing session, replacing unfinished one
+func StartPlaybackTiming(title string) {
+	playbackTiming.Lock()
+	defer playbackTiming.Unlock()
+
+	playbackTiming.current = &PlaybackTiming{
+		Title:   title,
+		Started: time.Now(),
+	}
+}
+
+// EnsurePlaybackTiming begins new timing session, if there is no active one,
+// used for playback, started directly with torrent URI.
+func EnsurePlaybackTiming(title string) {
+	playbackTiming.Lock()
+	active := playbackTiming.current != nil && time.Since(playbackTiming.current.Started) < playbackTimingTimeout
+	playbackTiming.Unlock()
+
+	if !active {
+		StartPlaybackTiming(title)
+	}
+}
+
+// PlaybackStep finishes previous step of active session and starts next one
+func PlaybackStep(name string) {
+	playbackTiming.Lock()
+	defer playbackTiming.Unlock()
+
+	p := playbackTiming.current
+	if p == nil {
+		return
+	}
+
+	now := time.Now()
+	p.closeSpan(now)
+	p.Spans = append(p.Spans, &TimingSpan{Name: name, Started: now})
+}
+
+// FinishPlaybackTiming closes active session, logs it and keeps it for diagnostics.
+// Non-empty reason marks session as failed.
+func FinishPlaybackTiming(reason string) {
+	playbackTiming.Lock()
+	p := playbackTiming.current
+	if p == nil {
+		playbackTiming.Unlock()
+		return
+	}
+
+	p.closeSpan(time.Now())
+	p.Finished = true
+	p.Failed = reason
+	playbackTiming.current = nil
+	playbackTiming.last = p
+	playbackTiming.Unlock()
+
+	log.Infof("Playback timing:\n%s", p)
+}
+
+// LastPlaybackTiming returns active session, or the last finished one
+func LastPlaybackTiming() *PlaybackTiming {
+	playbackTiming.Lock()
+	defer playbackTiming.Unlock()
+
+	if playbackTiming.current != nil {
+		return playbackTiming.current
+	}
+	return playbackTiming.last
+}
+
+func (p *PlaybackTiming) closeSpan(now time.Time) {
+	if len(p.Spans) == 0 {
+		return
+	}
+	if last := p.Spans[len(p.Spans)-1]; last.Duration == 0 {
+		last.Duration = now.Sub(last.Started)
+	}
+}
+
+// Slowest returns the longest step of the session
+func (p *PlaybackTiming) Slowest() *TimingSpan {
+	var ret *TimingSpan
+	for _, s := range p.Spans {
+		if ret == nil || s.Duration > ret.Duration {
+			ret = s
+		}
+	}
+	return ret
+}
+
+func (p *PlaybackTiming) String() string {
+	total := time.Duration(0)
+	lines := []string{p.Title}
+	for _, s := range p.Spans {
+		duration := s.Duration
+		if duration == 0 && !p.Finished {
+			duration = time.Since(s.Started)
+		}
+		total += duration
+		lines = append(lines, fmt.Sprintf("  %-18s %8.2fs", s.Name, duration.Seconds()))
+	}
+	lines = append(lines, fmt.Sprintf("  %-18s %8.2fs", "Total", total.Seconds()))
+
+	if p.Failed != "" {
+		lines = append(lines, "  Failed: "+p.Failed)
+	} else if !p.Finished {
+		lines = append(lines, "  In progress")
+	}
+
+	return strings.Join(lines, "\n")
+}
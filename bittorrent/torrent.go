@@ -29,6 +29,7 @@ import (
 
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/tvdb"
 	"github.com/elgatito/elementum/util"
@@ -338,6 +339,16 @@ func (t *Torrent) GetHumanizedSpeeds() (down, up string) {
 	return humanize.Bytes(uint64(downInt)), humanize.Bytes(uint64(upInt))
 }
 
+// ForceBufferFinished finishes buffering, even if buffer pieces are not yet downloaded
+func (t *Torrent) ForceBufferFinished() {
+	if !t.IsBuffering {
+		return
+	}
+
+	log.Infof("Forcing buffer finish at %.2f%%", t.BufferProgress)
+	t.bufferFinishedEvent()
+}
+
 func (t *Torrent) bufferFinishedEvent() {
 	t.muBuffer.Lock()
 	log.Infof("Buffer finished: %#v, %#v", t.IsBuffering, t.BufferPiecesProgress)
@@ -356,7 +367,9 @@ func (t *Torrent) bufferFinishedEvent() {
 // Kodi sends two requests, one for onecoming file read handler,
 // another for a piece of file from the end (probably to get codec descriptors and so on)
 // We set it as post-buffer and include in required buffer pieces array.
-func (t *Torrent) Buffer(file *File, isStartup bool) {
+// Zero startBufferSize means buffer size from settings.
+// Positive resumeOffset adds a buffer at that position in the file, to resume playback from there.
+func (t *Torrent) Buffer(file *File, isStartup bool, startBufferSize int64, resumeOffset int64) {
 	if file == nil || t.Closer.IsSet() {
 		t.bufferFinishedEvent()
 		return
@@ -366,10 +379,21 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 
 	t.startBufferTicker()
 
-	startBufferSize := t.Service.GetBufferSize()
+	if startBufferSize <= 0 {
+		startBufferSize = t.Service.GetBufferSize()
+	}
 	preBufferStart, preBufferEnd, preBufferOffset, preBufferSize := t.getBufferSize(file.Offset, 0, startBufferSize)
 	postBufferStart, postBufferEnd, postBufferOffset, postBufferSize := t.getBufferSize(file.Offset, file.Size-int64(config.Get().EndBufferSize), int64(config.Get().EndBufferSize))
 
+	bufferRanges := [][]int{{preBufferStart, preBufferEnd}, {postBufferStart, postBufferEnd}}
+	resumeBufferSize := int64(0)
+	if resumeOffset > 0 && resumeOffset < file.Size {
+		var resumeBufferStart, resumeBufferEnd int
+		resumeBufferStart, resumeBufferEnd, _, resumeBufferSize = t.getBufferSize(file.Offset, resumeOffset, startBufferSize)
+		bufferRanges = append(bufferRanges, []int{resumeBufferStart, resumeBufferEnd})
+		log.Infof("Adding resume buffer at %s: pieces %#v-%#v", humanize.Bytes(uint64(resumeOffset)), resumeBufferStart, resumeBufferEnd)
+	}
+
 	// TODO: Remove this piece of buffer adjustment?
 	// if config.Get().AutoAdjustBufferSize && preBufferEnd-preBufferStart < 10 {
 	// 	_, free := t.Service.GetMemoryStats()
@@ -426,8 +450,8 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 		}
 
 		// Increase memory size if buffer does not fit there
-		if preBufferSize+postBufferSize > t.MemorySize {
-			t.MemorySize = preBufferSize + postBufferSize + (1 * t.pieceLength)
+		if preBufferSize+postBufferSize+resumeBufferSize > t.MemorySize {
+			t.MemorySize = preBufferSize + postBufferSize + resumeBufferSize + (1 * t.pieceLength)
 			log.Infof("Adjusting memory size to %s, to fit all buffer!", humanize.Bytes(uint64(t.MemorySize)))
 			t.ms.SetMemorySize(t.MemorySize)
 		}
@@ -438,13 +462,12 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 	t.IsBufferingFinished = false
 	t.BufferProgress = 0
 	t.BufferProgressPrevious = 0
-	t.BufferLength = preBufferSize + postBufferSize
+	t.BufferLength = preBufferSize + postBufferSize + resumeBufferSize
 
-	for i := preBufferStart; i <= preBufferEnd; i++ {
-		t.BufferPiecesProgress[i] = 0
-	}
-	for i := postBufferStart; i <= postBufferEnd; i++ {
-		t.BufferPiecesProgress[i] = 0
+	for _, r := range bufferRanges {
+		for i := r[0]; i <= r[1]; i++ {
+			t.BufferPiecesProgress[i] = 0
+		}
 	}
 
 	t.BufferPiecesLength = 0
@@ -456,7 +479,7 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 
 	log.Infof("Setting buffer for file: %s (%s / %s). Desired: %s. Pieces: %#v-%#v + %#v-%#v, PieceLength: %s, Pre: %s, Post: %s, WithOffset: %#v / %#v (%#v)",
 		file.Path, humanize.Bytes(uint64(file.Size)), humanize.Bytes(uint64(t.ti.TotalSize())),
-		humanize.Bytes(uint64(t.Service.GetBufferSize())),
+		humanize.Bytes(uint64(startBufferSize)),
 		preBufferStart, preBufferEnd, postBufferStart, postBufferEnd,
 		humanize.Bytes(uint64(t.pieceLength)), humanize.Bytes(uint64(preBufferSize)), humanize.Bytes(uint64(postBufferSize)),
 		preBufferOffset, postBufferOffset, file.Offset)
@@ -478,21 +501,18 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 		piecesPriorities := t.th.PiecePriorities()
 		defer lt.DeleteStdVectorInt(piecesPriorities)
 
-		for curPiece = preBufferStart; curPiece <= preBufferEnd; curPiece++ { // get this part
-			piecesPriorities.Set(curPiece, 7)
-		}
-		for curPiece = postBufferStart; curPiece <= postBufferEnd; curPiece++ { // get this part
-			piecesPriorities.Set(curPiece, 7)
+		for _, r := range bufferRanges {
+			for curPiece = r[0]; curPiece <= r[1]; curPiece++ { // get this part
+				piecesPriorities.Set(curPiece, 7)
+			}
 		}
 		t.th.PrioritizePieces(piecesPriorities)
 	} else {
-		for curPiece = preBufferStart; curPiece <= preBufferEnd; curPiece++ { // get this part
-			t.demandPieces.AddInt(curPiece)
-			t.th.PiecePriority(curPiece, 3)
-		}
-		for curPiece = postBufferStart; curPiece <= postBufferEnd; curPiece++ { // get this part
-			t.demandPieces.AddInt(curPiece)
-			t.th.PiecePriority(curPiece, 3)
+		for _, r := range bufferRanges {
+			for curPiece = r[0]; curPiece <= r[1]; curPiece++ { // get this part
+				t.demandPieces.AddInt(curPiece)
+				t.th.PiecePriority(curPiece, 3)
+			}
 		}
 	}
 	t.muDemandPieces.Unlock()
@@ -511,11 +531,10 @@ func (t *Torrent) Buffer(file *File, isStartup bool) {
 
 	// As long as file storage has many enabled pieces, we make sure buffer pieces are sent immediately
 	if !t.IsMemoryStorage() {
-		for curPiece = preBufferStart; curPiece <= preBufferEnd; curPiece++ { // get this part
-			t.th.SetPieceDeadline(curPiece, 0, 0)
-		}
-		for curPiece = postBufferStart; curPiece <= postBufferEnd; curPiece++ { // get this part
-			t.th.SetPieceDeadline(curPiece, 0, 0)
+		for _, r := range bufferRanges {
+			for curPiece = r[0]; curPiece <= r[1]; curPiece++ { // get this part
+				t.th.SetPieceDeadline(curPiece, 0, 0)
+			}
 		}
 	}
 }
@@ -1090,6 +1109,99 @@ func (t *Torrent) Name() string {
 	return t.name
 }
 
+// Magnet returns magnet link of a torrent, with all trackers it is using
+func (t *Torrent) Magnet() string {
+	params := url.Values{}
+	params.Set("dn", t.Name())
+	if t.th != nil && t.th.Swigcptr() != 0 {
+		trackers := t.th.Trackers()
+		for i := 0; i < int(trackers.Size()); i++ {
+			params.Add("tr", trackers.Get(i).GetUrl())
+		}
+	}
+
+	return fmt.Sprintf("magnet:?xt=urn:btih:%s&%s", t.InfoHash(), params.Encode())
+}
+
+// TrackerInfo is a state of a tracker, torrent is announced to
+type TrackerInfo struct {
+	URL      string `json:"url"`
+	Tier     int    `json:"tier"`
+	Working  bool   `json:"working"`
+	Updating bool   `json:"updating"`
+	Fails    int    `json:"fails"`
+	Message  string `json:"message"`
+	Seeds    int    `json:"seeds"`
+	Peers    int    `json:"peers"`
+	// NextAnnounce is a number of seconds until next announce
+	NextAnnounce int `json:"next_announce"`
+}
+
+// PeersInfo is a summary of peers, torrent knows about, and where they came from
+type PeersInfo struct {
+	ConnectedSeeds    int            `json:"connected_seeds"`
+	ConnectedPeers    int            `json:"connected_peers"`
+	ListSeeds         int            `json:"list_seeds"`
+	ListPeers         int            `json:"list_peers"`
+	Complete          int            `json:"complete"`
+	Incomplete        int            `json:"incomplete"`
+	ConnectCandidates int            `json:"connect_candidates"`
+	DownloadRate      int            `json:"download_rate"`
+	UploadRate        int            `json:"upload_rate"`
+	Sources           map[string]int `json:"sources"`
+}
+
+// Trackers returns state of all trackers of a torrent
+func (t *Torrent) Trackers() []*TrackerInfo {
+	ret := []*TrackerInfo{}
+	if t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 {
+		return ret
+	}
+
+	trackers := t.th.Trackers()
+	for i := 0; i < int(trackers.Size()); i++ {
+		tracker := trackers.Get(i)
+		ret = append(ret, &TrackerInfo{
+			URL:          tracker.GetUrl(),
+			Tier:         int(tracker.GetTier()),
+			Working:      tracker.IsWorking(),
+			Updating:     tracker.GetUpdating(),
+			Fails:        int(tracker.GetFails()),
+			Message:      tracker.GetMessage(),
+			Seeds:        tracker.GetScrapeComplete(),
+			Peers:        tracker.GetScrapeIncomplete(),
+			NextAnnounce: tracker.NextAnnounceIn(),
+		})
+	}
+	return ret
+}
+
+// Peers returns summary of peers of a torrent, with number of peers, returned by each tracker and DHT.
+// Details of every connected peer are not available, since libtorrent bindings do not expose peer_info list.
+func (t *Torrent) Peers() *PeersInfo {
+	ret := &PeersInfo{Sources: map[string]int{}}
+	t.trackers.Range(func(tracker, peers interface{}) bool {
+		ret.Sources[tracker.(string)] = peers.(int)
+		return true
+	})
+
+	if t.Closer.IsSet() || t.th == nil || t.th.Swigcptr() == 0 {
+		return ret
+	}
+
+	ts := t.GetLastStatus(false)
+	ret.ConnectedSeeds = ts.GetNumSeeds()
+	ret.ConnectedPeers = ts.GetNumPeers() - ts.GetNumSeeds()
+	ret.ListSeeds = ts.GetListSeeds()
+	ret.ListPeers = ts.GetListPeers()
+	ret.Complete = ts.GetNumComplete()
+	ret.Incomplete = ts.GetNumIncomplete()
+	ret.ConnectCandidates = ts.GetConnectCandidates()
+	ret.DownloadRate = ts.GetDownloadPayloadRate()
+	ret.UploadRate = ts.GetUploadPayloadRate()
+	return ret
+}
+
 // Title returns name of a torrent, or, if present, how it looked in plugin that found it.
 func (t *Torrent) Title() string {
 	if t.title != "" {
@@ -1449,6 +1561,20 @@ func (t *Torrent) GetFileByIndex(q int) *File {
 	return nil
 }
 
+// IsFileDownloaded checks whether all pieces of the file are downloaded
+func (t *Torrent) IsFileDownloaded(f *File) bool {
+	if f == nil || t.Closer.IsSet() {
+		return false
+	}
+
+	for i := f.PieceStart; i <= f.PieceEnd; i++ {
+		if !t.hasPiece(i) {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *Torrent) updatePieces() error {
 	defer perf.ScopeTimer()()
 
@@ -1557,8 +1683,14 @@ func (t *Torrent) WaitForMetadata(infoHash string) (err error) {
 	sc := t.Service.Closer.C()
 	tc := t.Closer.C()
 	mc := t.GotInfo()
-	to := time.NewTicker(time.Duration(config.Get().MagnetResolveTimeout) * time.Second)
+	timeout := time.Duration(config.Get().MagnetResolveTimeout) * time.Second
+	to := time.NewTimer(timeout)
 	defer to.Stop()
+	progressTicker := time.NewTicker(1 * time.Second)
+	defer progressTicker.Stop()
+
+	// Retry with alternate trackers is made only once
+	retried := !config.Get().MagnetResolveRetry
 
 	log.Infof("Waiting for information fetched for torrent: %s", infoHash)
 	dialog := xbmc.NewDialogProgressBG("Elementum", "LOCALIZE[30583]", "LOCALIZE[30583]")
@@ -1571,10 +1703,25 @@ func (t *Torrent) WaitForMetadata(infoHash string) (err error) {
 	for {
 		select {
 		case <-to.C:
+			if !retried {
+				retried = true
+
+				added := t.addAlternateTrackers()
+				log.Warningf("No metadata received in %s for torrent %s, retrying with %d alternate trackers", timeout, infoHash, added)
+				to.Reset(timeout)
+				continue
+			}
+
 			err = fmt.Errorf("Expired timeout for resolving magnet link for %d seconds", config.Get().MagnetResolveTimeout)
 			log.Error(err)
 			return err
 
+		case <-progressTicker.C:
+			if dialog != nil {
+				stage, percent := t.metadataStage(retried && config.Get().MagnetResolveRetry)
+				dialog.Update(percent, "Elementum", stage)
+			}
+
 		case <-sc:
 			log.Warningf("Cancelling waiting for torrent metadata due to service closing: %s", infoHash)
 			return
@@ -1591,6 +1738,65 @@ func (t *Torrent) WaitForMetadata(infoHash string) (err error) {
 	}
 }
 
+// metadataStage describes current stage of fetching magnet metadata and its progress
+func (t *Torrent) metadataStage(isRetry bool) (stage string, percent int) {
+	ts := t.GetLastStatus(true)
+	if ts == nil || ts.Swigcptr() == 0 {
+		return "LOCALIZE[30583]", 0
+	}
+
+	peers := ts.GetNumPeers()
+	percent = int(ts.GetProgress() * 100)
+	switch {
+	case peers > 0:
+		stage = fmt.Sprintf("Fetching metadata from %d peers: %d%%", peers, percent)
+	case !config.Get().DisableDHT && !t.Service.Session.IsDhtRunning():
+		stage = "Bootstrapping DHT"
+	default:
+		stage = "Looking for peers"
+	}
+	if isRetry {
+		stage += " (alternate trackers)"
+	}
+
+	return
+}
+
+// addAlternateTrackers adds default trackers, that torrent does not yet have,
+// and forces re-announce to find peers for fetching metadata.
+// Returns number of added trackers.
+func (t *Torrent) addAlternateTrackers() (added int) {
+	if t.th == nil || t.th.Swigcptr() == 0 {
+		return
+	}
+
+	UpdateDefaultTrackers()
+
+	existing := []string{}
+	trackers := t.th.Trackers()
+	for i := 0; i < int(trackers.Size()); i++ {
+		existing = append(existing, trackers.Get(i).GetUrl())
+	}
+
+	for _, tracker := range extraTrackers {
+		if tracker == "" || util.StringSliceContains(existing, tracker) {
+			continue
+		}
+
+		entry := lt.NewAnnounceEntry(tracker)
+		t.th.AddTracker(entry)
+		lt.DeleteAnnounceEntry(entry)
+		added++
+	}
+
+	t.th.ForceReannounce()
+	if !config.Get().DisableDHT {
+		t.th.ForceDhtAnnounce()
+	}
+
+	return
+}
+
 // GetHandle ...
 func (t *Torrent) GetHandle() lt.TorrentHandle {
 	return t.th
@@ -1709,6 +1915,7 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 	}
 
 	var candidateFiles []int
+	var isoFiles []int
 
 	for i, f := range files {
 		size := f.Size
@@ -1718,8 +1925,11 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 		}
 		if size > minSize {
 			candidateFiles = append(candidateFiles, i)
+			if strings.EqualFold(filepath.Ext(f.Path), ".iso") {
+				isoFiles = append(isoFiles, i)
+			}
 		}
-		if strings.Contains(f.Path, "BDMV/STREAM/") {
+		if _, ok := bluRayDisc(f.Path); ok {
 			isBluRay = true
 			continue
 		}
@@ -1741,9 +1951,9 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 		candidateFiles = []int{}
 		dirs := map[string]int{}
 
+		// Main movie of a disc is the largest stream, menus and extras are small clips
 		for i, f := range files {
-			if idx := strings.Index(f.Path, "BDMV/STREAM/"); idx != -1 {
-				dir := f.Path[0 : idx-1]
+			if dir, ok := bluRayDisc(f.Path); ok {
 				if _, ok := dirs[dir]; !ok {
 					dirs[dir] = i
 				} else if files[dirs[dir]].Size < files[i].Size {
@@ -1754,11 +1964,17 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 
 		choices := make([]*CandidateFile, 0, len(candidateFiles))
 		for dir, index := range dirs {
+			name := dir
+			if name == "" {
+				name = t.Name()
+			}
+
 			choices = append(choices, &CandidateFile{
 				Index:       index,
-				Filename:    dir,
-				DisplayName: dir,
+				Filename:    name,
+				DisplayName: name,
 				Path:        dir,
+				Size:        files[index].Size,
 			})
 		}
 
@@ -1767,6 +1983,12 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 		return choices, biggestFile, nil
 	}
 
+	// Disc images are the content itself, other files are usually samples and extras
+	if len(isoFiles) > 0 {
+		log.Infof("Found %d disc images, using them as candidates", len(isoFiles))
+		candidateFiles = isoFiles
+	}
+
 	if len(candidateFiles) > 1 {
 		log.Info(fmt.Sprintf("There are %d candidate files", len(candidateFiles)))
 		choices := make([]*CandidateFile, 0, len(candidateFiles))
@@ -1789,6 +2011,18 @@ func (t *Torrent) GetCandidateFiles(btp *Player) ([]*CandidateFile, int, error)
 	return nil, biggestFile, nil
 }
 
+// bluRayDisc returns disc folder for a Blu-ray stream file (BDMV/STREAM/*.m2ts).
+// Root folder of a torrent is returned as an empty string.
+func bluRayDisc(path string) (string, bool) {
+	p := filepath.ToSlash(path)
+	idx := strings.Index(strings.ToUpper(p), "BDMV/STREAM/")
+	if idx == -1 || !strings.EqualFold(filepath.Ext(p), ".m2ts") || strings.Contains(p[idx+len("BDMV/STREAM/"):], "/") {
+		return "", false
+	}
+
+	return strings.TrimSuffix(p[:idx], "/"), true
+}
+
 // SelectDownloadFiles selects files for download, according to setting
 func (t *Torrent) SelectDownloadFiles(btp *Player) {
 	strategy := config.Get().DownloadFileStrategy
@@ -2149,7 +2383,7 @@ func (t *Torrent) AlertFinished() {
 
 	t.IsNeedFinishNotification = false
 
-	xbmc.Notify("Elementum", "LOCALIZE[30618];;"+t.Name(), config.AddonIcon())
+	notifications.Notify(notifications.DownloadFinished, "LOCALIZE[30618];;"+t.Name())
 }
 
 // GetLastStatus gets, or initially sets torrenthandle status
@@ -305,6 +305,11 @@ func NewTorrentFile(uri string) *TorrentFile {
 	return t
 }
 
+// Init parses magnet, quality tags and size of a torrent, filled by a native provider
+func (t *TorrentFile) Init() {
+	t.initialize()
+}
+
 func (t *TorrentFile) initialize() {
 	if t.IsMagnet() {
 		t.initializeFromMagnet()
@@ -130,6 +130,7 @@ func NewTorrentFSEntry(file http.File, tfs *TorrentFS, t *Torrent, f *File, name
 	t.muReaders.Unlock()
 
 	t.ResetReaders()
+	go t.prefetchNextAudio(f)
 
 	return tf, nil
 }
@@ -0,0 +1,132 @@
+package bittorrent
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/missinggo/perf"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// Hardware acceleration methods for transcoding, as ordered in settings
+const (
+	HWAccelNone = iota
+	HWAccelVAAPI
+	HWAccelNVENC
+	HWAccelQSV
+	HWAccelVideoToolbox
+	HWAccelV4L2M2M
+)
+
+var hwAccelEncoders = map[int]string{
+	HWAccelNone:         "libx264",
+	HWAccelVAAPI:        "h264_vaapi",
+	HWAccelNVENC:        "h264_nvenc",
+	HWAccelQSV:          "h264_qsv",
+	HWAccelVideoToolbox: "h264_videotoolbox",
+	HWAccelV4L2M2M:      "h264_v4l2m2m",
+}
+
+var hwAccelDecoderArgs = map[int][]string{
+	HWAccelVAAPI:        {"-hwaccel", "vaapi", "-hwaccel_output_format", "vaapi", "-vaapi_device", "/dev/dri/renderD128"},
+	HWAccelNVENC:        {"-hwaccel", "cuda"},
+	HWAccelQSV:          {"-hwaccel", "qsv"},
+	HWAccelVideoToolbox: {"-hwaccel", "videotoolbox"},
+}
+
+// Transcode serves torrent file, passed through external ffmpeg,
+// remuxed or transcoded into fragmented MP4 with H.264 video and stereo AAC audio,
+// for devices that can't play original codecs or container.
+// Source is read from our own /files/ endpoint, so pieces are prioritized as for usual playback.
+func Transcode(s *Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer perf.ScopeTimer()()
+
+		ffmpeg := config.Get().FFmpegPath
+		if ffmpeg == "" {
+			http.Error(w, "FFmpeg path is not set in settings", http.StatusServiceUnavailable)
+			return
+		}
+
+		filePath := strings.TrimPrefix(r.URL.EscapedPath(), "/transcode/")
+		source := fmt.Sprintf("http://127.0.0.1:%d/files/%s", config.Args.LocalPort, filePath)
+
+		w.Header().Set("Connection", "close")
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Accept-Ranges", "none")
+		if r.Method == "HEAD" {
+			return
+		}
+
+		args := transcodeArgs(source, r.URL.Query().Get("start"))
+		log.Infof("Transcoding %s: %s %s", filePath, ffmpeg, strings.Join(args, " "))
+
+		cmd := exec.CommandContext(r.Context(), ffmpeg, args...)
+		cmd.Stdout = w
+
+		stderr, err := cmd.StderrPipe()
+		if err != nil {
+			log.Errorf("Could not start ffmpeg: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := cmd.Start(); err != nil {
+			log.Errorf("Could not start ffmpeg: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			log.Debugf("ffmpeg | %s", scanner.Text())
+		}
+
+		if err := cmd.Wait(); err != nil && r.Context().Err() == nil {
+			log.Warningf("Transcoding of %s failed: %s", filePath, err)
+		}
+	})
+}
+
+// transcodeArgs returns ffmpeg arguments for configured transcoding mode
+func transcodeArgs(source string, start string) []string {
+	conf := config.Get()
+	transcodeVideo := conf.TranscodeVideo
+
+	encoder, ok := hwAccelEncoders[conf.TranscodeHWAccel]
+	if !ok {
+		encoder = hwAccelEncoders[HWAccelNone]
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "warning", "-nostdin"}
+	if transcodeVideo {
+		args = append(args, hwAccelDecoderArgs[conf.TranscodeHWAccel]...)
+	}
+	if _, err := strconv.ParseFloat(start, 64); err == nil {
+		args = append(args, "-ss", start)
+	}
+
+	args = append(args, "-i", source, "-map", "0:v:0", "-map", "0:a:0?", "-sn")
+
+	if transcodeVideo {
+		args = append(args, "-c:v", encoder)
+		if encoder == hwAccelEncoders[HWAccelNone] {
+			args = append(args, "-preset", "veryfast", "-crf", "22")
+		}
+		if conf.TranscodeHWAccel != HWAccelVAAPI {
+			args = append(args, "-pix_fmt", "yuv420p")
+		}
+	} else {
+		args = append(args, "-c:v", "copy")
+	}
+
+	return append(args,
+		"-c:a", "aac", "-ac", "2", "-b:a", "192k",
+		"-f", "mp4", "-movflags", "frag_keyframe+empty_moov+default_base_moof",
+		"pipe:1",
+	)
+}
@@ -23,6 +23,15 @@ const (
 	DownloadFileAll
 )
 
+const (
+	// ProbeFileDisabled ...
+	ProbeFileDisabled int = iota
+	// ProbeFileWarn ...
+	ProbeFileWarn
+	// ProbeFileSkip ...
+	ProbeFileSkip
+)
+
 var (
 	// Storages ...
 	Storages = []string{
@@ -0,0 +1,161 @@
+package bittorrent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Window properties, describing video which is going to be played
+const (
+	videoPropertyFPS    = "Elementum.Video.FPS"
+	videoPropertyHDR    = "Elementum.Video.HDR"
+	videoPropertySource = "Elementum.Video.Source"
+)
+
+const videoProbeTimeout = 15 * time.Second
+
+// Kodi's videoplayer.adjustrefreshrate value, when switching is disabled
+const adjustRefreshRateOff = 0
+
+var (
+	releaseFPSRe = regexp.MustCompile(`(?i)\W(23[.,]976|24|25|29[.,]97|30|48|50|59[.,]94|60|100|120)\s?fps\W`)
+	releaseHDRRe = []struct {
+		re  *regexp.Regexp
+		hdr string
+	}{
+		{regexp.MustCompile(`(?i)\W(dv|dovi|dolby\W?vision)\W`), "Dolby Vision"},
+		{regexp.MustCompile(`(?i)\WHDR10(\+|plus)`), "HDR10+"},
+		{regexp.MustCompile(`(?i)\WHLG\W`), "HLG"},
+		{regexp.MustCompile(`(?i)\WHDR(10)?\W`), "HDR10"},
+	}
+
+	probeFPSRe = regexp.MustCompile(`Video:.*?, ([\d.]+) fps`)
+	probeHDRRe = []struct {
+		re  *regexp.Regexp
+		hdr string
+	}{
+		{regexp.MustCompile(`DOVI configuration record`), "Dolby Vision"},
+		{regexp.MustCompile(`Video:.*smpte2084`), "HDR10"},
+		{regexp.MustCompile(`Video:.*arib-std-b67`), "HLG"},
+	}
+)
+
+// VideoInfo keeps frame rate and dynamic range of a video
+type VideoInfo struct {
+	FPS    float64
+	HDR    string
+	Source string
+}
+
+func (v *VideoInfo) String() string {
+	hdr := v.HDR
+	if hdr == "" {
+		hdr = "SDR"
+	}
+	fps := "unknown fps"
+	if v.FPS > 0 {
+		fps = fmt.Sprintf("%.3g fps", v.FPS)
+	}
+	return fmt.Sprintf("%s, %s (from %s)", fps, hdr, v.Source)
+}
+
+// IsPAL checks whether frame rate is 25 or 50 fps, which judders on some devices
+func (v *VideoInfo) IsPAL() bool {
+	return v.FPS == 25 || v.FPS == 50
+}
+
+// releaseVideoInfo detects frame rate and HDR format, mentioned in a release name
+func releaseVideoInfo(name string) *VideoInfo {
+	name = " " + name + " "
+	ret := &VideoInfo{Source: "release name"}
+
+	if m := releaseFPSRe.FindStringSubmatch(name); m != nil {
+		ret.FPS, _ = strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+	}
+	for _, tag := range releaseHDRRe {
+		if tag.re.MatchString(name) {
+			ret.HDR = tag.hdr
+			break
+		}
+	}
+
+	return ret
+}
+
+// probeVideoInfo reads container header with ffmpeg, if it is configured
+func probeVideoInfo(url string) *VideoInfo {
+	ffmpeg := config.Get().FFmpegPath
+	if ffmpeg == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), videoProbeTimeout)
+	defer cancel()
+
+	// ffmpeg without outputs prints input information and exits with an error
+	out, _ := exec.CommandContext(ctx, ffmpeg, "-hide_banner", "-nostdin", "-i", url).CombinedOutput()
+	if len(out) == 0 {
+		return nil
+	}
+
+	ret := &VideoInfo{Source: "container"}
+	if m := probeFPSRe.FindSubmatch(out); m != nil {
+		ret.FPS, _ = strconv.ParseFloat(string(m[1]), 64)
+	}
+	for _, tag := range probeHDRRe {
+		if tag.re.Match(out) {
+			ret.HDR = tag.hdr
+			break
+		}
+	}
+
+	if ret.FPS == 0 && ret.HDR == "" {
+		return nil
+	}
+	return ret
+}
+
+// InitVideoInfo detects frame rate and HDR format of chosen file before playback starts,
+// exposes them as window properties and warns about content, that may judder.
+func (btp *Player) InitVideoInfo() *VideoInfo {
+	if btp.chosenFile == nil {
+		return nil
+	}
+
+	info := probeVideoInfo(fmt.Sprintf("http://127.0.0.1:%d/files/%s", config.Args.LocalPort, btp.PlayURL()))
+	if info == nil {
+		info = releaseVideoInfo(filepath.Base(btp.chosenFile.Path))
+		if info.FPS == 0 && info.HDR == "" {
+			info = releaseVideoInfo(btp.t.Name())
+		}
+	}
+
+	log.Infof("Video info of %s: %s", btp.chosenFile.Path, info)
+
+	fps := ""
+	if info.FPS > 0 {
+		fps = strconv.FormatFloat(info.FPS, 'f', -1, 64)
+	}
+	xbmc.SetWindowProperty(videoPropertyFPS, fps)
+	xbmc.SetWindowProperty(videoPropertyHDR, info.HDR)
+	xbmc.SetWindowProperty(videoPropertySource, info.Source)
+
+	if info.IsPAL() && config.Get().JudderWarning {
+		message := fmt.Sprintf("%gfps content may judder on this device", info.FPS)
+		if xbmc.SettingsGetSettingInt("videoplayer.adjustrefreshrate") == adjustRefreshRateOff {
+			message += ", refresh rate switching is disabled"
+		}
+		xbmc.Notify("Elementum", message, config.AddonIcon())
+	}
+
+	return info
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// AccountKey returns the key, under which the value is stored. Trakt keys are separated
+// for each Trakt account, so that switching accounts does not show cached lists of another account.
+func AccountKey(key string) string {
+	username := config.Get().TraktUsername
+	if username == "" || !strings.HasPrefix(key, TraktKey) {
+		return key
+	}
+
+	return TraktKey + "user." + username + "." + strings.TrimPrefix(key, TraktKey)
+}
@@ -27,6 +27,7 @@ var (
 	errCacheMiss    = errors.New("cache: key not found")
 	errNotStored    = errors.New("cache: not stored")
 	errNotSupported = errors.New("cache: not supported")
+	errDecompress   = errors.New("cache: could not decompress")
 	log             = logging.MustGetLogger("cache")
 )
 
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// Values, smaller than this, are stored as is, since compression does not save much on them
+const compressMinSize = 512
+
+// Every zstd frame starts with this magic, msgpack encoded DBStoreItem never does,
+// so compressed and plain values, stored before compression was enabled, can live together.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd() {
+	zstdOnce.Do(func() {
+		var err error
+		if zstdEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1)); err != nil {
+			log.Warningf("Could not create cache compressor: %s", err)
+		}
+		if zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err != nil {
+			log.Warningf("Could not create cache decompressor: %s", err)
+		}
+	})
+}
+
+// compress returns value, compressed with zstd, if compression is enabled and value is worth compressing
+func compress(b []byte) []byte {
+	if !config.Get().CacheCompression || len(b) < compressMinSize {
+		return b
+	}
+
+	initZstd()
+	if zstdEncoder == nil {
+		return b
+	}
+
+	compressed := zstdEncoder.EncodeAll(b, make([]byte, 0, len(b)/2))
+	if len(compressed) >= len(b) {
+		return b
+	}
+	return compressed
+}
+
+// decompress returns plain value, compressed values are detected by zstd magic,
+// so values are readable even after compression was disabled.
+func decompress(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, zstdMagic) {
+		return b, nil
+	}
+
+	initZstd()
+	if zstdDecoder == nil {
+		return nil, errDecompress
+	}
+
+	return zstdDecoder.DecodeAll(b, nil)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,10 +47,43 @@ var (
 
 var dbStore *DBStore
 
+var (
+	stalePrefixes = map[string]bool{}
+	staleLock     sync.RWMutex
+)
+
+// AllowStale makes expired values with keys, starting with prefix, to be served,
+// while corresponding upstream service is not available.
+func AllowStale(prefix string, allow bool) {
+	staleLock.Lock()
+	defer staleLock.Unlock()
+
+	if allow {
+		stalePrefixes[prefix] = true
+	} else {
+		delete(stalePrefixes, prefix)
+	}
+}
+
+func isStaleAllowed(key string) bool {
+	staleLock.RLock()
+	defer staleLock.RUnlock()
+
+	for prefix := range stalePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewDBStore Returns instance of BoltDB backed cache store
 func NewDBStore() *DBStore {
 	if dbStore == nil {
 		dbStore = &DBStore{database.GetCache()}
+		if dbStore.db != nil {
+			dbStore.db.OnDelete(memory.invalidate)
+		}
 	}
 
 	return dbStore
@@ -74,7 +108,51 @@ func (c *DBStore) Set(key string, value interface{}, expires time.Duration) (err
 		return err
 	}
 
-	return c.db.SetBytes(database.CommonBucket, key, append([]byte(strconv.FormatInt(time.Now().UTC().Add(expires).Unix(), 10)), b...))
+	expires = namespaceExpire(key, expires)
+	return c.setBytes(key, b, time.Now().UTC().Add(expires).Unix())
+}
+
+// Update replaces stored value, keeping its expiration, so that modified value
+// does not outlive the original one.
+func (c *DBStore) Update(key string, value interface{}) (err error) {
+	data, err := c.db.GetBytes(database.CommonBucket, AccountKey(key))
+	if err != nil {
+		return err
+	} else if len(data) <= 10 {
+		return errors.New("data is empty")
+	}
+
+	expire, _ := database.ParseCacheItem(data)
+
+	item := DBStoreItem{
+		Key:   key,
+		Value: value,
+	}
+
+	// Recover from marshal errors
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Can't encode the value")
+		}
+	}()
+
+	b, err := msgpack.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return c.setBytes(key, b, expire)
+}
+
+func (c *DBStore) setBytes(key string, b []byte, expireAt int64) (err error) {
+	expire := strconv.FormatInt(expireAt, 10)
+	if err = c.db.SetBytes(database.CommonBucket, AccountKey(key), append([]byte(expire), compress(b)...)); err != nil {
+		return err
+	}
+
+	// Memory keeps plain value, so that hot keys are not decompressed on every read
+	memory.set(AccountKey(key), append([]byte(expire), b...))
+	return nil
 }
 
 // Add ...
@@ -89,11 +167,24 @@ func (c *DBStore) Replace(key string, value interface{}, expires time.Duration)
 
 // Get ...
 func (c *DBStore) Get(key string, value interface{}) (err error) {
-	data, errGet := c.db.GetBytes(database.CommonBucket, key)
-	if errGet != nil {
-		return errGet
-	} else if len(data) == 0 {
-		return errors.New("data is empty")
+	accountKey := AccountKey(key)
+	data, gen, ok := memory.get(accountKey)
+	if !ok {
+		var errGet error
+		data, errGet = c.db.GetBytes(database.CommonBucket, accountKey)
+		if errGet != nil {
+			return errGet
+		} else if len(data) <= 10 {
+			return errors.New("data is empty")
+		}
+
+		// Database values are valid only inside of a transaction, so a copy is kept
+		plain, errPlain := decompress(data[10:])
+		if errPlain != nil {
+			return errPlain
+		}
+		data = append(append([]byte(nil), data[:10]...), plain...)
+		memory.populate(accountKey, data, gen)
 	}
 
 	// Recover from unmarshal errors
@@ -110,8 +201,12 @@ func (c *DBStore) Get(key string, value interface{}) (err error) {
 	item := DBStoreItem{
 		Value: value,
 	}
-	if expires, _ := database.ParseCacheItem(data); expires > 0 && expires < util.NowInt64() {
-		go c.db.Delete(database.CommonBucket, key)
+	if expires, _ := database.ParseCacheItem(data); expires > 0 && expires < util.NowInt64() && !isStaleAllowed(key) {
+		memory.delete(accountKey)
+		// Recently expired values are kept, to be served when upstream service stops responding
+		if expires < util.NowInt64()-database.CacheStaleKeep {
+			go c.db.Delete(database.CommonBucket, accountKey)
+		}
 		return errors.New("key is expired")
 	}
 
@@ -122,9 +217,24 @@ func (c *DBStore) Get(key string, value interface{}) (err error) {
 	return nil
 }
 
+// Updated returns time, when the value was stored, calculated from its expiration
+func (c *DBStore) Updated(key string, expires time.Duration) (time.Time, error) {
+	data, err := c.db.GetBytes(database.CommonBucket, AccountKey(key))
+	if err != nil {
+		return time.Time{}, err
+	} else if len(data) == 0 {
+		return time.Time{}, errors.New("data is empty")
+	}
+
+	expire, _ := database.ParseCacheItem(data)
+	return time.Unix(expire, 0).Add(-namespaceExpire(key, expires)), nil
+}
+
 // Delete ...
 func (c *DBStore) Delete(key string) error {
-	return c.db.Delete(database.CommonBucket, key)
+	// Memory is cleared after the database, so that concurrent Get does not populate deleted value
+	defer memory.delete(AccountKey(key))
+	return c.db.Delete(database.CommonBucket, AccountKey(key))
 }
 
 // Increment ...
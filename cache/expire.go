@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// namespaceExpire returns expiration of a key, configured in settings for its namespace,
+// or the default one, passed by the caller.
+func namespaceExpire(key string, expires time.Duration) time.Duration {
+	conf := config.Get()
+
+	ttl := time.Duration(0)
+	switch {
+	case strings.HasPrefix(key, FanartKey), strings.HasPrefix(key, TMDBKey) && strings.HasSuffix(key, ".images"):
+		ttl = conf.CacheArtworkTTL
+	case strings.HasPrefix(key, TMDBKey):
+		ttl = conf.CacheTMDBTTL
+	case strings.HasPrefix(key, TraktKey+"movies."), strings.HasPrefix(key, TraktKey+"shows."):
+		ttl = conf.CacheTraktListsTTL
+	}
+
+	if ttl > 0 {
+		return ttl
+	}
+	return expires
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+)
+
+// Items, larger than this part of the limit, are not kept in memory,
+// so that a single large item does not evict all hot keys.
+const memoryItemMaxPart = 8
+
+// memoryCache is a size-bounded LRU of encoded values, that fronts DBStore,
+// so hot keys are not read from the database over and over again.
+// Encoded values are kept, since decoded ones are modified by callers.
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+	size  int
+	// gen is changed on every modification, so that values, read from the database,
+	// are not stored, if the key was changed or deleted meanwhile
+	gen uint64
+
+	hits   int64
+	misses int64
+}
+
+type memoryItem struct {
+	key  string
+	data []byte
+}
+
+// MemoryStats is a usage of in-memory cache
+type MemoryStats struct {
+	Items  int
+	Size   int
+	Limit  int
+	Hits   int64
+	Misses int64
+}
+
+var memory = &memoryCache{
+	items: map[string]*list.Element{},
+	order: list.New(),
+}
+
+// GetMemoryStats returns usage of in-memory cache
+func GetMemoryStats() MemoryStats {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+
+	return MemoryStats{
+		Items:  len(memory.items),
+		Size:   memory.size,
+		Limit:  config.Get().CacheMemorySize,
+		Hits:   memory.hits,
+		Misses: memory.misses,
+	}
+}
+
+// get returns stored value, on a miss it returns generation, that should be passed to populate
+func (m *memoryCache) get(key string) ([]byte, uint64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.items[key]; ok {
+		m.order.MoveToFront(e)
+		m.hits++
+		return e.Value.(*memoryItem).data, m.gen, true
+	}
+
+	m.misses++
+	return nil, m.gen, false
+}
+
+// set stores value, write-through is done by the caller, after value is stored in the database
+func (m *memoryCache) set(key string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	m.setLocked(key, data)
+}
+
+// populate stores value, read from the database, unless cache was modified since the miss
+func (m *memoryCache) populate(key string, data []byte, gen uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.gen != gen {
+		return
+	}
+	m.setLocked(key, data)
+}
+
+func (m *memoryCache) setLocked(key string, data []byte) {
+	m.removeLocked(key)
+
+	limit := config.Get().CacheMemorySize
+	if limit <= 0 {
+		if len(m.items) > 0 {
+			m.items = map[string]*list.Element{}
+			m.order.Init()
+			m.size = 0
+		}
+		return
+	} else if len(data) > limit/memoryItemMaxPart {
+		return
+	}
+
+	m.items[key] = m.order.PushFront(&memoryItem{key: key, data: data})
+	m.size += len(data)
+
+	for m.size > limit {
+		m.removeLocked(m.order.Back().Value.(*memoryItem).key)
+	}
+}
+
+func (m *memoryCache) delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	m.removeLocked(key)
+}
+
+func (m *memoryCache) removeLocked(key string) {
+	if e, ok := m.items[key]; ok {
+		m.size -= len(e.Value.(*memoryItem).data)
+		m.order.Remove(e)
+		delete(m.items, key)
+	}
+}
+
+// invalidate drops keys, that are deleted from the database, bypassing DBStore
+func (m *memoryCache) invalidate(bucket []byte, keys []string) {
+	if !bytes.Equal(bucket, database.CommonBucket) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gen++
+	if keys == nil {
+		m.items = map[string]*list.Element{}
+		m.order.Init()
+		m.size = 0
+		return
+	}
+
+	for _, key := range keys {
+		m.removeLocked(key)
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/util"
+)
+
+// Known namespaces, other keys are grouped by their first part
+var namespaces = []string{TMDBKey, TVDBKey, TraktKey, OMDbKey, ScraperKey, LibraryKey, FanartKey}
+
+// NamespaceStats is a size accounting of cached items with the same key prefix
+type NamespaceStats struct {
+	Prefix     string `json:"prefix"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes"`
+	Compressed int    `json:"compressed"`
+	Expired    int    `json:"expired"`
+}
+
+// Stats is a size accounting of the cache database
+type Stats struct {
+	Entries    int              `json:"entries"`
+	Bytes      int64            `json:"bytes"`
+	Limit      int64            `json:"limit"`
+	Namespaces []NamespaceStats `json:"namespaces"`
+	Memory     MemoryStats      `json:"memory"`
+}
+
+// GetStats returns number of entries and bytes, used by each namespace of the cache database
+func GetStats() Stats {
+	stats := Stats{
+		Limit:      config.Get().CacheMaxSize,
+		Namespaces: []NamespaceStats{},
+		Memory:     GetMemoryStats(),
+	}
+
+	db := database.GetCache()
+	if db == nil {
+		return stats
+	}
+
+	now := util.NowInt64()
+	byPrefix := map[string]*NamespaceStats{}
+	db.ForEach(database.CommonBucket, func(key []byte, value []byte) error {
+		prefix := namespace(string(key))
+		ns, ok := byPrefix[prefix]
+		if !ok {
+			ns = &NamespaceStats{Prefix: prefix}
+			byPrefix[prefix] = ns
+		}
+
+		ns.Entries++
+		ns.Bytes += int64(len(key) + len(value))
+		if len(value) > 10 && bytes.HasPrefix(value[10:], zstdMagic) {
+			ns.Compressed++
+		}
+		if expire, _ := database.ParseCacheItem(value); expire > 0 && expire < now {
+			ns.Expired++
+		}
+
+		return nil
+	})
+
+	for _, ns := range byPrefix {
+		stats.Entries += ns.Entries
+		stats.Bytes += ns.Bytes
+		stats.Namespaces = append(stats.Namespaces, *ns)
+	}
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		return stats.Namespaces[i].Bytes > stats.Namespaces[j].Bytes
+	})
+
+	return stats
+}
+
+// namespace returns prefix of a key, that is used to group keys in stats
+func namespace(key string) string {
+	for _, prefix := range namespaces {
+		if strings.HasPrefix(key, prefix) {
+			return prefix
+		}
+	}
+
+	if i := strings.IndexAny(key, "._"); i > 0 {
+		return key[:i+1]
+	}
+	return key
+}
@@ -13,6 +13,7 @@ const (
 	ScraperKey = "scraper."
 	LibraryKey = "library."
 	FanartKey  = "fanart."
+	OMDbKey    = "com.omdb."
 
 	TMDBEpisodeKey                 = TMDBKey + "episode.%d.%d.%d.%s"
 	TMDBEpisodeExpire              = GeneralExpire
@@ -26,6 +27,8 @@ const (
 	TMDBMovieImagesExpire          = GeneralExpire
 	TMDBMovieByIDKey               = TMDBKey + "movie.%s.%s"
 	TMDBMovieByIDExpire            = GeneralExpire
+	TMDBMovieProjectionKey         = TMDBKey + "movie.%d.projection.%s"
+	TMDBMovieProjectionExpire      = GeneralExpire
 	TMDBMovieGenresKey             = TMDBKey + "genres.movies.%s"
 	TMDBMovieGenresExpire          = GeneralExpire
 	TMDBMoviesIMDBKey              = TMDBKey + "imdb.list.%s.%d.%d"
@@ -44,6 +47,8 @@ const (
 	TMDBShowByIDExpire             = GeneralExpire
 	TMDBShowImagesKey              = TMDBKey + "show.%d.images"
 	TMDBShowImagesExpire           = GeneralExpire
+	TMDBShowProjectionKey          = TMDBKey + "show.%d.projection.%s"
+	TMDBShowProjectionExpire       = GeneralExpire
 	TMDBShowGenresKey              = TMDBKey + "genres.shows.%s"
 	TMDBShowGenresExpire           = GeneralExpire
 	TMDBShowsTopShowsKey           = TMDBKey + "topshows.%s.%s.%s.%s.%d.%d"
@@ -52,6 +57,14 @@ const (
 	TMDBShowsTopShowsTotalExpire   = 24 * time.Hour
 	TMDBEpisodeImagesKey           = TMDBKey + "show.%d.%d.%d.images"
 	TMDBEpisodeImagesExpire        = GeneralExpire
+	TMDBWatchProvidersKey          = TMDBKey + "providers.%s.%d.%s"
+	TMDBWatchProvidersExpire       = 24 * time.Hour
+	TMDBWatchProvidersListKey      = TMDBKey + "providers.list.%s.%s"
+	TMDBWatchProvidersListExpire   = GeneralExpire
+	TMDBPersonKey                  = TMDBKey + "person.%d.%s"
+	TMDBPersonExpire               = GeneralExpire
+	TMDBPersonCreditsKey           = TMDBKey + "person.%d.credits.%s"
+	TMDBPersonCreditsExpire        = GeneralExpire
 
 	TraktActivitiesKey                     = TraktKey + "last_activities"
 	TraktActivitiesExpire                  = 30 * 24 * time.Hour
@@ -67,8 +80,10 @@ const (
 	TraktMoviesByCategoryTotalExpire       = 24 * time.Hour
 	TraktMoviesWatchlistKey                = TraktKey + "movies.watchlist"
 	TraktMoviesWatchlistExpire             = GeneralExpire
+	TraktMoviesWatchlistPendingKey         = TraktKey + "pending.movies.watchlist"
 	TraktMoviesCollectionKey               = TraktKey + "movies.collection"
 	TraktMoviesCollectionExpire            = GeneralExpire
+	TraktMoviesCollectionPendingKey        = TraktKey + "pending.movies.collection"
 	TraktMoviesListKey                     = TraktKey + "movies.list.%s"
 	TraktMoviesListExpire                  = 1 * time.Minute
 	TraktMoviesCalendarKey                 = TraktKey + "movies.calendar.%s.%s"
@@ -87,12 +102,14 @@ const (
 	TraktShowsByCategoryTotalExpire        = 24 * time.Hour
 	TraktShowsWatchlistKey                 = TraktKey + "shows.watchlist"
 	TraktShowsWatchlistExpire              = GeneralExpire
+	TraktShowsWatchlistPendingKey          = TraktKey + "pending.shows.watchlist"
 	TraktShowsWatchedKey                   = TraktKey + "shows.watched"
 	TraktShowsWatchedExpire                = GeneralExpire
 	TraktShowsPausedKey                    = TraktKey + "shows.paused"
 	TraktShowsPausedExpire                 = GeneralExpire
 	TraktShowsCollectionKey                = TraktKey + "shows.collection"
 	TraktShowsCollectionExpire             = GeneralExpire
+	TraktShowsCollectionPendingKey         = TraktKey + "pending.shows.collection"
 	TraktShowsListKey                      = TraktKey + "shows.list.%s"
 	TraktShowsListExpire                   = 1 * time.Minute
 	TraktShowsCalendarKey                  = TraktKey + "shows.calendar.%s.%s"
@@ -123,15 +140,42 @@ const (
 	TraktShowTVDBExpire                    = GeneralExpire
 	TraktLockedAccountKey                  = TraktKey + "locked.account"
 	TraktLockedAccountExpire               = 24 * time.Hour
+	TraktGenresKey                         = TraktKey + "genres.%s"
+	TraktGenresExpire                      = GeneralExpire
+	TraktMoviesRatingsKey                  = TraktKey + "movies.ratings"
+	TraktMoviesRatingsExpire               = GeneralExpire
+	TraktShowsRatingsKey                   = TraktKey + "shows.ratings"
+	TraktShowsRatingsExpire                = GeneralExpire
+	TraktHiddenKey                         = TraktKey + "hidden.%s.%s"
+	TraktHiddenExpire                      = 6 * time.Hour
+	TraktCommentsKey                       = TraktKey + "comments.%s.%s.%d"
+	TraktCommentsExpire                    = 6 * time.Hour
+	TraktHistoryNotesKey                   = TraktKey + "history.notes"
+	TraktHistoryNotesExpire                = 6 * time.Hour
+	TraktNotesVIPRequiredKey               = TraktKey + "notes.vip"
+	TraktNotesVIPRequiredExpire            = 24 * time.Hour
 
-	TVDBShowByIDKey    = TVDBKey + "show.%d.%s"
-	TVDBShowByIDExpire = GeneralExpire
+	TVDBShowByIDKey          = TVDBKey + "show.%d.%s"
+	TVDBShowByIDExpire       = GeneralExpire
+	TVDBShowV4ByIDKey        = TVDBKey + "v4.show.%d.%s"
+	TVDBShowV4ByIDExpire     = GeneralExpire
+	TVDBSeriesExtendedKey    = TVDBKey + "v4.series.%d"
+	TVDBSeriesExtendedExpire = GeneralExpire
+	TVDBSeriesEpisodesKey    = TVDBKey + "v4.series.%d.episodes"
+	TVDBSeriesEpisodesExpire = 24 * time.Hour
+	TVDBSeasonArtworkKey     = TVDBKey + "v4.season.%d.artwork"
+	TVDBSeasonArtworkExpire  = GeneralExpire
+	TVDBTokenKey             = TVDBKey + "v4.token"
+	TVDBTokenExpire          = 25 * 24 * time.Hour
 
 	FanartMovieByIDKey    = FanartKey + "movie.%d"
 	FanartMovieByIDExpire = GeneralExpire
 	FanartShowByIDKey     = FanartKey + "show.%d"
 	FanartShowByIDExpire  = GeneralExpire
 
+	OMDbItemKey    = OMDbKey + "item.%s"
+	OMDbItemExpire = GeneralExpire
+
 	LibraryWatchedPlaycountKey    = LibraryKey + "WatchedLastPlaycount.%s"
 	LibraryWatchedPlaycountExpire = 30 * 24 * time.Hour
 	LibraryShowsLastUpdatesKey    = LibraryKey + "showsLastUpdates"
@@ -0,0 +1,82 @@
+package cast
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const castTimeout = 15 * time.Second
+
+// Content types of containers, that devices may play without transcoding
+var contentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".avi":  "video/x-msvideo",
+}
+
+var supportedContainers = map[string][]string{
+	DeviceChromecast: {".mp4", ".m4v", ".webm", ".mkv"},
+	DeviceAirPlay:    {".mp4", ".m4v", ".mov"},
+}
+
+// Supports checks whether device can play file's container natively
+func (d *Device) Supports(fileName string) bool {
+	ext := strings.ToLower(path.Ext(fileName))
+	for _, c := range supportedContainers[d.Type] {
+		if c == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns MIME type of a video file, guessed by its extension
+func ContentType(fileName string) string {
+	if contentType, ok := contentTypes[strings.ToLower(path.Ext(fileName))]; ok {
+		return contentType
+	}
+	return "video/mp4"
+}
+
+// Play sends the URL to the device, which fetches the stream directly from the daemon
+func (d *Device) Play(url, contentType, title string) error {
+	log.Infof("Sending %s to %s at %s:%d", url, d, d.Host, d.Port)
+
+	switch d.Type {
+	case DeviceChromecast:
+		return playChromecast(d, url, contentType, title)
+	case DeviceAirPlay:
+		return playAirPlay(d, url)
+	}
+
+	return fmt.Errorf("Unknown device type: %s", d.Type)
+}
+
+// playAirPlay starts playback of the URL with AirPlay video protocol
+func playAirPlay(d *Device, url string) error {
+	body := fmt.Sprintf("Content-Location: %s\nStart-Position: 0\n", url)
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s:%d/play", d.Host, d.Port), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/parameters")
+	req.Header.Set("User-Agent", "MediaControl/1.0")
+
+	client := &http.Client{Timeout: castTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Device responded with %s", resp.Status)
+	}
+	return nil
+}
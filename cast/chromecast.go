@@ -0,0 +1,239 @@
+package cast
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// CASTV2 protocol constants
+const (
+	castNamespaceConnection = "urn:x-cast:com.google.cast.tp.connection"
+	castNamespaceHeartbeat  = "urn:x-cast:com.google.cast.tp.heartbeat"
+	castNamespaceReceiver   = "urn:x-cast:com.google.cast.receiver"
+	castNamespaceMedia      = "urn:x-cast:com.google.cast.media"
+
+	castSenderID   = "sender-0"
+	castReceiverID = "receiver-0"
+
+	// Default Media Receiver application
+	castMediaReceiverApp = "CC1AD845"
+
+	castMaxMessageSize = 64 * 1024
+)
+
+type castConnection struct {
+	conn net.Conn
+}
+
+type castStatus struct {
+	Type   string `json:"type"`
+	Status struct {
+		Applications []struct {
+			AppID       string `json:"appId"`
+			TransportID string `json:"transportId"`
+		} `json:"applications"`
+	} `json:"status"`
+}
+
+// playChromecast launches Default Media Receiver on the device and loads the URL into it.
+// Connection is closed afterwards, receiver keeps playing on its own.
+func playChromecast(d *Device, url, contentType, title string) error {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: castTimeout}, "tcp", net.JoinHostPort(d.Host, fmt.Sprint(d.Port)), &tls.Config{
+		// Cast devices use self-signed certificates
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(castTimeout))
+
+	c := &castConnection{conn: conn}
+	if err := c.send(castReceiverID, castNamespaceConnection, map[string]interface{}{"type": "CONNECT"}); err != nil {
+		return err
+	}
+	if err := c.send(castReceiverID, castNamespaceReceiver, map[string]interface{}{"type": "LAUNCH", "appId": castMediaReceiverApp, "requestId": 1}); err != nil {
+		return err
+	}
+
+	transportID := ""
+	for transportID == "" {
+		namespace, payload, err := c.receive()
+		if err != nil {
+			return err
+		}
+		if namespace != castNamespaceReceiver {
+			continue
+		}
+
+		status := &castStatus{}
+		if err := json.Unmarshal([]byte(payload), status); err != nil {
+			continue
+		}
+		if status.Type == "LAUNCH_ERROR" {
+			return errors.New("Device refused to launch media receiver")
+		}
+		for _, app := range status.Status.Applications {
+			if app.AppID == castMediaReceiverApp && app.TransportID != "" {
+				transportID = app.TransportID
+			}
+		}
+	}
+
+	if err := c.send(transportID, castNamespaceConnection, map[string]interface{}{"type": "CONNECT"}); err != nil {
+		return err
+	}
+	if err := c.send(transportID, castNamespaceMedia, map[string]interface{}{
+		"type":        "LOAD",
+		"requestId":   2,
+		"autoplay":    true,
+		"currentTime": 0,
+		"media": map[string]interface{}{
+			"contentId":   url,
+			"contentType": contentType,
+			"streamType":  "BUFFERED",
+			"metadata": map[string]interface{}{
+				"metadataType": 0,
+				"title":        title,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	for {
+		namespace, payload, err := c.receive()
+		if err != nil {
+			return err
+		}
+		if namespace != castNamespaceMedia {
+			continue
+		}
+
+		status := &castStatus{}
+		if err := json.Unmarshal([]byte(payload), status); err != nil {
+			continue
+		}
+		switch status.Type {
+		case "MEDIA_STATUS":
+			return nil
+		case "LOAD_FAILED", "LOAD_CANCELLED", "INVALID_REQUEST":
+			return fmt.Errorf("Device failed to load media: %s", status.Type)
+		}
+	}
+}
+
+func (c *castConnection) send(destination, namespace string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	msg := encodeCastMessage(castSenderID, destination, namespace, string(data))
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(msg)))
+
+	_, err = c.conn.Write(append(header, msg...))
+	return err
+}
+
+// receive reads next message, answering heartbeat pings on the way
+func (c *castConnection) receive() (namespace, payload string, err error) {
+	for {
+		header := make([]byte, 4)
+		if _, err = io.ReadFull(c.conn, header); err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(header)
+		if size > castMaxMessageSize {
+			return "", "", fmt.Errorf("Cast message is too big: %d", size)
+		}
+
+		msg := make([]byte, size)
+		if _, err = io.ReadFull(c.conn, msg); err != nil {
+			return
+		}
+
+		if namespace, payload, err = decodeCastMessage(msg); err != nil {
+			return
+		}
+		if namespace == castNamespaceHeartbeat {
+			c.send(castReceiverID, castNamespaceHeartbeat, map[string]interface{}{"type": "PONG"})
+			continue
+		}
+		return
+	}
+}
+
+// encodeCastMessage encodes CastMessage protobuf with a string payload:
+// protocol_version(1), source_id(2), destination_id(3), namespace(4), payload_type(5), payload_utf8(6)
+func encodeCastMessage(source, destination, namespace, payload string) []byte {
+	buf := []byte{}
+	buf = appendVarintField(buf, 1, 0)
+	buf = appendStringField(buf, 2, source)
+	buf = appendStringField(buf, 3, destination)
+	buf = appendStringField(buf, 4, namespace)
+	buf = appendVarintField(buf, 5, 0)
+	buf = appendStringField(buf, 6, payload)
+	return buf
+}
+
+// decodeCastMessage returns namespace and string payload of a CastMessage protobuf
+func decodeCastMessage(buf []byte) (namespace, payload string, err error) {
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return "", "", errors.New("Malformed cast message")
+		}
+		buf = buf[n:]
+
+		switch key & 7 {
+		case 0:
+			if _, n = binary.Uvarint(buf); n <= 0 {
+				return "", "", errors.New("Malformed cast message")
+			}
+			buf = buf[n:]
+		case 2:
+			size, n := binary.Uvarint(buf)
+			if n <= 0 || uint64(len(buf)-n) < size {
+				return "", "", errors.New("Malformed cast message")
+			}
+			value := string(buf[n : n+int(size)])
+			buf = buf[n+int(size):]
+
+			switch key >> 3 {
+			case 4:
+				namespace = value
+			case 6:
+				payload = value
+			}
+		default:
+			return "", "", fmt.Errorf("Unsupported wire type in cast message: %d", key&7)
+		}
+	}
+	return
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	tmp := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(tmp, v)
+	return append(buf, tmp[:n]...)
+}
+
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	buf = appendVarint(buf, uint64(field<<3))
+	return appendVarint(buf, v)
+}
+
+func appendStringField(buf []byte, field int, v string) []byte {
+	buf = appendVarint(buf, uint64(field<<3|2))
+	buf = appendVarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
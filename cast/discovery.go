@@ -0,0 +1,156 @@
+package cast
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/op/go-logging"
+)
+
+var log = logging.MustGetLogger("cast")
+
+// Device types
+const (
+	DeviceChromecast = "Chromecast"
+	DeviceAirPlay    = "AirPlay"
+)
+
+const mdnsAddress = "224.0.0.251:5353"
+
+// mDNS services, advertised by cast devices
+var services = map[string]string{
+	"_googlecast._tcp.local.": DeviceChromecast,
+	"_airplay._tcp.local.":    DeviceAirPlay,
+}
+
+// Device is a cast device, found in local network
+type Device struct {
+	Name string
+	Type string
+	Host string
+	Port int
+
+	instance string
+	target   string
+}
+
+func (d *Device) String() string {
+	return fmt.Sprintf("%s (%s)", d.Name, d.Type)
+}
+
+// Discover sends mDNS queries for Chromecast and AirPlay services
+// and collects answers during the timeout.
+func Discover(timeout time.Duration) ([]*Device, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	addr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for service := range services {
+		m := new(dns.Msg)
+		m.SetQuestion(service, dns.TypePTR)
+		m.RecursionDesired = false
+
+		buf, err := m.Pack()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.WriteToUDP(buf, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	devices := map[string]*Device{}
+	device := func(instance string) *Device {
+		instance = strings.ToLower(instance)
+		if _, ok := devices[instance]; !ok {
+			devices[instance] = &Device{instance: instance}
+		}
+		return devices[instance]
+	}
+	addresses := map[string]net.IP{}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+
+		m := new(dns.Msg)
+		if err := m.Unpack(buf[:n]); err != nil {
+			continue
+		}
+
+		records := append(append(m.Answer, m.Ns...), m.Extra...)
+		for _, rr := range records {
+			switch r := rr.(type) {
+			case *dns.PTR:
+				if kind, ok := services[strings.ToLower(r.Hdr.Name)]; ok {
+					d := device(r.Ptr)
+					d.Type = kind
+					if d.Host == "" {
+						d.Host = from.IP.String()
+					}
+				}
+			case *dns.SRV:
+				d := device(r.Hdr.Name)
+				d.Port = int(r.Port)
+				d.target = strings.ToLower(r.Target)
+				if d.Host == "" {
+					d.Host = from.IP.String()
+				}
+			case *dns.TXT:
+				d := device(r.Hdr.Name)
+				for _, txt := range r.Txt {
+					if strings.HasPrefix(txt, "fn=") {
+						d.Name = txt[3:]
+					}
+				}
+			case *dns.A:
+				addresses[strings.ToLower(r.Hdr.Name)] = r.A
+			}
+		}
+	}
+
+	ret := []*Device{}
+	for _, d := range devices {
+		if d.Type == "" || d.Port == 0 || d.Host == "" {
+			continue
+		}
+		if ip, ok := addresses[d.target]; ok {
+			d.Host = ip.String()
+		}
+		if d.Name == "" {
+			d.Name = instanceName(d.instance)
+		}
+		ret = append(ret, d)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	log.Debugf("Discovered %d cast devices", len(ret))
+	return ret, nil
+}
+
+// instanceName returns first, human readable, label of the service instance name
+func instanceName(instance string) string {
+	labels := dns.SplitDomainName(instance)
+	if len(labels) == 0 {
+		return instance
+	}
+	return strings.Replace(labels[0], `\ `, " ", -1)
+}
@@ -23,7 +23,7 @@ import (
 )
 
 var log = logging.MustGetLogger("config")
-var privacyRegex = regexp.MustCompile(`(?i)(pass|password): "(.+?)"`)
+var privacyRegex = regexp.MustCompile(`(?i)(pass|password|apikey): "(.+?)"`)
 
 const (
 	maxMemorySize                = 300 * 1024 * 1024
@@ -32,6 +32,35 @@ const (
 	defaultEndBufferSize         = 1 * 1024 * 1024
 	defaultDiskCacheSize         = 12 * 1024 * 1024
 
+	// Upper bounds of user configurable cache expiration
+	maxCacheTraktListsMinutes = 7 * 24 * 60
+	maxCacheTMDBHours         = 30 * 24
+	maxCacheArtworkHours      = 90 * 24
+
+	// Upper bound of in-memory cache in front of the cache database
+	maxCacheMemorySize = 256 * 1024 * 1024
+	// Upper bound of cache database size, when eviction is enabled
+	maxCacheDatabaseSize = 4096 * 1024 * 1024
+
+	// PostPlaybackKeep keeps torrent seeding after playback
+	PostPlaybackKeep = 0
+	// PostPlaybackPause pauses torrent after playback, keeping downloaded data
+	PostPlaybackPause = 1
+	// PostPlaybackDelete removes torrent and its data after playback
+	PostPlaybackDelete = 2
+	// PostPlaybackAsk asks user what to do with torrent after playback
+	PostPlaybackAsk = 3
+
+	// TitleDisplayLocalized shows localized titles
+	TitleDisplayLocalized = 0
+	// TitleDisplayBoth shows localized titles, followed by original ones
+	TitleDisplayBoth = 1
+	// TitleDisplayOriginal shows original titles only
+	TitleDisplayOriginal = 2
+
+	// MaxTorznabInstances is a number of Jackett/Prowlarr instances, configurable in settings
+	MaxTorznabInstances = 3
+
 	// TraktReadClientID ...
 	TraktReadClientID = "eb8839a79fb2af4ebfb93f993a8a539abd4d9674a7638497bbc662d2a4b22346"
 	// TraktReadClientSecret ...
@@ -57,12 +86,23 @@ type Configuration struct {
 	XbmcPath                   string
 	SpoofUserAgent             int
 	DownloadFileStrategy       int
+	ProbeFileAction            int
 	KeepDownloading            int
 	KeepFilesPlaying           int
 	KeepFilesFinished          int
+	PostPlaybackMovies         int
+	PostPlaybackEpisodes       int
+	PostPlaybackPartial        int
 	UseTorrentHistory          bool
 	TorrentHistorySize         int
 	UseFanartTv                bool
+	FanartTvClientKey          string
+	FanartTvLanguages          string
+	FanartTvClearArt           bool
+	FanartTvPreferBanners      bool
+	OMDbAPIKey                 string
+	TVDBAPIKey                 string
+	TVDBPin                    string
 	DisableBgProgress          bool
 	DisableBgProgressPlayback  bool
 	ForceUseTrakt              bool
@@ -72,6 +112,8 @@ type Configuration struct {
 	CacheSearchDuration        int
 	ShowFilesWatched           bool
 	ResultsPerPage             int
+	AllowedLanguages           []string
+	MinVoteCount               int
 	GreetingEnabled            bool
 	EnableOverlayStatus        bool
 	SilentStreamStart          bool
@@ -82,10 +124,12 @@ type Configuration struct {
 	ChooseStreamAutoSearch     bool
 	ForceLinkType              bool
 	UseOriginalTitle           bool
+	TitleDisplay               int
 	UseAnimeEnTitle            bool
 	UseLowestReleaseDate       bool
 	AddSpecials                bool
 	AddEpisodeNumbers          bool
+	HideEpisodeStills          bool
 	ShowUnairedSeasons         bool
 	ShowUnairedEpisodes        bool
 	ShowSeasonsAll             bool
@@ -94,6 +138,46 @@ type Configuration struct {
 	SmartEpisodeStart          bool
 	SmartEpisodeMatch          bool
 	SmartEpisodeChoose         bool
+	AutoPlayNext               bool
+	AutoPlayNextDelay          int
+	AutoPlayNextBinge          int
+	IntroOffsetEnabled         bool
+	MeteredMode                bool
+	PlaceholderArt             bool
+	SeasonalCollections        bool
+	SeasonalHalloweenList      string
+	SeasonalHalloweenKeywords  string
+	SeasonalChristmasList      string
+	SeasonalChristmasKeywords  string
+	KidsMode                   bool
+	KidsModePIN                string
+	KidsGenres                 string
+	KidsCertification          string
+	KidsLists                  []string
+	ExportPath                 string
+	MaxActiveDownloads         int
+	PreferredAudioLanguages    []string
+	PreferredSubtitleLanguages []string
+	FFmpegPath                 string
+	TranscodeVideo             bool
+	TranscodeHWAccel           int
+	JudderWarning              bool
+	TextlessBackdrops          bool
+	CacheTraktListsTTL         time.Duration
+	CacheTMDBTTL               time.Duration
+	CacheArtworkTTL            time.Duration
+	CacheMemorySize            int
+	CacheCompression           bool
+	CacheMaxSize               int64
+	PrewarmEnabled             bool
+	PrewarmHour                int
+	TorznabInstances           []TorznabInstance
+	TorznabMovieCategories     string
+	TorznabShowCategories      string
+	TorznabAnimeCategories     string
+	TorznabServerAPIKey        string
+	ProfilesPrimaryURL         string
+	StreamingSearch            bool
 	LibraryEnabled             bool
 	LibrarySyncEnabled         bool
 	LibrarySyncPlaybackEnabled bool
@@ -115,9 +199,17 @@ type Configuration struct {
 	BufferTimeout              int
 	BufferSize                 int
 	EndBufferSize              int
+	BufferSeconds              int
 	KodiBufferSize             int
 	UploadRateLimit            int
 	DownloadRateLimit          int
+	NightModeEnabled           bool
+	NightModeStart             string
+	NightModeEnd               string
+	NightModeAction            int
+	NightModeDownloadRate      int
+	NightModeUploadRate        int
+	NightModePlayback          bool
 	AutoloadTorrents           bool
 	AutoloadTorrentsPaused     bool
 	LimitAfterBuffering        bool
@@ -137,6 +229,8 @@ type Configuration struct {
 	DisableUTP               bool
 	DisableUPNP              bool
 	EncryptionPolicy         int
+	AnonymousMode            bool
+	CustomUserAgent          string
 	ListenPortMin            int
 	ListenPortMax            int
 	ListenInterfaces         string
@@ -152,6 +246,7 @@ type Configuration struct {
 	LibtorrentProfile        int
 	MagnetTrackers           int
 	MagnetResolveTimeout     int
+	MagnetResolveRetry       bool
 	Scrobble                 bool
 
 	AutoScrapeEnabled        bool
@@ -162,6 +257,20 @@ type Configuration struct {
 	AutoScrapeLimitMovies    int
 	AutoScrapeInterval       int
 
+	RSSEnabled       bool
+	RSSCheckInterval int
+
+	AutoDownloadEnabled  bool
+	AutoDownloadDelay    int
+	AutoDownloadAttempts int
+
+	StreamingAlertsEnabled  bool
+	StreamingAlertsServices string
+
+	NotificationsWebhookURL     string
+	NotificationsTelegramToken  string
+	NotificationsTelegramChatID string
+
 	TraktAuthorized                bool
 	TraktUsername                  string
 	TraktToken                     string
@@ -189,7 +298,12 @@ type Configuration struct {
 	TraktSyncRemovedShows          bool
 	TraktSyncRemovedShowsLocation  int
 	TraktSyncRemovedShowsList      int
+	TraktFilterWatched             bool
+	TraktFilterCollected           bool
+	TraktFilterHidden              bool
 	TraktProgressUnaired           bool
+	TraktProgressExcludeNewsTalk   bool
+	TraktProgressExcludeGenres     string
 	TraktProgressSort              int
 	TraktProgressDateFormat        string
 	TraktProgressColorDate         string
@@ -201,6 +315,9 @@ type Configuration struct {
 	TraktCalendarsColorShow        string
 	TraktCalendarsColorEpisode     string
 	TraktCalendarsColorUnaired     string
+	TraktCalendarsStartDays        int
+	TraktCalendarsDays             int
+	TraktCalendarsICSDays          int
 
 	UpdateFrequency  int
 	UpdateDelay      int
@@ -255,6 +372,12 @@ type Configuration struct {
 	LogLevel        int
 }
 
+// TorznabInstance is a Jackett or Prowlarr endpoint, queried via Torznab API
+type TorznabInstance struct {
+	URL    string
+	APIKey string
+}
+
 // Addon ...
 type Addon struct {
 	ID      string
@@ -514,19 +637,38 @@ func Reload() *Configuration {
 		BufferTimeout:              settings["buffer_timeout"].(int),
 		BufferSize:                 settings["buffer_size"].(int) * 1024 * 1024,
 		EndBufferSize:              settings["end_buffer_size"].(int) * 1024 * 1024,
+		BufferSeconds:              settings["buffer_seconds"].(int),
 		UploadRateLimit:            settings["max_upload_rate"].(int) * 1024,
 		DownloadRateLimit:          settings["max_download_rate"].(int) * 1024,
+		NightModeEnabled:           settings["night_mode_enabled"].(bool),
+		NightModeStart:             strings.TrimSpace(settings["night_mode_start"].(string)),
+		NightModeEnd:               strings.TrimSpace(settings["night_mode_end"].(string)),
+		NightModeAction:            settings["night_mode_action"].(int),
+		NightModeDownloadRate:      settings["night_mode_download_rate"].(int) * 1024,
+		NightModeUploadRate:        settings["night_mode_upload_rate"].(int) * 1024,
+		NightModePlayback:          settings["night_mode_playback"].(bool),
 		AutoloadTorrents:           settings["autoload_torrents"].(bool),
 		AutoloadTorrentsPaused:     settings["autoload_torrents_paused"].(bool),
 		SpoofUserAgent:             settings["spoof_user_agent"].(int),
 		LimitAfterBuffering:        settings["limit_after_buffering"].(bool),
 		DownloadFileStrategy:       settings["download_file_strategy"].(int),
+		ProbeFileAction:            settings["probe_file_action"].(int),
 		KeepDownloading:            settings["keep_downloading"].(int),
 		KeepFilesPlaying:           settings["keep_files_playing"].(int),
 		KeepFilesFinished:          settings["keep_files_finished"].(int),
+		PostPlaybackMovies:         settings["post_playback_movies"].(int),
+		PostPlaybackEpisodes:       settings["post_playback_episodes"].(int),
+		PostPlaybackPartial:        settings["post_playback_partial"].(int),
 		UseTorrentHistory:          settings["use_torrent_history"].(bool),
 		TorrentHistorySize:         settings["torrent_history_size"].(int),
 		UseFanartTv:                settings["use_fanart_tv"].(bool),
+		FanartTvClientKey:          strings.TrimSpace(settings["fanart_tv_client_key"].(string)),
+		FanartTvLanguages:          strings.TrimSpace(settings["fanart_tv_languages"].(string)),
+		FanartTvClearArt:           settings["fanart_tv_clearart"].(bool),
+		FanartTvPreferBanners:      settings["fanart_tv_prefer_banners"].(bool),
+		OMDbAPIKey:                 strings.TrimSpace(settings["omdb_api_key"].(string)),
+		TVDBAPIKey:                 strings.TrimSpace(settings["tvdb_api_key"].(string)),
+		TVDBPin:                    strings.TrimSpace(settings["tvdb_pin"].(string)),
 		DisableBgProgress:          settings["disable_bg_progress"].(bool),
 		DisableBgProgressPlayback:  settings["disable_bg_progress_playback"].(bool),
 		ForceUseTrakt:              settings["force_use_trakt"].(bool),
@@ -535,6 +677,7 @@ func Reload() *Configuration {
 		UseCacheTorrents:           settings["use_cache_torrents"].(bool),
 		CacheSearchDuration:        settings["cache_search_duration"].(int),
 		ResultsPerPage:             settings["results_per_page"].(int),
+		MinVoteCount:               settings["min_vote_count"].(int),
 		ShowFilesWatched:           settings["show_files_watched"].(bool),
 		GreetingEnabled:            settings["greeting_enabled"].(bool),
 		EnableOverlayStatus:        settings["enable_overlay_status"].(bool),
@@ -546,10 +689,12 @@ func Reload() *Configuration {
 		ChooseStreamAutoSearch:     settings["choose_stream_auto_search"].(bool),
 		ForceLinkType:              settings["force_link_type"].(bool),
 		UseOriginalTitle:           settings["use_original_title"].(bool),
+		TitleDisplay:               settings["title_display"].(int),
 		UseAnimeEnTitle:            settings["use_anime_en_title"].(bool),
 		UseLowestReleaseDate:       settings["use_lowest_release_date"].(bool),
 		AddSpecials:                settings["add_specials"].(bool),
 		AddEpisodeNumbers:          settings["add_episode_numbers"].(bool),
+		HideEpisodeStills:          settings["hide_episode_stills"].(bool),
 		ShowUnairedSeasons:         settings["unaired_seasons"].(bool),
 		ShowUnairedEpisodes:        settings["unaired_episodes"].(bool),
 		ShowSeasonsAll:             settings["seasons_all"].(bool),
@@ -559,6 +704,37 @@ func Reload() *Configuration {
 		SmartEpisodeStart:          settings["smart_episode_start"].(bool),
 		SmartEpisodeMatch:          settings["smart_episode_match"].(bool),
 		SmartEpisodeChoose:         settings["smart_episode_choose"].(bool),
+		AutoPlayNext:               settings["autoplay_next"].(bool),
+		AutoPlayNextDelay:          settings["autoplay_next_delay"].(int),
+		AutoPlayNextBinge:          settings["autoplay_next_binge"].(int),
+		IntroOffsetEnabled:         settings["intro_offset_enabled"].(bool),
+		MeteredMode:                settings["metered_mode"].(bool),
+		PlaceholderArt:             settings["placeholder_art"].(bool),
+		SeasonalCollections:        settings["seasonal_collections"].(bool),
+		SeasonalHalloweenList:      settings["seasonal_halloween_list"].(string),
+		SeasonalHalloweenKeywords:  settings["seasonal_halloween_keywords"].(string),
+		SeasonalChristmasList:      settings["seasonal_christmas_list"].(string),
+		SeasonalChristmasKeywords:  settings["seasonal_christmas_keywords"].(string),
+		KidsMode:                   settings["kids_mode"].(bool),
+		KidsModePIN:                settings["kids_mode_pin"].(string),
+		KidsGenres:                 settings["kids_genres"].(string),
+		KidsCertification:          settings["kids_certification"].(string),
+		ExportPath:                 settings["export_path"].(string),
+		MaxActiveDownloads:         settings["max_active_downloads"].(int),
+		FFmpegPath:                 settings["ffmpeg_path"].(string),
+		TranscodeVideo:             settings["transcode_video"].(bool),
+		TranscodeHWAccel:           settings["transcode_hwaccel"].(int),
+		JudderWarning:              settings["judder_warning"].(bool),
+		TextlessBackdrops:          settings["textless_backdrops"].(bool),
+		CacheCompression:           settings["cache_compression"].(bool),
+		PrewarmEnabled:             settings["prewarm_enabled"].(bool),
+		PrewarmHour:                settings["prewarm_hour"].(int),
+		TorznabMovieCategories:     settings["jackett_movie_categories"].(string),
+		TorznabShowCategories:      settings["jackett_show_categories"].(string),
+		TorznabAnimeCategories:     settings["jackett_anime_categories"].(string),
+		TorznabServerAPIKey:        strings.TrimSpace(settings["torznab_server_api_key"].(string)),
+		ProfilesPrimaryURL:         strings.TrimRight(strings.TrimSpace(settings["profiles_primary_url"].(string)), "/"),
+		StreamingSearch:            settings["streaming_search"].(bool),
 		LibraryEnabled:             settings["library_enabled"].(bool),
 		LibrarySyncEnabled:         settings["library_sync_enabled"].(bool),
 		LibrarySyncPlaybackEnabled: settings["library_sync_playback_enabled"].(bool),
@@ -576,6 +752,8 @@ func Reload() *Configuration {
 		DisableUTP:                 settings["disable_utp"].(bool),
 		DisableUPNP:                settings["disable_upnp"].(bool),
 		EncryptionPolicy:           settings["encryption_policy"].(int),
+		AnonymousMode:              settings["anonymous_mode"].(bool),
+		CustomUserAgent:            strings.TrimSpace(settings["custom_user_agent"].(string)),
 		ListenPortMin:              settings["listen_port_min"].(int),
 		ListenPortMax:              settings["listen_port_max"].(int),
 		ListenInterfaces:           settings["listen_interfaces"].(string),
@@ -591,6 +769,7 @@ func Reload() *Configuration {
 		LibtorrentProfile:          settings["libtorrent_profile"].(int),
 		MagnetTrackers:             settings["magnet_trackers"].(int),
 		MagnetResolveTimeout:       settings["magnet_resolve_timeout"].(int),
+		MagnetResolveRetry:         settings["magnet_resolve_retry"].(bool),
 		ConnectionsLimit:           settings["connections_limit"].(int),
 		ConnTrackerLimit:           settings["conntracker_limit"].(int),
 		ConnTrackerLimitAuto:       settings["conntracker_limit_auto"].(bool),
@@ -605,6 +784,20 @@ func Reload() *Configuration {
 		AutoScrapeLimitMovies:    settings["autoscrape_limit_movies"].(int),
 		AutoScrapeInterval:       settings["autoscrape_interval"].(int),
 
+		RSSEnabled:       settings["rss_enabled"].(bool),
+		RSSCheckInterval: settings["rss_check_interval"].(int),
+
+		AutoDownloadEnabled:  settings["autodownload_enabled"].(bool),
+		AutoDownloadDelay:    settings["autodownload_delay"].(int),
+		AutoDownloadAttempts: settings["autodownload_attempts"].(int),
+
+		StreamingAlertsEnabled:  settings["streaming_alerts_enabled"].(bool),
+		StreamingAlertsServices: settings["streaming_alerts_services"].(string),
+
+		NotificationsWebhookURL:     settings["notifications_webhook_url"].(string),
+		NotificationsTelegramToken:  settings["notifications_telegram_token"].(string),
+		NotificationsTelegramChatID: settings["notifications_telegram_chat_id"].(string),
+
 		TraktUsername:                  settings["trakt_username"].(string),
 		TraktToken:                     settings["trakt_token"].(string),
 		TraktRefreshToken:              settings["trakt_refresh_token"].(string),
@@ -631,7 +824,12 @@ func Reload() *Configuration {
 		TraktSyncRemovedShows:          settings["trakt_sync_removed_shows"].(bool),
 		TraktSyncRemovedShowsLocation:  settings["trakt_sync_removed_shows_location"].(int),
 		TraktSyncRemovedShowsList:      settings["trakt_sync_removed_shows_list"].(int),
+		TraktFilterWatched:             settings["trakt_filter_watched"].(bool),
+		TraktFilterCollected:           settings["trakt_filter_collected"].(bool),
+		TraktFilterHidden:              settings["trakt_filter_hidden"].(bool),
 		TraktProgressUnaired:           settings["trakt_progress_unaired"].(bool),
+		TraktProgressExcludeNewsTalk:   settings["trakt_progress_exclude_news_talk"].(bool),
+		TraktProgressExcludeGenres:     strings.TrimSpace(settings["trakt_progress_exclude_genres"].(string)),
 		TraktProgressSort:              settings["trakt_progress_sort"].(int),
 		TraktProgressDateFormat:        settings["trakt_progress_date_format"].(string),
 		TraktProgressColorDate:         settings["trakt_progress_color_date"].(string),
@@ -643,6 +841,9 @@ func Reload() *Configuration {
 		TraktCalendarsColorShow:        settings["trakt_calendars_color_show"].(string),
 		TraktCalendarsColorEpisode:     settings["trakt_calendars_color_episode"].(string),
 		TraktCalendarsColorUnaired:     settings["trakt_calendars_color_unaired"].(string),
+		TraktCalendarsStartDays:        settings["trakt_calendars_start_days"].(int),
+		TraktCalendarsDays:             settings["trakt_calendars_days"].(int),
+		TraktCalendarsICSDays:          settings["trakt_calendars_ics_days"].(int),
 
 		UpdateFrequency:  settings["library_update_frequency"].(int),
 		UpdateDelay:      settings["library_update_delay"].(int),
@@ -735,6 +936,32 @@ func Reload() *Configuration {
 		}
 	}
 
+	// Cache expiration of namespaces, zero keeps built-in defaults
+	newConfig.CacheTraktListsTTL = boundedDuration(settings["cache_trakt_lists_ttl"].(int), maxCacheTraktListsMinutes, time.Minute)
+	newConfig.CacheTMDBTTL = boundedDuration(settings["cache_tmdb_ttl"].(int), maxCacheTMDBHours, time.Hour)
+	newConfig.CacheArtworkTTL = boundedDuration(settings["cache_artwork_ttl"].(int), maxCacheArtworkHours, time.Hour)
+
+	// Country, set in settings, is used instead of Kodi region for charts, certifications and watch providers
+	if country := strings.ToUpper(strings.TrimSpace(settings["country"].(string))); len(country) == 2 {
+		newConfig.Region = country
+	}
+
+	// In-memory cache size, zero disables it
+	newConfig.CacheMemorySize = settings["cache_memory_size"].(int) * 1024 * 1024
+	if newConfig.CacheMemorySize < 0 {
+		newConfig.CacheMemorySize = 0
+	} else if newConfig.CacheMemorySize > maxCacheMemorySize {
+		newConfig.CacheMemorySize = maxCacheMemorySize
+	}
+
+	// Cache database size, over which cached values are evicted, zero disables eviction
+	newConfig.CacheMaxSize = int64(settings["cache_max_size"].(int)) * 1024 * 1024
+	if newConfig.CacheMaxSize < 0 {
+		newConfig.CacheMaxSize = 0
+	} else if newConfig.CacheMaxSize > maxCacheDatabaseSize {
+		newConfig.CacheMaxSize = maxCacheDatabaseSize
+	}
+
 	// Set default Trakt Frequency
 	if newConfig.TraktToken != "" && newConfig.TraktSyncFrequencyMin == 0 {
 		newConfig.TraktSyncFrequencyMin = defaultTraktSyncFrequencyMin
@@ -778,6 +1005,57 @@ func Reload() *Configuration {
 		newConfig.StrmLanguage = newConfig.Language
 	}
 
+	// Read list of allowed original languages, separated by comma
+	for _, lang := range strings.Split(settings["allowed_languages"].(string), ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			newConfig.AllowedLanguages = append(newConfig.AllowedLanguages, lang)
+		}
+	}
+
+	// Read preferred languages of audio and subtitles streams, separated by comma
+	for _, lang := range strings.Split(settings["preferred_audio_languages"].(string), ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			newConfig.PreferredAudioLanguages = append(newConfig.PreferredAudioLanguages, lang)
+		}
+	}
+	for _, lang := range strings.Split(settings["preferred_subtitle_languages"].(string), ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			newConfig.PreferredSubtitleLanguages = append(newConfig.PreferredSubtitleLanguages, lang)
+		}
+	}
+
+	// Read Trakt lists, allowed in kids mode, as "user/listId" separated by comma
+	for _, list := range strings.Split(settings["kids_lists"].(string), ",") {
+		if list = strings.TrimSpace(list); list != "" {
+			newConfig.KidsLists = append(newConfig.KidsLists, list)
+		}
+	}
+
+	// Read Jackett/Prowlarr instances, only those with URL are used
+	for i := 1; i <= MaxTorznabInstances; i++ {
+		instance := TorznabInstance{
+			URL:    strings.TrimSpace(settings[fmt.Sprintf("jackett_url_%d", i)].(string)),
+			APIKey: strings.TrimSpace(settings[fmt.Sprintf("jackett_api_key_%d", i)].(string)),
+		}
+		if instance.URL != "" {
+			newConfig.TorznabInstances = append(newConfig.TorznabInstances, instance)
+		}
+	}
+
+	if newConfig.ExportPath != "" {
+		newConfig.ExportPath = TranslatePath(newConfig.ExportPath)
+	}
+	if newConfig.FFmpegPath != "" {
+		newConfig.FFmpegPath = TranslatePath(newConfig.FFmpegPath)
+	}
+
+	// Kids should not be asked to choose between torrents
+	if newConfig.KidsMode {
+		newConfig.ChooseStreamAutoMovie = true
+		newConfig.ChooseStreamAutoShow = true
+		newConfig.ChooseStreamAutoSearch = true
+	}
+
 	if newConfig.SessionSave == 0 {
 		newConfig.SessionSave = 10
 	}
@@ -806,6 +1084,25 @@ func Reload() *Configuration {
 	return config
 }
 
+// SetMeteredMode changes metered mode of current configuration without waiting for settings reload
+func SetMeteredMode(enabled bool) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	config.MeteredMode = enabled
+}
+
+// SetTraktAccount changes Trakt authorization of current configuration without waiting for settings reload
+func SetTraktAccount(username, token, refreshToken string, expiry int) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	config.TraktUsername = username
+	config.TraktToken = token
+	config.TraktRefreshToken = refreshToken
+	config.TraktTokenExpiry = expiry
+}
+
 // AddonIcon ...
 func AddonIcon() string {
 	return filepath.Join(Get().Info.Path, "icon.png")
@@ -952,6 +1249,16 @@ func findExistingPath(paths []string, addon string) string {
 	return ""
 }
 
+// boundedDuration converts setting value into duration, limited to [0, max] units
+func boundedDuration(value int, max int, unit time.Duration) time.Duration {
+	if value < 0 {
+		value = 0
+	} else if value > max {
+		value = max
+	}
+	return time.Duration(value) * unit
+}
+
 func getKodiBufferSize() int {
 	xmlFile, err := os.Open(filepath.Join(xbmc.TranslatePath("special://userdata"), "advancedsettings.xml"))
 	if err != nil {
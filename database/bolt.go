@@ -8,11 +8,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/boltdb/bolt"
+	"github.com/dustin/go-humanize"
 
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/util"
@@ -112,6 +114,8 @@ func (d *BoltDatabase) BucketExists(bucket []byte) (res bool) {
 
 // RecreateBucket ...
 func (d *BoltDatabase) RecreateBucket(bucket []byte) error {
+	defer d.deleted(bucket, nil)
+
 	return d.db.Update(func(tx *bolt.Tx) error {
 		errDrop := tx.DeleteBucket(bucket)
 		if errDrop != nil {
@@ -123,16 +127,31 @@ func (d *BoltDatabase) RecreateBucket(bucket []byte) error {
 	})
 }
 
+// OnDelete sets a callback, that is called after keys are deleted from a bucket,
+// keys are nil, if the whole bucket is dropped
+func (d *BoltDatabase) OnDelete(callback func(bucket []byte, keys []string)) {
+	d.onDelete = callback
+}
+
+func (d *BoltDatabase) deleted(bucket []byte, keys []string) {
+	if d.onDelete != nil {
+		d.onDelete(bucket, keys)
+	}
+}
+
 // MaintenanceRefreshHandler ...
 func (d *BoltDatabase) MaintenanceRefreshHandler() {
 	backupPath := filepath.Join(config.Get().Info.Profile, d.backupFileName)
 
 	d.CreateBackup(backupPath)
 	d.CacheCleanup()
+	d.CacheEvict(config.Get().CacheMaxSize)
 
 	tickerBackup := time.NewTicker(2 * time.Hour)
+	tickerEvict := time.NewTicker(30 * time.Minute)
 
 	defer tickerBackup.Stop()
+	defer tickerEvict.Stop()
 	defer close(d.quit)
 
 	for {
@@ -141,6 +160,8 @@ func (d *BoltDatabase) MaintenanceRefreshHandler() {
 			go func() {
 				d.CreateBackup(backupPath)
 			}()
+		case <-tickerEvict.C:
+			go d.CacheEvict(config.Get().CacheMaxSize)
 			// case <-tickerCache.C:
 			// 	go d.CacheCleanup()
 		case <-d.quit:
@@ -221,7 +242,7 @@ func (d *BoltDatabase) CacheCleanup() {
 		toRemove := []string{}
 		d.ForEach(bucket, func(key []byte, value []byte) error {
 			expire, _ := ParseCacheItem(value)
-			if (expire > 0 && expire < now) || expire == 0 {
+			if (expire > 0 && expire < now-CacheStaleKeep) || expire == 0 {
 				toRemove = append(toRemove, string(key))
 			}
 
@@ -235,6 +256,60 @@ func (d *BoltDatabase) CacheCleanup() {
 	}
 }
 
+// CacheEvict removes items, that expire soonest, while size of cached items is over the limit,
+// so that cache database does not grow unbounded. Eviction stops a bit below the limit,
+// so that it does not run again on every next item, that is stored.
+func (d *BoltDatabase) CacheEvict(maxSize int64) {
+	if maxSize <= 0 {
+		return
+	}
+
+	defer perf.ScopeTimer()()
+
+	type cacheEntry struct {
+		key    string
+		size   int64
+		expire int64
+	}
+
+	for _, bucket := range CacheBuckets {
+		if !d.BucketExists(bucket) {
+			continue
+		}
+
+		var total int64
+		entries := []cacheEntry{}
+		d.ForEach(bucket, func(key []byte, value []byte) error {
+			expire, _ := ParseCacheItem(value)
+			size := int64(len(key) + len(value))
+			entries = append(entries, cacheEntry{string(key), size, expire})
+			total += size
+
+			return nil
+		})
+		if total <= maxSize {
+			continue
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].expire < entries[j].expire
+		})
+
+		target := maxSize / 10 * 9
+		toRemove := []string{}
+		for _, e := range entries {
+			if total <= target {
+				break
+			}
+			toRemove = append(toRemove, e.key)
+			total -= e.size
+		}
+
+		log.Infof("Cache is over %s, evicting %d items", humanize.Bytes(uint64(maxSize)), len(toRemove))
+		d.BatchDelete(bucket, toRemove)
+	}
+}
+
 // DeleteWithPrefix ...
 func (d *BoltDatabase) DeleteWithPrefix(bucket []byte, prefix []byte) {
 	toRemove := []string{}
@@ -490,6 +565,8 @@ func (d *BoltDatabase) BatchSetObject(bucket []byte, objects map[string]interfac
 
 // Delete ...
 func (d *BoltDatabase) Delete(bucket []byte, key string) error {
+	defer d.deleted(bucket, []string{key})
+
 	return d.db.Update(func(tx *bolt.Tx) error {
 		return tx.Bucket(bucket).Delete([]byte(key))
 	})
@@ -497,6 +574,8 @@ func (d *BoltDatabase) Delete(bucket []byte, key string) error {
 
 // BatchDelete ...
 func (d *BoltDatabase) BatchDelete(bucket []byte, keys []string) error {
+	defer d.deleted(bucket, keys)
+
 	return d.db.Batch(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucket)
 		for _, key := range keys {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	bolt "go.etcd.io/bbolt"
 
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/util"
 )
 
 // GetStorm returns common database
@@ -372,3 +374,842 @@ func (d *StormDatabase) AddTorrentHistory(infoHash, name string, b []byte) {
 	}
 	d.db.ReIndex(&TorrentHistory{})
 }
+
+// Rewatch handlers
+
+// GetRewatch returns rewatch cursor for a show by its Trakt ID, or nil if show is not rewatched
+func (d *StormDatabase) GetRewatch(showID int) *RewatchItem {
+	defer perf.ScopeTimer()()
+
+	item := &RewatchItem{}
+	if err := d.db.One("ShowID", showID, item); err != nil {
+		return nil
+	}
+
+	return item
+}
+
+// StartRewatch starts rewatch for a show from the first episode
+func (d *StormDatabase) StartRewatch(showID int) error {
+	defer perf.ScopeTimer()()
+
+	item := RewatchItem{
+		ShowID:    showID,
+		StartedAt: time.Now().UTC(),
+		Season:    1,
+		Episode:   1,
+	}
+
+	return d.db.Save(&item)
+}
+
+// UpdateRewatchCursor saves next episode to watch for a rewatched show
+func (d *StormDatabase) UpdateRewatchCursor(showID, season, episode int) error {
+	defer perf.ScopeTimer()()
+
+	item := RewatchItem{}
+	if err := d.db.One("ShowID", showID, &item); err != nil {
+		return err
+	}
+
+	if item.Season == season && item.Episode == episode {
+		return nil
+	}
+
+	item.Season = season
+	item.Episode = episode
+	return d.db.Update(&item)
+}
+
+// StopRewatch removes rewatch cursor for a show
+func (d *StormDatabase) StopRewatch(showID int) error {
+	defer perf.ScopeTimer()()
+
+	item := RewatchItem{}
+	if err := d.db.One("ShowID", showID, &item); err != nil {
+		return err
+	}
+
+	return d.db.DeleteStruct(&item)
+}
+
+// Continue watching handlers
+
+// DismissProgress keeps show out of continue watching, until its next episode changes
+func (d *StormDatabase) DismissProgress(showID, season, episode int) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&ProgressDismissedItem{
+		ShowID:  showID,
+		Season:  season,
+		Episode: episode,
+		Added:   time.Now().UTC(),
+	})
+}
+
+// UndismissProgress returns show back to continue watching
+func (d *StormDatabase) UndismissProgress(showID int) error {
+	defer perf.ScopeTimer()()
+
+	item := ProgressDismissedItem{}
+	if err := d.db.One("ShowID", showID, &item); err != nil {
+		return err
+	}
+
+	return d.db.DeleteStruct(&item)
+}
+
+// GetProgressDismissed returns shows, dismissed from continue watching, by show ID
+func (d *StormDatabase) GetProgressDismissed() map[int]*ProgressDismissedItem {
+	defer perf.ScopeTimer()()
+
+	var items []ProgressDismissedItem
+	d.db.All(&items)
+
+	ret := map[int]*ProgressDismissedItem{}
+	for i := range items {
+		ret[items[i].ShowID] = &items[i]
+	}
+	return ret
+}
+
+// Show intro handlers
+
+// GetShowIntro returns intro information for a show, or nil if nothing is stored
+func (d *StormDatabase) GetShowIntro(showID int) *ShowIntro {
+	defer perf.ScopeTimer()()
+
+	item := &ShowIntro{}
+	if err := d.db.One("ShowID", showID, item); err != nil {
+		return nil
+	}
+
+	return item
+}
+
+// SetShowIntroOffset saves configured start offset for a show
+func (d *StormDatabase) SetShowIntroOffset(showID, offset int) error {
+	defer perf.ScopeTimer()()
+
+	item := ShowIntro{ShowID: showID}
+	d.db.One("ShowID", showID, &item)
+
+	item.Offset = offset
+	return d.db.Save(&item)
+}
+
+// AddShowIntroSample updates average intro length for a show with new measured length
+func (d *StormDatabase) AddShowIntroSample(showID int, length float64) error {
+	defer perf.ScopeTimer()()
+
+	item := ShowIntro{ShowID: showID}
+	d.db.One("ShowID", showID, &item)
+
+	item.AverageLength = (item.AverageLength*float64(item.Samples) + length) / float64(item.Samples+1)
+	item.Samples++
+	return d.db.Save(&item)
+}
+
+// IsSpoilerProtected returns true if unwatched episodes of a show should not reveal details
+func (d *StormDatabase) IsSpoilerProtected(showID int) bool {
+	defer perf.ScopeTimer()()
+
+	var item SpoilerItem
+	return d.db.One("ShowID", showID, &item) == nil
+}
+
+// SetSpoilerProtection enables or disables spoiler protection for a show
+func (d *StormDatabase) SetSpoilerProtection(showID int, enabled bool) error {
+	defer perf.ScopeTimer()()
+
+	item := SpoilerItem{ShowID: showID}
+	if enabled {
+		return d.db.Save(&item)
+	}
+
+	if err := d.db.One("ShowID", showID, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// Synced lists handlers
+
+// GetSyncedLists returns public Trakt lists, enrolled into library sync
+func (d *StormDatabase) GetSyncedLists() []SyncedListItem {
+	defer perf.ScopeTimer()()
+
+	var items []SyncedListItem
+	d.db.All(&items)
+	return items
+}
+
+// IsSyncedList returns true if Trakt list is enrolled into library sync
+func (d *StormDatabase) IsSyncedList(listID int) bool {
+	defer perf.ScopeTimer()()
+
+	var item SyncedListItem
+	return d.db.One("ListID", listID, &item) == nil
+}
+
+// SetSyncedList enrolls Trakt list into library sync, or removes it from sync
+func (d *StormDatabase) SetSyncedList(listID int, user, name string, enabled bool) error {
+	defer perf.ScopeTimer()()
+
+	item := SyncedListItem{ListID: listID, User: user, Name: name}
+	if enabled {
+		return d.db.Save(&item)
+	}
+
+	if err := d.db.One("ListID", listID, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// GetListSort returns local sort order override of Trakt list, or nil
+func (d *StormDatabase) GetListSort(listID int) *ListSortItem {
+	defer perf.ScopeTimer()()
+
+	var item ListSortItem
+	if err := d.db.One("ListID", listID, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// SetListSort overrides sort order of Trakt list, empty sortBy removes override
+func (d *StormDatabase) SetListSort(listID int, sortBy, sortHow string) error {
+	defer perf.ScopeTimer()()
+
+	item := ListSortItem{ListID: listID, SortBy: sortBy, SortHow: sortHow}
+	if sortBy != "" {
+		return d.db.Save(&item)
+	}
+
+	if err := d.db.One("ListID", listID, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// Download queue handlers
+
+// GetQueueItems returns download queue state of torrents, by infohash
+func (d *StormDatabase) GetQueueItems() map[string]*QueueItem {
+	defer perf.ScopeTimer()()
+
+	var items []*QueueItem
+	d.db.All(&items)
+
+	ret := map[string]*QueueItem{}
+	for _, item := range items {
+		ret[item.InfoHash] = item
+	}
+	return ret
+}
+
+// SetQueuePriorities saves order of torrents in download queue
+func (d *StormDatabase) SetQueuePriorities(hashes []string) error {
+	defer perf.ScopeTimer()()
+
+	for i, hash := range hashes {
+		item := QueueItem{InfoHash: hash}
+		d.db.One("InfoHash", hash, &item)
+
+		item.Priority = i
+		if err := d.db.Save(&item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetQueued marks torrent as paused by download queue, or started by it
+func (d *StormDatabase) SetQueued(infoHash string, queued bool) error {
+	defer perf.ScopeTimer()()
+
+	item := QueueItem{InfoHash: infoHash, Priority: -1}
+	if err := d.db.One("InfoHash", infoHash, &item); err != nil && !queued {
+		return nil
+	}
+
+	item.Queued = queued
+	return d.db.Save(&item)
+}
+
+// DeleteQueueItem removes torrent from download queue
+func (d *StormDatabase) DeleteQueueItem(infoHash string) error {
+	defer perf.ScopeTimer()()
+
+	item := QueueItem{}
+	if err := d.db.One("InfoHash", infoHash, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// GetProviderSettings returns search limits of a provider, or defaults if not configured
+func (d *StormDatabase) GetProviderSettings(id string) *ProviderSettings {
+	defer perf.ScopeTimer()()
+
+	item := &ProviderSettings{ID: id}
+	if err := d.db.One("ID", id, item); err != nil {
+		return &ProviderSettings{ID: id}
+	}
+	return item
+}
+
+// SetProviderSettings saves search limits of a provider
+func (d *StormDatabase) SetProviderSettings(item *ProviderSettings) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(item)
+}
+
+// GetLocalFile returns downloaded file of media item, or nil
+func (d *StormDatabase) GetLocalFile(contentType string, tmdbID int) *LocalFile {
+	defer perf.ScopeTimer()()
+
+	var f LocalFile
+	if err := d.db.One("ID", fmt.Sprintf("%s_%d", contentType, tmdbID), &f); err != nil {
+		return nil
+	}
+	return &f
+}
+
+// SetLocalFile saves downloaded file of media item
+func (d *StormDatabase) SetLocalFile(contentType string, tmdbID int, f *LocalFile) error {
+	defer perf.ScopeTimer()()
+
+	f.ID = fmt.Sprintf("%s_%d", contentType, tmdbID)
+	return d.db.Save(f)
+}
+
+// DeleteLocalFile forgets downloaded file of media item
+func (d *StormDatabase) DeleteLocalFile(f *LocalFile) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.DeleteStruct(f)
+}
+
+// AddScrobbleItem queues Trakt playback event, replacing queued event of the same item
+func (d *StormDatabase) AddScrobbleItem(item *ScrobbleItem) error {
+	defer perf.ScopeTimer()()
+
+	item.ID = fmt.Sprintf("%s_%d", item.ContentType, item.TMDBID)
+	return d.db.Save(item)
+}
+
+// GetScrobbleItems returns queued Trakt playback events, oldest first
+func (d *StormDatabase) GetScrobbleItems() []ScrobbleItem {
+	defer perf.ScopeTimer()()
+
+	var items []ScrobbleItem
+	d.db.Select().OrderBy("Created").Find(&items)
+	return items
+}
+
+// UpdateScrobbleItem saves retry state of queued Trakt playback event
+func (d *StormDatabase) UpdateScrobbleItem(item *ScrobbleItem) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(item)
+}
+
+// DeleteScrobbleItem removes Trakt playback event from the queue
+func (d *StormDatabase) DeleteScrobbleItem(item *ScrobbleItem) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.DeleteStruct(item)
+}
+
+// AddNotInterested marks media item as not interesting for the user
+func (d *StormDatabase) AddNotInterested(contentType string, tmdbID int) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&NotInterestedItem{
+		ID:          fmt.Sprintf("%s_%d", contentType, tmdbID),
+		ContentType: contentType,
+		TMDBID:      tmdbID,
+		Added:       time.Now(),
+	})
+}
+
+// GetNotInterested returns TMDB ids of items of this content type, marked as not interesting
+func (d *StormDatabase) GetNotInterested(contentType string) map[int]bool {
+	defer perf.ScopeTimer()()
+
+	var items []NotInterestedItem
+	d.db.Select(q.Eq("ContentType", contentType)).Find(&items)
+
+	ret := map[int]bool{}
+	for _, item := range items {
+		ret[item.TMDBID] = true
+	}
+	return ret
+}
+
+// GetSettingsProfiles returns saved settings profiles, ordered by name
+func (d *StormDatabase) GetSettingsProfiles() []SettingsProfile {
+	defer perf.ScopeTimer()()
+
+	var items []SettingsProfile
+	d.db.Select().OrderBy("Name").Find(&items)
+	return items
+}
+
+// GetSettingsProfile returns settings profile by name, or nil
+func (d *StormDatabase) GetSettingsProfile(name string) *SettingsProfile {
+	defer perf.ScopeTimer()()
+
+	var p SettingsProfile
+	if err := d.db.One("Name", name, &p); err != nil {
+		return nil
+	}
+	return &p
+}
+
+// SaveSettingsProfile saves settings profile, replacing profile with the same name
+func (d *StormDatabase) SaveSettingsProfile(p *SettingsProfile) error {
+	defer perf.ScopeTimer()()
+
+	p.Updated = time.Now()
+	return d.db.Save(p)
+}
+
+// DeleteSettingsProfile removes settings profile by name
+func (d *StormDatabase) DeleteSettingsProfile(name string) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.DeleteStruct(&SettingsProfile{Name: name})
+}
+
+// AddJournalEntry saves library mutation to the journal
+func (d *StormDatabase) AddJournalEntry(entry *JournalEntry) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(entry)
+}
+
+// GetJournalEntries returns latest journal entries, newest first,
+// only entries of given TMDB ID, if it is set
+func (d *StormDatabase) GetJournalEntries(tmdbID int, limit int) []JournalEntry {
+	defer perf.ScopeTimer()()
+
+	matchers := []q.Matcher{}
+	if tmdbID != 0 {
+		matchers = append(matchers, q.Eq("TMDBID", tmdbID))
+	}
+
+	var entries []JournalEntry
+	d.db.Select(matchers...).OrderBy("ID").Reverse().Limit(limit).Find(&entries)
+	return entries
+}
+
+// CleanupJournal removes journal entries, recorded before given time
+func (d *StormDatabase) CleanupJournal(before time.Time) error {
+	defer perf.ScopeTimer()()
+
+	if err := d.db.Select(q.Lt("Time", before)).Delete(&JournalEntry{}); err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// GetShowTMDBMapping returns TMDB ID, recorded for a Trakt show, or zero if unknown
+func (d *StormDatabase) GetShowTMDBMapping(traktID int) int {
+	defer perf.ScopeTimer()()
+
+	item := ShowIDMapping{}
+	if err := d.db.One("TraktID", traktID, &item); err != nil {
+		return 0
+	}
+	return item.TMDBID
+}
+
+// SetShowTMDBMapping records TMDB ID of a Trakt show
+func (d *StormDatabase) SetShowTMDBMapping(traktID int, tmdbID int) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&ShowIDMapping{TraktID: traktID, TMDBID: tmdbID})
+}
+
+// Show progress handlers
+
+// GetShowProgress returns stored progress of a show, or nil if nothing is stored
+func (d *StormDatabase) GetShowProgress(traktID int) *ShowProgress {
+	defer perf.ScopeTimer()()
+
+	item := &ShowProgress{}
+	if err := d.db.One("TraktID", traktID, item); err != nil {
+		return nil
+	}
+
+	return item
+}
+
+// SaveShowProgress stores fetched progress of a show
+func (d *StormDatabase) SaveShowProgress(item *ShowProgress) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(item)
+}
+
+// Trakt profile handlers
+
+// GetTraktProfile returns stored Trakt authorization of a Kodi profile, or nil if nothing is stored
+func (d *StormDatabase) GetTraktProfile(profile string) *TraktProfile {
+	defer perf.ScopeTimer()()
+
+	item := &TraktProfile{}
+	if err := d.db.One("Profile", profile, item); err != nil {
+		return nil
+	}
+
+	return item
+}
+
+// SaveTraktProfile stores Trakt authorization of a Kodi profile
+func (d *StormDatabase) SaveTraktProfile(item *TraktProfile) error {
+	defer perf.ScopeTimer()()
+
+	item.Updated = time.Now()
+	return d.db.Save(item)
+}
+
+// Discover preset handlers
+
+// GetDiscoverPresets returns discover presets of a media type
+func (d *StormDatabase) GetDiscoverPresets(mediaType string) []DiscoverPreset {
+	defer perf.ScopeTimer()()
+
+	var items []DiscoverPreset
+	d.db.Select(q.Eq("MediaType", mediaType)).Find(&items)
+	return items
+}
+
+// GetDiscoverPreset returns discover preset by ID, or nil
+func (d *StormDatabase) GetDiscoverPreset(id int) *DiscoverPreset {
+	defer perf.ScopeTimer()()
+
+	var item DiscoverPreset
+	if err := d.db.One("ID", id, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// SaveDiscoverPreset adds new discover preset, or updates existing one
+func (d *StormDatabase) SaveDiscoverPreset(item *DiscoverPreset) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(item)
+}
+
+// DeleteDiscoverPreset removes discover preset
+func (d *StormDatabase) DeleteDiscoverPreset(id int) error {
+	defer perf.ScopeTimer()()
+
+	var item DiscoverPreset
+	if err := d.db.One("ID", id, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// RSS handlers
+
+// GetRSSFeeds returns all configured RSS feeds
+func (d *StormDatabase) GetRSSFeeds() []RSSFeed {
+	defer perf.ScopeTimer()()
+
+	var items []RSSFeed
+	d.db.All(&items)
+	return items
+}
+
+// GetRSSFeed returns RSS feed by ID, or nil
+func (d *StormDatabase) GetRSSFeed(id int) *RSSFeed {
+	defer perf.ScopeTimer()()
+
+	var item RSSFeed
+	if err := d.db.One("ID", id, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// SaveRSSFeed adds new RSS feed, or updates existing one
+func (d *StormDatabase) SaveRSSFeed(item *RSSFeed) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(item)
+}
+
+// DeleteRSSFeed removes RSS feed with its processed items
+func (d *StormDatabase) DeleteRSSFeed(id int) error {
+	defer perf.ScopeTimer()()
+
+	var items []RSSItem
+	d.db.Find("FeedID", id, &items)
+	for i := range items {
+		d.db.DeleteStruct(&items[i])
+	}
+
+	var item RSSFeed
+	if err := d.db.One("ID", id, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// IsRSSItemProcessed checks if RSS item was already processed
+func (d *StormDatabase) IsRSSItemProcessed(feedID int, guid string) bool {
+	defer perf.ScopeTimer()()
+
+	var item RSSItem
+	return d.db.One("ID", fmt.Sprintf("%d.%s", feedID, guid), &item) == nil
+}
+
+// AddRSSItem marks RSS item as processed
+func (d *StormDatabase) AddRSSItem(feedID int, guid, title, infoHash string) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&RSSItem{
+		ID:       fmt.Sprintf("%d.%s", feedID, guid),
+		FeedID:   feedID,
+		Title:    title,
+		InfoHash: infoHash,
+		Added:    time.Now(),
+	})
+}
+
+// GetRSSItems returns processed items of a feed
+func (d *StormDatabase) GetRSSItems(feedID int) []RSSItem {
+	defer perf.ScopeTimer()()
+
+	var items []RSSItem
+	d.db.Find("FeedID", feedID, &items)
+	return items
+}
+
+// Auto-download handlers
+
+// GetAutoDownloadShows returns all shows, marked for auto-download
+func (d *StormDatabase) GetAutoDownloadShows() []AutoDownloadShow {
+	defer perf.ScopeTimer()()
+
+	var items []AutoDownloadShow
+	d.db.All(&items)
+	return items
+}
+
+// IsAutoDownloadShow checks if show is marked for auto-download
+func (d *StormDatabase) IsAutoDownloadShow(showID int) bool {
+	defer perf.ScopeTimer()()
+
+	var item AutoDownloadShow
+	return d.db.One("ShowID", showID, &item) == nil
+}
+
+// SetAutoDownloadShow marks or unmarks show for auto-download,
+// unmarking also removes episodes, which were not added yet
+func (d *StormDatabase) SetAutoDownloadShow(showID int, enabled bool) error {
+	defer perf.ScopeTimer()()
+
+	if enabled {
+		return d.db.Save(&AutoDownloadShow{
+			ShowID: showID,
+			Added:  time.Now(),
+		})
+	}
+
+	var episodes []AutoDownloadEpisode
+	d.db.Find("ShowID", showID, &episodes)
+	for i := range episodes {
+		if episodes[i].State != AutoDownloadAdded {
+			d.db.DeleteStruct(&episodes[i])
+		}
+	}
+
+	var item AutoDownloadShow
+	if err := d.db.One("ShowID", showID, &item); err != nil {
+		return nil
+	}
+	return d.db.DeleteStruct(&item)
+}
+
+// GetAutoDownloadEpisodes returns all tracked episodes of auto-downloaded shows
+func (d *StormDatabase) GetAutoDownloadEpisodes() []AutoDownloadEpisode {
+	defer perf.ScopeTimer()()
+
+	var items []AutoDownloadEpisode
+	d.db.All(&items)
+	return items
+}
+
+// GetAutoDownloadEpisode returns tracked episode, or nil
+func (d *StormDatabase) GetAutoDownloadEpisode(showID, season, episode int) *AutoDownloadEpisode {
+	defer perf.ScopeTimer()()
+
+	var item AutoDownloadEpisode
+	if err := d.db.One("ID", fmt.Sprintf("%d.%d.%d", showID, season, episode), &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// SaveAutoDownloadEpisode adds new tracked episode, or updates existing one
+func (d *StormDatabase) SaveAutoDownloadEpisode(item *AutoDownloadEpisode) error {
+	defer perf.ScopeTimer()()
+
+	item.ID = fmt.Sprintf("%d.%d.%d", item.ShowID, item.Season, item.Episode)
+	return d.db.Save(item)
+}
+
+// Streaming state handlers
+
+// GetStreamingState returns last known streaming services of an item, or nil
+func (d *StormDatabase) GetStreamingState(mediaType string, tmdbID int) *StreamingState {
+	defer perf.ScopeTimer()()
+
+	var item StreamingState
+	if err := d.db.One("ID", fmt.Sprintf("%s.%d", mediaType, tmdbID), &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// SaveStreamingState stores streaming services of an item
+func (d *StormDatabase) SaveStreamingState(item *StreamingState) error {
+	defer perf.ScopeTimer()()
+
+	item.ID = fmt.Sprintf("%s.%d", item.MediaType, item.TMDBID)
+	return d.db.Save(item)
+}
+
+// GetStreamingChanges returns items, which streaming services were changed since given time, latest first
+func (d *StormDatabase) GetStreamingChanges(since time.Time) []StreamingState {
+	defer perf.ScopeTimer()()
+
+	var items []StreamingState
+	d.db.Range("Changed", since, time.Now(), &items, storm.Reverse())
+	return items
+}
+
+// Notification preference handlers
+
+// GetNotificationChannels returns configured channels of an event, or def if not configured
+func (d *StormDatabase) GetNotificationChannels(event string, def int) int {
+	defer perf.ScopeTimer()()
+
+	var item NotificationPreference
+	if err := d.db.One("Event", event, &item); err != nil {
+		return def
+	}
+	return item.Channels
+}
+
+// SetNotificationChannels stores channels of an event
+func (d *StormDatabase) SetNotificationChannels(event string, channels int) error {
+	defer perf.ScopeTimer()()
+
+	return d.db.Save(&NotificationPreference{
+		Event:    event,
+		Channels: channels,
+	})
+}
+
+// Tag handlers
+
+// GetItemTags returns tags and note for an item, or nil if nothing is stored
+func (d *StormDatabase) GetItemTags(mediaType string, tmdbID int) *TagItem {
+	defer perf.ScopeTimer()()
+
+	item := &TagItem{}
+	if err := d.db.One("ID", fmt.Sprintf("%s.%d", mediaType, tmdbID), item); err != nil {
+		return nil
+	}
+
+	return item
+}
+
+// SetItemTags replaces tags of an item
+func (d *StormDatabase) SetItemTags(mediaType string, tmdbID int, tags []string) error {
+	defer perf.ScopeTimer()()
+
+	item := d.getOrNewTagItem(mediaType, tmdbID)
+	item.Tags = tags
+	return d.saveTagItem(item)
+}
+
+// SetItemNote replaces note of an item
+func (d *StormDatabase) SetItemNote(mediaType string, tmdbID int, note string) error {
+	defer perf.ScopeTimer()()
+
+	item := d.getOrNewTagItem(mediaType, tmdbID)
+	item.Note = note
+	return d.saveTagItem(item)
+}
+
+// GetTags returns all tags, used for items of this media type
+func (d *StormDatabase) GetTags(mediaType string) []string {
+	defer perf.ScopeTimer()()
+
+	var items []TagItem
+	d.db.Select(q.Eq("MediaType", mediaType)).Find(&items)
+
+	ret := []string{}
+	for _, item := range items {
+		for _, tag := range item.Tags {
+			if !util.StringSliceContains(ret, tag) {
+				ret = append(ret, tag)
+			}
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+// GetTaggedItems returns TMDB ids of items of this media type, having the tag
+func (d *StormDatabase) GetTaggedItems(mediaType, tag string) []int {
+	defer perf.ScopeTimer()()
+
+	var items []TagItem
+	d.db.Select(q.Eq("MediaType", mediaType)).Find(&items)
+
+	ret := []int{}
+	for _, item := range items {
+		if util.StringSliceContains(item.Tags, tag) {
+			ret = append(ret, item.TMDBID)
+		}
+	}
+
+	return ret
+}
+
+func (d *StormDatabase) getOrNewTagItem(mediaType string, tmdbID int) *TagItem {
+	if item := d.GetItemTags(mediaType, tmdbID); item != nil {
+		return item
+	}
+
+	return &TagItem{
+		ID:        fmt.Sprintf("%s.%d", mediaType, tmdbID),
+		MediaType: mediaType,
+		TMDBID:    tmdbID,
+	}
+}
+
+// saveTagItem saves item, or removes it if it has neither tags nor note
+func (d *StormDatabase) saveTagItem(item *TagItem) error {
+	if len(item.Tags) == 0 && item.Note == "" {
+		if err := d.db.DeleteStruct(item); err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		return nil
+	}
+
+	return d.db.Save(item)
+}
@@ -24,6 +24,9 @@ type BoltDatabase struct {
 	quit           chan struct{}
 	fileName       string
 	backupFileName string
+
+	// onDelete is called with deleted keys, or with nil keys, when whole bucket is dropped
+	onDelete func(bucket []byte, keys []string)
 }
 
 // SqliteDatabase ...
@@ -97,6 +100,239 @@ type TorrentHistory struct {
 	Metadata []byte
 }
 
+// RewatchItem keeps local rewatch cursor for a show, ShowID is a Trakt ID
+type RewatchItem struct {
+	ShowID    int `storm:"id"`
+	StartedAt time.Time
+	Season    int
+	Episode   int
+}
+
+// ProgressDismissedItem keeps a show out of continue watching, while its next episode stays the same
+type ProgressDismissedItem struct {
+	ShowID  int `storm:"id"`
+	Season  int
+	Episode int
+	Added   time.Time
+}
+
+// ShowIntro keeps learned intro length and configured start offset for a show
+type ShowIntro struct {
+	ShowID        int `storm:"id"`
+	Offset        int
+	AverageLength float64
+	Samples       int
+}
+
+// SpoilerItem marks a show with enabled spoiler protection
+type SpoilerItem struct {
+	ShowID int `storm:"id"`
+}
+
+// SyncedListItem is a public Trakt list of another user, synced to the library
+type SyncedListItem struct {
+	ListID int `storm:"id"`
+	User   string
+	Name   string
+}
+
+// ListSortItem overrides sort order of a Trakt list, configured in Trakt
+type ListSortItem struct {
+	ListID  int `storm:"id"`
+	SortBy  string
+	SortHow string
+}
+
+// ShowIDMapping keeps TMDB ID of a show, missing in Trakt entries
+type ShowIDMapping struct {
+	TraktID int `storm:"id"`
+	TMDBID  int
+}
+
+// JournalEntry records a library mutation with its reason
+type JournalEntry struct {
+	ID        int64 `storm:"id,increment"`
+	Time      time.Time
+	Action    string
+	MediaType int
+	TMDBID    int `storm:"index"`
+	Title     string
+	Reason    string
+}
+
+// ProviderSettings keeps search limits of a provider, zero values mean global defaults
+type ProviderSettings struct {
+	ID            string `storm:"id"`
+	Timeout       int
+	MaxConcurrent int
+	// Content types, provider is not searched for
+	DisabledTypes []string
+}
+
+// LocalFile maps media item to a completely downloaded torrent file,
+// which is kept on disk after torrent removal.
+type LocalFile struct {
+	ID            string `storm:"id"`
+	InfoHash      string
+	OriginalIndex int
+	// Path is relative to download path
+	Path  string
+	Size  int64
+	Added time.Time
+}
+
+// ScrobbleItem is a Trakt playback event, that could not be sent and waits for retry.
+// Only the latest event of an item is kept.
+type ScrobbleItem struct {
+	ID          string `storm:"id"`
+	Action      string
+	ContentType string
+	TMDBID      int
+	Progress    float64
+	Created     time.Time
+	Attempts    int
+	NextAttempt time.Time
+}
+
+// NotInterestedItem marks a movie or show, that should not be offered in discovery listings
+type NotInterestedItem struct {
+	ID          string `storm:"id"`
+	ContentType string `storm:"index"`
+	TMDBID      int
+	Added       time.Time
+}
+
+// SettingsProfile is a named set of settings and enabled providers, shared between devices
+type SettingsProfile struct {
+	Name      string `storm:"id"`
+	Settings  map[string]string
+	Providers []string
+	Updated   time.Time
+}
+
+// QueueItem keeps position of a torrent in download queue
+type QueueItem struct {
+	InfoHash string `storm:"id"`
+	Priority int
+	// Torrent is paused by download queue, not by user
+	Queued bool
+}
+
+// TagItem keeps user tags and note for a movie or a show
+type TagItem struct {
+	ID        string `storm:"id"`
+	MediaType string `storm:"index"`
+	TMDBID    int
+	Tags      []string
+	Note      string
+}
+
+// ShowProgress keeps last fetched Trakt watched progress of a show,
+// so that it is requested again only when show activity changes
+type ShowProgress struct {
+	TraktID       int `storm:"id"`
+	LastWatchedAt time.Time
+	LastUpdatedAt time.Time
+	Fetched       time.Time
+	Progress      []byte
+}
+
+// TraktProfile keeps Trakt authorization of a Kodi profile
+type TraktProfile struct {
+	Profile      string `storm:"id"`
+	Username     string
+	Token        string
+	RefreshToken string
+	TokenExpiry  int
+	Updated      time.Time
+}
+
+// DiscoverPreset is a named combination of TMDB discover filters, shown as a menu entry
+type DiscoverPreset struct {
+	ID        int    `storm:"id,increment"`
+	MediaType string `storm:"index"`
+	Name      string
+	// Filters are kept as encoded query values
+	Filters string
+}
+
+// RSSFeed is a tracker RSS feed, watched for new torrents, with rules to match them
+type RSSFeed struct {
+	ID      int `storm:"id,increment"`
+	Name    string
+	URL     string
+	Enabled bool
+	// TitleRegex is matched against item titles, case insensitive
+	TitleRegex string
+	// MinResolution is an index of bittorrent.Resolutions, zero means any
+	MinResolution int
+	// MaxSize in megabytes, zero means unlimited
+	MaxSize int
+	// ShowID is TMDB ID of a show, matched episodes are mapped to
+	ShowID  int
+	Checked time.Time
+}
+
+// RSSItem marks RSS feed item, which was already processed
+type RSSItem struct {
+	ID       string `storm:"id"`
+	FeedID   int    `storm:"index"`
+	Title    string
+	InfoHash string
+	Added    time.Time
+}
+
+// Auto-download states of an episode
+const (
+	// AutoDownloadPending is an episode, which is not aired yet, or is not found yet
+	AutoDownloadPending = iota
+	// AutoDownloadAdded is an episode, which torrent is added
+	AutoDownloadAdded
+	// AutoDownloadFailed is an episode, which was not found after all attempts
+	AutoDownloadFailed
+)
+
+// AutoDownloadShow marks a show, new episodes of which are downloaded automatically
+type AutoDownloadShow struct {
+	ShowID int `storm:"id"`
+	// Added limits downloads to episodes, aired after the show was marked
+	Added time.Time
+}
+
+// AutoDownloadEpisode is a new episode of auto-downloaded show
+type AutoDownloadEpisode struct {
+	ID          string `storm:"id"`
+	ShowID      int    `storm:"index"`
+	Season      int
+	Episode     int
+	AirDate     string
+	State       int
+	Attempts    int
+	LastAttempt time.Time
+	InfoHash    string
+	Title       string
+}
+
+// StreamingState keeps streaming services of a watchlist item, to detect when it arrives on, or leaves a service
+type StreamingState struct {
+	ID        string `storm:"id"`
+	MediaType string
+	TMDBID    int
+	Title     string
+	Providers []string
+	// Arrived and Left are services, changed on the last change
+	Arrived []string
+	Left    []string
+	Changed time.Time `storm:"index"`
+	Checked time.Time
+}
+
+// NotificationPreference keeps channels, a notification event is sent to
+type NotificationPreference struct {
+	Event    string `storm:"id"`
+	Channels int
+}
+
 var (
 	stormFileName        = "storm.db"
 	backupStormFileName  = "storm-backup.db"
@@ -116,6 +352,13 @@ var (
 	once sync.Once
 )
 
+const (
+	// IntroOffsetAuto uses learned average intro length as start offset
+	IntroOffsetAuto = 0
+	// IntroOffsetDisabled disables start offset for a show
+	IntroOffsetDisabled = -1
+)
+
 const (
 	// StatusRemove ...
 	StatusRemove = iota
@@ -137,6 +380,10 @@ var CacheBuckets = [][]byte{
 	CommonBucket,
 }
 
+// CacheStaleKeep is how long expired items stay in Cache database,
+// so that they can be served, while upstream service is not available
+const CacheStaleKeep = 3 * 24 * 60 * 60
+
 const (
 	// BTItemBucket ...
 	BTItemBucket = "BTItem"
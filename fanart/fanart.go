@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elgatito/elementum/cache"
@@ -37,6 +38,10 @@ var (
 
 var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
 
+var breaker = util.NewCircuitBreaker("Fanart.tv", func(open bool) {
+	cache.AllowStale(cache.FanartKey, open)
+})
+
 // Movie ...
 type Movie struct {
 	Name            string   `json:"name"`
@@ -98,11 +103,19 @@ type Disk struct {
 
 // Get ...
 func Get(endPoint string, params url.Values) (resp *napping.Response, err error) {
+	if !breaker.Allow() {
+		return nil, util.ErrCircuitOpen
+	}
+
 	header := http.Header{
 		"Content-type": []string{"application/json"},
 		"api-key":      []string{ClientID},
 		"api-version":  []string{APIVersion},
 	}
+	// Personal key gives access to newer images and higher limits
+	if clientKey := config.Get().FanartTvClientKey; clientKey != "" {
+		header.Set("client-key", clientKey)
+	}
 
 	req := napping.Request{
 		Url:    fmt.Sprintf("%s/%s/%s", APIURL, APIVersion, endPoint),
@@ -125,6 +138,11 @@ func Get(endPoint string, params url.Values) (resp *napping.Response, err error)
 
 		return nil
 	})
+	if resp != nil {
+		breaker.Report(resp.Status(), err)
+	} else {
+		breaker.Report(0, err)
+	}
 	return
 }
 
@@ -140,6 +158,10 @@ func GetMovie(tmdbID int) (movie *Movie) {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf(cache.FanartMovieByIDKey, tmdbID)
 	if err := cacheStore.Get(key, &movie); err != nil {
+		if config.Get().MeteredMode {
+			return nil
+		}
+
 		resp, err := Get(endPoint, params)
 		if err != nil {
 			log.Debugf("Error getting fanart for movie (%d): %#v", tmdbID, err)
@@ -169,6 +191,10 @@ func GetShow(tvdbID int) (show *Show) {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf(cache.FanartShowByIDKey, tvdbID)
 	if err := cacheStore.Get(key, &show); err != nil {
+		if config.Get().MeteredMode {
+			return nil
+		}
+
 		resp, err := Get(endPoint, params)
 		if err != nil {
 			log.Debugf("Error getting fanart for show (%d): %#v", tvdbID, err)
@@ -186,6 +212,93 @@ func GetShow(tvdbID int) (show *Show) {
 	return
 }
 
+// languages returns preferred languages of art, in order of preference,
+// English and language neutral art is used, when there is no art in preferred languages.
+func languages() []string {
+	ret := []string{}
+	for _, lang := range strings.Split(config.Get().FanartTvLanguages, ",") {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			ret = append(ret, lang)
+		}
+	}
+
+	if len(ret) == 0 {
+		ret = append(ret, config.Get().Language)
+	}
+	return ret
+}
+
+// languageRank returns position of image language in preferred languages,
+// English and language neutral images go after preferred ones, -1 means image is not used.
+func languageRank(lang string, preferred []string) int {
+	for i, l := range preferred {
+		if lang == l {
+			return i
+		}
+	}
+	if lang == "en" || lang == "" {
+		return len(preferred)
+	}
+	return -1
+}
+
+// bestImage returns image in the most preferred language, with most likes.
+// English and language neutral images are taken only if they have likes.
+func bestImage(images []*Image, preferred []string) *Image {
+	var best *Image
+	bestRank := -1
+	bestLikes := 0
+
+	for _, i := range images {
+		rank := languageRank(i.Lang, preferred)
+		likes := likeConvert(i.Likes)
+		if rank < 0 || (rank == len(preferred) && likes == 0) {
+			continue
+		}
+
+		if best == nil || rank < bestRank || (rank == bestRank && likes > bestLikes) {
+			best = i
+			bestRank = rank
+			bestLikes = likes
+		}
+	}
+
+	return best
+}
+
+// appendImages adds images, that are not added yet, in order of language preference
+func appendImages(res []string, images []*Image, preferred []string) []string {
+	for rank := 0; rank <= len(preferred); rank++ {
+		for _, i := range images {
+			if languageRank(i.Lang, preferred) == rank && !contains(res, i.URL) {
+				res = append(res, i.URL)
+			}
+		}
+	}
+	return res
+}
+
+// showImages returns images of a list, that match the filter
+func showImages(list []*ShowImage, filter func(i *ShowImage) bool) []*Image {
+	ret := make([]*Image, 0, len(list))
+	for _, i := range list {
+		if i != nil && filter(i) {
+			ret = append(ret, &i.Image)
+		}
+	}
+	return ret
+}
+
+func nonNilImages(list []*Image) []*Image {
+	ret := make([]*Image, 0, len(list))
+	for _, i := range list {
+		if i != nil {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
 // GetMultipleImage returns multiple images in a list
 func GetMultipleImage(old string, lists ...[]*Image) []string {
 	if lists == nil || len(lists) == 0 {
@@ -193,22 +306,9 @@ func GetMultipleImage(old string, lists ...[]*Image) []string {
 	}
 
 	res := []string{}
-	language := config.Get().Language
+	preferred := languages()
 	for _, l := range lists {
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			if i.Lang == language && !contains(res, i.URL) {
-				res = append(res, i.URL)
-			}
-			if i.Lang == "en" || i.Lang == "" {
-				if !contains(res, i.URL) {
-					res = append(res, i.URL)
-				}
-			}
-		}
+		res = appendImages(res, nonNilImages(l), preferred)
 	}
 
 	if len(res) > 0 {
@@ -218,35 +318,16 @@ func GetMultipleImage(old string, lists ...[]*Image) []string {
 }
 
 // GetBestImage returns best image from multiple lists,
-// according to the lang setting. Taking order of lists into account.
+// according to the languages setting. Taking order of lists into account.
 func GetBestImage(old string, lists ...[]*Image) string {
 	if lists == nil || len(lists) == 0 {
 		return ""
 	}
 
-	language := config.Get().Language
+	preferred := languages()
 	for _, l := range lists {
-		bestLikes := 0
-		bestItem := ""
-
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			if i.Lang == language {
-				return i.URL
-			}
-			if i.Lang == "en" || i.Lang == "" {
-				if likes := likeConvert(i.Likes); likes > bestLikes {
-					bestItem = i.URL
-					bestLikes = likes
-				}
-			}
-		}
-
-		if bestLikes > 0 {
-			return bestItem
+		if best := bestImage(nonNilImages(l), preferred); best != nil {
+			return best.URL
 		}
 	}
 
@@ -260,45 +341,19 @@ func GetMultipleShowImage(season, old string, lists ...[]*ShowImage) []string {
 	}
 
 	res := []string{}
-	language := config.Get().Language
+	preferred := languages()
 	for _, l := range lists {
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			if season == "" || i.Season == season {
-				if i.Lang == language && !contains(res, i.URL) {
-					res = append(res, i.URL)
-				}
-				if i.Lang == "en" || i.Lang == "" {
-					if !contains(res, i.URL) {
-						res = append(res, i.URL)
-					}
-				}
-			}
-		}
+		res = appendImages(res, showImages(l, func(i *ShowImage) bool {
+			return season == "" || i.Season == season
+		}), preferred)
 
 		if len(res) > 0 {
 			return res
 		}
 
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			if season == "" || i.Season == "0" || i.Season == "" {
-				if i.Lang == language && !contains(res, i.URL) {
-					res = append(res, i.URL)
-				}
-				if i.Lang == "en" || i.Lang == "" {
-					if !contains(res, i.URL) {
-						res = append(res, i.URL)
-					}
-				}
-			}
-		}
+		res = appendImages(res, showImages(l, func(i *ShowImage) bool {
+			return season == "" || i.Season == "0" || i.Season == ""
+		}), preferred)
 	}
 
 	if len(res) > 0 {
@@ -308,73 +363,51 @@ func GetMultipleShowImage(season, old string, lists ...[]*ShowImage) []string {
 }
 
 // GetBestShowImage returns best image from multiple lists,
-// according to the lang setting. Taking order of lists into account.
+// according to the languages setting. Taking order of lists into account.
 func GetBestShowImage(season string, isStrict bool, old string, lists ...[]*ShowImage) string {
 	if lists == nil || len(lists) == 0 {
 		return ""
 	}
 
 	idx := 0
-	language := config.Get().Language
+	preferred := languages()
 	for _, l := range lists {
 		idx++
 
-		bestLikes := 0
-		bestItem := ""
-
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			if season == "" || i.Season == season {
-				if i.Lang == language {
-					return i.URL
-				}
-				if i.Lang == "en" || i.Lang == "" {
-					if likes := likeConvert(i.Likes); likes > bestLikes {
-						bestItem = i.URL
-						bestLikes = likes
-					}
-				}
-			}
+		best := bestImage(showImages(l, func(i *ShowImage) bool {
+			return season == "" || i.Season == season
+		}), preferred)
+		if best != nil {
+			return best.URL
 		}
 
-		if bestLikes > 0 {
-			return bestItem
-		}
-
-		for _, i := range l {
-			if i == nil {
-				continue
-			}
-
-			// Take item with season=0 only if this is not a strict mode,
-			//    which means first array is season dedicated, and 0 means special.
-			if season == "" || (i.Season == "0" && (!isStrict || idx > 1)) || i.Season == "" {
-				if i.Lang == language {
-					return i.URL
-				}
-				if i.Lang == "en" || i.Lang == "" {
-					if likes := likeConvert(i.Likes); likes > bestLikes {
-						bestItem = i.URL
-						bestLikes = likes
-					}
-				}
-			}
-		}
-
-		if bestLikes > 0 {
-			return bestItem
+		// Take item with season=0 only if this is not a strict mode,
+		//    which means first array is season dedicated, and 0 means special.
+		best = bestImage(showImages(l, func(i *ShowImage) bool {
+			return season == "" || (i.Season == "0" && (!isStrict || idx > 1)) || i.Season == ""
+		}), preferred)
+		if best != nil {
+			return best.URL
 		}
 	}
 
 	return old
 }
 
+// withArtSettings keeps art, that is disabled in settings, from fanart.tv out of the list item
+func withArtSettings(art *xbmc.ListItemArt, old *xbmc.ListItemArt) *xbmc.ListItemArt {
+	if !config.Get().FanartTvClearArt {
+		art.ClearArt = old.ClearArt
+	}
+	if !config.Get().FanartTvPreferBanners && old.Banner != "" {
+		art.Banner = old.Banner
+	}
+	return art
+}
+
 // ToListItemArt ...
 func (fa *Movie) ToListItemArt(old *xbmc.ListItemArt) *xbmc.ListItemArt {
-	return &xbmc.ListItemArt{
+	return withArtSettings(&xbmc.ListItemArt{
 		Poster:    GetBestImage(old.Poster, fa.MoviePoster),
 		Thumbnail: old.Thumbnail,
 		Banner:    GetBestImage(old.Banner, fa.MovieBanner),
@@ -383,12 +416,12 @@ func (fa *Movie) ToListItemArt(old *xbmc.ListItemArt) *xbmc.ListItemArt {
 		ClearArt:  GetBestImage(old.ClearArt, fa.HDMovieClearArt, fa.MovieClearArt),
 		ClearLogo: GetBestImage(old.ClearLogo, fa.HDMovieLogo, fa.MovieLogo),
 		Landscape: GetBestImage(old.Landscape, fa.MovieThumb),
-	}
+	}, old)
 }
 
 // ToListItemArt ...
 func (fa *Show) ToListItemArt(old *xbmc.ListItemArt) *xbmc.ListItemArt {
-	return &xbmc.ListItemArt{
+	return withArtSettings(&xbmc.ListItemArt{
 		Poster:    GetBestShowImage("", false, old.Poster, fa.TVPoster),
 		Thumbnail: old.Thumbnail,
 		Banner:    GetBestShowImage("", false, old.Banner, fa.TVBanner),
@@ -397,14 +430,14 @@ func (fa *Show) ToListItemArt(old *xbmc.ListItemArt) *xbmc.ListItemArt {
 		ClearArt:  GetBestShowImage("", false, old.ClearArt, fa.HDClearArt, fa.ClearArt),
 		ClearLogo: GetBestShowImage("", false, old.ClearLogo, fa.HdtvLogo, fa.ClearLogo),
 		Landscape: GetBestShowImage("", false, old.Landscape, fa.TVThumb),
-	}
+	}, old)
 }
 
 // ToSeasonListItemArt ...
 func (fa *Show) ToSeasonListItemArt(season int, old *xbmc.ListItemArt) *xbmc.ListItemArt {
 	s := strconv.Itoa(season)
 
-	return &xbmc.ListItemArt{
+	return withArtSettings(&xbmc.ListItemArt{
 		TvShowPoster: GetBestShowImage("", true, old.Poster, fa.SeasonPoster, fa.TVPoster),
 		Poster:       GetBestShowImage(s, true, old.Poster, fa.SeasonPoster, fa.TVPoster),
 		Thumbnail:    old.Thumbnail,
@@ -414,14 +447,14 @@ func (fa *Show) ToSeasonListItemArt(season int, old *xbmc.ListItemArt) *xbmc.Lis
 		ClearArt:     GetBestShowImage(s, false, old.ClearArt, fa.HDClearArt, fa.ClearArt),
 		ClearLogo:    GetBestShowImage(s, false, old.ClearLogo, fa.HdtvLogo, fa.ClearLogo),
 		Landscape:    GetBestShowImage(s, true, old.Landscape, fa.SeasonThumb, fa.TVThumb),
-	}
+	}, old)
 }
 
 // ToEpisodeListItemArt ...
 func (fa *Show) ToEpisodeListItemArt(season int, old *xbmc.ListItemArt) *xbmc.ListItemArt {
 	s := strconv.Itoa(season)
 
-	return &xbmc.ListItemArt{
+	return withArtSettings(&xbmc.ListItemArt{
 		TvShowPoster: GetBestShowImage("", true, old.Poster, fa.SeasonPoster, fa.TVPoster),
 		Poster:       GetBestShowImage(s, true, old.Poster, fa.SeasonPoster, fa.TVPoster),
 		Thumbnail:    old.Thumbnail,
@@ -431,7 +464,19 @@ func (fa *Show) ToEpisodeListItemArt(season int, old *xbmc.ListItemArt) *xbmc.Li
 		ClearArt:     GetBestShowImage(s, false, old.ClearArt, fa.HDClearArt, fa.ClearArt),
 		ClearLogo:    GetBestShowImage(s, false, old.ClearLogo, fa.HdtvLogo, fa.ClearLogo),
 		Landscape:    GetBestShowImage(s, true, old.Landscape, fa.SeasonThumb, fa.TVThumb),
+	}, old)
+}
+
+// EpisodeFallbackThumbnail returns thumbnail for an episode without own still:
+// season thumb, if fanart.tv has one, or show fanart otherwise.
+func EpisodeFallbackThumbnail(fa *Show, season int, showFanArt string) string {
+	if fa != nil {
+		if thumb := GetBestShowImage(strconv.Itoa(season), true, "", fa.SeasonThumb); thumb != "" {
+			return thumb
+		}
 	}
+
+	return showFanArt
 }
 
 func likeConvert(likes string) int {
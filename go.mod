@@ -40,7 +40,7 @@ require (
 	github.com/likexian/gokit v0.23.3 // indirect
 	github.com/mattn/go-colorable v0.1.7 // indirect
 	github.com/mdempsky/maligned v0.0.0-20201101000000-d73c43cb16d0 // indirect
-	github.com/miekg/dns v1.1.31 // indirect
+	github.com/miekg/dns v1.1.31
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.1 // indirect
 	github.com/mschoch/smat v0.2.0 // indirect
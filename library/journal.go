@@ -0,0 +1,87 @@
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elgatito/elementum/database"
+)
+
+const (
+	// JournalAdded is an action of STRM files written to the library
+	JournalAdded = "Added"
+	// JournalRemoved is an action of STRM files removed from the library
+	JournalRemoved = "Removed"
+	// JournalKodiScan is an action of triggered Kodi library scan
+	JournalKodiScan = "Kodi scan"
+	// JournalKodiClean is an action of triggered Kodi library clean
+	JournalKodiClean = "Kodi clean"
+)
+
+const (
+	// ReasonUser ...
+	ReasonUser = "Requested by user"
+	// ReasonSelection ...
+	ReasonSelection = "Added from selection"
+	// ReasonAutoScrape ...
+	ReasonAutoScrape = "Auto-added from scraped lists"
+	// ReasonKodiRemoved ...
+	ReasonKodiRemoved = "Removed from Kodi library"
+	// ReasonTorrentRemoved ...
+	ReasonTorrentRemoved = "Torrent marked for removal"
+	// ReasonShowsUpdate ...
+	ReasonShowsUpdate = "Scheduled update of library shows"
+	// ReasonPlannedUpdate ...
+	ReasonPlannedUpdate = "Planned library update"
+)
+
+const (
+	journalLimit = 500
+	journalKeep  = 90 * 24 * time.Hour
+
+	// Media type of entries, not related to a library item
+	journalNoMedia = -1
+)
+
+// journal records library mutation with its reason
+func journal(action string, mediaType int, tmdbID int, title string, reason string) {
+	entry := &database.JournalEntry{
+		Time:      time.Now(),
+		Action:    action,
+		MediaType: mediaType,
+		TMDBID:    tmdbID,
+		Title:     title,
+		Reason:    reason,
+	}
+	if err := database.GetStorm().AddJournalEntry(entry); err != nil {
+		log.Warningf("Could not record library journal entry: %s", err)
+	}
+}
+
+// RecordKodiUpdate records triggered Kodi library scan or clean
+func RecordKodiUpdate(action string, reason string) {
+	journal(action, journalNoMedia, 0, "", reason)
+}
+
+// JournalEntries returns latest library journal entries, newest first,
+// only entries of given movie or show, if TMDB ID is set
+func JournalEntries(tmdbID int) []database.JournalEntry {
+	return database.GetStorm().GetJournalEntries(tmdbID, journalLimit)
+}
+
+// traktListReason describes Trakt list sync, which caused library change
+func traktListReason(listID string) string {
+	switch listID {
+	case "watchlist":
+		return "Trakt watchlist sync"
+	case "collection":
+		return "Trakt collection sync"
+	}
+	return fmt.Sprintf("Trakt list %s sync", listID)
+}
+
+func cleanupJournal() {
+	if err := database.GetStorm().CleanupJournal(time.Now().Add(-journalKeep)); err != nil {
+		log.Warningf("Could not cleanup library journal: %s", err)
+	}
+}
@@ -20,9 +20,12 @@ import (
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/metadata"
+	"github.com/elgatito/elementum/notifications"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
 	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/watchdog"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -129,6 +132,11 @@ func Get() *Library {
 // Init makes preparations on program start
 func Init() {
 	InitDB()
+	go cleanupJournal()
+
+	// Running tasks are aborted by watchdog before restart, so only a new run is planned
+	watchdog.RegisterRestart(watchdog.LibrarySync, PlanOverallUpdate)
+	watchdog.RegisterRestart(watchdog.TraktSync, PlanTraktUpdate)
 
 	if err := checkMoviesPath(); err != nil {
 		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
@@ -194,7 +202,7 @@ func Init() {
 						}
 						if len(showEpisodes) == libraryTotal {
 							ID := strconv.Itoa(showEpisodes[0].ShowID)
-							if _, err := RemoveShow(ID); err != nil {
+							if _, err := RemoveShow(ID, ReasonKodiRemoved); err != nil {
 								log.Error("Unable to remove show after removing all episodes...")
 							}
 						} else {
@@ -213,6 +221,7 @@ func Init() {
 					if len(labels) > 0 {
 						label = strings.Join(labels, ", ")
 						if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
+							RecordKodiUpdate(JournalKodiClean, ReasonKodiRemoved)
 							xbmc.VideoLibraryClean()
 						}
 					}
@@ -224,6 +233,7 @@ func Init() {
 						}
 					}
 					if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
+						RecordKodiUpdate(JournalKodiClean, ReasonKodiRemoved)
 						xbmc.VideoLibraryClean()
 					}
 				}
@@ -253,6 +263,11 @@ func Init() {
 			case <-closing:
 				return
 			default:
+				if config.Get().MeteredMode {
+					log.Info("Skipping startup library update in metered mode")
+					return
+				}
+
 				PlanTraktUpdate()
 				updateLibraryShows()
 			}
@@ -268,14 +283,16 @@ func Init() {
 	}()
 
 	started := time.Now()
-	language := config.Get().Language
-	tmdb.PopularMovies(tmdb.DiscoverFilters{}, language, 1)
-	tmdb.PopularShows(tmdb.DiscoverFilters{}, language, 1)
-	if _, _, err := trakt.TopMovies("trending", "1"); err != nil {
-		log.Warning(err)
-	}
-	if _, _, err := trakt.TopShows("trending", "1"); err != nil {
-		log.Warning(err)
+	if !config.Get().MeteredMode {
+		language := config.Get().Language
+		tmdb.PopularMovies(tmdb.DiscoverFilters{}, language, 1)
+		tmdb.PopularShows(tmdb.DiscoverFilters{}, language, 1)
+		if _, _, err := trakt.TopMovies("trending", "1"); err != nil {
+			log.Warning(err)
+		}
+		if _, _, err := trakt.TopShows("trending", "1"); err != nil {
+			log.Warning(err)
+		}
 	}
 
 	tmdb.WarmingUp.Set()
@@ -292,11 +309,13 @@ func Init() {
 	traktSyncTicker := time.NewTicker(time.Duration(traktFrequency) * time.Minute)
 	markedForRemovalTicker := time.NewTicker(30 * time.Second)
 	watcherTicker := time.NewTicker(1 * time.Second)
+	prewarmTicker := time.NewTicker(prewarmCheckInterval)
 
 	defer updateTicker.Stop()
 	defer traktSyncTicker.Stop()
 	defer markedForRemovalTicker.Stop()
 	defer watcherTicker.Stop()
+	defer prewarmTicker.Stop()
 
 	closing := closer.C()
 
@@ -305,7 +324,7 @@ func Init() {
 		case <-watcherTicker.C:
 			if l.Running.IsOverall || l.Running.IsMovies || l.Running.IsShows || l.Running.IsEpisodes || l.Running.IsKodi || l.Running.IsTrakt {
 				continue
-			} else if l.Pending.IsKodi {
+			} else if l.Pending.IsKodi && isScanSettled() {
 				go RefreshKodi()
 			} else if l.Pending.IsTrakt {
 				go RefreshTrakt()
@@ -319,7 +338,7 @@ func Init() {
 				go Refresh()
 			}
 		case <-updateTicker.C:
-			if config.Get().UpdateFrequency > 0 && config.Get().LibraryEnabled && config.Get().LibrarySyncEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying()) {
+			if !config.Get().MeteredMode && config.Get().UpdateFrequency > 0 && config.Get().LibraryEnabled && config.Get().LibrarySyncEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying()) {
 				go func() {
 					if err := updateLibraryShows(); err != nil {
 						log.Warning(err)
@@ -328,8 +347,14 @@ func Init() {
 					PlanKodiUpdate()
 				}()
 			}
+		case <-prewarmTicker.C:
+			if isPrewarmTime(time.Now()) && !xbmc.PlayerIsPlaying() {
+				go prewarmLibrary()
+			}
 		case <-traktSyncTicker.C:
-			PlanTraktUpdate()
+			if !config.Get().MeteredMode {
+				PlanTraktUpdate()
+			}
 		case <-markedForRemovalTicker.C:
 			var items []database.BTItem
 			database.GetStormDB().Select(q.Eq("State", database.StatusRemove)).Find(&items)
@@ -339,13 +364,13 @@ func Init() {
 				// Remove from Elementum's library to prevent duplicates
 				if item.Type == movieType {
 					if IsDuplicateMovie(strconv.Itoa(item.ID)) {
-						if _, err := RemoveMovie(item.ID); err != nil {
+						if _, err := RemoveMovie(item.ID, ReasonTorrentRemoved); err != nil {
 							log.Warning("Nothing left to remove from Elementum")
 						}
 					}
 				} else {
 					if IsDuplicateEpisode(item.ShowID, item.Season, item.Episode) {
-						if err := RemoveEpisode(item.ID, item.ShowID, item.Season, item.Episode); err != nil {
+						if err := RemoveEpisode(item.ID, item.ShowID, item.Season, item.Episode, ReasonTorrentRemoved); err != nil {
 							log.Warning(err)
 						}
 					}
@@ -394,7 +419,7 @@ func updateLibraryShows() error {
 			continue
 		}
 
-		if _, err := writeShowStrm(i.ShowID, false, false); err != nil {
+		if _, err := writeShowStrm(i.ShowID, false, false, ReasonShowsUpdate); err != nil {
 			log.Errorf("Error updating show: %s", err)
 		}
 	}
@@ -463,7 +488,7 @@ func checkShowsPath() error {
 // Writers
 //
 
-func writeMovieStrm(tmdbID string, force bool) (*tmdb.Movie, error) {
+func writeMovieStrm(tmdbID string, force bool, reason string) (*tmdb.Movie, error) {
 	movie := tmdb.GetMovieByID(tmdbID, config.Get().StrmLanguage)
 	if movie == nil {
 		return nil, errors.New("Can't find the movie")
@@ -501,6 +526,8 @@ func writeMovieStrm(tmdbID string, force bool) (*tmdb.Movie, error) {
 		return movie, err
 	}
 
+	journal(JournalAdded, MovieType, movie.ID, movie.Title, reason)
+	queueScan(moviePath)
 	return movie, nil
 }
 
@@ -536,7 +563,7 @@ https://www.themoviedb.org/movie/%v
 	return nil
 }
 
-func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
+func writeShowStrm(showID int, adding, force bool, reason string) (*tmdb.Show, error) {
 	// We should not write strm filex for shows that are marked as deleted
 	if wasRemoved(showID, ShowType) {
 		return nil, fmt.Errorf("Show is marked as removed")
@@ -566,6 +593,13 @@ func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
 
 	now := util.UTCBod()
 	addSpecials := config.Get().AddSpecials
+	written := 0
+	defer func() {
+		if written > 0 {
+			journal(JournalAdded, ShowType, showID, fmt.Sprintf("%s (%d episodes)", show.Name, written), reason)
+			queueScan(showPath)
+		}
+	}()
 
 	for _, season := range show.Seasons {
 		if season.EpisodeCount == 0 {
@@ -621,6 +655,7 @@ func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
 				log.Error(err)
 				return show, err
 			}
+			written++
 		}
 		if len(reAddIDs) > 0 {
 			if err := updateBatchDBItem(reAddIDs, StateActive, EpisodeType, showID); err != nil {
@@ -672,7 +707,7 @@ https://www.themoviedb.org/tv/%v
 //
 
 // RemoveMovie removes movie from the library
-func RemoveMovie(tmdbID int) (*tmdb.Movie, error) {
+func RemoveMovie(tmdbID int, reason string) (*tmdb.Movie, error) {
 	if err := checkMoviesPath(); err != nil {
 		return nil, err
 	}
@@ -680,8 +715,7 @@ func RemoveMovie(tmdbID int) (*tmdb.Movie, error) {
 		deleteDBItem(tmdbID, MovieType, true)
 	}()
 
-	ID := strconv.Itoa(tmdbID)
-	movie := tmdb.GetMovieByID(ID, config.Get().StrmLanguage)
+	movie := resolveMovie(tmdbID)
 	if movie == nil {
 		return nil, errors.New("Can't resolve movie")
 	}
@@ -707,12 +741,13 @@ func RemoveMovie(tmdbID int) (*tmdb.Movie, error) {
 		return movie, err
 	}
 
+	journal(JournalRemoved, MovieType, tmdbID, movie.Title, reason)
 	log.Warningf("%s removed from library", movie.Title)
 	return movie, nil
 }
 
 // RemoveShow removes show from the library
-func RemoveShow(tmdbID string) (*tmdb.Show, error) {
+func RemoveShow(tmdbID string, reason string) (*tmdb.Show, error) {
 	if err := checkShowsPath(); err != nil {
 		return nil, err
 	}
@@ -721,8 +756,7 @@ func RemoveShow(tmdbID string) (*tmdb.Show, error) {
 		deleteDBItem(ID, ShowType, true)
 	}()
 
-	show := tmdb.GetShow(ID, config.Get().StrmLanguage)
-
+	show := resolveShow(ID)
 	if show == nil {
 		return nil, errors.New("Unable to find show to remove")
 	}
@@ -748,14 +782,67 @@ func RemoveShow(tmdbID string) (*tmdb.Show, error) {
 		return show, err
 	}
 
+	journal(JournalRemoved, ShowType, ID, show.Name, reason)
 	log.Warningf("Directory %s removed from disk", path)
 	log.Warningf("%s removed from library", show.Name)
 
 	return show, nil
 }
 
+// resolveMovie returns TMDB movie, or a movie with titles from fallback metadata sources,
+// if TMDB is not available, so that strm files can be found by name
+func resolveMovie(tmdbID int) *tmdb.Movie {
+	if movie := tmdb.GetMovieByID(strconv.Itoa(tmdbID), config.Get().StrmLanguage); movie != nil {
+		return movie
+	}
+
+	ids := metadata.IDs{TMDB: tmdbID}
+	if m, err := GetMovieByTMDB(tmdbID); err == nil && m.UIDs != nil {
+		ids.TVDB = m.UIDs.TVDB
+		ids.IMDB = m.UIDs.IMDB
+	}
+
+	item := metadata.NewResolver(metadata.TVDBSource, metadata.OMDbSource).Resolve(metadata.MovieType, ids)
+	if item == nil || item.Year == 0 {
+		return nil
+	}
+
+	movie := &tmdb.Movie{}
+	movie.ID = tmdbID
+	movie.Title = item.Title
+	movie.OriginalTitle = item.OriginalTitle
+	movie.ReleaseDate = strconv.Itoa(item.Year)
+	return movie
+}
+
+// resolveShow returns TMDB show, or a show with titles from fallback metadata sources,
+// if TMDB is not available, so that strm files can be found by name
+func resolveShow(tmdbID int) *tmdb.Show {
+	if show := tmdb.GetShow(tmdbID, config.Get().StrmLanguage); show != nil {
+		return show
+	}
+
+	ids := metadata.IDs{TMDB: tmdbID}
+	if s, err := GetShowByTMDB(tmdbID); err == nil && s.UIDs != nil {
+		ids.TVDB = s.UIDs.TVDB
+		ids.IMDB = s.UIDs.IMDB
+	}
+
+	item := metadata.NewResolver(metadata.TVDBSource, metadata.OMDbSource).Resolve(metadata.ShowType, ids)
+	if item == nil || item.Year == 0 {
+		return nil
+	}
+
+	show := &tmdb.Show{}
+	show.ID = tmdbID
+	show.Name = item.Title
+	show.OriginalName = item.OriginalTitle
+	show.FirstAirDate = strconv.Itoa(item.Year)
+	return show
+}
+
 // RemoveEpisode removes episode from the library
-func RemoveEpisode(tmdbID int, showID int, seasonNumber int, episodeNumber int) error {
+func RemoveEpisode(tmdbID int, showID int, seasonNumber int, episodeNumber int, reason string) error {
 	if err := checkShowsPath(); err != nil {
 		return err
 	}
@@ -782,6 +869,9 @@ func RemoveEpisode(tmdbID int, showID int, seasonNumber int, episodeNumber int)
 		if err := os.Remove(episodePath); err != nil {
 			return err
 		}
+
+		// Episodes are recorded with show's ID, to be listed in show's journal
+		journal(JournalRemoved, EpisodeType, showID, fmt.Sprintf("%s S%02dE%02d", show.Name, seasonNumber, episodeNumber), reason)
 	}
 
 	removedEpisodes <- &removedEpisode{
@@ -1041,6 +1131,64 @@ func ClearTmdbCache() {
 	xbmc.Refresh()
 }
 
+// RefreshMovieMetadata deletes cached TMDB, Trakt and artwork data of a movie and fetches it again
+func RefreshMovieMetadata(tmdbID int) {
+	cacheDB := database.GetCache()
+	if cacheDB == nil {
+		return
+	}
+
+	id := strconv.Itoa(tmdbID)
+	cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(fmt.Sprintf("%smovie.%d.", cache.TMDBKey, tmdbID)))
+	cacheDB.Delete(database.CommonBucket, cache.AccountKey(fmt.Sprintf(cache.TraktMovieByTMDBKey, id)))
+	cacheDB.Delete(database.CommonBucket, fmt.Sprintf(cache.FanartMovieByIDKey, tmdbID))
+
+	language := config.Get().Language
+	if movie := tmdb.GetMovieByID(id, language); movie != nil {
+		log.Infof("Refreshed metadata of movie %s (%d)", movie.Title, tmdbID)
+	}
+	tmdb.GetImages(tmdbID)
+	trakt.GetMovieByTMDB(id)
+}
+
+// RefreshShowMetadata deletes cached TMDB, Trakt and artwork data of a show,
+// its seasons and episodes, and fetches it again
+func RefreshShowMetadata(tmdbID int) {
+	cacheDB := database.GetCache()
+	if cacheDB == nil {
+		return
+	}
+
+	id := strconv.Itoa(tmdbID)
+	if traktShow := trakt.GetShowByTMDB(id); traktShow != nil && traktShow.IDs != nil {
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey(fmt.Sprintf("%sseason.%d.", cache.TraktKey, traktShow.IDs.Trakt))))
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(cache.AccountKey(fmt.Sprintf("%sepisode.%d.", cache.TraktKey, traktShow.IDs.Trakt))))
+	}
+	cacheDB.Delete(database.CommonBucket, cache.AccountKey(fmt.Sprintf(cache.TraktShowTMDBKey, id)))
+
+	language := config.Get().Language
+	if show := tmdb.GetShow(tmdbID, language); show != nil {
+		cacheDB.Delete(database.CommonBucket, fmt.Sprintf(cache.FanartShowByIDKey, util.StrInterfaceToInt(show.ExternalIDs.TVDBID)))
+	}
+	for _, prefix := range []string{"show.%d.", "season.%d.", "episode.%d."} {
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(cache.TMDBKey+fmt.Sprintf(prefix, tmdbID)))
+	}
+
+	show := tmdb.GetShow(tmdbID, language)
+	if show == nil {
+		return
+	}
+	for _, season := range show.Seasons {
+		if season != nil {
+			tmdb.GetSeason(tmdbID, season.Season, language, len(show.Seasons))
+		}
+	}
+	tmdb.GetShowImages(tmdbID)
+	trakt.GetShowByTMDB(id)
+
+	log.Infof("Refreshed metadata of show %s (%d)", show.Name, tmdbID)
+}
+
 //
 // Utilities
 // 		mainly copied from api/routes to skip cycle imports
@@ -1092,7 +1240,8 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 		movies, err = trakt.CollectionMovies(isUpdateNeeded)
 		label = "LOCALIZE[30257]"
 	default:
-		movies, err = trakt.ListItemsMovies("", listID, isUpdateNeeded)
+		user, id := splitListID(listID)
+		movies, err = trakt.ListItemsMovies(user, id, isUpdateNeeded)
 		label = "LOCALIZE[30263]"
 	}
 
@@ -1127,7 +1276,7 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 			continue
 		}
 
-		if _, err := writeMovieStrm(tmdbID, false); err != nil {
+		if _, err := writeMovieStrm(tmdbID, false, traktListReason(listID)); err != nil {
 			continue
 		}
 
@@ -1141,12 +1290,21 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 	if !updating && len(movieIDs) > 0 {
 		log.Noticef("Movies list (%s) added", listID)
 		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
-			xbmc.VideoLibraryScan()
+			PlanKodiUpdate()
 		}
 	}
 	return nil
 }
 
+// splitListID returns owner and ID of a list, given as "user/id" for lists
+// of other users, or as plain ID for lists of current user.
+func splitListID(listID string) (user string, id string) {
+	if i := strings.Index(listID, "/"); i >= 0 {
+		return listID[:i], listID[i+1:]
+	}
+	return "", listID
+}
+
 //
 // Shows internals
 //
@@ -1179,8 +1337,9 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 
 		label = "LOCALIZE[30257]"
 	default:
-		previous, _ = trakt.PreviousListItemsShows(listID)
-		current, _ = trakt.ListItemsShows("", listID, isUpdateNeeded)
+		user, id := splitListID(listID)
+		previous, _ = trakt.PreviousListItemsShows(id)
+		current, _ = trakt.ListItemsShows(user, id, isUpdateNeeded)
 
 		label = "LOCALIZE[30263]"
 	}
@@ -1240,7 +1399,7 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 			continue
 		}
 
-		if _, err := writeShowStrm(show.Show.IDs.TMDB, false, false); err != nil {
+		if _, err := writeShowStrm(show.Show.IDs.TMDB, false, false, traktListReason(listID)); err != nil {
 			continue
 		}
 
@@ -1270,7 +1429,7 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 	if !updating && len(showIDs) > 0 {
 		log.Noticef("Shows list (%s) added", listID)
 		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
-			xbmc.VideoLibraryScan()
+			PlanKodiUpdate()
 		}
 	}
 	return nil
@@ -1303,7 +1462,7 @@ func DiffTraktShows(previous, current []*trakt.Shows, isInitialized bool) []*tra
 //
 
 // AddMovie is adding movie to the library
-func AddMovie(tmdbID string, force bool) (*tmdb.Movie, error) {
+func AddMovie(tmdbID string, force bool, reason string) (*tmdb.Movie, error) {
 	if err := checkMoviesPath(); err != nil {
 		return nil, err
 	}
@@ -1314,11 +1473,11 @@ func AddMovie(tmdbID string, force bool) (*tmdb.Movie, error) {
 	}
 
 	if !force && IsDuplicateMovie(tmdbID) {
-		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30287];;%s", movie.Title), config.AddonIcon())
+		notifications.Notify(notifications.LibraryDuplicate, fmt.Sprintf("LOCALIZE[30287];;%s", movie.Title))
 		return nil, fmt.Errorf("Movie already added")
 	}
 
-	if _, err := writeMovieStrm(tmdbID, force); err != nil {
+	if _, err := writeMovieStrm(tmdbID, force, reason); err != nil {
 		return movie, err
 	}
 
@@ -1332,7 +1491,7 @@ func AddMovie(tmdbID string, force bool) (*tmdb.Movie, error) {
 }
 
 // AddShow is adding show to the library
-func AddShow(tmdbID string, force bool) (*tmdb.Show, error) {
+func AddShow(tmdbID string, force bool, reason string) (*tmdb.Show, error) {
 	if err := checkShowsPath(); err != nil {
 		return nil, err
 	}
@@ -1341,7 +1500,7 @@ func AddShow(tmdbID string, force bool) (*tmdb.Show, error) {
 	show := tmdb.GetShowByID(tmdbID, config.Get().Language)
 
 	if !force && IsDuplicateShow(tmdbID) {
-		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30287];;%s", show.Name), config.AddonIcon())
+		notifications.Notify(notifications.LibraryDuplicate, fmt.Sprintf("LOCALIZE[30287];;%s", show.Name))
 		return show, fmt.Errorf("Show already added")
 	}
 
@@ -1349,7 +1508,7 @@ func AddShow(tmdbID string, force bool) (*tmdb.Show, error) {
 		return show, err
 	}
 
-	if _, err := writeShowStrm(ID, true, force); err != nil {
+	if _, err := writeShowStrm(ID, true, force, reason); err != nil {
 		log.Errorf("Error writing strm for a show: %s", err)
 		return show, err
 	}
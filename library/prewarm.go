@@ -0,0 +1,134 @@
+package library
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const (
+	prewarmCheckInterval = 10 * time.Minute
+
+	// Pause between library items, to avoid API bursts
+	prewarmItemDelay = 2 * time.Second
+
+	// How many last seasons of a show to refresh, for next episodes info
+	prewarmSeasons = 2
+)
+
+var prewarm = struct {
+	sync.Mutex
+	running bool
+	lastDay string
+}{}
+
+// isPrewarmTime checks whether it is configured idle hour and library was not pre-warmed today
+func isPrewarmTime(now time.Time) bool {
+	conf := config.Get()
+	if !conf.PrewarmEnabled || conf.MeteredMode || now.Hour() != conf.PrewarmHour {
+		return false
+	}
+
+	prewarm.Lock()
+	defer prewarm.Unlock()
+
+	return !prewarm.running && prewarm.lastDay != now.Format("2006-01-02")
+}
+
+// prewarmLibrary refreshes cached TMDB and Trakt metadata of library movies and shows,
+// so browsing after idle hours does not wait for API requests.
+func prewarmLibrary() {
+	prewarm.Lock()
+	if prewarm.running {
+		prewarm.Unlock()
+		return
+	}
+	prewarm.running = true
+	prewarm.lastDay = time.Now().Format("2006-01-02")
+	prewarm.Unlock()
+
+	defer func() {
+		prewarm.Lock()
+		prewarm.running = false
+		prewarm.Unlock()
+	}()
+
+	var items []database.LibraryItem
+	if err := database.GetStormDB().Select(q.Eq("State", StateActive)).Find(&items); err != nil {
+		log.Infof("Nothing to pre-warm in library: %s", err)
+		return
+	}
+
+	begin := time.Now()
+	language := config.Get().Language
+	closing := closer.C()
+	refreshed := 0
+
+	for _, i := range items {
+		if i.ID == 0 || (i.MediaType != MovieType && i.MediaType != ShowType) {
+			continue
+		}
+
+		// Stop, once idle hours are over or user starts watching
+		if time.Now().Hour() != config.Get().PrewarmHour || xbmc.PlayerIsPlaying() {
+			log.Infof("Stopping library pre-warm, %d items refreshed", refreshed)
+			return
+		}
+
+		if i.MediaType == MovieType {
+			prewarmMovie(i.ID, language)
+		} else {
+			prewarmShow(i.ID, language)
+		}
+		refreshed++
+
+		select {
+		case <-closing:
+			return
+		case <-time.After(prewarmItemDelay):
+		}
+	}
+
+	log.Infof("Library pre-warmed, %d items refreshed in %s", refreshed, time.Since(begin))
+	notifications.Notify(notifications.CacheWarm, fmt.Sprintf("LOCALIZE[30780];;%d", refreshed))
+}
+
+func prewarmMovie(tmdbID int, language string) {
+	id := strconv.Itoa(tmdbID)
+	cacheStore := cache.NewDBStore()
+
+	cacheStore.Delete(fmt.Sprintf(cache.TMDBMovieByIDKey, id, language))
+	tmdb.GetMovieByID(id, language)
+
+	cacheStore.Delete(fmt.Sprintf(cache.TraktMovieByTMDBKey, id))
+	trakt.GetMovieByTMDB(id)
+}
+
+func prewarmShow(tmdbID int, language string) {
+	cacheStore := cache.NewDBStore()
+
+	cacheStore.Delete(fmt.Sprintf(cache.TMDBShowByIDKey, tmdbID, language))
+	show := tmdb.GetShow(tmdbID, language)
+	if show == nil {
+		return
+	}
+
+	for season := show.NumberOfSeasons; season > 0 && season > show.NumberOfSeasons-prewarmSeasons; season-- {
+		cacheStore.Delete(fmt.Sprintf(cache.TMDBSeasonKey, tmdbID, season, language))
+		tmdb.GetSeason(tmdbID, season, language, len(show.Seasons))
+	}
+
+	cacheStore.Delete(fmt.Sprintf(cache.TraktShowTMDBKey, strconv.Itoa(tmdbID)))
+	trakt.GetShowByTMDB(strconv.Itoa(tmdbID))
+}
@@ -21,9 +21,13 @@ import (
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
 	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/watchdog"
 	"github.com/elgatito/elementum/xbmc"
 )
 
+// Library refresh, running longer than that, is reported by watchdog
+const librarySyncExpected = 30 * time.Minute
+
 var (
 	movieRegexp = regexp.MustCompile(`^plugin://plugin.video.elementum.*/movie/\w+/(\d+)`)
 	showRegexp  = regexp.MustCompile(`^plugin://plugin.video.elementum.*/show/\w+/(\d+)/(\d+)/(\d+)`)
@@ -45,7 +49,7 @@ func RefreshKodi() error {
 
 	l.Running.IsKodi = true
 	l.Pending.IsKodi = false
-	xbmc.VideoLibraryScan()
+	scanKodi()
 	l.Running.IsKodi = false
 
 	return nil
@@ -59,8 +63,17 @@ func Refresh() error {
 
 	l.Pending.IsOverall = false
 	l.Running.IsOverall = true
-	defer func() {
+	task := watchdog.Start(watchdog.LibrarySync, librarySyncExpected, func() {
 		l.Running.IsOverall = false
+		l.Running.IsMovies = false
+		l.Running.IsShows = false
+		l.Running.IsEpisodes = false
+	})
+	defer func() {
+		task.Done()
+		if !task.IsAborted() {
+			l.Running.IsOverall = false
+		}
 		util.FreeMemoryGC()
 	}()
 
@@ -394,7 +407,7 @@ func RefreshMovie(kodiID, action int) {
 		}
 
 		if action == ActionDelete {
-			if _, err := RemoveMovie(uids.TMDB); err != nil {
+			if _, err := RemoveMovie(uids.TMDB, ReasonKodiRemoved); err != nil {
 				log.Warning("Nothing left to remove from Elementum")
 			}
 		}
@@ -429,7 +442,7 @@ func RefreshShow(kodiID, action int) {
 	if action == ActionDelete || action == ActionSafeDelete {
 		if action == ActionDelete {
 			id := strconv.Itoa(uids.TMDB)
-			if _, err := RemoveShow(id); err != nil {
+			if _, err := RemoveShow(id, ReasonKodiRemoved); err != nil {
 				log.Warning("Nothing left to remove from Elementum")
 			}
 		}
@@ -465,7 +478,7 @@ func RefreshEpisode(kodiID, action int) {
 	}
 
 	if action == ActionDelete {
-		RemoveEpisode(e.UIDs.TMDB, s.UIDs.TMDB, e.Season, e.Episode)
+		RemoveEpisode(e.UIDs.TMDB, s.UIDs.TMDB, e.Season, e.Episode, ReasonKodiRemoved)
 	}
 
 	l.mu.Shows.Lock()
@@ -954,7 +967,8 @@ func PlanOverallUpdate() {
 	l.Pending.IsOverall = true
 }
 
-// PlanKodiUpdate ...
+// PlanKodiUpdate plans Kodi scan, scoped to directories written since last scan.
+// Scan is started once library stops changing.
 func PlanKodiUpdate() {
 	l.Pending.IsKodi = true
 }
@@ -0,0 +1,75 @@
+package library
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const (
+	// Quiet period after last written item, so additions are batched into a single scan
+	scanDebounce = 10 * time.Second
+
+	// Above this number of changed directories, single global scan is cheaper
+	maxScopedScans = 20
+)
+
+var scanQueue = struct {
+	sync.Mutex
+	dirs    map[string]bool
+	changed time.Time
+}{
+	dirs: map[string]bool{},
+}
+
+// queueScan adds changed library directory to the next Kodi scan
+func queueScan(dir string) {
+	scanQueue.Lock()
+	defer scanQueue.Unlock()
+
+	scanQueue.dirs[dir] = true
+	scanQueue.changed = time.Now()
+}
+
+// isScanSettled checks whether nothing was written to the library during debounce period
+func isScanSettled() bool {
+	scanQueue.Lock()
+	defer scanQueue.Unlock()
+
+	return time.Since(scanQueue.changed) >= scanDebounce
+}
+
+func takeScanDirs() []string {
+	scanQueue.Lock()
+	defer scanQueue.Unlock()
+
+	ret := make([]string, 0, len(scanQueue.dirs))
+	for dir := range scanQueue.dirs {
+		ret = append(ret, dir)
+	}
+	sort.Strings(ret)
+
+	scanQueue.dirs = map[string]bool{}
+	return ret
+}
+
+// scanKodi runs Kodi scan, scoped to changed directories, if there are not too many of them
+func scanKodi() {
+	dirs := takeScanDirs()
+	if len(dirs) == 0 || len(dirs) > maxScopedScans {
+		RecordKodiUpdate(JournalKodiScan, ReasonPlannedUpdate)
+		xbmc.VideoLibraryScan()
+		return
+	}
+
+	log.Infof("Scanning %d changed library directories", len(dirs))
+	RecordKodiUpdate(JournalKodiScan, fmt.Sprintf("%s of %d directories", ReasonPlannedUpdate, len(dirs)))
+
+	// Kodi queues scan jobs and runs them one by one
+	for _, dir := range dirs {
+		xbmc.VideoLibraryScanDirectory(dir, false)
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/cespare/xxhash"
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/watchdog"
 	"github.com/elgatito/elementum/xbmc"
 )
 
+// Trakt sync, running longer than that, is reported by watchdog
+const traktSyncExpected = 15 * time.Minute
+
 var (
 	// IsTraktInitialized used to mark if we need only incremental updates from Trakt
 	IsTraktInitialized bool
@@ -39,8 +44,14 @@ func RefreshTrakt() error {
 
 	l.Pending.IsTrakt = false
 	l.Running.IsTrakt = true
-	defer func() {
+	task := watchdog.Start(watchdog.TraktSync, traktSyncExpected, func() {
 		l.Running.IsTrakt = false
+	})
+	defer func() {
+		task.Done()
+		if !task.IsAborted() {
+			l.Running.IsTrakt = false
+		}
 	}()
 
 	log.Infof("Running Trakt sync")
@@ -169,6 +180,9 @@ func RefreshTrakt() error {
 			isErrored = true
 		}
 	}
+	if err := RefreshTraktSyncedLists(false); err != nil {
+		isErrored = true
+	}
 
 	return nil
 }
@@ -790,3 +804,19 @@ func RefreshTraktLists(isRefreshNeeded bool) error {
 
 	return nil
 }
+
+// RefreshTraktSyncedLists syncs public lists of other users, enrolled from lists search.
+// Their changes are not reflected in user activities, so cached list items expiration is used.
+func RefreshTraktSyncedLists(isRefreshNeeded bool) error {
+	for _, list := range database.GetStorm().GetSyncedLists() {
+		listID := fmt.Sprintf("%s/%d", list.User, list.ListID)
+		if err := SyncMoviesList(listID, false, isRefreshNeeded); err != nil {
+			continue
+		}
+		if err := SyncShowsList(listID, false, isRefreshNeeded); err != nil {
+			continue
+		}
+	}
+
+	return nil
+}
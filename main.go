@@ -18,15 +18,20 @@ import (
 	"github.com/op/go-logging"
 
 	"github.com/elgatito/elementum/api"
+	"github.com/elgatito/elementum/autodownload"
 	"github.com/elgatito/elementum/bittorrent"
 	"github.com/elgatito/elementum/broadcast"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/library"
 	"github.com/elgatito/elementum/lockfile"
+	"github.com/elgatito/elementum/providers/jackett"
+	"github.com/elgatito/elementum/rss"
 	"github.com/elgatito/elementum/scrape"
+	"github.com/elgatito/elementum/streamalerts"
 	"github.com/elgatito/elementum/trakt"
 	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/watchdog"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -174,6 +179,7 @@ func main() {
 		handler := http.StripPrefix("/files/", http.FileServer(bittorrent.NewTorrentFS(s, r.Method)))
 		handler.ServeHTTP(w, r)
 	}))
+	http.Handle("/transcode/", bittorrent.Transcode(s))
 	http.Handle("/reload", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		s.Reconfigure()
 	}))
@@ -223,12 +229,26 @@ func main() {
 		xbmc.ResetRPC()
 	}()
 
+	jackett.Register()
+
 	go library.Init()
-	go trakt.TokenRefreshHandler()
+	go api.ResumeCrashedPlayback(s)
+	go func() {
+		// Trakt authorization of current Kodi profile should be restored before tokens are refreshed
+		if err := trakt.SwitchProfile(""); err != nil {
+			log.Warningf("Could not restore Trakt profile: %s", err)
+		}
+		trakt.TokenRefreshHandler()
+	}()
+	go trakt.ScrobbleQueueHandler()
 	go db.MaintenanceRefreshHandler()
 	go cacheDb.MaintenanceRefreshHandler()
 	go scrape.Start()
+	go rss.Run(s, broadcast.Closer.C())
+	go autodownload.Run(s, broadcast.Closer.C())
+	go streamalerts.Run(broadcast.Closer.C())
 	go util.FreeMemoryGC()
+	go watchdog.Run(broadcast.Closer.C())
 
 	log.Infof("Prepared in %s", time.Since(now))
 	log.Infof("Starting HTTP server")
@@ -116,6 +116,7 @@ func Notification(w http.ResponseWriter, r *http.Request, s *bittorrent.Service)
 		}
 
 		go p.InitSubtitles()
+		go p.InitStreams()
 		// TODO: enable when find a way to provide external audio tracks
 		// go p.InitAudio()
 
@@ -151,6 +152,11 @@ func Notification(w http.ResponseWriter, r *http.Request, s *bittorrent.Service)
 			}
 		}
 
+		// Skipping intro for next episode from the same season pack
+		if p.Params().ResumePlayback == bittorrent.ResumeNo && p.Params().IntroOffset > 0 {
+			resumePosition = p.Params().IntroOffset
+		}
+
 		if resumePosition > -1 {
 			go func(resume float64) {
 				log.Infof("OnPlay. Seeking to %v", resume)
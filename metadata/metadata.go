@@ -0,0 +1,167 @@
+package metadata
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Media types of resolved items
+const (
+	MovieType = "movie"
+	ShowType  = "show"
+)
+
+var log = logging.MustGetLogger("metadata")
+
+// IDs identify an item in all sources, any of them can be empty
+type IDs struct {
+	TMDB int
+	TVDB int
+	IMDB string
+}
+
+// Item is metadata of a movie or a show, normalized from any of the sources
+type Item struct {
+	// Source is a name of a source, which resolved this item
+	Source    string
+	MediaType string
+	IDs       IDs
+
+	Title         string
+	OriginalTitle string
+	Year          int
+	Plot          string
+	Genres        []string
+	Rating        float32
+	Votes         int
+	// Runtime in minutes
+	Runtime       int
+	Certification string
+	Trailer       string
+
+	Poster string
+	FanArt string
+	Banner string
+
+	// listItem is a complete list item, built by a source, when it has more details, than normalized fields
+	listItem *xbmc.ListItem
+}
+
+// NewItem returns metadata of a list item, built by a source
+func NewItem(listItem *xbmc.ListItem) *Item {
+	item := &Item{listItem: listItem}
+	if listItem.Info != nil {
+		item.Title = listItem.Info.Title
+		item.OriginalTitle = listItem.Info.OriginalTitle
+		item.Year = listItem.Info.Year
+		item.Plot = listItem.Info.Plot
+		item.Rating = listItem.Info.Rating
+		item.Votes, _ = strconv.Atoi(listItem.Info.Votes)
+		item.Runtime = listItem.Info.Duration / 60
+		item.Certification = listItem.Info.MPAA
+		item.Trailer = listItem.Info.Trailer
+		item.IDs.IMDB = listItem.Info.IMDBNumber
+		if listItem.Info.Genre != "" {
+			item.Genres = strings.Split(listItem.Info.Genre, " / ")
+		}
+	}
+	if listItem.Art != nil {
+		item.Poster = listItem.Art.Poster
+		item.FanArt = listItem.Art.FanArt
+		item.Banner = listItem.Art.Banner
+	}
+	return item
+}
+
+// Source returns metadata of an item, or nil if it cannot be resolved
+type Source struct {
+	Name    string
+	Resolve func(mediaType string, ids IDs) *Item
+}
+
+// Resolver tries sources in order, until one of them resolves an item
+type Resolver struct {
+	sources []*Source
+}
+
+// NewResolver returns resolver, which tries given sources in order
+func NewResolver(sources ...*Source) *Resolver {
+	return &Resolver{sources: sources}
+}
+
+// Resolve returns metadata from the first source, which has it, or nil
+func (r *Resolver) Resolve(mediaType string, ids IDs) *Item {
+	for _, source := range r.sources {
+		if source == nil {
+			continue
+		}
+
+		if item := source.Resolve(mediaType, ids); item != nil {
+			item.Source = source.Name
+			item.MediaType = mediaType
+			return item
+		}
+		log.Debugf("Could not resolve %s %+v with %s", mediaType, ids, source.Name)
+	}
+	return nil
+}
+
+// Resolve returns metadata, using TMDB, given fallback source, like Trakt data, which is already fetched,
+// then TVDB and OMDb
+func Resolve(mediaType string, ids IDs, fallback *Source) *Item {
+	return NewResolver(TMDBSource, fallback, TVDBSource, OMDbSource).Resolve(mediaType, ids)
+}
+
+// ToListItem returns list item of resolved metadata
+func (i *Item) ToListItem() *xbmc.ListItem {
+	if i.listItem != nil {
+		return i.listItem
+	}
+
+	title := i.Title
+	originalTitle := i.OriginalTitle
+	if originalTitle == "" {
+		originalTitle = title
+	}
+
+	dbType := "movie"
+	if i.MediaType == ShowType {
+		dbType = "tvshow"
+	}
+
+	item := &xbmc.ListItem{
+		Label: title,
+		Info: &xbmc.ListItemInfo{
+			Title:         title,
+			OriginalTitle: originalTitle,
+			Year:          i.Year,
+			Genre:         strings.Join(i.Genres, " / "),
+			Plot:          i.Plot,
+			PlotOutline:   i.Plot,
+			Rating:        i.Rating,
+			Votes:         strconv.Itoa(i.Votes),
+			Duration:      i.Runtime * 60,
+			MPAA:          i.Certification,
+			Code:          i.IDs.IMDB,
+			IMDBNumber:    i.IDs.IMDB,
+			Trailer:       i.Trailer,
+			DBTYPE:        dbType,
+			Mediatype:     dbType,
+		},
+		Art: &xbmc.ListItemArt{
+			Poster:    i.Poster,
+			FanArt:    i.FanArt,
+			Banner:    i.Banner,
+			Thumbnail: i.Poster,
+		},
+		Thumbnail: i.Poster,
+	}
+	if i.MediaType == ShowType {
+		item.Art.TvShowPoster = i.Poster
+	}
+	return item
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/jmcvetta/napping"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+)
+
+// OMDbURL ...
+const OMDbURL = "https://www.omdbapi.com/"
+
+// OMDbItem is a movie or a show, returned by OMDb
+type OMDbItem struct {
+	Title      string `json:"Title"`
+	Year       string `json:"Year"`
+	Rated      string `json:"Rated"`
+	Runtime    string `json:"Runtime"`
+	Genre      string `json:"Genre"`
+	Plot       string `json:"Plot"`
+	Poster     string `json:"Poster"`
+	IMDBRating string `json:"imdbRating"`
+	IMDBVotes  string `json:"imdbVotes"`
+	IMDBID     string `json:"imdbID"`
+	Type       string `json:"Type"`
+	Response   string `json:"Response"`
+	Error      string `json:"Error"`
+}
+
+func getOMDb(imdbID string) (item *OMDbItem) {
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.OMDbItemKey, imdbID)
+	if err := cacheStore.Get(key, &item); err == nil {
+		return
+	}
+
+	if config.Get().MeteredMode {
+		return nil
+	}
+
+	params := napping.Params{
+		"i":      imdbID,
+		"plot":   "full",
+		"apikey": config.Get().OMDbAPIKey,
+	}.AsUrlValues()
+
+	resp, err := napping.Get(OMDbURL, &params, &item, nil)
+	if err != nil {
+		log.Debugf("Error getting OMDb item (%s): %#v", imdbID, err)
+		return nil
+	} else if resp.Status() != 200 {
+		log.Warningf("Bad status getting OMDb item (%s): %d", imdbID, resp.Status())
+		return nil
+	} else if item == nil || item.Response != "True" {
+		if item != nil {
+			log.Debugf("OMDb could not find %s: %s", imdbID, item.Error)
+		}
+		return nil
+	}
+
+	cacheStore.Set(key, item, cache.OMDbItemExpire)
+	return
+}
@@ -0,0 +1,196 @@
+package metadata
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/tvdb"
+	"github.com/elgatito/elementum/util"
+)
+
+// TMDBSource resolves items by TMDB ID, or by IMDB/TVDB ID, using TMDB find
+var TMDBSource = &Source{
+	Name: "TMDB",
+	Resolve: func(mediaType string, ids IDs) *Item {
+		tmdbID := ids.TMDB
+		if tmdbID == 0 {
+			tmdbID = findTMDBID(mediaType, ids)
+		}
+		if tmdbID == 0 {
+			return nil
+		}
+
+		if mediaType == MovieType {
+			movie := tmdb.GetMovie(tmdbID, config.Get().Language)
+			if movie == nil {
+				return nil
+			}
+
+			return &Item{
+				IDs:           IDs{TMDB: movie.ID, TVDB: ids.TVDB, IMDB: movie.IMDBId},
+				Title:         movie.Title,
+				OriginalTitle: movie.OriginalTitle,
+				Year:          yearOf(movie.ReleaseDate),
+				Plot:          movie.Overview,
+				Genres:        genreNames(movie.Genres),
+				Rating:        movie.VoteAverage,
+				Votes:         movie.VoteCount,
+				Runtime:       movie.Runtime,
+				Poster:        tmdb.ImageURL(movie.PosterPath, "w1280"),
+				FanArt:        tmdb.ImageURL(movie.BackdropPath, "w1280"),
+				listItem:      movie.ToListItem(),
+			}
+		}
+
+		show := tmdb.GetShow(tmdbID, config.Get().Language)
+		if show == nil {
+			return nil
+		}
+
+		item := &Item{
+			IDs:           IDs{TMDB: show.ID, TVDB: ids.TVDB, IMDB: ids.IMDB},
+			Title:         show.Name,
+			OriginalTitle: show.OriginalName,
+			Year:          yearOf(show.FirstAirDate),
+			Plot:          show.Overview,
+			Genres:        genreNames(show.Genres),
+			Rating:        show.VoteAverage,
+			Votes:         show.VoteCount,
+			Poster:        tmdb.ImageURL(show.PosterPath, "w1280"),
+			FanArt:        tmdb.ImageURL(show.BackdropPath, "w1280"),
+			listItem:      show.ToListItem(),
+		}
+		if len(show.EpisodeRunTime) > 0 {
+			item.Runtime = show.EpisodeRunTime[0]
+		}
+		if show.ExternalIDs != nil {
+			if item.IDs.TVDB == 0 {
+				item.IDs.TVDB = util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
+			}
+			if item.IDs.IMDB == "" {
+				item.IDs.IMDB = show.ExternalIDs.IMDBId
+			}
+		}
+		return item
+	},
+}
+
+// TVDBSource resolves shows by TVDB ID
+var TVDBSource = &Source{
+	Name: "TVDB",
+	Resolve: func(mediaType string, ids IDs) *Item {
+		if mediaType != ShowType || ids.TVDB == 0 {
+			return nil
+		}
+
+		show, err := tvdb.GetShow(ids.TVDB, config.Get().Language)
+		if err != nil || show == nil || show.SeriesName == "" {
+			return nil
+		}
+
+		item := &Item{
+			IDs:           IDs{TMDB: ids.TMDB, TVDB: show.ID, IMDB: show.ImdbID},
+			Title:         show.SeriesName,
+			Year:          yearOf(show.FirstAired),
+			Plot:          show.Overview,
+			Votes:         util.StrInterfaceToInt(show.RatingCount),
+			Runtime:       show.Runtime,
+			Certification: show.ContentRating,
+		}
+		for _, genre := range strings.Split(show.Genre, "|") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				item.Genres = append(item.Genres, genre)
+			}
+		}
+		if rating, err := strconv.ParseFloat(show.Rating, 32); err == nil {
+			item.Rating = float32(rating)
+		}
+		if show.Poster != "" {
+			item.Poster = tvdb.ImageURL(show.Poster)
+		}
+		if show.FanArt != "" {
+			item.FanArt = tvdb.ImageURL(show.FanArt)
+		}
+		if show.Banner != "" {
+			item.Banner = tvdb.ImageURL(show.Banner)
+		}
+		return item
+	},
+}
+
+// OMDbSource resolves movies and shows by IMDB ID, if OMDb API key is configured
+var OMDbSource = &Source{
+	Name: "OMDb",
+	Resolve: func(mediaType string, ids IDs) *Item {
+		if ids.IMDB == "" || config.Get().OMDbAPIKey == "" {
+			return nil
+		}
+
+		r := getOMDb(ids.IMDB)
+		if r == nil {
+			return nil
+		}
+
+		item := &Item{
+			IDs:           IDs{TMDB: ids.TMDB, TVDB: ids.TVDB, IMDB: r.IMDBID},
+			Title:         r.Title,
+			Year:          yearOf(r.Year),
+			Plot:          r.Plot,
+			Votes:         util.StrInterfaceToInt(strings.Replace(r.IMDBVotes, ",", "", -1)),
+			Runtime:       util.StrInterfaceToInt(strings.TrimSuffix(r.Runtime, " min")),
+			Certification: r.Rated,
+		}
+		for _, genre := range strings.Split(r.Genre, ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				item.Genres = append(item.Genres, genre)
+			}
+		}
+		if rating, err := strconv.ParseFloat(r.IMDBRating, 32); err == nil {
+			item.Rating = float32(rating)
+		}
+		if strings.HasPrefix(r.Poster, "http") {
+			item.Poster = r.Poster
+		}
+		return item
+	},
+}
+
+func findTMDBID(mediaType string, ids IDs) int {
+	var result *tmdb.FindResult
+	if ids.IMDB != "" {
+		result = tmdb.Find(ids.IMDB, "imdb_id")
+	} else if ids.TVDB != 0 && mediaType == ShowType {
+		result = tmdb.Find(strconv.Itoa(ids.TVDB), "tvdb_id")
+	}
+	if result == nil {
+		return 0
+	}
+
+	if mediaType == MovieType && len(result.MovieResults) > 0 {
+		return result.MovieResults[0].ID
+	} else if mediaType == ShowType && len(result.TVResults) > 0 {
+		return result.TVResults[0].ID
+	}
+	return 0
+}
+
+func genreNames(genres []*tmdb.IDName) []string {
+	ret := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		if genre != nil {
+			ret = append(ret, genre.Name)
+		}
+	}
+	return ret
+}
+
+// yearOf returns year of a date, or of a year range, like "2010–2015"
+func yearOf(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
@@ -0,0 +1,195 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// Event is a type of notification, channels are configured per event
+type Event string
+
+// Notification events
+const (
+	DownloadFinished   Event = "download_finished"
+	AuthExpired        Event = "auth_expired"
+	ServiceUnavailable Event = "service_unavailable"
+	LibraryDuplicate   Event = "library_duplicate"
+	AutoDownload       Event = "autodownload"
+	RSS                Event = "rss"
+	Streaming          Event = "streaming"
+	CacheWarm          Event = "cache_warm"
+)
+
+// Channels are bit flags, where notification is sent to
+const (
+	ChannelLog = 1 << iota
+	ChannelKodi
+	ChannelWebhook
+	ChannelTelegram
+)
+
+// Events lists all events in the order, they are shown in preferences, with their labels
+var Events = []struct {
+	Event Event
+	Label string
+}{
+	{DownloadFinished, "LOCALIZE[30771]"},
+	{AuthExpired, "LOCALIZE[30772]"},
+	{ServiceUnavailable, "LOCALIZE[30773]"},
+	{LibraryDuplicate, "LOCALIZE[30774]"},
+	{AutoDownload, "LOCALIZE[30759]"},
+	{RSS, "LOCALIZE[30738]"},
+	{Streaming, "LOCALIZE[30770]"},
+	{CacheWarm, "LOCALIZE[30775]"},
+}
+
+// Channels lists all channels with their labels
+var Channels = []struct {
+	Channel int
+	Label   string
+}{
+	{ChannelKodi, "LOCALIZE[30776]"},
+	{ChannelWebhook, "LOCALIZE[30777]"},
+	{ChannelTelegram, "LOCALIZE[30778]"},
+	{ChannelLog, "LOCALIZE[30779]"},
+}
+
+const (
+	defaultChannels = ChannelLog | ChannelKodi
+	sendTimeout     = 10 * time.Second
+)
+
+var (
+	log = logging.MustGetLogger("notifications")
+
+	localizeMatcher = regexp.MustCompile(`LOCALIZE\[(\d+)\]`)
+
+	// Events, which are too noisy to be shown in Kodi by default
+	quietEvents = map[Event]bool{
+		CacheWarm: true,
+	}
+
+	client = &http.Client{Timeout: sendTimeout}
+)
+
+// GetChannels returns channels, configured for an event
+func GetChannels(event Event) int {
+	def := defaultChannels
+	if quietEvents[event] {
+		def = ChannelLog
+	}
+	return database.GetStorm().GetNotificationChannels(string(event), def)
+}
+
+// SetChannels changes channels of an event
+func SetChannels(event Event, channels int) error {
+	return database.GetStorm().SetNotificationChannels(string(event), channels)
+}
+
+// Notify sends a message of an event to all channels, enabled for this event.
+// Message can contain LOCALIZE[] strings with ";;" separated arguments, same as for xbmc.Notify.
+func Notify(event Event, message string) {
+	channels := GetChannels(event)
+
+	if channels&ChannelLog != 0 {
+		log.Noticef("[%s] %s", event, message)
+	}
+	if channels&ChannelKodi != 0 {
+		xbmc.Notify("Elementum", message, config.AddonIcon())
+	}
+	if channels&(ChannelWebhook|ChannelTelegram) == 0 {
+		return
+	}
+
+	text := localize(message)
+	go func() {
+		if channels&ChannelWebhook != 0 {
+			if err := sendWebhook(event, text); err != nil {
+				log.Warningf("Could not send webhook notification: %s", err)
+			}
+		}
+		if channels&ChannelTelegram != 0 {
+			if err := sendTelegram(text); err != nil {
+				log.Warningf("Could not send Telegram notification: %s", err)
+			}
+		}
+	}()
+}
+
+// localize resolves LOCALIZE[] strings and substitutes arguments, for channels outside of Kodi
+func localize(message string) string {
+	parts := strings.Split(message, ";;")
+	text := localizeMatcher.ReplaceAllStringFunc(parts[0], func(s string) string {
+		id, _ := strconv.Atoi(localizeMatcher.FindStringSubmatch(s)[1])
+		return xbmc.GetLocalizedString(id)
+	})
+
+	for _, arg := range parts[1:] {
+		if i := strings.Index(text, "%s"); i >= 0 {
+			text = text[:i] + arg + text[i+2:]
+		} else if i := strings.Index(text, "%d"); i >= 0 {
+			text = text[:i] + arg + text[i+2:]
+		} else {
+			text += " " + arg
+		}
+	}
+	return text
+}
+
+func sendWebhook(event Event, text string) error {
+	webhookURL := config.Get().NotificationsWebhookURL
+	if webhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"source":  "elementum",
+		"event":   string(event),
+		"message": text,
+	})
+	if err != nil {
+		return err
+	}
+
+	return post(webhookURL, "application/json", body)
+}
+
+func sendTelegram(text string) error {
+	token := config.Get().NotificationsTelegramToken
+	chatID := config.Get().NotificationsTelegramChatID
+	if token == "" || chatID == "" {
+		return nil
+	}
+
+	body := url.Values{
+		"chat_id": {chatID},
+		"text":    {"Elementum: " + text},
+	}
+	return post(fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", token), "application/x-www-form-urlencoded", []byte(body.Encode()))
+}
+
+func post(to string, contentType string, body []byte) error {
+	resp, err := client.Post(to, contentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Bad status: %d", resp.StatusCode)
+	}
+	return nil
+}
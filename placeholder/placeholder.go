@@ -0,0 +1,290 @@
+package placeholder
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cespare/xxhash"
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const (
+	// KindPoster is a vertical card, used for posters and thumbnails
+	KindPoster = "poster"
+	// KindLandscape is a horizontal card, used for fanart and episode thumbnails
+	KindLandscape = "landscape"
+
+	cacheDirectory = "placeholders"
+	margin         = 20
+	glyphSpacing   = 1
+	lineSpacing    = 3
+	maxInitials    = 3
+
+	// Generated images are removed, when they are old or when there are too many of them
+	cacheExpire     = 30 * 24 * time.Hour
+	cacheMaxFiles   = 1000
+	cleanupInterval = time.Hour
+)
+
+var (
+	log = logging.MustGetLogger("placeholder")
+
+	cleanupMu   sync.Mutex
+	lastCleanup time.Time
+
+	sizes = map[string]image.Point{
+		KindPoster:    {400, 600},
+		KindLandscape: {640, 360},
+	}
+)
+
+// URL returns address of generated placeholder image for a title
+func URL(kind string, title string) string {
+	return fmt.Sprintf("%s/placeholder/%s?title=%s", util.GetHTTPHost(), kind, url.QueryEscape(title))
+}
+
+// Fill sets placeholder images for list item, if it has no artwork
+func Fill(item *xbmc.ListItem) {
+	if item == nil || !config.Get().PlaceholderArt {
+		return
+	}
+
+	title := item.Label
+	if item.Info != nil && item.Info.Title != "" {
+		title = item.Info.Title
+	}
+	if title == "" {
+		return
+	}
+
+	if item.Art == nil {
+		item.Art = &xbmc.ListItemArt{}
+	}
+	if item.Art.Poster == "" {
+		item.Art.Poster = URL(KindPoster, title)
+	}
+	if item.Art.Thumbnail == "" {
+		item.Art.Thumbnail = item.Art.Poster
+	}
+	if item.Art.FanArt == "" {
+		item.Art.FanArt = URL(KindLandscape, title)
+	}
+	if item.Thumbnail == "" {
+		item.Thumbnail = item.Art.Poster
+	}
+}
+
+// Get returns PNG image for a title, generating it if it is not yet cached
+func Get(kind string, title string) ([]byte, error) {
+	size, ok := sizes[kind]
+	if !ok {
+		return nil, fmt.Errorf("Unknown placeholder kind: %s", kind)
+	}
+
+	dir := filepath.Join(config.Get().Info.Profile, cacheDirectory)
+	path := filepath.Join(dir, fmt.Sprintf("%s_%x.png", kind, xxhash.Sum64String(title)))
+	if b, err := ioutil.ReadFile(path); err == nil {
+		return b, nil
+	}
+
+	b, err := render(title, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warningf("Could not create placeholders directory: %s", err)
+	} else if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		log.Warningf("Could not save placeholder image: %s", err)
+	} else {
+		go cleanup(dir)
+	}
+
+	return b, nil
+}
+
+// cleanup removes expired images and oldest images over the limit, not more often than once in cleanupInterval
+func cleanup(dir string) {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+
+	if time.Since(lastCleanup) < cleanupInterval {
+		return
+	}
+	lastCleanup = time.Now()
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	kept := 0
+	removed := 0
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".png" {
+			continue
+		}
+
+		if kept < cacheMaxFiles && time.Since(f.ModTime()) < cacheExpire {
+			kept++
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+			log.Warningf("Could not remove placeholder image: %s", err)
+		} else {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		log.Infof("Removed %d cached placeholder images", removed)
+	}
+}
+
+// render draws title text over a card, colored according to the title
+func render(title string, size image.Point) ([]byte, error) {
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(img, img.Bounds(), &image.Uniform{cardColor(title)}, image.Point{}, draw.Src)
+
+	scale := size.X / 80
+	charWidth := (glyphWidth + glyphSpacing) * scale
+	lineHeight := (glyphHeight + lineSpacing) * scale
+
+	lines := wrap(printable(title), (size.X-2*margin)/charWidth)
+	if maxLines := (size.Y - 2*margin) / lineHeight; len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+
+	y := (size.Y - len(lines)*lineHeight) / 2
+	for _, line := range lines {
+		x := (size.X - len([]rune(line))*charWidth) / 2
+		for _, r := range line {
+			drawGlyph(img, r, x, y, scale)
+			x += charWidth
+		}
+		y += lineHeight
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// printable returns text, that the font is able to draw. Unsupported punctuation is dropped,
+// and titles with unsupported letters, like non-latin ones, are replaced by initials of words,
+// that start with a supported letter, or by an empty string, so that a plain card is drawn.
+func printable(title string) string {
+	words := []string{}
+	initials := ""
+	complete := true
+	for _, word := range strings.FieldsFunc(strings.ToUpper(title), unicode.IsSpace) {
+		kept := []rune{}
+		for _, r := range word {
+			if _, ok := font[r]; ok {
+				kept = append(kept, r)
+			} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				complete = false
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+
+		words = append(words, string(kept))
+		if first, _ := utf8.DecodeRuneInString(word); first == kept[0] && len([]rune(initials)) < maxInitials {
+			initials += string(first)
+		}
+	}
+
+	if complete {
+		return strings.Join(words, " ")
+	}
+	return initials
+}
+
+func drawGlyph(img *image.RGBA, r rune, x, y, scale int) {
+	glyph, ok := font[r]
+	if !ok {
+		glyph = font['?']
+	}
+
+	for row := 0; row < glyphHeight; row++ {
+		for col := 0; col < glyphWidth; col++ {
+			if glyph[row]&(1<<uint(glyphWidth-1-col)) == 0 {
+				continue
+			}
+
+			rect := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+			draw.Draw(img, rect, &image.Uniform{color.White}, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// wrap splits text into lines, not longer than width characters
+func wrap(text string, width int) (lines []string) {
+	if width <= 0 {
+		return
+	}
+
+	line := ""
+	for _, word := range strings.FieldsFunc(text, unicode.IsSpace) {
+		for len([]rune(word)) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, string([]rune(word)[:width]))
+			word = string([]rune(word)[width:])
+		}
+
+		if line == "" {
+			line = word
+		} else if len([]rune(line))+1+len([]rune(word)) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return
+}
+
+// cardColor returns dark color, derived from the title, so same title always gets same card
+func cardColor(title string) color.RGBA {
+	h := xxhash.Sum64String(title)
+	return color.RGBA{
+		R: uint8(40 + h%80),
+		G: uint8(40 + (h>>8)%80),
+		B: uint8(40 + (h>>16)%80),
+		A: 255,
+	}
+}
@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+const (
+	// MediaAnime is declared by providers, which can search for anime shows
+	MediaAnime = "anime"
+
+	capabilitiesTimeout = 5 * time.Second
+	capabilitiesTTL     = 6 * time.Hour
+)
+
+// Capabilities are declared by provider addon in reply to "capabilities" call,
+// so that searches, which provider can't handle, are not sent to it.
+type Capabilities struct {
+	// MediaTypes lists supported content types, and "anime" if anime shows are supported
+	MediaTypes      []string `json:"media_types"`
+	AbsoluteNumbers bool     `json:"absolute_numbers"`
+	SeasonPacks     bool     `json:"season_packs"`
+	NeedsAuth       bool     `json:"needs_auth"`
+
+	// Declared is false for providers, which don't support negotiation,
+	// such providers get every search call, like before.
+	Declared   bool      `json:"-"`
+	Negotiated time.Time `json:"-"`
+}
+
+var providerCapabilities = struct {
+	sync.Mutex
+	items map[string]*Capabilities
+	locks map[string]*sync.Mutex
+}{
+	items: map[string]*Capabilities{},
+	locks: map[string]*sync.Mutex{},
+}
+
+// Supports checks whether provider can handle search for content type
+func (c *Capabilities) Supports(content string, anime bool, withAuth bool) bool {
+	if !c.Declared {
+		return true
+	}
+
+	if !util.StringSliceContains(c.MediaTypes, content) {
+		return false
+	} else if content == ContentSeason && !c.SeasonPacks {
+		return false
+	} else if anime && !util.StringSliceContains(c.MediaTypes, MediaAnime) {
+		return false
+	} else if c.NeedsAuth && !withAuth {
+		return false
+	}
+	return true
+}
+
+// SupportsAbsoluteNumbers checks whether anime absolute episode numbers should be resolved for provider
+func (c *Capabilities) SupportsAbsoluteNumbers() bool {
+	return !c.Declared || c.AbsoluteNumbers
+}
+
+// Capabilities returns capabilities of the provider, negotiating them if not known yet
+func (as *AddonSearcher) Capabilities() *Capabilities {
+	providerCapabilities.Lock()
+	lock, ok := providerCapabilities.locks[as.addonID]
+	if !ok {
+		lock = &sync.Mutex{}
+		providerCapabilities.locks[as.addonID] = lock
+	}
+	providerCapabilities.Unlock()
+
+	// Only one negotiation per provider at a time, other searches wait for its result
+	lock.Lock()
+	defer lock.Unlock()
+
+	providerCapabilities.Lock()
+	c, ok := providerCapabilities.items[as.addonID]
+	providerCapabilities.Unlock()
+	if ok && time.Since(c.Negotiated) < capabilitiesTTL {
+		return c
+	}
+
+	c = as.negotiate()
+
+	providerCapabilities.Lock()
+	providerCapabilities.items[as.addonID] = c
+	providerCapabilities.Unlock()
+
+	return c
+}
+
+// negotiate asks provider for its capabilities.
+// Providers, that don't answer in time, are considered as supporting everything.
+func (as *AddonSearcher) negotiate() *Capabilities {
+	c := &Capabilities{Negotiated: time.Now()}
+
+	cid, ch := GetCallback()
+	payload := &SearchPayload{
+		Method:      "capabilities",
+		CallbackURL: fmt.Sprintf("%s/callbacks/%s", util.GetHTTPHost(), cid),
+		SearchObject: &GeneralSearchObject{
+			ProxyURL:         config.Get().ProxyURL,
+			InternalProxyURL: util.InternalProxyURL(),
+			ElementumURL:     util.ElementumURL(),
+			Silent:           true,
+		},
+	}
+
+	xbmc.ExecuteAddon(as.addonID, payload.String())
+
+	select {
+	case <-time.After(capabilitiesTimeout):
+		RemoveCallback(cid)
+		as.log.Infof("Provider %s does not declare capabilities", as.addonID)
+	case result := <-ch:
+		if err := json.Unmarshal(result, c); err != nil || len(c.MediaTypes) == 0 {
+			as.log.Infof("Provider %s does not declare capabilities", as.addonID)
+			c = &Capabilities{Negotiated: c.Negotiated}
+		} else {
+			c.Declared = true
+			as.log.Infof("Provider %s capabilities: %+v", as.addonID, *c)
+		}
+	}
+
+	return c
+}
+
+// skips reports and logs whether search should not be sent to the provider
+func (as *AddonSearcher) skips(content string, anime bool, withAuth bool) bool {
+	if as.Capabilities().Supports(content, anime, withAuth) {
+		return false
+	}
+
+	as.log.Debugf("Skipping %s search in provider %s, it is not supported", content, as.addonID)
+	return true
+}
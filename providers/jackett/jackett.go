@@ -0,0 +1,187 @@
+package jackett
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/providers"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+var log = logging.MustGetLogger("jackett")
+
+// Provider searches torrents on a Jackett or Prowlarr instance via Torznab API.
+// Each provider is bound to a position in configured instances.
+type Provider struct {
+	index int
+}
+
+// Register adds native providers for all configurable Jackett/Prowlarr instances,
+// providers without configured instance stay disabled.
+func Register() {
+	for i := 0; i < config.MaxTorznabInstances; i++ {
+		providers.RegisterNativeProvider(&Provider{index: i})
+	}
+}
+
+func (p *Provider) instance() *config.TorznabInstance {
+	instances := config.Get().TorznabInstances
+	if p.index >= len(instances) {
+		return nil
+	}
+	return &instances[p.index]
+}
+
+// ID ...
+func (p *Provider) ID() string {
+	return fmt.Sprintf("jackett.%d", p.index+1)
+}
+
+// Name ...
+func (p *Provider) Name() string {
+	if instance := p.instance(); instance != nil {
+		if u, err := url.Parse(instance.URL); err == nil && u.Host != "" {
+			return "Jackett " + u.Host
+		}
+	}
+	return "Jackett"
+}
+
+// Enabled ...
+func (p *Provider) Enabled() bool {
+	return p.instance() != nil
+}
+
+// SearchLinks ...
+func (p *Provider) SearchLinks(query string) []*bittorrent.TorrentFile {
+	return p.search(url.Values{"t": {"search"}, "q": {query}})
+}
+
+// SearchMovieLinks ...
+func (p *Provider) SearchMovieLinks(movie *tmdb.Movie) []*bittorrent.TorrentFile {
+	if movie == nil {
+		return []*bittorrent.TorrentFile{}
+	}
+
+	params := url.Values{"t": {"movie"}, "cat": {config.Get().TorznabMovieCategories}}
+	if movie.IMDBId != "" {
+		params.Set("imdbid", movie.IMDBId)
+		if ret := p.search(params); len(ret) > 0 {
+			return ret
+		}
+		params.Del("imdbid")
+	}
+
+	title := movie.Title
+	if config.Get().UseOriginalTitle && movie.OriginalTitle != "" {
+		title = movie.OriginalTitle
+	}
+	if year := strings.Split(movie.ReleaseDate, "-")[0]; year != "" {
+		title += " " + year
+	}
+	params.Set("q", title)
+	return p.search(params)
+}
+
+// SearchMovieLinksSilent ...
+func (p *Provider) SearchMovieLinksSilent(movie *tmdb.Movie, withAuth bool) []*bittorrent.TorrentFile {
+	return p.SearchMovieLinks(movie)
+}
+
+// SearchSeasonLinks ...
+func (p *Provider) SearchSeasonLinks(show *tmdb.Show, season *tmdb.Season) []*bittorrent.TorrentFile {
+	if show == nil || season == nil {
+		return []*bittorrent.TorrentFile{}
+	}
+
+	params := showParams(show)
+	params.Set("season", strconv.Itoa(season.Season))
+	return p.searchShow(show, params)
+}
+
+// SearchEpisodeLinks ...
+func (p *Provider) SearchEpisodeLinks(show *tmdb.Show, episode *tmdb.Episode) []*bittorrent.TorrentFile {
+	if show == nil || episode == nil {
+		return []*bittorrent.TorrentFile{}
+	}
+
+	params := showParams(show)
+
+	// Anime releases are mostly numbered by absolute episode number
+	if show.IsAnime() {
+		if an, _ := show.AnimeInfo(episode); an != 0 {
+			params.Set("t", "search")
+			params.Set("q", fmt.Sprintf("%s %02d", showTitle(show), an))
+			return p.search(params)
+		}
+	}
+
+	params.Set("season", strconv.Itoa(episode.SeasonNumber))
+	params.Set("ep", strconv.Itoa(episode.EpisodeNumber))
+	return p.searchShow(show, params)
+}
+
+// searchShow looks up by TVDB ID first, since not all indexers support it,
+// and falls back to title search.
+func (p *Provider) searchShow(show *tmdb.Show, params url.Values) []*bittorrent.TorrentFile {
+	if show.ExternalIDs != nil {
+		if tvdbID := util.StrInterfaceToInt(show.ExternalIDs.TVDBID); tvdbID > 0 {
+			params.Set("tvdbid", strconv.Itoa(tvdbID))
+			if ret := p.search(params); len(ret) > 0 {
+				return ret
+			}
+			params.Del("tvdbid")
+		}
+	}
+
+	params.Set("q", showTitle(show))
+	return p.search(params)
+}
+
+func (p *Provider) search(params url.Values) []*bittorrent.TorrentFile {
+	ret := []*bittorrent.TorrentFile{}
+
+	instance := p.instance()
+	if instance == nil {
+		return ret
+	}
+
+	items, err := query(instance, params)
+	if err != nil {
+		log.Warningf("Search with %s failed: %s", p.Name(), err)
+		return ret
+	}
+
+	name := p.Name()
+	for i := range items {
+		if t := items[i].torrentFile(name); t != nil {
+			ret = append(ret, t)
+		}
+	}
+
+	log.Debugf("Found %d torrents with %s for %s", len(ret), name, params.Encode())
+	return ret
+}
+
+// showParams returns tvsearch params with categories, mapped for shows or anime
+func showParams(show *tmdb.Show) url.Values {
+	categories := config.Get().TorznabShowCategories
+	if show.IsAnime() && config.Get().TorznabAnimeCategories != "" {
+		categories = config.Get().TorznabAnimeCategories
+	}
+	return url.Values{"t": {"tvsearch"}, "cat": {categories}}
+}
+
+func showTitle(show *tmdb.Show) string {
+	if config.Get().UseOriginalTitle && show.OriginalName != "" {
+		return show.OriginalName
+	}
+	return show.Name
+}
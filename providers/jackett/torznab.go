@@ -0,0 +1,138 @@
+package jackett
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+)
+
+const requestTimeout = 30 * time.Second
+
+type torznabFeed struct {
+	XMLName xml.Name      `xml:"rss"`
+	Items   []torznabItem `xml:"channel>item"`
+}
+
+type torznabError struct {
+	XMLName     xml.Name `xml:"error"`
+	Code        int      `xml:"code,attr"`
+	Description string   `xml:"description,attr"`
+}
+
+type torznabItem struct {
+	Title     string `xml:"title"`
+	Link      string `xml:"link"`
+	Size      uint64 `xml:"size"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length uint64 `xml:"length,attr"`
+	} `xml:"enclosure"`
+	Attrs []torznabAttr `xml:"attr"`
+}
+
+type torznabAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// apiURL returns Torznab API endpoint of an instance,
+// configured URL may be given with or without "/api" suffix.
+func apiURL(instance *config.TorznabInstance) string {
+	ret := strings.TrimRight(instance.URL, "/")
+	if !strings.HasSuffix(ret, "/api") {
+		ret += "/api"
+	}
+	return ret
+}
+
+// query requests Torznab API of an instance, empty params are not sent
+func query(instance *config.TorznabInstance, params url.Values) ([]torznabItem, error) {
+	values := url.Values{}
+	for k, v := range params {
+		if len(v) > 0 && v[0] != "" {
+			values.Set(k, v[0])
+		}
+	}
+	values.Set("apikey", instance.APIKey)
+
+	client := &http.Client{Timeout: requestTimeout}
+	resp, err := client.Get(apiURL(instance) + "?" + values.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors are returned as <error> document, mostly with 200 status
+	feedErr := torznabError{}
+	if xml.Unmarshal(body, &feedErr) == nil {
+		return nil, fmt.Errorf("%s (code %d)", feedErr.Description, feedErr.Code)
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Instance responded with %s", resp.Status)
+	}
+
+	feed := torznabFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	return feed.Items, nil
+}
+
+func (i *torznabItem) attr(name string) string {
+	for _, a := range i.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// torrentFile converts search result into a torrent, preferring magnet over .torrent download
+func (i *torznabItem) torrentFile(provider string) *bittorrent.TorrentFile {
+	uri := i.attr("magneturl")
+	if uri == "" {
+		uri = i.Enclosure.URL
+	}
+	if uri == "" {
+		uri = i.Link
+	}
+	if uri == "" {
+		return nil
+	}
+
+	size := i.Size
+	if size == 0 {
+		size = i.Enclosure.Length
+	}
+
+	t := &bittorrent.TorrentFile{
+		URI:      uri,
+		InfoHash: strings.ToLower(i.attr("infohash")),
+		Title:    i.Title,
+		Name:     i.Title,
+		Provider: provider,
+		Icon:     config.AddonIcon(),
+	}
+	if size > 0 {
+		t.Size = humanize.Bytes(size)
+	}
+	t.Seeds, _ = strconv.ParseInt(i.attr("seeders"), 10, 64)
+	t.Peers, _ = strconv.ParseInt(i.attr("peers"), 10, 64)
+
+	t.Init()
+	return t
+}
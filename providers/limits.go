@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/util"
+)
+
+// Content types of provider searches
+const (
+	ContentMovie   = "movie"
+	ContentSeason  = "season"
+	ContentEpisode = "episode"
+	ContentSearch  = "search"
+)
+
+// ContentTypes lists all content types, provider can be searched for
+var ContentTypes = []string{ContentMovie, ContentSeason, ContentEpisode, ContentSearch}
+
+var providerSlots = struct {
+	sync.Mutex
+	slots map[string]chan struct{}
+}{
+	slots: map[string]chan struct{}{},
+}
+
+// searcherID returns ID of a provider, used to store its settings
+func searcherID(searcher interface{}) string {
+	switch s := searcher.(type) {
+	case *AddonSearcher:
+		return s.addonID
+	case NativeProvider:
+		return s.ID()
+	}
+	return ""
+}
+
+// isContentEnabled checks whether provider is allowed to be searched for content type
+func isContentEnabled(searcher interface{}, content string) bool {
+	id := searcherID(searcher)
+	if id == "" {
+		return true
+	}
+
+	return !util.StringSliceContains(database.GetStorm().GetProviderSettings(id).DisabledTypes, content)
+}
+
+// searchTimeout returns provider's own timeout, or global one
+func searchTimeout(settings *database.ProviderSettings) time.Duration {
+	if settings.Timeout > 0 {
+		return time.Duration(settings.Timeout) * time.Second
+	} else if config.Get().CustomProviderTimeoutEnabled {
+		return time.Duration(config.Get().CustomProviderTimeout) * time.Second
+	}
+	return providerTimeout()
+}
+
+// acquireSlot waits for a free call slot of a provider, limited by its settings.
+// Returns release function, or false if no slot was freed during timeout.
+func acquireSlot(settings *database.ProviderSettings, timeout time.Duration) (func(), bool) {
+	if settings.MaxConcurrent <= 0 {
+		return func() {}, true
+	}
+
+	providerSlots.Lock()
+	slots, ok := providerSlots.slots[settings.ID]
+	if !ok || cap(slots) != settings.MaxConcurrent {
+		slots = make(chan struct{}, settings.MaxConcurrent)
+		providerSlots.slots[settings.ID] = slots
+	}
+	providerSlots.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	case <-time.After(timeout):
+		return nil, false
+	}
+}
+
+// AllTimedOut reports whether every enabled provider did not answer its last search in time
+func AllTimedOut() bool {
+	searchers := getSearchers()
+	if len(searchers) == 0 {
+		return false
+	}
+
+	for _, searcher := range searchers {
+		if id := searcherID(searcher); id == "" || !GetProviderStats(id).LastTimedOut {
+			return false
+		}
+	}
+	return true
+}
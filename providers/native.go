@@ -0,0 +1,46 @@
+package providers
+
+import (
+	"sync"
+)
+
+// NativeProvider is a torrent provider, written in Go and run in-process,
+// without Python addon round-trips and callbacks.
+// Provider should implement any of Searcher, MovieSearcher, SeasonSearcher and EpisodeSearcher,
+// only implemented searches are run.
+type NativeProvider interface {
+	ID() string
+	Name() string
+	Enabled() bool
+}
+
+var (
+	nativeProviders   = []NativeProvider{}
+	nativeProvidersMu sync.RWMutex
+)
+
+// RegisterNativeProvider adds provider to searchers, replacing provider with the same ID
+func RegisterNativeProvider(provider NativeProvider) {
+	nativeProvidersMu.Lock()
+	defer nativeProvidersMu.Unlock()
+
+	for i, p := range nativeProviders {
+		if p.ID() == provider.ID() {
+			nativeProviders[i] = provider
+			return
+		}
+	}
+
+	log.Infof("Registered native provider %s", provider.ID())
+	nativeProviders = append(nativeProviders, provider)
+}
+
+// NativeProviders returns registered native providers
+func NativeProviders() []NativeProvider {
+	nativeProvidersMu.RLock()
+	defer nativeProvidersMu.RUnlock()
+
+	ret := make([]NativeProvider, len(nativeProviders))
+	copy(ret, nativeProviders)
+	return ret
+}
@@ -5,7 +5,8 @@ import (
 	"encoding/json"
 )
 
-// SearchPayload ...
+// SearchPayload is sent to provider addon for every call.
+// Method is one of "capabilities", "search", "search_movie", "search_season" or "search_episode".
 type SearchPayload struct {
 	Method       string      `json:"method"`
 	CallbackURL  string      `json:"callback_url"`
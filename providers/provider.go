@@ -25,3 +25,13 @@ type SeasonSearcher interface {
 type EpisodeSearcher interface {
 	SearchEpisodeLinks(show *tmdb.Show, episode *tmdb.Episode) []*bittorrent.TorrentFile
 }
+
+// SilentSeasonSearcher is a SeasonSearcher, that can search without user interaction
+type SilentSeasonSearcher interface {
+	SearchSeasonLinksSilent(show *tmdb.Show, season *tmdb.Season, withAuth bool) []*bittorrent.TorrentFile
+}
+
+// SilentEpisodeSearcher is an EpisodeSearcher, that can search without user interaction
+type SilentEpisodeSearcher interface {
+	SearchEpisodeLinksSilent(show *tmdb.Show, episode *tmdb.Episode, withAuth bool) []*bittorrent.TorrentFile
+}
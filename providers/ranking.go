@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+)
+
+// RankingRule is a single rule, used to sort found torrents
+type RankingRule struct {
+	Name  string
+	Less  lessFunc
+	Value func(t *bittorrent.TorrentFile) string
+}
+
+var resolutionPreferenceNames = map[int]string{
+	Sort1080p720p480p: "1080p > 720p > 480p",
+	Sort720p1080p480p: "720p > 1080p > 480p",
+	Sort720p480p1080p: "720p > 480p > 1080p",
+	Sort480p720p1080p: "480p > 720p > 1080p",
+}
+
+// RankingRules returns rules, used to sort torrents, in order of their priority
+func RankingRules(sortType int) []*RankingRule {
+	conf := config.Get()
+	sortMode := conf.SortingModeMovies
+	resolutionPreference := conf.ResolutionPreferenceMovies
+
+	if sortType == SortShows {
+		sortMode = conf.SortingModeShows
+		resolutionPreference = conf.ResolutionPreferenceShows
+	}
+
+	seeds := &RankingRule{
+		Name:  "Seeds",
+		Less:  func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Seeds > c2.Seeds },
+		Value: func(t *bittorrent.TorrentFile) string { return fmt.Sprintf("%d", t.Seeds) },
+	}
+
+	switch sortMode {
+	case SortBySize:
+		return []*RankingRule{{
+			Name:  "Size",
+			Less:  func(c1, c2 *bittorrent.TorrentFile) bool { return c1.SizeParsed > c2.SizeParsed },
+			Value: func(t *bittorrent.TorrentFile) string { return t.Size },
+		}}
+	case SortBySeeders:
+		return []*RankingRule{seeds}
+	}
+
+	resolution := &RankingRule{
+		Name:  "Resolution (" + resolutionPreferenceNames[resolutionPreference] + ")",
+		Value: func(t *bittorrent.TorrentFile) string { return bittorrent.Resolutions[t.Resolution] },
+	}
+	switch resolutionPreference {
+	case Sort1080p720p480p:
+		resolution.Less = func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Resolution > c2.Resolution }
+	case Sort480p720p1080p:
+		resolution.Less = func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Resolution < c2.Resolution }
+	case Sort720p1080p480p:
+		resolution.Less = func(c1, c2 *bittorrent.TorrentFile) bool { return Resolution720p1080p(c1) < Resolution720p1080p(c2) }
+	case Sort720p480p1080p:
+		resolution.Less = func(c1, c2 *bittorrent.TorrentFile) bool { return Resolution720p480p(c1) < Resolution720p480p(c2) }
+	default:
+		return []*RankingRule{seeds}
+	}
+
+	if sortMode == SortBalanced {
+		balanced := &RankingRule{
+			Name:  fmt.Sprintf("Balanced seeds (+%d%%)", conf.PercentageAdditionalSeeders),
+			Less:  func(c1, c2 *bittorrent.TorrentFile) bool { return float64(c1.Seeds) > Balanced(c2) },
+			Value: func(t *bittorrent.TorrentFile) string { return fmt.Sprintf("%d (%.0f)", t.Seeds, Balanced(t)) },
+		}
+		return []*RankingRule{balanced, resolution}
+	}
+
+	return []*RankingRule{resolution, seeds}
+}
+
+// SortTorrents sorts torrents with given ranking rules
+func SortTorrents(torrents []*bittorrent.TorrentFile, rules []*RankingRule) {
+	less := make([]lessFunc, 0, len(rules))
+	for _, r := range rules {
+		less = append(less, r.Less)
+	}
+	SortBy(less...).Sort(torrents)
+}
+
+// ExplainRanking describes, which of sorted torrents would be picked automatically and why
+func ExplainRanking(torrents []*bittorrent.TorrentFile, sortType int, limit int) string {
+	rules := RankingRules(sortType)
+	if len(torrents) == 0 {
+		return "No torrents found, nothing would be picked."
+	}
+
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Found %d torrents, ranked by: %s", len(torrents), strings.Join(names, ", then ")),
+		"",
+	}
+
+	if limit > len(torrents) {
+		limit = len(torrents)
+	}
+	for i, t := range torrents[:limit] {
+		title := fmt.Sprintf("#%d %s", i+1, t.Name)
+		if i == 0 {
+			title = "[B][COLOR gold]Picked:[/COLOR][/B] " + t.Name
+		}
+		lines = append(lines, title)
+		lines = append(lines, fmt.Sprintf("    Provider: %s, Size: %s, Seeds/Peers: %d/%d", t.Provider, t.Size, t.Seeds, t.Peers))
+
+		for _, r := range rules {
+			lines = append(lines, fmt.Sprintf("    %s: %s", r.Name, r.Value(t)))
+		}
+
+		if i+1 < len(torrents) {
+			lines = append(lines, "    Ranked above next one by: "+decidingRule(rules, t, torrents[i+1]))
+		}
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// decidingRule returns name of the first rule, that puts first torrent before the second
+func decidingRule(rules []*RankingRule, t1, t2 *bittorrent.TorrentFile) string {
+	for _, r := range rules {
+		if r.Less(t1, t2) {
+			return r.Name
+		} else if r.Less(t2, t1) {
+			break
+		}
+	}
+	return "equal rank"
+}
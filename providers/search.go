@@ -3,7 +3,6 @@ package providers
 import (
 	"io/ioutil"
 	"os"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,9 +11,9 @@ import (
 	"github.com/zeebo/bencode"
 
 	"github.com/elgatito/elementum/bittorrent"
-	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/util"
+	"github.com/elgatito/elementum/watchdog"
 	"github.com/elgatito/elementum/xbmc"
 )
 
@@ -52,6 +51,9 @@ var (
 	log            = logging.MustGetLogger("linkssearch")
 )
 
+// Search, running longer than that, is reported by watchdog
+const searchExpected = 2 * time.Minute
+
 // Search ...
 func Search(searchers []Searcher, query string) []*bittorrent.TorrentFile {
 	torrentsChan := make(chan *bittorrent.TorrentFile)
@@ -73,6 +75,27 @@ func Search(searchers []Searcher, query string) []*bittorrent.TorrentFile {
 	return processLinks(torrentsChan, SortMovies, false)
 }
 
+// SearchSilent searches query without dialogs
+func SearchSilent(searchers []Searcher, query string) []*bittorrent.TorrentFile {
+	torrentsChan := make(chan *bittorrent.TorrentFile)
+	go func() {
+		wg := sync.WaitGroup{}
+		for _, searcher := range searchers {
+			wg.Add(1)
+			go func(searcher Searcher) {
+				defer wg.Done()
+				for _, torrent := range searcher.SearchLinks(query) {
+					torrentsChan <- torrent
+				}
+			}(searcher)
+		}
+		wg.Wait()
+		close(torrentsChan)
+	}()
+
+	return processLinks(torrentsChan, SortMovies, true)
+}
+
 // SearchMovie ...
 func SearchMovie(searchers []MovieSearcher, movie *tmdb.Movie) []*bittorrent.TorrentFile {
 	torrentsChan := make(chan *bittorrent.TorrentFile)
@@ -157,6 +180,60 @@ func SearchEpisode(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.E
 	return processLinks(torrentsChan, SortShows, false)
 }
 
+// SearchSeasonSilent searches season without dialogs, providers without silent search are called as usual
+func SearchSeasonSilent(searchers []SeasonSearcher, show *tmdb.Show, season *tmdb.Season, withAuth bool) []*bittorrent.TorrentFile {
+	torrentsChan := make(chan *bittorrent.TorrentFile)
+	go func() {
+		wg := sync.WaitGroup{}
+		for _, searcher := range searchers {
+			wg.Add(1)
+			go func(searcher SeasonSearcher) {
+				defer wg.Done()
+				var torrents []*bittorrent.TorrentFile
+				if s, ok := searcher.(SilentSeasonSearcher); ok {
+					torrents = s.SearchSeasonLinksSilent(show, season, withAuth)
+				} else {
+					torrents = searcher.SearchSeasonLinks(show, season)
+				}
+				for _, torrent := range torrents {
+					torrentsChan <- torrent
+				}
+			}(searcher)
+		}
+		wg.Wait()
+		close(torrentsChan)
+	}()
+
+	return processLinks(torrentsChan, SortShows, true)
+}
+
+// SearchEpisodeSilent searches episode without dialogs, providers without silent search are called as usual
+func SearchEpisodeSilent(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.Episode, withAuth bool) []*bittorrent.TorrentFile {
+	torrentsChan := make(chan *bittorrent.TorrentFile)
+	go func() {
+		wg := sync.WaitGroup{}
+		for _, searcher := range searchers {
+			wg.Add(1)
+			go func(searcher EpisodeSearcher) {
+				defer wg.Done()
+				var torrents []*bittorrent.TorrentFile
+				if s, ok := searcher.(SilentEpisodeSearcher); ok {
+					torrents = s.SearchEpisodeLinksSilent(show, episode, withAuth)
+				} else {
+					torrents = searcher.SearchEpisodeLinks(show, episode)
+				}
+				for _, torrent := range torrents {
+					torrentsChan <- torrent
+				}
+			}(searcher)
+		}
+		wg.Wait()
+		close(torrentsChan)
+	}()
+
+	return processLinks(torrentsChan, SortShows, true)
+}
+
 func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSilent bool) []*bittorrent.TorrentFile {
 	torrentsMap := map[string]*bittorrent.TorrentFile{}
 
@@ -168,14 +245,20 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 	progressUpdate := make(chan string)
 	closed := util.Event{}
 
+	task := watchdog.Start(watchdog.ProviderSearch, searchExpected, nil)
 	defer func() {
+		task.Done()
+
 		log.Debug("Closing progressupdate")
 		closed.Set()
-		close(progressUpdate)
+		// Stuck resolvers of aborted search may still send updates
+		if !task.IsAborted() {
+			close(progressUpdate)
+		}
 	}()
 
 	wg := sync.WaitGroup{}
-	for torrent := range torrentsChan {
+	for torrent := range receiveLinks(torrentsChan, task) {
 		wg.Add(1)
 		if !strings.HasPrefix(torrent.URI, "magnet") {
 			progressTotal++
@@ -243,62 +326,31 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 		}()
 	}
 
-	wg.Wait()
+	resolvedAll := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(resolvedAll)
+	}()
 
-	if !isSilent {
-		dialogProgressBG.Update(100, "Elementum", "LOCALIZE[30117]")
+	select {
+	case <-resolvedAll:
+	case <-task.C():
 	}
 
-	for _, torrent := range torrents {
-		if torrent.InfoHash == "" {
-			continue
-		}
-
-		torrentKey := torrent.InfoHash
-		if torrent.IsPrivate {
-			torrentKey = torrent.InfoHash + "-" + torrent.Provider
+	if task.IsAborted() {
+		log.Warning("Search was aborted by watchdog")
+		if !isSilent {
+			dialogProgressBG.Close()
 		}
+		return []*bittorrent.TorrentFile{}
+	}
 
-		if existingTorrent, exists := torrentsMap[torrentKey]; exists {
-			// Collect all trackers
-			for _, trackerURL := range torrent.Trackers {
-				if !util.StringSliceContains(existingTorrent.Trackers, trackerURL) {
-					existingTorrent.Trackers = append(existingTorrent.Trackers, trackerURL)
-				}
-			}
-
-			existingTorrent.Provider += ", " + torrent.Provider
-			if torrent.Resolution > existingTorrent.Resolution {
-				existingTorrent.Name = torrent.Name
-				existingTorrent.Resolution = torrent.Resolution
-			}
-			if torrent.VideoCodec > existingTorrent.VideoCodec {
-				existingTorrent.VideoCodec = torrent.VideoCodec
-			}
-			if torrent.AudioCodec > existingTorrent.AudioCodec {
-				existingTorrent.AudioCodec = torrent.AudioCodec
-			}
-			if torrent.RipType > existingTorrent.RipType {
-				existingTorrent.RipType = torrent.RipType
-			}
-			if torrent.SceneRating > existingTorrent.SceneRating {
-				existingTorrent.SceneRating = torrent.SceneRating
-			}
-			if existingTorrent.Title == "" && torrent.Title != "" {
-				existingTorrent.Title = torrent.Title
-			}
-			if existingTorrent.IsMagnet() && !torrent.IsMagnet() {
-				existingTorrent.URI = torrent.URI
-			}
-			if existingTorrent.Seeds < torrent.Seeds {
-				existingTorrent.Seeds = torrent.Seeds
-				existingTorrent.Peers = torrent.Peers
-			}
+	if !isSilent {
+		dialogProgressBG.Update(100, "Elementum", "LOCALIZE[30117]")
+	}
 
-			existingTorrent.Multi = true
-		} else {
-			torrentsMap[torrentKey] = torrent
-		}
+	for _, torrent := range torrents {
+		mergeLink(torrentsMap, torrent)
 	}
 
 	torrents = make([]*bittorrent.TorrentFile, 0, len(torrentsMap))
@@ -321,6 +373,100 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 		dialogProgressBG = nil
 	}
 
+	updateTorrentFiles(torrents)
+
+	// Sorting resulting list of torrents
+	SortTorrents(torrents, RankingRules(sortType))
+
+	// log.Info("Sorted torrent candidates.")
+	// for _, torrent := range torrents {
+	// 	log.Infof("S:%d P:%d %s - %s - %s", torrent.Seeds, torrent.Peers, torrent.Name, torrent.Provider, torrent.URI)
+	// }
+
+	return torrents
+}
+
+// receiveLinks passes links, found by searchers, until search is aborted.
+// After abort the rest of links is discarded, so that searchers are not blocked.
+func receiveLinks(torrentsChan chan *bittorrent.TorrentFile, task *watchdog.Task) <-chan *bittorrent.TorrentFile {
+	ret := make(chan *bittorrent.TorrentFile)
+	go func() {
+		defer close(ret)
+		for {
+			select {
+			case torrent, ok := <-torrentsChan:
+				if !ok {
+					return
+				}
+				ret <- torrent
+			case <-task.C():
+				go func() {
+					for range torrentsChan {
+					}
+				}()
+				return
+			}
+		}
+	}()
+	return ret
+}
+
+// mergeLink adds torrent to unique torrents, merging trackers, providers and
+// quality tags of torrents, found by several providers
+func mergeLink(torrentsMap map[string]*bittorrent.TorrentFile, torrent *bittorrent.TorrentFile) {
+	if torrent.InfoHash == "" {
+		return
+	}
+
+	torrentKey := torrent.InfoHash
+	if torrent.IsPrivate {
+		torrentKey = torrent.InfoHash + "-" + torrent.Provider
+	}
+
+	if existingTorrent, exists := torrentsMap[torrentKey]; exists {
+		// Collect all trackers
+		for _, trackerURL := range torrent.Trackers {
+			if !util.StringSliceContains(existingTorrent.Trackers, trackerURL) {
+				existingTorrent.Trackers = append(existingTorrent.Trackers, trackerURL)
+			}
+		}
+
+		existingTorrent.Provider += ", " + torrent.Provider
+		if torrent.Resolution > existingTorrent.Resolution {
+			existingTorrent.Name = torrent.Name
+			existingTorrent.Resolution = torrent.Resolution
+		}
+		if torrent.VideoCodec > existingTorrent.VideoCodec {
+			existingTorrent.VideoCodec = torrent.VideoCodec
+		}
+		if torrent.AudioCodec > existingTorrent.AudioCodec {
+			existingTorrent.AudioCodec = torrent.AudioCodec
+		}
+		if torrent.RipType > existingTorrent.RipType {
+			existingTorrent.RipType = torrent.RipType
+		}
+		if torrent.SceneRating > existingTorrent.SceneRating {
+			existingTorrent.SceneRating = torrent.SceneRating
+		}
+		if existingTorrent.Title == "" && torrent.Title != "" {
+			existingTorrent.Title = torrent.Title
+		}
+		if existingTorrent.IsMagnet() && !torrent.IsMagnet() {
+			existingTorrent.URI = torrent.URI
+		}
+		if existingTorrent.Seeds < torrent.Seeds {
+			existingTorrent.Seeds = torrent.Seeds
+			existingTorrent.Peers = torrent.Peers
+		}
+
+		existingTorrent.Multi = true
+	} else {
+		torrentsMap[torrentKey] = torrent
+	}
+}
+
+// updateTorrentFiles writes collected trackers into downloaded .torrent files
+func updateTorrentFiles(torrents []*bittorrent.TorrentFile) {
 	for _, t := range torrents {
 		if _, err := os.Stat(t.URI); err != nil {
 			continue
@@ -367,67 +513,4 @@ func processLinks(torrentsChan chan *bittorrent.TorrentFile, sortType int, isSil
 		}
 
 	}
-
-	// Sorting resulting list of torrents
-	conf := config.Get()
-	sortMode := conf.SortingModeMovies
-	resolutionPreference := conf.ResolutionPreferenceMovies
-
-	if sortType == SortShows {
-		sortMode = conf.SortingModeShows
-		resolutionPreference = conf.ResolutionPreferenceShows
-	}
-
-	seeds := func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Seeds > c2.Seeds }
-	resolutionUp := func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Resolution < c2.Resolution }
-	resolutionDown := func(c1, c2 *bittorrent.TorrentFile) bool { return c1.Resolution > c2.Resolution }
-	resolution720p1080p := func(c1, c2 *bittorrent.TorrentFile) bool { return Resolution720p1080p(c1) < Resolution720p1080p(c2) }
-	resolution720p480p := func(c1, c2 *bittorrent.TorrentFile) bool { return Resolution720p480p(c1) < Resolution720p480p(c2) }
-	balanced := func(c1, c2 *bittorrent.TorrentFile) bool { return float64(c1.Seeds) > Balanced(c2) }
-
-	if sortMode == SortBySize {
-		sort.Slice(torrents, func(i, j int) bool {
-			return torrents[i].SizeParsed > torrents[j].SizeParsed
-		})
-	} else if sortMode == SortBySeeders {
-		sort.Sort(sort.Reverse(BySeeds(torrents)))
-	} else {
-		switch resolutionPreference {
-		case Sort1080p720p480p:
-			if sortMode == SortBalanced {
-				SortBy(balanced, resolutionDown).Sort(torrents)
-			} else {
-				SortBy(resolutionDown, seeds).Sort(torrents)
-			}
-			break
-		case Sort480p720p1080p:
-			if sortMode == SortBalanced {
-				SortBy(balanced, resolutionUp).Sort(torrents)
-			} else {
-				SortBy(resolutionUp, seeds).Sort(torrents)
-			}
-			break
-		case Sort720p1080p480p:
-			if sortMode == SortBalanced {
-				SortBy(balanced, resolution720p1080p).Sort(torrents)
-			} else {
-				SortBy(resolution720p1080p, seeds).Sort(torrents)
-			}
-			break
-		case Sort720p480p1080p:
-			if sortMode == SortBalanced {
-				SortBy(balanced, resolution720p480p).Sort(torrents)
-			} else {
-				SortBy(resolution720p480p, seeds).Sort(torrents)
-			}
-			break
-		}
-	}
-
-	// log.Info("Sorted torrent candidates.")
-	// for _, torrent := range torrents {
-	// 	log.Infof("S:%d P:%d %s - %s - %s", torrent.Seeds, torrent.Peers, torrent.Name, torrent.Provider, torrent.URI)
-	// }
-
-	return torrents
 }
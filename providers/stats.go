@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/database"
+)
+
+const (
+	statsKeyPrefix = "provider.stats."
+	statsExpire    = 30 * 24 * 60 * 60
+
+	// Provider, failed this number of searches in a row, is considered failing
+	failingThreshold = 3
+	// Failing provider gets this part of its timeout, not to delay other results
+	failingTimeoutDivisor = 3
+	failingTimeoutMin     = 5 * time.Second
+)
+
+// ProviderStats keeps search statistics of a provider
+type ProviderStats struct {
+	ID        string `json:"id"`
+	Calls     int    `json:"calls"`
+	Successes int    `json:"successes"`
+	Timeouts  int    `json:"timeouts"`
+	// Latency is a sum of all calls latencies in milliseconds
+	Latency int64 `json:"latency"`
+	// Results is a sum of all returned torrents
+	Results int `json:"results"`
+	// Failures is a number of failed calls in a row
+	Failures     int       `json:"failures"`
+	LastTimedOut bool      `json:"last_timed_out"`
+	LastCall     time.Time `json:"last_call"`
+}
+
+var providerStats = struct {
+	sync.Mutex
+	items map[string]*ProviderStats
+}{
+	items: map[string]*ProviderStats{},
+}
+
+// AverageLatency returns average time of provider answer
+func (s *ProviderStats) AverageLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return time.Duration(s.Latency/int64(s.Calls)) * time.Millisecond
+}
+
+// AverageResults returns average count of torrents, returned by provider
+func (s *ProviderStats) AverageResults() float64 {
+	if s.Successes == 0 {
+		return 0
+	}
+	return float64(s.Results) / float64(s.Successes)
+}
+
+// SuccessRate returns percent of calls, answered in time
+func (s *ProviderStats) SuccessRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Successes) * 100 / float64(s.Calls)
+}
+
+// IsFailing reports whether provider fails consistently and should be deprioritized
+func (s *ProviderStats) IsFailing() bool {
+	return s.Failures >= failingThreshold
+}
+
+// GetProviderStats returns a copy of provider statistics, loaded from cache database if needed
+func GetProviderStats(id string) ProviderStats {
+	providerStats.Lock()
+	defer providerStats.Unlock()
+
+	return *loadStats(id)
+}
+
+// recordCall adds provider call result to statistics
+func recordCall(id string, latency time.Duration, results int, success bool, timedOut bool) {
+	providerStats.Lock()
+	defer providerStats.Unlock()
+
+	s := loadStats(id)
+	s.Calls++
+	s.Latency += latency.Milliseconds()
+	s.LastTimedOut = timedOut
+	s.LastCall = time.Now()
+	if timedOut {
+		s.Timeouts++
+	}
+	if success {
+		s.Successes++
+		s.Results += results
+		s.Failures = 0
+	} else {
+		s.Failures++
+		if s.Failures == failingThreshold {
+			log.Warningf("Provider %s failed %d searches in a row, deprioritizing it", id, s.Failures)
+		}
+	}
+
+	if err := database.GetCache().SetCachedObject(database.CommonBucket, statsExpire, statsKeyPrefix+id, s); err != nil {
+		log.Warningf("Could not save statistics of %s: %s", id, err)
+	}
+}
+
+func loadStats(id string) *ProviderStats {
+	if s, ok := providerStats.items[id]; ok {
+		return s
+	}
+
+	s := &ProviderStats{}
+	if err := database.GetCache().GetCachedObject(database.CommonBucket, statsKeyPrefix+id, s); err != nil || s.ID != id {
+		s = &ProviderStats{ID: id}
+	}
+	providerStats.items[id] = s
+	return s
+}
+
+// prioritizedTimeout shortens timeout of a failing provider
+func prioritizedTimeout(id string, timeout time.Duration) time.Duration {
+	if s := GetProviderStats(id); !s.IsFailing() {
+		return timeout
+	}
+
+	if reduced := timeout / failingTimeoutDivisor; reduced > failingTimeoutMin {
+		return reduced
+	} else if timeout > failingTimeoutMin {
+		return failingTimeoutMin
+	}
+	return timeout
+}
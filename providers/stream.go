@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// SearchResults is a snapshot of streaming search, sent each time a provider answers.
+// Torrents are unique, sorted torrents of all answered providers.
+type SearchResults struct {
+	Torrents []*bittorrent.TorrentFile
+	Pending  int
+	Total    int
+}
+
+// SearchMovieStream ...
+func SearchMovieStream(searchers []MovieSearcher, movie *tmdb.Movie) <-chan *SearchResults {
+	calls := make([]func() []*bittorrent.TorrentFile, 0, len(searchers))
+	for _, searcher := range searchers {
+		searcher := searcher
+		calls = append(calls, func() []*bittorrent.TorrentFile {
+			return searcher.SearchMovieLinks(movie)
+		})
+	}
+
+	return streamLinks(calls, SortMovies)
+}
+
+// SearchSeasonStream ...
+func SearchSeasonStream(searchers []SeasonSearcher, show *tmdb.Show, season *tmdb.Season) <-chan *SearchResults {
+	calls := make([]func() []*bittorrent.TorrentFile, 0, len(searchers))
+	for _, searcher := range searchers {
+		searcher := searcher
+		calls = append(calls, func() []*bittorrent.TorrentFile {
+			return searcher.SearchSeasonLinks(show, season)
+		})
+	}
+
+	return streamLinks(calls, SortShows)
+}
+
+// SearchEpisodeStream ...
+func SearchEpisodeStream(searchers []EpisodeSearcher, show *tmdb.Show, episode *tmdb.Episode) <-chan *SearchResults {
+	calls := make([]func() []*bittorrent.TorrentFile, 0, len(searchers))
+	for _, searcher := range searchers {
+		searcher := searcher
+		calls = append(calls, func() []*bittorrent.TorrentFile {
+			return searcher.SearchEpisodeLinks(show, episode)
+		})
+	}
+
+	return streamLinks(calls, SortShows)
+}
+
+// streamLinks runs provider searches in parallel and merges results of each provider,
+// as soon as they are resolved. Channel is closed after the last provider answers.
+func streamLinks(calls []func() []*bittorrent.TorrentFile, sortType int) <-chan *SearchResults {
+	// Buffered, so that aggregator is not blocked by the consumer, showing a dialog
+	results := make(chan *SearchResults, len(calls))
+	batches := make(chan []*bittorrent.TorrentFile)
+
+	for _, call := range calls {
+		go func(call func() []*bittorrent.TorrentFile) {
+			torrents := call()
+			resolveLinks(torrents)
+			batches <- torrents
+		}(call)
+	}
+
+	go func() {
+		defer close(results)
+
+		torrentsMap := map[string]*bittorrent.TorrentFile{}
+		for pending := len(calls); pending > 0; {
+			batch := <-batches
+			pending--
+
+			for _, torrent := range batch {
+				mergeLink(torrentsMap, torrent)
+			}
+
+			results <- &SearchResults{
+				Torrents: snapshotLinks(torrentsMap, sortType),
+				Pending:  pending,
+				Total:    len(calls),
+			}
+		}
+	}()
+
+	return results
+}
+
+// resolveLinks resolves torrents of a provider in parallel, giving up on slow ones
+func resolveLinks(torrents []*bittorrent.TorrentFile) {
+	wg := sync.WaitGroup{}
+	for _, torrent := range torrents {
+		wg.Add(1)
+		go func(torrent *bittorrent.TorrentFile) {
+			defer wg.Done()
+
+			resolved := make(chan error, 1)
+			go func() {
+				resolved <- torrent.Resolve()
+			}()
+
+			select {
+			case <-time.After(trackerTimeout * 2):
+			case err := <-resolved:
+				if err != nil {
+					log.Warningf("Resolve failed for %s : %s", torrent.URI, err.Error())
+				}
+			}
+		}(torrent)
+	}
+	wg.Wait()
+}
+
+// snapshotLinks returns sorted copies of merged torrents,
+// so that later merges do not change torrents, shown to the user
+func snapshotLinks(torrentsMap map[string]*bittorrent.TorrentFile, sortType int) []*bittorrent.TorrentFile {
+	torrents := make([]*bittorrent.TorrentFile, 0, len(torrentsMap))
+	for _, torrent := range torrentsMap {
+		torrent.UpdateTorrentTrackers()
+		torrents = append(torrents, torrent)
+	}
+	updateTorrentFiles(torrents)
+
+	ret := make([]*bittorrent.TorrentFile, 0, len(torrents))
+	for _, torrent := range torrents {
+		t := *torrent
+		t.Trackers = append([]string{}, torrent.Trackers...)
+		ret = append(ret, &t)
+	}
+
+	SortTorrents(ret, RankingRules(sortType))
+	return ret
+}
@@ -12,7 +12,9 @@ import (
 
 	"github.com/elgatito/elementum/bittorrent"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/tvdb"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 	"github.com/gin-gonic/gin"
@@ -79,6 +81,11 @@ func getSearchers() []interface{} {
 			list = append(list, NewAddonSearcher(addon.ID))
 		}
 	}
+	for _, provider := range NativeProviders() {
+		if provider.Enabled() {
+			list = append(list, provider)
+		}
+	}
 	return list
 }
 
@@ -86,7 +93,9 @@ func getSearchers() []interface{} {
 func GetMovieSearchers() []MovieSearcher {
 	searchers := make([]MovieSearcher, 0)
 	for _, searcher := range getSearchers() {
-		searchers = append(searchers, searcher.(MovieSearcher))
+		if s, ok := searcher.(MovieSearcher); ok && isContentEnabled(searcher, ContentMovie) {
+			searchers = append(searchers, s)
+		}
 	}
 	return searchers
 }
@@ -95,7 +104,9 @@ func GetMovieSearchers() []MovieSearcher {
 func GetSeasonSearchers() []SeasonSearcher {
 	searchers := make([]SeasonSearcher, 0)
 	for _, searcher := range getSearchers() {
-		searchers = append(searchers, searcher.(SeasonSearcher))
+		if s, ok := searcher.(SeasonSearcher); ok && isContentEnabled(searcher, ContentSeason) {
+			searchers = append(searchers, s)
+		}
 	}
 	return searchers
 }
@@ -104,7 +115,9 @@ func GetSeasonSearchers() []SeasonSearcher {
 func GetEpisodeSearchers() []EpisodeSearcher {
 	searchers := make([]EpisodeSearcher, 0)
 	for _, searcher := range getSearchers() {
-		searchers = append(searchers, searcher.(EpisodeSearcher))
+		if s, ok := searcher.(EpisodeSearcher); ok && isContentEnabled(searcher, ContentEpisode) {
+			searchers = append(searchers, s)
+		}
 	}
 	return searchers
 }
@@ -113,7 +126,9 @@ func GetEpisodeSearchers() []EpisodeSearcher {
 func GetSearchers() []Searcher {
 	searchers := make([]Searcher, 0)
 	for _, searcher := range getSearchers() {
-		searchers = append(searchers, searcher.(Searcher))
+		if s, ok := searcher.(Searcher); ok && isContentEnabled(searcher, ContentSearch) {
+			searchers = append(searchers, s)
+		}
 	}
 	return searchers
 }
@@ -294,7 +309,7 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 
 	// Is this an Anime?
 	absoluteNumber := 0
-	if tvdbID > 0 && show.IsAnime() {
+	if tvdbID > 0 && show.IsAnime() && as.Capabilities().SupportsAbsoluteNumbers() {
 		an, st := show.AnimeInfo(episode)
 
 		if an != 0 {
@@ -340,6 +355,15 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 		}
 	}
 
+	// Collect titles, missing in TMDB, from TVDB translations
+	if tvdbID > 0 && tvdb.IsV4Enabled() {
+		for language, title := range tvdb.GetTitles(tvdbID) {
+			if _, ok := sObject.Titles[language]; !ok {
+				sObject.Titles[language] = NormalizeTitle(title)
+			}
+		}
+	}
+
 	if show.IsAnime() && config.Get().UseAnimeEnTitle {
 		if t, ok := sObject.Titles["en"]; ok {
 			sObject.Titles["original"] = t
@@ -355,6 +379,19 @@ func (as *AddonSearcher) GetEpisodeSearchObject(show *tmdb.Show, episode *tmdb.E
 
 func (as *AddonSearcher) call(method string, searchObject interface{}) []*bittorrent.TorrentFile {
 	torrents := make([]*bittorrent.TorrentFile, 0)
+
+	settings := database.GetStorm().GetProviderSettings(as.addonID)
+	timeout := prioritizedTimeout(as.addonID, searchTimeout(settings))
+	started := time.Now()
+
+	release, ok := acquireSlot(settings, timeout)
+	if !ok {
+		as.log.Warningf("Provider %s has too many running searches. Ignored.", as.addonID)
+		recordCall(as.addonID, time.Since(started), 0, false, true)
+		return torrents
+	}
+	defer release()
+
 	cid, c := GetCallback()
 	cbURL := fmt.Sprintf("%s/callbacks/%s", util.GetHTTPHost(), cid)
 
@@ -366,18 +403,17 @@ func (as *AddonSearcher) call(method string, searchObject interface{}) []*bittor
 
 	xbmc.ExecuteAddon(as.addonID, payload.String())
 
-	timeout := providerTimeout()
-	if config.Get().CustomProviderTimeoutEnabled == true {
-		timeout = time.Duration(config.Get().CustomProviderTimeout) * time.Second
-	}
-
 	select {
 	case <-time.After(timeout):
 		as.log.Warningf("Provider %s was too slow. Ignored.", as.addonID)
 		RemoveCallback(cid)
+		recordCall(as.addonID, time.Since(started), 0, false, true)
 	case result := <-c:
 		if err := json.Unmarshal(result, &torrents); err != nil {
 			log.Errorf("Failed to unmarshal torrents: %s", err)
+			recordCall(as.addonID, time.Since(started), 0, false, false)
+		} else {
+			recordCall(as.addonID, time.Since(started), len(torrents), true, false)
 		}
 	}
 
@@ -386,12 +422,16 @@ func (as *AddonSearcher) call(method string, searchObject interface{}) []*bittor
 
 // SearchLinks ...
 func (as *AddonSearcher) SearchLinks(query string) []*bittorrent.TorrentFile {
+	if as.skips(ContentSearch, false, true) {
+		return []*bittorrent.TorrentFile{}
+	}
+
 	return as.call("search", as.GetQuerySearchObject(query))
 }
 
 // SearchMovieLinks ...
 func (as *AddonSearcher) SearchMovieLinks(movie *tmdb.Movie) []*bittorrent.TorrentFile {
-	if movie == nil {
+	if movie == nil || as.skips(ContentMovie, false, true) {
 		return []*bittorrent.TorrentFile{}
 	}
 
@@ -400,7 +440,7 @@ func (as *AddonSearcher) SearchMovieLinks(movie *tmdb.Movie) []*bittorrent.Torre
 
 // SearchMovieLinksSilent ...
 func (as *AddonSearcher) SearchMovieLinksSilent(movie *tmdb.Movie, withAuth bool) []*bittorrent.TorrentFile {
-	if movie == nil {
+	if movie == nil || as.skips(ContentMovie, false, withAuth) {
 		return []*bittorrent.TorrentFile{}
 	}
 
@@ -409,18 +449,42 @@ func (as *AddonSearcher) SearchMovieLinksSilent(movie *tmdb.Movie, withAuth bool
 
 // SearchSeasonLinks ...
 func (as *AddonSearcher) SearchSeasonLinks(show *tmdb.Show, season *tmdb.Season) []*bittorrent.TorrentFile {
-	if show == nil || season == nil {
+	if show == nil || season == nil || as.skips(ContentSeason, show.IsAnime(), true) {
 		return []*bittorrent.TorrentFile{}
 	}
 
 	return as.call("search_season", as.GetSeasonSearchObject(show, season))
 }
 
+// SearchSeasonLinksSilent ...
+func (as *AddonSearcher) SearchSeasonLinksSilent(show *tmdb.Show, season *tmdb.Season, withAuth bool) []*bittorrent.TorrentFile {
+	if show == nil || season == nil || as.skips(ContentSeason, show.IsAnime(), withAuth) {
+		return []*bittorrent.TorrentFile{}
+	}
+
+	o := as.GetSeasonSearchObject(show, season)
+	o.Silent = true
+	o.SkipAuth = !withAuth
+	return as.call("search_season", o)
+}
+
 // SearchEpisodeLinks ...
 func (as *AddonSearcher) SearchEpisodeLinks(show *tmdb.Show, episode *tmdb.Episode) []*bittorrent.TorrentFile {
-	if show == nil || episode == nil {
+	if show == nil || episode == nil || as.skips(ContentEpisode, show.IsAnime(), true) {
 		return []*bittorrent.TorrentFile{}
 	}
 
 	return as.call("search_episode", as.GetEpisodeSearchObject(show, episode))
 }
+
+// SearchEpisodeLinksSilent ...
+func (as *AddonSearcher) SearchEpisodeLinksSilent(show *tmdb.Show, episode *tmdb.Episode, withAuth bool) []*bittorrent.TorrentFile {
+	if show == nil || episode == nil || as.skips(ContentEpisode, show.IsAnime(), withAuth) {
+		return []*bittorrent.TorrentFile{}
+	}
+
+	o := as.GetEpisodeSearchObject(show, episode)
+	o.Silent = true
+	o.SkipAuth = !withAuth
+	return as.call("search_episode", o)
+}
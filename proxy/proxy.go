@@ -59,6 +59,8 @@ func handleRequest(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *ht
 	req.Header.Del("Connection")
 	req.Header.Del("Accept-Encoding")
 
+	req, resp := applyRule(req, ctx)
+
 	// req.Header.Del("Cookie")
 	// req.Header.Del("Origin")
 
@@ -74,7 +76,7 @@ func handleRequest(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *ht
 	ctx.UserData = bodyBytes
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	return req, nil
+	return req, resp
 }
 
 func handleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
@@ -86,7 +88,7 @@ func handleResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
 		dumpResponse(resp, ctx, false, false)
 	}
 
-	return resp
+	return cacheResponse(resp, ctx)
 }
 
 func dumpRequest(req *http.Request, ctx *goproxy.ProxyCtx, details bool, body bool) {
@@ -131,6 +133,11 @@ func StartProxy() *CustomProxy {
 	Proxy.OnRequest(goproxy.ReqHostMatches(regexp.MustCompile(hostMatch))).
 		HandleConnect(AlwaysHTTPMitm)
 
+	if err := LoadRules(); err != nil {
+		log.Warningf("Could not load proxy rules: %s", err)
+	}
+	startWatchRules()
+
 	Proxy.OnRequest().DoFunc(handleRequest)
 	Proxy.OnResponse().DoFunc(handleResponse)
 
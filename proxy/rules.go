@@ -0,0 +1,305 @@
+package proxy
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+
+	"github.com/elazarl/goproxy"
+)
+
+const (
+	rulesFileName  = "proxy_rules.json"
+	cacheHitHeader = "X-Elementum-Cache"
+
+	// Responses, larger than this, are not cached, and are passed through as is
+	responseMaxBodySize = 1024 * 1024
+	// Cached responses are evicted, least recently used first, above this total size
+	responsesMaxSize = 32 * 1024 * 1024
+)
+
+// Rule describes rewriting of requests for a single domain.
+// Domain matches the host itself and all of its subdomains.
+type Rule struct {
+	Domain   string            `json:"domain"`
+	Mirror   string            `json:"mirror"`
+	Headers  map[string]string `json:"headers"`
+	CacheTTL int               `json:"cache_ttl"`
+}
+
+type cachedResponse struct {
+	key     string
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+var (
+	rules     = []*Rule{}
+	rulesTime time.Time
+	rulesLock sync.RWMutex
+
+	responses      = map[string]*list.Element{}
+	responsesOrder = list.New()
+	responsesSize  int
+	responsesLock  sync.Mutex
+
+	watchRulesOnce sync.Once
+)
+
+// RulesPath returns location of the rules file
+func RulesPath() string {
+	return filepath.Join(config.Get().Info.Profile, rulesFileName)
+}
+
+// LoadRules reads rewriting rules from the rules file and drops cached responses
+func LoadRules() error {
+	path := RulesPath()
+
+	st, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		setRules([]*Rule{}, time.Time{})
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	newRules := []*Rule{}
+	if err := json.Unmarshal(data, &newRules); err != nil {
+		log.Warningf("Could not parse proxy rules from %s: %s", path, err)
+		return err
+	}
+
+	for _, r := range newRules {
+		r.Domain = strings.ToLower(strings.TrimPrefix(r.Domain, "."))
+	}
+
+	setRules(newRules, st.ModTime())
+	log.Infof("Loaded %d proxy rules from %s", len(newRules), path)
+	return nil
+}
+
+// reloadRulesIfChanged reloads rules file if it was modified since last load
+func reloadRulesIfChanged() {
+	st, err := os.Stat(RulesPath())
+	if err != nil {
+		return
+	}
+
+	rulesLock.RLock()
+	changed := !st.ModTime().Equal(rulesTime)
+	rulesLock.RUnlock()
+
+	if changed {
+		LoadRules()
+	}
+}
+
+// startWatchRules starts rules watcher, only once, as proxy is restarted on every reconfigure
+func startWatchRules() {
+	watchRulesOnce.Do(func() {
+		go watchRules()
+	})
+}
+
+// watchRules periodically checks rules file for modifications and drops expired responses
+func watchRules() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reloadRulesIfChanged()
+		pruneResponses()
+	}
+}
+
+func setRules(newRules []*Rule, modTime time.Time) {
+	rulesLock.Lock()
+	rules = newRules
+	rulesTime = modTime
+	rulesLock.Unlock()
+
+	responsesLock.Lock()
+	responses = map[string]*list.Element{}
+	responsesOrder.Init()
+	responsesSize = 0
+	responsesLock.Unlock()
+}
+
+// matchRule returns rule for the host, preferring the most specific domain.
+// Hosts, already rewritten to a mirror, are matched as well.
+func matchRule(host string) *Rule {
+	host = stripPort(host)
+
+	rulesLock.RLock()
+	defer rulesLock.RUnlock()
+
+	var ret *Rule
+	for _, r := range rules {
+		if r.Domain == "" {
+			continue
+		} else if host != r.Domain && !strings.HasSuffix(host, "."+r.Domain) && (r.Mirror == "" || host != stripPort(r.Mirror)) {
+			continue
+		}
+		if ret == nil || len(r.Domain) > len(ret.Domain) {
+			ret = r
+		}
+	}
+
+	return ret
+}
+
+// applyRule rewrites request according to the rule and returns cached response, if there is one
+func applyRule(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+	rule := matchRule(req.URL.Host)
+	if rule == nil {
+		return req, nil
+	}
+
+	if rule.Mirror != "" {
+		log.Debugf("[%d] Rewriting %s to mirror %s", ctx.Session, req.URL.Host, rule.Mirror)
+		req.URL.Host = rule.Mirror
+		req.Host = rule.Mirror
+	}
+	for k, v := range rule.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if !isCacheable(req, rule) {
+		return req, nil
+	}
+
+	responsesLock.Lock()
+	defer responsesLock.Unlock()
+
+	key := req.URL.String()
+	if e, ok := responses[key]; ok {
+		if cached := e.Value.(*cachedResponse); time.Now().Before(cached.expires) {
+			log.Debugf("[%d] Serving %s from proxy cache", ctx.Session, key)
+			responsesOrder.MoveToFront(e)
+			return req, cached.toResponse(req)
+		}
+		removeResponseLocked(key)
+	}
+
+	return req, nil
+}
+
+// cacheResponse stores successful response body if the rule allows caching
+func cacheResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+	if resp == nil || resp.StatusCode != http.StatusOK || ctx.Req == nil || resp.Header.Get(cacheHitHeader) != "" {
+		return resp
+	}
+
+	rule := matchRule(ctx.Req.URL.Host)
+	if rule == nil || !isCacheable(ctx.Req, rule) {
+		return resp
+	}
+
+	if resp.ContentLength > responseMaxBodySize {
+		return resp
+	}
+
+	// Body is read up to the limit, larger bodies are passed through without caching
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, responseMaxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp
+	} else if len(body) > responseMaxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	key := ctx.Req.URL.String()
+
+	responsesLock.Lock()
+	defer responsesLock.Unlock()
+
+	removeResponseLocked(key)
+	responses[key] = responsesOrder.PushFront(&cachedResponse{
+		key:     key,
+		status:  resp.StatusCode,
+		header:  resp.Header.Clone(),
+		body:    body,
+		expires: time.Now().Add(time.Duration(rule.CacheTTL) * time.Second),
+	})
+	responsesSize += len(body)
+
+	for responsesSize > responsesMaxSize {
+		removeResponseLocked(responsesOrder.Back().Value.(*cachedResponse).key)
+	}
+
+	return resp
+}
+
+// pruneResponses drops expired responses
+func pruneResponses() {
+	responsesLock.Lock()
+	defer responsesLock.Unlock()
+
+	now := time.Now()
+	for key, e := range responses {
+		if !now.Before(e.Value.(*cachedResponse).expires) {
+			removeResponseLocked(key)
+		}
+	}
+}
+
+func removeResponseLocked(key string) {
+	if e, ok := responses[key]; ok {
+		responsesSize -= len(e.Value.(*cachedResponse).body)
+		responsesOrder.Remove(e)
+		delete(responses, key)
+	}
+}
+
+func stripPort(host string) string {
+	host = strings.ToLower(host)
+	if idx := strings.LastIndex(host, ":"); idx != -1 && !strings.HasSuffix(host, "]") {
+		host = host[:idx]
+	}
+	return host
+}
+
+func isCacheable(req *http.Request, rule *Rule) bool {
+	return rule.CacheTTL > 0 && req.Method == http.MethodGet
+}
+
+func (c *cachedResponse) toResponse(req *http.Request) *http.Response {
+	header := c.header.Clone()
+	header.Set(cacheHitHeader, "HIT")
+
+	return &http.Response{
+		StatusCode:    c.status,
+		Status:        http.StatusText(c.status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,136 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+)
+
+const requestTimeout = 30 * time.Second
+
+type rssFeed struct {
+	XMLName xml.Name  `xml:"rss"`
+	Items   []rssItem `xml:"channel>item"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	Link      string `xml:"link"`
+	GUID      string `xml:"guid"`
+	Size      uint64 `xml:"size"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length uint64 `xml:"length,attr"`
+	} `xml:"enclosure"`
+	// Torznab and ezRSS attributes, used by most trackers
+	Attrs      []rssAttr `xml:"attr"`
+	MagnetURI  string    `xml:"magnetURI"`
+	InfoHash   string    `xml:"infoHash"`
+	ContentLen uint64    `xml:"contentLength"`
+}
+
+type rssAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// fetch downloads and parses RSS feed
+func fetch(url string) ([]rssItem, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Feed responded with %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := rssFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	return feed.Items, nil
+}
+
+func (i *rssItem) attr(name string) string {
+	for _, a := range i.Attrs {
+		if a.Name == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// id returns stable identifier of an item, to process it only once
+func (i *rssItem) id() string {
+	if i.GUID != "" {
+		return i.GUID
+	} else if i.Link != "" {
+		return i.Link
+	}
+	return i.Title
+}
+
+// torrentFile converts feed item into a torrent, preferring magnet over .torrent download
+func (i *rssItem) torrentFile() *bittorrent.TorrentFile {
+	uri := i.MagnetURI
+	if uri == "" {
+		uri = i.attr("magneturl")
+	}
+	if uri == "" {
+		uri = i.Enclosure.URL
+	}
+	if uri == "" {
+		uri = i.Link
+	}
+	if uri == "" {
+		return nil
+	}
+
+	size := i.Size
+	if size == 0 {
+		size = i.ContentLen
+	}
+	if size == 0 {
+		size, _ = strconv.ParseUint(i.attr("size"), 10, 64)
+	}
+	if size == 0 {
+		size = i.Enclosure.Length
+	}
+
+	infoHash := i.InfoHash
+	if infoHash == "" {
+		infoHash = i.attr("infohash")
+	}
+
+	t := &bittorrent.TorrentFile{
+		URI:      uri,
+		InfoHash: strings.ToLower(infoHash),
+		Title:    i.Title,
+		Name:     i.Title,
+		Provider: "RSS",
+		Icon:     config.AddonIcon(),
+	}
+	if size > 0 {
+		t.Size = humanize.Bytes(size)
+	}
+
+	t.Init()
+	return t
+}
@@ -0,0 +1,178 @@
+package rss
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/bittorrent"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// Feeds are checked not more often, than this, regardless of settings
+const minCheckInterval = 5 * time.Minute
+
+var (
+	log = logging.MustGetLogger("rss")
+
+	// Prevents parallel checks from the loop and from manual refresh
+	checkMu sync.Mutex
+
+	episodeMatcher = regexp.MustCompile(`(?i)\bS(\d{1,2})[\s._-]*E(\d{1,3})\b`)
+)
+
+// Run checks RSS feeds periodically, until closing is signalled
+func Run(s *bittorrent.Service, closing <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var lastCheck time.Time
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			conf := config.Get()
+			if !conf.RSSEnabled {
+				continue
+			}
+
+			interval := time.Duration(conf.RSSCheckInterval) * time.Minute
+			if interval < minCheckInterval {
+				interval = minCheckInterval
+			}
+			if time.Since(lastCheck) < interval {
+				continue
+			}
+
+			lastCheck = time.Now()
+			Check(s)
+		}
+	}
+}
+
+// Check processes new items of all enabled feeds, and returns number of added downloads
+func Check(s *bittorrent.Service) (added int) {
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	for _, feed := range database.GetStorm().GetRSSFeeds() {
+		if !feed.Enabled {
+			continue
+		}
+
+		feed := feed
+		count, err := checkFeed(s, &feed)
+		if err != nil {
+			log.Warningf("Could not check RSS feed '%s': %s", feed.Name, err)
+			continue
+		}
+		added += count
+
+		feed.Checked = time.Now()
+		database.GetStorm().SaveRSSFeed(&feed)
+	}
+
+	return
+}
+
+func checkFeed(s *bittorrent.Service, feed *database.RSSFeed) (added int, err error) {
+	var matcher *regexp.Regexp
+	if feed.TitleRegex != "" {
+		if matcher, err = regexp.Compile("(?i)" + feed.TitleRegex); err != nil {
+			return 0, fmt.Errorf("Wrong title regex: %s", err)
+		}
+	}
+
+	items, err := fetch(feed.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	// Feeds usually list newest items first, older items are processed first
+	for i := len(items) - 1; i >= 0; i-- {
+		item := &items[i]
+		guid := item.id()
+		if guid == "" || database.GetStorm().IsRSSItemProcessed(feed.ID, guid) {
+			continue
+		}
+
+		t := item.torrentFile()
+		if t == nil || !matches(feed, matcher, t) {
+			continue
+		}
+
+		infoHash, err := download(s, feed, t)
+		if err != nil {
+			log.Warningf("Could not add RSS item '%s': %s", item.Title, err)
+			continue
+		}
+
+		database.GetStorm().AddRSSItem(feed.ID, guid, item.Title, infoHash)
+		notifications.Notify(notifications.RSS, fmt.Sprintf("RSS: %s", item.Title))
+		added++
+	}
+
+	return
+}
+
+// matches checks feed rules: title regex, minimal quality and maximal size
+func matches(feed *database.RSSFeed, matcher *regexp.Regexp, t *bittorrent.TorrentFile) bool {
+	if matcher != nil && !matcher.MatchString(t.Name) {
+		return false
+	}
+	if feed.MinResolution > 0 && t.Resolution < feed.MinResolution {
+		return false
+	}
+	if feed.MaxSize > 0 && t.SizeParsed > uint64(feed.MaxSize)*1024*1024 {
+		return false
+	}
+	return true
+}
+
+// download adds torrent with all files and maps it to a show episode, when it is identifiable
+func download(s *bittorrent.Service, feed *database.RSSFeed, t *bittorrent.TorrentFile) (string, error) {
+	log.Infof("Adding RSS item '%s' from feed '%s'", t.Name, feed.Name)
+
+	torrent, err := s.AddTorrent(t.URI, false, config.Get().DownloadStorage)
+	if err != nil {
+		return "", err
+	}
+
+	showID, season, episode := identify(feed, t.Name)
+	mediaID, mediaType := 0, ""
+	if showID != 0 {
+		if e := tmdb.GetEpisode(showID, season, episode, config.Get().Language); e != nil {
+			mediaID, mediaType = e.ID, "episode"
+		}
+	}
+	database.GetStorm().UpdateBTItem(torrent.InfoHash(), mediaID, mediaType, []string{}, t.Name, showID, season, episode)
+
+	torrent.DownloadAllFiles()
+	torrent.SaveDBFiles()
+
+	return torrent.InfoHash(), nil
+}
+
+// identify returns TMDB show, season and episode of an item, if feed is bound to a show
+// and title contains episode number
+func identify(feed *database.RSSFeed, title string) (showID, season, episode int) {
+	if feed.ShowID == 0 {
+		return
+	}
+
+	m := episodeMatcher.FindStringSubmatch(title)
+	if m == nil {
+		return
+	}
+	season, _ = strconv.Atoi(m[1])
+	episode, _ = strconv.Atoi(m[2])
+	return feed.ShowID, season, episode
+}
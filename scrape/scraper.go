@@ -20,7 +20,6 @@ import (
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
 	"github.com/elgatito/elementum/util"
-	"github.com/elgatito/elementum/xbmc"
 )
 
 const (
@@ -96,7 +95,7 @@ func runUpdater() {
 
 		// Update Kodi library if needed
 		if libraryUpdated {
-			xbmc.VideoLibraryScanDirectory(library.MoviesLibraryPath(), true)
+			library.PlanKodiUpdate()
 		}
 	}()
 
@@ -197,7 +196,7 @@ func addMovieToLibrary(m *trakt.Movie) {
 		return
 	}
 
-	library.AddMovie(strconv.Itoa(m.IDs.TMDB), false)
+	library.AddMovie(strconv.Itoa(m.IDs.TMDB), false, library.ReasonAutoScrape)
 	if config.Get().TraktToken != "" && config.Get().TraktSyncAddedMovies {
 		go trakt.SyncAddedItem("movies", strconv.Itoa(m.IDs.TMDB), config.Get().TraktSyncAddedMoviesLocation)
 	}
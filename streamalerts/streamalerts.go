@@ -0,0 +1,152 @@
+package streamalerts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+)
+
+// Watch providers are cached by TMDB client for a day, so checking more often makes no sense
+const checkInterval = 24 * time.Hour
+
+var log = logging.MustGetLogger("streamalerts")
+
+// Run checks streaming services of watchlist items periodically, until closing is signalled.
+// JustWatch data, provided by TMDB, has no leaving dates, so changes are detected by comparing
+// current services with the ones, known from the previous check.
+func Run(closing <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var lastCheck time.Time
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			if !config.Get().StreamingAlertsEnabled || !config.Get().TraktAuthorized || time.Since(lastCheck) < checkInterval {
+				continue
+			}
+
+			lastCheck = time.Now()
+			Check()
+		}
+	}
+}
+
+// Check compares streaming services of watchlist items with previously known ones
+// and notifies about changes on configured services
+func Check() {
+	if movies, err := trakt.WatchlistMovies(false); err == nil {
+		for _, movie := range movies {
+			if movie != nil && movie.Movie != nil && movie.Movie.IDs.TMDB != 0 {
+				check("movie", movie.Movie.IDs.TMDB, movie.Movie.Title)
+			}
+		}
+	} else {
+		log.Warningf("Could not get movies watchlist: %s", err)
+	}
+
+	if shows, err := trakt.WatchlistShows(false); err == nil {
+		for _, show := range shows {
+			if show != nil && show.Show != nil && show.Show.IDs.TMDB != 0 {
+				check("show", show.Show.IDs.TMDB, show.Show.Title)
+			}
+		}
+	} else {
+		log.Warningf("Could not get shows watchlist: %s", err)
+	}
+}
+
+func check(mediaType string, tmdbID int, title string) {
+	current := services(tmdb.GetWatchProviders(mediaType, tmdbID, config.Get().Region))
+
+	state := database.GetStorm().GetStreamingState(mediaType, tmdbID)
+	if state == nil {
+		// First check only remembers services, there is nothing to compare with
+		database.GetStorm().SaveStreamingState(&database.StreamingState{
+			MediaType: mediaType,
+			TMDBID:    tmdbID,
+			Title:     title,
+			Providers: current,
+			Checked:   time.Now(),
+		})
+		return
+	}
+
+	arrived := difference(current, state.Providers)
+	left := difference(state.Providers, current)
+	if len(arrived) > 0 || len(left) > 0 {
+		state.Arrived = arrived
+		state.Left = left
+		state.Changed = time.Now()
+
+		if len(arrived) > 0 {
+			notifications.Notify(notifications.Streaming, fmt.Sprintf("LOCALIZE[30768];;%s: %s", title, strings.Join(arrived, ", ")))
+		}
+		if len(left) > 0 {
+			notifications.Notify(notifications.Streaming, fmt.Sprintf("LOCALIZE[30769];;%s: %s", title, strings.Join(left, ", ")))
+		}
+	}
+
+	state.Title = title
+	state.Providers = current
+	state.Checked = time.Now()
+	database.GetStorm().SaveStreamingState(state)
+}
+
+// services returns names of subscription and free services, limited to configured ones
+func services(providers *tmdb.WatchProviders) []string {
+	if providers == nil {
+		return []string{}
+	}
+
+	names := tmdb.WatchProviderNames(append(append(append([]*tmdb.WatchProvider{}, providers.Flatrate...), providers.Free...), providers.Ads...))
+
+	wanted := []string{}
+	for _, name := range strings.Split(config.Get().StreamingAlertsServices, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			wanted = append(wanted, name)
+		}
+	}
+	if len(wanted) == 0 {
+		return names
+	}
+
+	ret := []string{}
+	for _, name := range names {
+		for _, w := range wanted {
+			if strings.Contains(strings.ToLower(name), w) {
+				ret = append(ret, name)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// difference returns items of a, missing in b
+func difference(a, b []string) []string {
+	ret := []string{}
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
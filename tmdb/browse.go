@@ -0,0 +1,82 @@
+package tmdb
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmcvetta/napping"
+)
+
+// PopularKeywords are TMDB keywords, offered for movies browsing
+var PopularKeywords = []*IDName{
+	{ID: 12377, Name: "Zombie"},
+	{ID: 10051, Name: "Heist"},
+	{ID: 4379, Name: "Time travel"},
+	{ID: 4565, Name: "Dystopia"},
+	{ID: 4458, Name: "Post-apocalyptic future"},
+	{ID: 9715, Name: "Superhero"},
+	{ID: 9951, Name: "Alien"},
+	{ID: 9882, Name: "Space"},
+	{ID: 310, Name: "Artificial intelligence"},
+	{ID: 3133, Name: "Vampire"},
+	{ID: 10714, Name: "Serial killer"},
+	{ID: 9748, Name: "Revenge"},
+	{ID: 10349, Name: "Survival"},
+	{ID: 779, Name: "Martial arts"},
+	{ID: 7312, Name: "Road trip"},
+	{ID: 6075, Name: "Sports"},
+	{ID: 5565, Name: "Biography"},
+	{ID: 818, Name: "Based on novel or book"},
+}
+
+// PopularNetworks are TV networks and streaming services, offered for shows browsing
+var PopularNetworks = []*IDName{
+	{ID: 213, Name: "Netflix"},
+	{ID: 49, Name: "HBO"},
+	{ID: 3186, Name: "Max"},
+	{ID: 1024, Name: "Amazon"},
+	{ID: 2739, Name: "Disney+"},
+	{ID: 2552, Name: "Apple TV+"},
+	{ID: 453, Name: "Hulu"},
+	{ID: 4330, Name: "Paramount+"},
+	{ID: 3353, Name: "Peacock"},
+	{ID: 174, Name: "AMC"},
+	{ID: 88, Name: "FX"},
+	{ID: 67, Name: "Showtime"},
+	{ID: 318, Name: "Starz"},
+	{ID: 77, Name: "Syfy"},
+	{ID: 4, Name: "BBC One"},
+	{ID: 26, Name: "Channel 4"},
+	{ID: 6, Name: "NBC"},
+	{ID: 16, Name: "CBS"},
+	{ID: 2, Name: "ABC"},
+	{ID: 19, Name: "FOX"},
+	{ID: 71, Name: "The CW"},
+	{ID: 56, Name: "Cartoon Network"},
+	{ID: 80, Name: "Adult Swim"},
+}
+
+// MoviesByKeyword returns popular movies, tagged with a TMDB keyword
+func MoviesByKeyword(keywordID int, language string, page int) (Movies, int) {
+	p := napping.Params{
+		"language":                 language,
+		"sort_by":                  "popularity.desc",
+		"primary_release_date.lte": time.Now().UTC().Format("2006-01-02"),
+		"with_keywords":            strconv.Itoa(keywordID),
+	}
+
+	return listMovies("discover/movie", fmt.Sprintf("keyword.%d", keywordID), p, page)
+}
+
+// ShowsByNetwork returns popular shows, originally aired on a TV network or a streaming service
+func ShowsByNetwork(networkID int, language string, page int) (Shows, int) {
+	p := napping.Params{
+		"language":           language,
+		"sort_by":            "popularity.desc",
+		"first_air_date.lte": time.Now().UTC().Format("2006-01-02"),
+		"with_networks":      strconv.Itoa(networkID),
+	}
+
+	return listShows("discover/tv", fmt.Sprintf("network.%d", networkID), p, page)
+}
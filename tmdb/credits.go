@@ -0,0 +1,129 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Shows can have hundreds of guest stars, only main cast is kept
+const aggregateCastLimit = 50
+
+// AggregateCredits are credits of a show or a season, combined from all of its episodes
+type AggregateCredits struct {
+	Cast []*AggregateCast `json:"cast"`
+	Crew []*AggregateCrew `json:"crew"`
+}
+
+// AggregateCast is a cast member with all roles, played in a show or a season
+type AggregateCast struct {
+	IDName
+	Order             int    `json:"order"`
+	ProfilePath       string `json:"profile_path"`
+	TotalEpisodeCount int    `json:"total_episode_count"`
+	Roles             []*struct {
+		CreditID     string `json:"credit_id"`
+		Character    string `json:"character"`
+		EpisodeCount int    `json:"episode_count"`
+	} `json:"roles"`
+}
+
+// AggregateCrew is a crew member with all jobs, done for a show or a season
+type AggregateCrew struct {
+	IDName
+	Department        string `json:"department"`
+	ProfilePath       string `json:"profile_path"`
+	TotalEpisodeCount int    `json:"total_episode_count"`
+	Jobs              []*struct {
+		CreditID     string `json:"credit_id"`
+		Job          string `json:"job"`
+		EpisodeCount int    `json:"episode_count"`
+	} `json:"jobs"`
+}
+
+// ToCredits converts aggregate credits to regular credits, with episode counts added to characters,
+// so that main cast of the whole series is shown, instead of the latest season cast
+func (c *AggregateCredits) ToCredits() *Credits {
+	if c == nil {
+		return nil
+	}
+
+	cast := make([]*AggregateCast, 0, len(c.Cast))
+	for _, member := range c.Cast {
+		if member != nil {
+			cast = append(cast, member)
+		}
+	}
+	sort.SliceStable(cast, func(i, j int) bool {
+		return cast[i].TotalEpisodeCount > cast[j].TotalEpisodeCount
+	})
+	if len(cast) > aggregateCastLimit {
+		cast = cast[:aggregateCastLimit]
+	}
+
+	ret := &Credits{
+		Cast: make([]*Cast, 0, len(cast)),
+		Crew: make([]*Crew, 0, len(c.Crew)),
+	}
+	for i, member := range cast {
+		characters := make([]string, 0, len(member.Roles))
+		creditID := ""
+		for _, role := range member.Roles {
+			if role == nil {
+				continue
+			}
+			if creditID == "" {
+				creditID = role.CreditID
+			}
+			if role.Character != "" {
+				characters = append(characters, role.Character)
+			}
+		}
+
+		character := strings.Join(characters, " / ")
+		if member.TotalEpisodeCount > 0 {
+			character = strings.TrimSpace(fmt.Sprintf("%s (%s)", character, episodesCountLabel(member.TotalEpisodeCount)))
+		}
+
+		ret.Cast = append(ret.Cast, &Cast{
+			IDName:      member.IDName,
+			Character:   character,
+			CreditID:    creditID,
+			Order:       i,
+			ProfilePath: member.ProfilePath,
+		})
+	}
+
+	crew := make([]*AggregateCrew, 0, len(c.Crew))
+	for _, member := range c.Crew {
+		if member != nil {
+			crew = append(crew, member)
+		}
+	}
+	sort.SliceStable(crew, func(i, j int) bool {
+		return crew[i].TotalEpisodeCount > crew[j].TotalEpisodeCount
+	})
+	for _, member := range crew {
+		for _, job := range member.Jobs {
+			if job == nil {
+				continue
+			}
+			ret.Crew = append(ret.Crew, &Crew{
+				IDName:      member.IDName,
+				CreditID:    job.CreditID,
+				Department:  member.Department,
+				Job:         job.Job,
+				ProfilePath: member.ProfilePath,
+			})
+		}
+	}
+
+	return ret
+}
+
+func episodesCountLabel(count int) string {
+	if count == 1 {
+		return "1 episode"
+	}
+	return fmt.Sprintf("%d episodes", count)
+}
@@ -0,0 +1,187 @@
+package tmdb
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+
+	"github.com/jmcvetta/napping"
+)
+
+// Query parameters, discover filters are stored with
+const (
+	discoverGenre          = "genre"
+	discoverCountry        = "country"
+	discoverLanguage       = "language"
+	discoverYearFrom       = "year_from"
+	discoverYearTo         = "year_to"
+	discoverVoteAverageMin = "vote_average"
+	discoverVoteCountMin   = "vote_count"
+	discoverRuntimeMin     = "runtime_min"
+	discoverRuntimeMax     = "runtime_max"
+	discoverWatchProvider  = "provider"
+	discoverCertification  = "certification"
+	discoverKeywords       = "keywords"
+)
+
+// ParseDiscoverFilters reads filters from query values, see DiscoverFilters.Values
+func ParseDiscoverFilters(values url.Values) DiscoverFilters {
+	atoi := func(key string) int {
+		i, _ := strconv.Atoi(values.Get(key))
+		return i
+	}
+	vote, _ := strconv.ParseFloat(values.Get(discoverVoteAverageMin), 64)
+
+	return DiscoverFilters{
+		Genre:          values.Get(discoverGenre),
+		Country:        values.Get(discoverCountry),
+		Language:       values.Get(discoverLanguage),
+		YearFrom:       atoi(discoverYearFrom),
+		YearTo:         atoi(discoverYearTo),
+		VoteAverageMin: vote,
+		VoteCountMin:   atoi(discoverVoteCountMin),
+		RuntimeMin:     atoi(discoverRuntimeMin),
+		RuntimeMax:     atoi(discoverRuntimeMax),
+		WatchProvider:  values.Get(discoverWatchProvider),
+		Certification:  values.Get(discoverCertification),
+		Keywords:       values.Get(discoverKeywords),
+	}
+}
+
+// Values returns filters, that are set, as query values
+func (f DiscoverFilters) Values() url.Values {
+	ret := url.Values{}
+	setString := func(key, value string) {
+		if value != "" {
+			ret.Set(key, value)
+		}
+	}
+	setInt := func(key string, value int) {
+		if value != 0 {
+			ret.Set(key, strconv.Itoa(value))
+		}
+	}
+
+	setString(discoverGenre, f.Genre)
+	setString(discoverCountry, f.Country)
+	setString(discoverLanguage, f.Language)
+	setInt(discoverYearFrom, f.YearFrom)
+	setInt(discoverYearTo, f.YearTo)
+	if f.VoteAverageMin > 0 {
+		ret.Set(discoverVoteAverageMin, strconv.FormatFloat(f.VoteAverageMin, 'f', -1, 64))
+	}
+	setInt(discoverVoteCountMin, f.VoteCountMin)
+	setInt(discoverRuntimeMin, f.RuntimeMin)
+	setInt(discoverRuntimeMax, f.RuntimeMax)
+	setString(discoverWatchProvider, f.WatchProvider)
+	setString(discoverCertification, f.Certification)
+	setString(discoverKeywords, f.Keywords)
+	return ret
+}
+
+// cacheKey returns short key, identifying the set of filters
+func (f DiscoverFilters) cacheKey() string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(f.Values().Encode())))[:12]
+}
+
+// discoverParams converts filters to TMDB discover parameters.
+// Movies are filtered by primary release date, shows by first air date.
+func (f DiscoverFilters) discoverParams(isMovie bool, language string) napping.Params {
+	dateField := "first_air_date"
+	if isMovie {
+		dateField = "primary_release_date"
+	}
+
+	p := napping.Params{
+		"language":         language,
+		"sort_by":          "popularity.desc",
+		dateField + ".lte": time.Now().UTC().Format("2006-01-02"),
+	}
+	if f.Genre != "" {
+		p["with_genres"] = f.Genre
+	}
+	if f.Country != "" {
+		if isMovie {
+			p["region"] = f.Country
+		} else {
+			p["with_origin_country"] = f.Country
+		}
+	}
+	if f.Language != "" {
+		p["with_original_language"] = f.Language
+	}
+	if f.YearFrom > 0 {
+		p[dateField+".gte"] = fmt.Sprintf("%d-01-01", f.YearFrom)
+	}
+	if f.YearTo > 0 {
+		if to := fmt.Sprintf("%d-12-31", f.YearTo); to < p[dateField+".lte"] {
+			p[dateField+".lte"] = to
+		}
+	}
+	if f.VoteAverageMin > 0 {
+		p["vote_average.gte"] = strconv.FormatFloat(f.VoteAverageMin, 'f', -1, 64)
+	}
+	if f.VoteCountMin > 0 {
+		p["vote_count.gte"] = strconv.Itoa(f.VoteCountMin)
+	}
+	if f.RuntimeMin > 0 {
+		p["with_runtime.gte"] = strconv.Itoa(f.RuntimeMin)
+	}
+	if f.RuntimeMax > 0 {
+		p["with_runtime.lte"] = strconv.Itoa(f.RuntimeMax)
+	}
+	if f.WatchProvider != "" {
+		p["with_watch_providers"] = strings.Replace(f.WatchProvider, ",", "|", -1)
+		p["watch_region"] = config.Get().Region
+	}
+	if f.Certification != "" && isMovie {
+		p["certification_country"] = config.Get().Region
+		p["certification"] = f.Certification
+	}
+	if f.Keywords != "" {
+		p["with_keywords"] = strings.Replace(f.Keywords, ",", "|", -1)
+	}
+
+	return p
+}
+
+// DiscoverMovies returns popular movies, matching all set filters
+func DiscoverMovies(filters DiscoverFilters, language string, page int) (Movies, int) {
+	return listMovies("discover/movie", "discover."+filters.cacheKey(), filters.discoverParams(true, language), page)
+}
+
+// DiscoverShows returns popular shows, matching all set filters
+func DiscoverShows(filters DiscoverFilters, language string, page int) (Shows, int) {
+	return listShows("discover/tv", "discover."+filters.cacheKey(), filters.discoverParams(false, language), page)
+}
+
+// CountryPopularMovies returns popular movies, released in a country, by regional release dates
+func CountryPopularMovies(country string, language string, page int) (Movies, int) {
+	p := napping.Params{
+		"language":          language,
+		"sort_by":           "popularity.desc",
+		"region":            country,
+		"release_date.lte":  time.Now().UTC().Format("2006-01-02"),
+		"with_release_type": "2|3|4",
+	}
+
+	return listMovies("discover/movie", "country.popular", p, page)
+}
+
+// CountryPopularShows returns popular shows, available on streaming services in a country
+func CountryPopularShows(country string, language string, page int) (Shows, int) {
+	p := napping.Params{
+		"language":                      language,
+		"sort_by":                       "popularity.desc",
+		"first_air_date.lte":            time.Now().UTC().Format("2006-01-02"),
+		"watch_region":                  country,
+		"with_watch_monetization_types": "flatrate|free|ads",
+	}
+
+	return listShows("discover/tv", "country.popular."+country, p, page)
+}
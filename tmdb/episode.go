@@ -8,6 +8,8 @@ import (
 
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/fanart"
 	"github.com/elgatito/elementum/playcount"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
@@ -23,7 +25,6 @@ func GetEpisode(showID int, seasonNumber int, episodeNumber int, language string
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/episode/%d", tmdbEndpoint, showID, seasonNumber, episodeNumber),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"append_to_response":     "credits,images,videos,alternative_titles,translations,external_ids,trailers",
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 				"language":               language,
@@ -80,9 +81,19 @@ func (episodes EpisodeList) ToListItems(show *Show, season *Season) []*xbmc.List
 
 // ToListItem ...
 func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
-	episodeLabel := episode.name(show)
+	playCount := playcount.GetWatchedEpisodeByTMDB(show.ID, episode.SeasonNumber, episode.EpisodeNumber).Int()
+	hideSpoilers := playCount == 0 && database.GetStorm().IsSpoilerProtected(show.ID)
+
+	name := episode.name(show)
+	overview := episode.overview(show)
+	if hideSpoilers {
+		name = fmt.Sprintf("Episode %d", episode.EpisodeNumber)
+		overview = ""
+	}
+
+	episodeLabel := name
 	if config.Get().AddEpisodeNumbers {
-		episodeLabel = fmt.Sprintf("%dx%02d %s", episode.SeasonNumber, episode.EpisodeNumber, episode.name(show))
+		episodeLabel = fmt.Sprintf("%dx%02d %s", episode.SeasonNumber, episode.EpisodeNumber, name)
 	}
 
 	runtime := 1800
@@ -94,20 +105,20 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 		Label:  episodeLabel,
 		Label2: fmt.Sprintf("%f", episode.VoteAverage),
 		Info: &xbmc.ListItemInfo{
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountEpisode, show.ID, episode.SeasonNumber*1000+episode.EpisodeNumber),
 			Title:         episodeLabel,
-			OriginalTitle: episode.name(show),
+			OriginalTitle: name,
 			Season:        episode.SeasonNumber,
 			Episode:       episode.EpisodeNumber,
 			TVShowTitle:   show.name(),
-			Plot:          episode.overview(show),
-			PlotOutline:   episode.overview(show),
+			Plot:          overview,
+			PlotOutline:   overview,
 			Rating:        episode.VoteAverage,
 			Aired:         episode.AirDate,
 			Duration:      runtime,
 			Code:          show.ExternalIDs.IMDBId,
 			IMDBNumber:    show.ExternalIDs.IMDBId,
-			PlayCount:     playcount.GetWatchedEpisodeByTMDB(show.ID, episode.SeasonNumber, episode.EpisodeNumber).Int(),
+			PlayCount:     playCount,
 			MPAA:          show.mpaa(),
 			DBTYPE:        "episode",
 			Mediatype:     "episode",
@@ -138,15 +149,20 @@ func (episode *Episode) ToListItem(show *Show, season *Season) *xbmc.ListItem {
 		}
 	}
 
+	var fa *fanart.Show
 	if config.Get().UseFanartTv && show.FanArt != nil {
-		item.Art = show.FanArt.ToEpisodeListItemArt(season.Season, item.Art)
+		fa = show.FanArt
+		item.Art = fa.ToEpisodeListItemArt(season.Season, item.Art)
 	}
 
-	if episode.StillPath != "" {
+	if episode.StillPath != "" && !config.Get().HideEpisodeStills && !hideSpoilers {
 		item.Art.FanArt = ImageURL(episode.StillPath, "w1280")
 		item.Art.Thumbnail = ImageURL(episode.StillPath, "w1280")
 		item.Art.Poster = ImageURL(episode.StillPath, "w1280")
 		item.Thumbnail = ImageURL(episode.StillPath, "w1280")
+	} else if thumb := fanart.EpisodeFallbackThumbnail(fa, episode.SeasonNumber, item.Art.FanArt); thumb != "" {
+		item.Art.Thumbnail = thumb
+		item.Thumbnail = thumb
 	}
 
 	genres := make([]string, 0, len(show.Genres))
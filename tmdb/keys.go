@@ -0,0 +1,210 @@
+package tmdb
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// Default cooldown of a key, when TMDB does not say when to retry
+const apiKeyCooldown = 10 * time.Second
+
+// APIKeyUsage is usage accounting of a TMDB API key
+type APIKeyUsage struct {
+	key string
+
+	Custom        bool
+	Requests      int64
+	RateLimited   int64
+	LastUsed      time.Time
+	CooldownUntil time.Time
+	// Invalid keys failed the check and are never used
+	Invalid bool
+}
+
+var (
+	apiKeysMu    sync.Mutex
+	apiKeysUsage []*APIKeyUsage
+	// Index of the key, used for requests, until it gets rate limited
+	apiKeyCurrent int
+)
+
+// initAPIKeys builds usage of built-in keys and custom key, which is preferred, if configured
+func initAPIKeys(custom string) {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	resetAPIKeys(custom)
+}
+
+func resetAPIKeys(custom string) {
+	apiKeysUsage = make([]*APIKeyUsage, 0, len(apiKeys)+1)
+	if custom != "" {
+		apiKeysUsage = append(apiKeysUsage, &APIKeyUsage{key: custom, Custom: true})
+	}
+	for _, key := range apiKeys {
+		if key != custom {
+			apiKeysUsage = append(apiKeysUsage, &APIKeyUsage{key: key})
+		}
+	}
+
+	// Built-in keys are shared, so load is spread by starting from a random one
+	apiKeyCurrent = 0
+	if custom == "" {
+		apiKeyCurrent = rand.Intn(len(apiKeysUsage))
+	}
+}
+
+// CheckAPIKey checks all TMDB API keys, keys, that failed the check, are not used
+func CheckAPIKey() {
+	log.Info("Checking TMDB API keys...")
+	initAPIKeys(config.Get().TMDBApiKey)
+
+	apiKeysMu.Lock()
+	keys := append([]*APIKeyUsage{}, apiKeysUsage...)
+	apiKeysMu.Unlock()
+
+	valid := 0
+	for _, k := range keys {
+		ok, err := tmdbCheck(k.key)
+		if err != nil {
+			// TMDB is not reachable, so keys cannot be checked
+			return
+		} else if ok {
+			valid++
+			continue
+		}
+
+		log.Warningf("TMDB API key failed: %s...", k.prefix())
+		apiKeysMu.Lock()
+		k.Invalid = true
+		apiKeysMu.Unlock()
+	}
+
+	if valid == 0 {
+		log.Error("No valid TMDB API key found")
+	} else {
+		log.Noticef("TMDB API keys check passed, %d of %d keys are valid", valid, len(keys))
+	}
+}
+
+// nextAPIKey returns a key for a request: current key, if it is not cooling down,
+// or the next available one. If all keys are cooling down, the one, that is available first, is returned.
+func nextAPIKey() *APIKeyUsage {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	if len(apiKeysUsage) == 0 {
+		resetAPIKeys(config.Get().TMDBApiKey)
+	}
+
+	now := time.Now()
+	var earliest *APIKeyUsage
+	for i := 0; i < len(apiKeysUsage); i++ {
+		index := (apiKeyCurrent + i) % len(apiKeysUsage)
+		k := apiKeysUsage[index]
+		if k.Invalid {
+			continue
+		}
+
+		if k.CooldownUntil.Before(now) {
+			if index != apiKeyCurrent {
+				log.Infof("Rotating TMDB API key to %s...", k.prefix())
+				apiKeyCurrent = index
+			}
+			k.Requests++
+			k.LastUsed = now
+			return k
+		}
+		if earliest == nil || k.CooldownUntil.Before(earliest.CooldownUntil) {
+			earliest = k
+		}
+	}
+
+	if earliest != nil {
+		earliest.Requests++
+		earliest.LastUsed = now
+	}
+	return earliest
+}
+
+// prefix returns beginning of a key, so that keys are not written to logs
+func (k *APIKeyUsage) prefix() string {
+	if len(k.key) > 7 {
+		return k.key[:7]
+	}
+	return k.key
+}
+
+// coolDown marks key as rate limited until time, TMDB asked to retry after
+func (k *APIKeyUsage) coolDown(header http.Header) {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	cooldown := apiKeyCooldown
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		cooldown = time.Duration(seconds) * time.Second
+	}
+
+	k.RateLimited++
+	k.CooldownUntil = time.Now().Add(cooldown)
+	log.Warningf("TMDB API key %s is rate limited, cooling down for %s", k.prefix(), cooldown)
+}
+
+// hasAvailableAPIKey checks if there is a key, that is not cooling down
+func hasAvailableAPIKey() bool {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	now := time.Now()
+	for _, k := range apiKeysUsage {
+		if !k.Invalid && k.CooldownUntil.Before(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeysUsage returns usage of all TMDB API keys
+func APIKeysUsage() []APIKeyUsage {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	ret := make([]APIKeyUsage, 0, len(apiKeysUsage))
+	for _, k := range apiKeysUsage {
+		ret = append(ret, *k)
+	}
+	return ret
+}
+
+// WriteAPIKeysUsage writes usage of TMDB API keys, for debug info
+func WriteAPIKeysUsage(w io.Writer) {
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+
+	fmt.Fprint(w, "TMDB API keys:\n")
+	for i, k := range apiKeysUsage {
+		state := "available"
+		if k.Invalid {
+			state = "invalid"
+		} else if k.CooldownUntil.After(time.Now()) {
+			state = fmt.Sprintf("cooling down for %s", time.Until(k.CooldownUntil).Round(time.Second))
+		}
+		if i == apiKeyCurrent {
+			state += ", current"
+		}
+
+		kind := "built-in"
+		if k.Custom {
+			kind = "custom"
+		}
+		fmt.Fprintf(w, "    %s... (%s): %d requests, %d rate limited, %s\n", k.prefix(), kind, k.Requests, k.RateLimited, state)
+	}
+	fmt.Fprint(w, "\n")
+}
@@ -2,7 +2,6 @@ package tmdb
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,6 +11,7 @@ import (
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/fanart"
+	"github.com/elgatito/elementum/placeholder"
 	"github.com/elgatito/elementum/playcount"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
@@ -32,10 +32,15 @@ func GetImages(movieID int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf(cache.TMDBMovieImagesKey, movieID)
 	if err := cacheStore.Get(key, &images); err != nil {
+		// Images are appended to movie requests, so there is no need for another request
+		var movie *Movie
+		if err := cacheStore.Get(fmt.Sprintf(cache.TMDBMovieByIDKey, strconv.Itoa(movieID), config.Get().Language), &movie); err == nil && movie != nil && movie.Images != nil {
+			return movie.Images
+		}
+
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/movie/%d/images", tmdbEndpoint, movieID),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 			}.AsUrlValues(),
 			Result:      &images,
@@ -63,7 +68,6 @@ func GetMovieByID(movieID string, language string) *Movie {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/movie/%s", tmdbEndpoint, movieID),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"append_to_response":     "credits,images,alternative_titles,translations,external_ids,trailers,release_dates",
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 				"language":               language,
@@ -118,7 +122,6 @@ func GetMovieGenres(language string) []*Genre {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/genre/movie/list", tmdbEndpoint),
 			Params: napping.Params{
-				"api_key":  apiKey,
 				"language": language,
 			}.AsUrlValues(),
 			Result:      &genres,
@@ -131,7 +134,6 @@ func GetMovieGenres(language string) []*Genre {
 			err = MakeRequest(APIRequest{
 				URL: fmt.Sprintf("%s/genre/movie/list", tmdbEndpoint),
 				Params: napping.Params{
-					"api_key":  apiKey,
 					"language": "en-US",
 				}.AsUrlValues(),
 				Result:      &genres,
@@ -161,9 +163,8 @@ func SearchMovies(query string, language string, page int) (Movies, int) {
 	MakeRequest(APIRequest{
 		URL: fmt.Sprintf("%s/search/movie", tmdbEndpoint),
 		Params: napping.Params{
-			"api_key": apiKey,
-			"query":   query,
-			"page":    strconv.Itoa(page),
+			"query": query,
+			"page":  strconv.Itoa(page),
 		}.AsUrlValues(),
 		Result:      &results,
 		Description: "search movie",
@@ -194,10 +195,8 @@ func GetIMDBList(listID string, language string, page int) (movies Movies, total
 	totalKey := fmt.Sprintf(cache.TMDBMoviesIMDBTotalKey, listID)
 	if err := cacheStore.Get(key, &movies); err != nil {
 		err = MakeRequest(APIRequest{
-			URL: fmt.Sprintf("%s/list/%s", tmdbEndpoint, listID),
-			Params: napping.Params{
-				"api_key": apiKey,
-			}.AsUrlValues(),
+			URL:         fmt.Sprintf("%s/list/%s", tmdbEndpoint, listID),
+			Params:      napping.Params{}.AsUrlValues(),
 			Result:      &results,
 			Description: "IMDB list",
 		})
@@ -229,7 +228,6 @@ func GetIMDBList(listID string, language string, page int) (movies Movies, total
 }
 
 func listMovies(endpoint string, cacheKey string, params napping.Params, page int) (Movies, int) {
-	params["api_key"] = apiKey
 	totalResults := -1
 
 	genre := params["with_genres"]
@@ -350,6 +348,36 @@ func PopularMovies(params DiscoverFilters, language string, page int) (Movies, i
 	return listMovies("discover/movie", "popular", p, page)
 }
 
+// KeywordMovies returns popular movies, tagged with any of comma separated TMDB keyword IDs
+func KeywordMovies(keywords string, language string, page int) (Movies, int) {
+	p := napping.Params{
+		"language":       language,
+		"sort_by":        "popularity.desc",
+		"vote_count.gte": "50",
+		"with_keywords":  strings.Replace(keywords, ",", "|", -1),
+	}
+
+	return listMovies("discover/movie", "keywords."+keywords, p, page)
+}
+
+// KidsMovies returns popular movies of any of comma separated genres,
+// limited by US certification, if it is set
+func KidsMovies(genres string, certification string, language string, page int) (Movies, int) {
+	p := napping.Params{
+		"language":                 language,
+		"sort_by":                  "popularity.desc",
+		"primary_release_date.lte": time.Now().UTC().Format("2006-01-02"),
+		"with_genres":              strings.Replace(genres, ",", "|", -1),
+		"include_adult":            "false",
+	}
+	if certification != "" {
+		p["certification_country"] = "US"
+		p["certification.lte"] = certification
+	}
+
+	return listMovies("discover/movie", "kids."+certification, p, page)
+}
+
 // RecentMovies ...
 func RecentMovies(params DiscoverFilters, language string, page int) (Movies, int) {
 	var p napping.Params
@@ -422,17 +450,14 @@ func (movie *Movie) Year() int {
 
 // ToListItem ...
 func (movie *Movie) ToListItem() *xbmc.ListItem {
-	title := movie.title()
-	if config.Get().UseOriginalTitle && movie.OriginalTitle != "" {
-		title = movie.OriginalTitle
-	}
+	title := DisplayTitle(movie.title(), movie.OriginalTitle)
 
 	item := &xbmc.ListItem{
 		Label:  title,
 		Label2: fmt.Sprintf("%f", movie.VoteAverage),
 		Info: &xbmc.ListItemInfo{
 			Year:          movie.Year(),
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountMovie, movie.ID, 0),
 			Title:         title,
 			OriginalTitle: movie.OriginalTitle,
 			Plot:          movie.overview(),
@@ -458,14 +483,20 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 
 	if movie.Images != nil && movie.Images.Backdrops != nil {
 		fanarts := make([]string, 0)
-		for _, backdrop := range movie.Images.Backdrops {
+		for _, backdrop := range SortImages(movie.Images.Backdrops, config.Get().TextlessBackdrops) {
 			fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
 		}
 		if len(fanarts) > 0 {
-			item.Art.FanArt = fanarts[rand.Intn(len(fanarts))]
+			item.Art.FanArt = fanarts[0]
 			item.Art.FanArts = fanarts
 		}
 	}
+	if movie.Images != nil {
+		if poster := BestImage(movie.Images.Posters, false); poster != nil {
+			item.Art.Poster = ImageURL(poster.FilePath, "w1280")
+			item.Art.Thumbnail = ImageURL(poster.FilePath, "w300")
+		}
+	}
 
 	if config.Get().UseFanartTv && movie.FanArt != nil {
 		item.Art = movie.FanArt.ToListItemArt(item.Art)
@@ -539,6 +570,8 @@ func (movie *Movie) ToListItem() *xbmc.ListItem {
 		item.Info.Director = strings.Join(directors, " / ")
 		item.Info.Writer = strings.Join(writers, " / ")
 	}
+
+	placeholder.Fill(item)
 	return item
 }
 
@@ -2478,9 +2478,9 @@ func (z IDName) Msgsize() (s int) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Image) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 4
+	// map header, size 6
 	// string "FilePath"
-	o = append(o, 0x84, 0xa8, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68)
+	o = append(o, 0x86, 0xa8, 0x46, 0x69, 0x6c, 0x65, 0x50, 0x61, 0x74, 0x68)
 	o = msgp.AppendString(o, z.FilePath)
 	// string "Height"
 	o = append(o, 0xa6, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74)
@@ -2491,6 +2491,12 @@ func (z *Image) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Width"
 	o = append(o, 0xa5, 0x57, 0x69, 0x64, 0x74, 0x68)
 	o = msgp.AppendInt(o, z.Width)
+	// string "VoteAverage"
+	o = append(o, 0xab, 0x56, 0x6f, 0x74, 0x65, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65)
+	o = msgp.AppendFloat64(o, z.VoteAverage)
+	// string "VoteCount"
+	o = append(o, 0xa9, 0x56, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74)
+	o = msgp.AppendInt(o, z.VoteCount)
 	return
 }
 
@@ -2536,6 +2542,18 @@ func (z *Image) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Width")
 				return
 			}
+		case "VoteAverage":
+			z.VoteAverage, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "VoteAverage")
+				return
+			}
+		case "VoteCount":
+			z.VoteCount, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "VoteCount")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -2550,7 +2568,7 @@ func (z *Image) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *Image) Msgsize() (s int) {
-	s = 1 + 9 + msgp.StringPrefixSize + len(z.FilePath) + 7 + msgp.IntSize + 9 + msgp.StringPrefixSize + len(z.Iso639_1) + 6 + msgp.IntSize
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.FilePath) + 7 + msgp.IntSize + 9 + msgp.StringPrefixSize + len(z.Iso639_1) + 6 + msgp.IntSize + 12 + msgp.Float64Size + 10 + msgp.IntSize
 	return
 }
 
@@ -0,0 +1,124 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elgatito/elementum/cache"
+
+	"github.com/jmcvetta/napping"
+)
+
+// Person is an actor, a director or any other crew member
+type Person struct {
+	ID                 int     `json:"id"`
+	Name               string  `json:"name"`
+	Biography          string  `json:"biography"`
+	Birthday           string  `json:"birthday"`
+	Deathday           string  `json:"deathday"`
+	PlaceOfBirth       string  `json:"place_of_birth"`
+	ProfilePath        string  `json:"profile_path"`
+	KnownForDepartment string  `json:"known_for_department"`
+	IMDBId             string  `json:"imdb_id"`
+	Popularity         float64 `json:"popularity"`
+}
+
+// PersonCredit is a movie or a show, a person has taken part in
+type PersonCredit struct {
+	ID           int     `json:"id"`
+	MediaType    string  `json:"media_type"`
+	Title        string  `json:"title"`
+	Name         string  `json:"name"`
+	Character    string  `json:"character"`
+	Job          string  `json:"job"`
+	Department   string  `json:"department"`
+	ReleaseDate  string  `json:"release_date"`
+	FirstAirDate string  `json:"first_air_date"`
+	EpisodeCount int     `json:"episode_count"`
+	Popularity   float64 `json:"popularity"`
+	VoteCount    int     `json:"vote_count"`
+}
+
+// PersonCredits are combined movie and show credits of a person
+type PersonCredits struct {
+	Cast []*PersonCredit `json:"cast"`
+	Crew []*PersonCredit `json:"crew"`
+}
+
+// GetPerson returns person details, or nil
+func GetPerson(personID int, language string) *Person {
+	var person *Person
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBPersonKey, personID, language)
+	if err := cacheStore.Get(key, &person); err == nil {
+		return person
+	}
+
+	err := MakeRequest(APIRequest{
+		URL: fmt.Sprintf("%s/person/%d", tmdbEndpoint, personID),
+		Params: napping.Params{
+			"language": language,
+		}.AsUrlValues(),
+		Result:      &person,
+		Description: "person",
+	})
+	if err != nil || person == nil {
+		return nil
+	}
+
+	cacheStore.Set(key, person, cache.TMDBPersonExpire)
+	return person
+}
+
+// GetPersonCredits returns movies and shows, a person has taken part in, or nil
+func GetPersonCredits(personID int, language string) *PersonCredits {
+	var credits *PersonCredits
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBPersonCreditsKey, personID, language)
+	if err := cacheStore.Get(key, &credits); err == nil {
+		return credits
+	}
+
+	err := MakeRequest(APIRequest{
+		URL: fmt.Sprintf("%s/person/%d/combined_credits", tmdbEndpoint, personID),
+		Params: napping.Params{
+			"language": language,
+		}.AsUrlValues(),
+		Result:      &credits,
+		Description: "person credits",
+	})
+	if err != nil || credits == nil {
+		return nil
+	}
+
+	cacheStore.Set(key, credits, cache.TMDBPersonCreditsExpire)
+	return credits
+}
+
+// IDs returns unique TMDB IDs of movies ("movie") or shows ("tv") of all credits, most popular first
+func (c *PersonCredits) IDs(mediaType string) []int {
+	if c == nil {
+		return nil
+	}
+
+	seen := map[int]bool{}
+	credits := make([]*PersonCredit, 0, len(c.Cast)+len(c.Crew))
+	for _, list := range [][]*PersonCredit{c.Cast, c.Crew} {
+		for _, credit := range list {
+			if credit == nil || credit.MediaType != mediaType || seen[credit.ID] {
+				continue
+			}
+			seen[credit.ID] = true
+			credits = append(credits, credit)
+		}
+	}
+	sort.SliceStable(credits, func(i, j int) bool {
+		return credits[i].Popularity > credits[j].Popularity
+	})
+
+	ids := make([]int, 0, len(credits))
+	for _, credit := range credits {
+		ids = append(ids, credit.ID)
+	}
+	return ids
+}
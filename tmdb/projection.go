@@ -0,0 +1,222 @@
+package tmdb
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/util"
+	"github.com/jmcvetta/napping"
+)
+
+// Only fields, needed to show list items, are requested, when there is no full movie or show in the cache
+const projectionAppendToResponse = "images,external_ids"
+
+// Projection is a lightweight part of a movie or a show, that is enough to render list items,
+// and is much smaller to store in the cache than a full movie or show.
+type Projection struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	Year          int     `json:"year"`
+	Overview      string  `json:"overview"`
+	VoteAverage   float32 `json:"vote_average"`
+	VoteCount     int     `json:"vote_count"`
+	IMDBId        string  `json:"imdb_id"`
+	TVDBId        int     `json:"tvdb_id"`
+
+	// Paths of best images, use ImageURL to get URLs
+	Poster           string `json:"poster"`
+	Backdrop         string `json:"backdrop"`
+	TextlessBackdrop string `json:"textless_backdrop"`
+}
+
+// PosterURL returns URL of the best poster
+func (p *Projection) PosterURL() string {
+	if p.Poster == "" {
+		return ""
+	}
+	return ImageURL(p.Poster, "w1280")
+}
+
+// BackdropURL returns URL of the best backdrop, textless one, if textless backdrops are preferred
+func (p *Projection) BackdropURL() string {
+	backdrop := p.Backdrop
+	if config.Get().TextlessBackdrops && p.TextlessBackdrop != "" {
+		backdrop = p.TextlessBackdrop
+	}
+	if backdrop == "" {
+		return ""
+	}
+	return ImageURL(backdrop, "w1280")
+}
+
+// GetMovieProjections returns projections of movies, each movie is requested once,
+// with images appended to the same request, and only when it is not in the cache already.
+func GetMovieProjections(tmdbIds []int, language string) map[int]*Projection {
+	return getProjections(tmdbIds, func(id int) *Projection {
+		return GetMovieProjection(id, language)
+	})
+}
+
+// GetShowProjections returns projections of shows, each show is requested once,
+// with images appended to the same request, and only when it is not in the cache already.
+func GetShowProjections(tmdbIds []int, language string) map[int]*Projection {
+	return getProjections(tmdbIds, func(id int) *Projection {
+		return GetShowProjection(id, language)
+	})
+}
+
+func getProjections(tmdbIds []int, get func(id int) *Projection) map[int]*Projection {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	ret := map[int]*Projection{}
+	for _, id := range tmdbIds {
+		if id == 0 {
+			continue
+		} else if _, ok := ret[id]; ok {
+			continue
+		}
+		ret[id] = nil
+
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			p := get(id)
+
+			mu.Lock()
+			ret[id] = p
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	return ret
+}
+
+// GetMovieProjection returns projection of a movie
+func GetMovieProjection(tmdbID int, language string) (p *Projection) {
+	if tmdbID == 0 {
+		return nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBMovieProjectionKey, tmdbID, language)
+	if err := cacheStore.Get(key, &p); err == nil {
+		return
+	}
+
+	// Full movie, if it is already cached, has everything for a projection
+	var movie *Movie
+	if err := cacheStore.Get(fmt.Sprintf(cache.TMDBMovieByIDKey, strconv.Itoa(tmdbID), language), &movie); err != nil || movie == nil {
+		MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/movie/%d", tmdbEndpoint, tmdbID),
+			Params: napping.Params{
+				"append_to_response":     projectionAppendToResponse,
+				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
+				"language":               language,
+			}.AsUrlValues(),
+			Result:      &movie,
+			Description: "movie projection",
+		})
+		if movie == nil {
+			return nil
+		}
+
+		if movie.Images != nil {
+			cacheStore.Set(fmt.Sprintf(cache.TMDBMovieImagesKey, tmdbID), movie.Images, cache.TMDBMovieImagesExpire)
+		}
+	}
+
+	p = &Projection{
+		ID:            movie.ID,
+		Title:         movie.title(),
+		OriginalTitle: movie.OriginalTitle,
+		Year:          movie.Year(),
+		Overview:      movie.overview(),
+		VoteAverage:   movie.VoteAverage,
+		VoteCount:     movie.VoteCount,
+		IMDBId:        movie.IMDBId,
+	}
+	p.setImages(movie.Images, movie.PosterPath, movie.BackdropPath)
+
+	cacheStore.Set(key, p, cache.TMDBMovieProjectionExpire)
+	return
+}
+
+// GetShowProjection returns projection of a show
+func GetShowProjection(tmdbID int, language string) (p *Projection) {
+	if tmdbID == 0 {
+		return nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBShowProjectionKey, tmdbID, language)
+	if err := cacheStore.Get(key, &p); err == nil {
+		return
+	}
+
+	// Full show, if it is already cached, has everything for a projection
+	var show *Show
+	if err := cacheStore.Get(fmt.Sprintf(cache.TMDBShowByIDKey, tmdbID, language), &show); err != nil || show == nil {
+		MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/tv/%d", tmdbEndpoint, tmdbID),
+			Params: napping.Params{
+				"append_to_response":     projectionAppendToResponse,
+				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
+				"language":               language,
+			}.AsUrlValues(),
+			Result:      &show,
+			Description: "show projection",
+		})
+		if show == nil {
+			return nil
+		}
+
+		if show.Images != nil {
+			cacheStore.Set(fmt.Sprintf(cache.TMDBShowImagesKey, tmdbID), show.Images, cache.TMDBShowImagesExpire)
+		}
+	}
+
+	p = &Projection{
+		ID:            show.ID,
+		Title:         show.name(),
+		OriginalTitle: show.OriginalName,
+		Overview:      show.overview(),
+		VoteAverage:   show.VoteAverage,
+		VoteCount:     show.VoteCount,
+	}
+	if len(show.FirstAirDate) >= 4 {
+		p.Year, _ = strconv.Atoi(show.FirstAirDate[:4])
+	}
+	if show.ExternalIDs != nil {
+		p.IMDBId = show.ExternalIDs.IMDBId
+		p.TVDBId = util.StrInterfaceToInt(show.ExternalIDs.TVDBID)
+	}
+	p.setImages(show.Images, show.PosterPath, show.BackdropPath)
+
+	cacheStore.Set(key, p, cache.TMDBShowProjectionExpire)
+	return
+}
+
+func (p *Projection) setImages(images *Images, poster, backdrop string) {
+	p.Poster = poster
+	p.Backdrop = backdrop
+	if images == nil {
+		return
+	}
+
+	if image := BestImage(images.Posters, false); image != nil {
+		p.Poster = image.FilePath
+	}
+	if image := BestImage(images.Backdrops, false); image != nil {
+		p.Backdrop = image.FilePath
+	}
+	if image := BestImage(images.Backdrops, true); image != nil {
+		p.TextlessBackdrop = image.FilePath
+	}
+}
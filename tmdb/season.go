@@ -36,8 +36,7 @@ func GetSeason(showID int, seasonNumber int, language string, seasonsCount int)
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d", tmdbEndpoint, showID, seasonNumber),
 			Params: napping.Params{
-				"api_key":                apiKey,
-				"append_to_response":     "credits,images,videos,external_ids,alternative_titles,translations,trailers",
+				"append_to_response":     "aggregate_credits,images,videos,external_ids,alternative_titles,translations,trailers",
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 				"language":               language,
 			}.AsUrlValues(),
@@ -53,6 +52,10 @@ func GetSeason(showID int, seasonNumber int, language string, seasonsCount int)
 		}
 
 		season.EpisodeCount = len(season.Episodes)
+		if season.AggregateCredits != nil {
+			season.Credits = season.AggregateCredits.ToCredits()
+			season.AggregateCredits = nil
+		}
 
 		// Fix for shows that have translations but return empty strings
 		// for episode names and overviews.
@@ -157,7 +160,7 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 	item := &xbmc.ListItem{
 		Label: name,
 		Info: &xbmc.ListItemInfo{
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountSeason, show.ID, season.Season),
 			Title:         name,
 			OriginalTitle: name,
 			Season:        season.Season,
@@ -210,6 +213,23 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 		item.Info.Genre = show.Genres[0].Name
 	}
 
+	// Season credits are available only for fetched seasons, seasons of a show listing use show credits
+	credits := season.Credits
+	if credits == nil {
+		credits = show.Credits
+	}
+	if credits != nil {
+		item.CastMembers = make([]xbmc.ListItemCastMember, 0)
+		for _, cast := range credits.Cast {
+			item.CastMembers = append(item.CastMembers, xbmc.ListItemCastMember{
+				Name:      cast.Name,
+				Role:      cast.Character,
+				Thumbnail: ImageURL(cast.ProfilePath, "w500"),
+				Order:     cast.Order,
+			})
+		}
+	}
+
 	return item
 }
 
@@ -2,7 +2,6 @@ package tmdb
 
 import (
 	"fmt"
-	"math/rand"
 	"runtime"
 	"sort"
 	"strconv"
@@ -13,6 +12,7 @@ import (
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/fanart"
+	"github.com/elgatito/elementum/placeholder"
 	"github.com/elgatito/elementum/playcount"
 	"github.com/elgatito/elementum/tvdb"
 	"github.com/elgatito/elementum/util"
@@ -34,10 +34,15 @@ func GetShowImages(showID int) *Images {
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf(cache.TMDBShowImagesKey, showID)
 	if err := cacheStore.Get(key, &images); err != nil {
+		// Images are appended to show requests, so there is no need for another request
+		var show *Show
+		if err := cacheStore.Get(fmt.Sprintf(cache.TMDBShowByIDKey, showID, config.Get().Language), &show); err == nil && show != nil && show.Images != nil {
+			return show.Images
+		}
+
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/images", tmdbEndpoint, showID),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 			}.AsUrlValues(),
 			Result:      &images,
@@ -60,7 +65,6 @@ func GetSeasonImages(showID int, season int) *Images {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/images", tmdbEndpoint, showID, season),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 			}.AsUrlValues(),
 			Result:      &images,
@@ -83,7 +87,6 @@ func GetEpisodeImages(showID, season, episode int) *Images {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d/season/%d/episode/%d/images", tmdbEndpoint, showID, season, episode),
 			Params: napping.Params{
-				"api_key":                apiKey,
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 			}.AsUrlValues(),
 			Result:      &images,
@@ -114,8 +117,7 @@ func GetShow(showID int, language string) (show *Show) {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/tv/%d", tmdbEndpoint, showID),
 			Params: napping.Params{
-				"api_key":                apiKey,
-				"append_to_response":     "credits,images,alternative_titles,translations,external_ids,content_ratings",
+				"append_to_response":     "aggregate_credits,images,alternative_titles,translations,external_ids,content_ratings",
 				"include_image_language": fmt.Sprintf("%s,en,null", config.Get().Language),
 				"language":               language,
 			}.AsUrlValues(),
@@ -130,6 +132,11 @@ func GetShow(showID int, language string) (show *Show) {
 			return nil
 		}
 
+		if show.AggregateCredits != nil {
+			show.Credits = show.AggregateCredits.ToCredits()
+			show.AggregateCredits = nil
+		}
+
 		if config.Get().UseFanartTv {
 			show.FanArt = fanart.GetShow(util.StrInterfaceToInt(show.ExternalIDs.TVDBID))
 		}
@@ -173,9 +180,8 @@ func SearchShows(query string, language string, page int) (Shows, int) {
 	MakeRequest(APIRequest{
 		URL: fmt.Sprintf("%s/search/tv", tmdbEndpoint),
 		Params: napping.Params{
-			"api_key": apiKey,
-			"query":   query,
-			"page":    strconv.Itoa(page),
+			"query": query,
+			"page":  strconv.Itoa(page),
 		}.AsUrlValues(),
 		Result:      &results,
 		Description: "search show",
@@ -193,7 +199,6 @@ func SearchShows(query string, language string, page int) (Shows, int) {
 }
 
 func listShows(endpoint string, cacheKey string, params napping.Params, page int) (Shows, int) {
-	params["api_key"] = apiKey
 	totalResults := -1
 
 	genre := params["with_genres"]
@@ -313,6 +318,18 @@ func PopularShows(params DiscoverFilters, language string, page int) (Shows, int
 	return listShows("discover/tv", "popular", p, page)
 }
 
+// KidsShows returns popular shows of any of comma separated genres
+func KidsShows(genres string, language string, page int) (Shows, int) {
+	p := napping.Params{
+		"language":           language,
+		"sort_by":            "popularity.desc",
+		"first_air_date.lte": time.Now().UTC().Format("2006-01-02"),
+		"with_genres":        strings.Replace(genres, ",", "|", -1),
+	}
+
+	return listShows("discover/tv", "kids", p, page)
+}
+
 // RecentShows ...
 func RecentShows(params DiscoverFilters, language string, page int) (Shows, int) {
 	var p napping.Params
@@ -409,7 +426,6 @@ func GetTVGenres(language string) []*Genre {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/genre/tv/list", tmdbEndpoint),
 			Params: napping.Params{
-				"api_key":  apiKey,
 				"language": language,
 			}.AsUrlValues(),
 			Result:      &genres,
@@ -422,7 +438,6 @@ func GetTVGenres(language string) []*Genre {
 			err = MakeRequest(APIRequest{
 				URL: fmt.Sprintf("%s/genre/tv/list", tmdbEndpoint),
 				Params: napping.Params{
-					"api_key":  apiKey,
 					"language": "en-US",
 				}.AsUrlValues(),
 				Result:      &genres,
@@ -515,16 +530,13 @@ func (show *Show) AnimeInfoWithShow(episode *Episode, tvdbShow *tvdb.Show) (an i
 func (show *Show) ToListItem() *xbmc.ListItem {
 	year, _ := strconv.Atoi(strings.Split(show.FirstAirDate, "-")[0])
 
-	name := show.name()
-	if config.Get().UseOriginalTitle && show.OriginalName != "" {
-		name = show.OriginalName
-	}
+	name := DisplayTitle(show.name(), show.OriginalName)
 
 	item := &xbmc.ListItem{
 		Label: name,
 		Info: &xbmc.ListItemInfo{
 			Year:          year,
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountShow, show.ID, 0),
 			Title:         name,
 			OriginalTitle: show.OriginalName,
 			Plot:          show.overview(),
@@ -552,14 +564,21 @@ func (show *Show) ToListItem() *xbmc.ListItem {
 
 	if show.Images != nil && show.Images.Backdrops != nil {
 		fanarts := make([]string, 0)
-		for _, backdrop := range show.Images.Backdrops {
+		for _, backdrop := range SortImages(show.Images.Backdrops, config.Get().TextlessBackdrops) {
 			fanarts = append(fanarts, ImageURL(backdrop.FilePath, "w1280"))
 		}
 		if len(fanarts) > 0 {
-			item.Art.FanArt = fanarts[rand.Intn(len(fanarts))]
+			item.Art.FanArt = fanarts[0]
 			item.Art.FanArts = fanarts
 		}
 	}
+	if show.Images != nil {
+		if poster := BestImage(show.Images.Posters, false); poster != nil {
+			item.Art.Poster = ImageURL(poster.FilePath, "w1280")
+			item.Art.Thumbnail = item.Art.Poster
+			item.Art.TvShowPoster = item.Art.Poster
+		}
+	}
 
 	if config.Get().UseFanartTv && show.FanArt != nil {
 		item.Art = show.FanArt.ToListItemArt(item.Art)
@@ -607,6 +626,8 @@ func (show *Show) ToListItem() *xbmc.ListItem {
 		item.Info.Director = strings.Join(directors, " / ")
 		item.Info.Writer = strings.Join(writers, " / ")
 	}
+
+	placeholder.Fill(item)
 	return item
 }
 
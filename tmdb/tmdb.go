@@ -2,14 +2,16 @@ package tmdb
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
 	"github.com/elgatito/elementum/fanart"
+	"github.com/elgatito/elementum/notifications"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 	"github.com/jmcvetta/napping"
@@ -106,6 +108,9 @@ type Show struct {
 	Credits *Credits `json:"credits,omitempty"`
 	Images  *Images  `json:"images,omitempty"`
 
+	// AggregateCredits are converted into Credits once fetched, and are not stored
+	AggregateCredits *AggregateCredits `json:"aggregate_credits,omitempty" msg:"-"`
+
 	Seasons SeasonList `json:"seasons"`
 }
 
@@ -136,6 +141,9 @@ type Season struct {
 	Credits *Credits `json:"credits,omitempty"`
 	Images  *Images  `json:"images,omitempty"`
 
+	// AggregateCredits are converted into Credits once fetched, and are not stored
+	AggregateCredits *AggregateCredits `json:"aggregate_credits,omitempty" msg:"-"`
+
 	Episodes EpisodeList `json:"episodes"`
 }
 
@@ -224,10 +232,12 @@ type LanguageList struct {
 
 // Image ...
 type Image struct {
-	FilePath string `json:"file_path"`
-	Height   int    `json:"height"`
-	Iso639_1 string `json:"iso_639_1"`
-	Width    int    `json:"width"`
+	FilePath    string  `json:"file_path"`
+	Height      int     `json:"height"`
+	Iso639_1    string  `json:"iso_639_1"`
+	Width       int     `json:"width"`
+	VoteAverage float64 `json:"vote_average"`
+	VoteCount   int     `json:"vote_count"`
 }
 
 // Images ...
@@ -361,6 +371,17 @@ type DiscoverFilters struct {
 	Genre    string
 	Country  string
 	Language string
+
+	// Filters below are used by discover presets only
+	YearFrom       int     `msg:"-"`
+	YearTo         int     `msg:"-"`
+	VoteAverageMin float64 `msg:"-"`
+	VoteCountMin   int     `msg:"-"`
+	RuntimeMin     int     `msg:"-"`
+	RuntimeMax     int     `msg:"-"`
+	WatchProvider  string  `msg:"-"`
+	Certification  string  `msg:"-"`
+	Keywords       string  `msg:"-"`
 }
 
 // APIRequest ...
@@ -389,48 +410,21 @@ var (
 		"ae4bd1b6fce2a5648671bfc171d15ba4",
 		"29a551a65eef108dd01b46e27eb0554a",
 	}
-	apiKey = apiKeys[rand.Intn(len(apiKeys))]
 	// WarmingUp ...
 	WarmingUp = util.Event{}
 )
 
 var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
 
-// CheckAPIKey ...
-func CheckAPIKey() {
-	log.Info("Checking TMDB API key...")
-
-	customAPIKey := config.Get().TMDBApiKey
-	if customAPIKey != "" {
-		apiKeys = append(apiKeys, customAPIKey)
-		apiKey = customAPIKey
+var breaker = util.NewCircuitBreaker("TMDB", func(open bool) {
+	cache.AllowStale(cache.TMDBKey, open)
+	if open {
+		notifications.Notify(notifications.ServiceUnavailable, "LOCALIZE[30858];;TMDB")
 	}
+})
 
-	result := false
-	for index := len(apiKeys) - 1; index >= 0; index-- {
-		result = tmdbCheck(apiKey)
-		if result {
-			log.Noticef("TMDB API key check passed, using %s...", apiKey[:7])
-			break
-		} else {
-			log.Warningf("TMDB API key failed: %s", apiKey)
-			if apiKey == apiKeys[index] {
-				apiKeys = append(apiKeys[:index], apiKeys[index+1:]...)
-			}
-			if len(apiKeys) > 0 {
-				apiKey = apiKeys[rand.Intn(len(apiKeys))]
-			} else {
-				result = false
-				break
-			}
-		}
-	}
-	if result == false {
-		log.Error("No valid TMDB API key found")
-	}
-}
-
-func tmdbCheck(key string) bool {
+// tmdbCheck checks if API key is valid, error is returned, if TMDB is not reachable
+func tmdbCheck(key string) (bool, error) {
 	var result *Entity
 
 	urlValues := napping.Params{
@@ -447,12 +441,34 @@ func tmdbCheck(key string) bool {
 	if err != nil {
 		log.Error(err.Error())
 		xbmc.Notify("Elementum", "TMDB check failed, check your logs.", config.AddonIcon())
-		return false
+		return false, err
 	} else if resp.Status() != 200 {
-		return false
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// DisplayTitle returns title of a movie or a show, formatted according to title display setting
+func DisplayTitle(localized, original string) string {
+	if original == "" || strings.EqualFold(original, localized) {
+		return localized
+	} else if localized == "" {
+		return original
+	}
+
+	switch config.Get().TitleDisplay {
+	case config.TitleDisplayBoth:
+		return fmt.Sprintf("%s (%s)", localized, original)
+	case config.TitleDisplayOriginal:
+		return original
 	}
 
-	return true
+	// Original title, used for search, was also displayed before title display setting was added
+	if config.Get().UseOriginalTitle {
+		return original
+	}
+	return localized
 }
 
 // ImageURL ...
@@ -523,7 +539,6 @@ func Find(externalID string, externalSource string) *FindResult {
 		err = MakeRequest(APIRequest{
 			URL: fmt.Sprintf("%s/find/%s", tmdbEndpoint, externalID),
 			Params: napping.Params{
-				"api_key":         apiKey,
 				"external_source": externalSource,
 			}.AsUrlValues(),
 			Result:      &result,
@@ -546,10 +561,8 @@ func GetCountries(language string) []*Country {
 	key := fmt.Sprintf(cache.TMDBCountriesKey, language)
 	if err := cacheStore.Get(key, &countries); err != nil {
 		err = MakeRequest(APIRequest{
-			URL: fmt.Sprintf("%s/configuration/countries", tmdbEndpoint),
-			Params: napping.Params{
-				"api_key": apiKey,
-			}.AsUrlValues(),
+			URL:         fmt.Sprintf("%s/configuration/countries", tmdbEndpoint),
+			Params:      napping.Params{}.AsUrlValues(),
 			Result:      &countries,
 			Description: "countries",
 		})
@@ -570,10 +583,8 @@ func GetLanguages(language string) []*Language {
 	key := fmt.Sprintf(cache.TMDBLanguagesKey, language)
 	if err := cacheStore.Get(key, &languages); err != nil {
 		err = MakeRequest(APIRequest{
-			URL: fmt.Sprintf("%s/configuration/languages", tmdbEndpoint),
-			Params: napping.Params{
-				"api_key": apiKey,
-			}.AsUrlValues(),
+			URL:         fmt.Sprintf("%s/configuration/languages", tmdbEndpoint),
+			Params:      napping.Params{}.AsUrlValues(),
 			Result:      &languages,
 			Description: "languages",
 		})
@@ -593,36 +604,112 @@ func GetLanguages(language string) []*Language {
 }
 
 // MakeRequest used to proxy requests with proper RateLimiter usage and HTTP error processing
-func MakeRequest(r APIRequest) (ret error) {
-	rl.Call(func() error {
-		resp, err := napping.Get(
-			r.URL,
-			&r.Params,
-			r.Result,
-			r.ErrMsg,
-		)
-		if err != nil {
-			log.Errorf("Failed to make request to %s for %s with %+v: %s", r.URL, r.Description, r.Params, err)
-			ret = err
-		} else if resp.Status() == 429 {
-			log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
-			rl.CoolDown(resp.HttpResponse().Header)
-			ret = util.ErrExceeded
-			return util.ErrExceeded
-		} else if resp.Status() == 404 {
-			log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
-			rl.CoolDown(resp.HttpResponse().Header)
-			ret = util.ErrNotFound
-			return util.ErrNotFound
-		} else if resp.Status() != 200 {
-			log.Errorf("Bad status getting %s with %+v on %s: %d", r.Description, r.Params, r.URL, resp.Status())
-			ret = util.ErrHTTP
-			return util.ErrHTTP
-		}
+func MakeRequest(r APIRequest) error {
+	if !breaker.Allow() {
+		return util.ErrCircuitOpen
+	}
+
+	if r.Params == nil {
+		r.Params = url.Values{}
+	}
 
-		ret = nil
-		return nil
+	// Transient failures are retried outside of rate limiter, so that waiting
+	// for next attempt does not hold a rate limiter slot
+	var resp *napping.Response
+	err := util.DefaultRetryPolicy.Do("GET", func() (int, error) {
+		var errGet error
+		rl.Call(func() error {
+			key := nextAPIKey()
+			if key != nil {
+				r.Params.Set("api_key", key.key)
+			}
+
+			resp, errGet = napping.Get(
+				r.URL,
+				&r.Params,
+				r.Result,
+				r.ErrMsg,
+			)
+			if errGet != nil {
+				return errGet
+			} else if resp.Status() == 429 {
+				log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
+				if key != nil {
+					key.coolDown(resp.HttpResponse().Header)
+				}
+				// Request is repeated with another key right away, or after cooldown, if all keys are rate limited
+				if !hasAvailableAPIKey() {
+					rl.CoolDown(resp.HttpResponse().Header)
+				}
+				return util.ErrExceeded
+			} else if resp.Status() == 404 {
+				log.Warningf("Rate limit exceeded getting %s with %+v on %s, cooling down...", r.Description, r.Params, r.URL)
+				rl.CoolDown(resp.HttpResponse().Header)
+				return util.ErrNotFound
+			}
+
+			return nil
+		})
+		return util.ResponseStatus(resp), errGet
+	})
+	breaker.Report(util.ResponseStatus(resp), err)
+
+	if err != nil {
+		log.Errorf("Failed to make request to %s for %s with %+v: %s", r.URL, r.Description, r.Params, err)
+		return err
+	} else if resp.Status() == 429 {
+		return util.ErrExceeded
+	} else if resp.Status() == 404 {
+		return util.ErrNotFound
+	} else if resp.Status() != 200 {
+		log.Errorf("Bad status getting %s with %+v on %s: %d", r.Description, r.Params, r.URL, resp.Status())
+		return util.ErrHTTP
+	}
+
+	return nil
+}
+
+// Images with fewer votes are weighted towards neutral rating,
+// so a single high vote does not win over well voted images.
+const (
+	imageVotesMinimum = 3
+	imageNeutralVote  = 5.0
+)
+
+// SortImages returns images, ordered by score: language preference, votes and resolution.
+// Posters prefer configured language, backdrops may prefer images without text.
+func SortImages(images []*Image, textless bool) []*Image {
+	ret := make([]*Image, len(images))
+	copy(ret, images)
+
+	language := config.Get().Language
+	sort.SliceStable(ret, func(i, j int) bool {
+		return imageScore(ret[i], language, textless) > imageScore(ret[j], language, textless)
 	})
+	return ret
+}
+
+// BestImage returns the image with the highest score, or nil for empty list
+func BestImage(images []*Image, textless bool) *Image {
+	if sorted := SortImages(images, textless); len(sorted) > 0 {
+		return sorted[0]
+	}
+	return nil
+}
+
+func imageScore(image *Image, language string, textless bool) float64 {
+	score := (image.VoteAverage*float64(image.VoteCount) + imageNeutralVote*imageVotesMinimum) / float64(image.VoteCount+imageVotesMinimum)
+
+	// Up to one point for resolution, full point for 4K width
+	score += math.Min(float64(image.Width)/3840, 1)
+
+	if textless {
+		if image.Iso639_1 == "" {
+			score += 10
+		}
+	} else if image.Iso639_1 == language {
+		score += 10
+	}
 
-	return
+	return score
 }
@@ -0,0 +1,127 @@
+package tmdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elgatito/elementum/cache"
+
+	"github.com/jmcvetta/napping"
+)
+
+// WatchProvider is a streaming service, or a store, offering a movie or a show
+type WatchProvider struct {
+	ProviderID      int    `json:"provider_id"`
+	ProviderName    string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+// WatchProviders lists, where a movie or a show can be watched in a country
+type WatchProviders struct {
+	Link     string           `json:"link"`
+	Flatrate []*WatchProvider `json:"flatrate"`
+	Free     []*WatchProvider `json:"free"`
+	Ads      []*WatchProvider `json:"ads"`
+	Rent     []*WatchProvider `json:"rent"`
+	Buy      []*WatchProvider `json:"buy"`
+}
+
+type watchProvidersResults struct {
+	Results map[string]*WatchProviders `json:"results"`
+}
+
+type watchProvidersList struct {
+	Results []*WatchProvider `json:"results"`
+}
+
+// mediaType is "movie" or "tv", as used by TMDB endpoints
+func watchProvidersEndpoint(mediaType string) string {
+	if mediaType == "movie" {
+		return "movie"
+	}
+	return "tv"
+}
+
+// GetWatchProviders returns, where a movie or a show can be watched in a country, or nil if it is not offered there.
+// Data is provided to TMDB by JustWatch.
+func GetWatchProviders(mediaType string, id int, country string) *WatchProviders {
+	endpoint := watchProvidersEndpoint(mediaType)
+	country = strings.ToUpper(country)
+
+	var providers *WatchProviders
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBWatchProvidersKey, endpoint, id, country)
+	if err := cacheStore.Get(key, &providers); err == nil {
+		return providers
+	}
+
+	results := watchProvidersResults{}
+	err := MakeRequest(APIRequest{
+		URL:         fmt.Sprintf("%s/%s/%d/watch/providers", tmdbEndpoint, endpoint, id),
+		Params:      napping.Params{}.AsUrlValues(),
+		Result:      &results,
+		Description: endpoint + " watch providers",
+	})
+	if err != nil {
+		return nil
+	}
+
+	providers = results.Results[country]
+	cacheStore.Set(key, providers, cache.TMDBWatchProvidersExpire)
+	return providers
+}
+
+// GetWatchProvidersList returns streaming services, available in a country, sorted by popularity
+func GetWatchProvidersList(mediaType string, country string) []*WatchProvider {
+	endpoint := watchProvidersEndpoint(mediaType)
+	country = strings.ToUpper(country)
+
+	list := watchProvidersList{}
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBWatchProvidersListKey, endpoint, country)
+	if err := cacheStore.Get(key, &list); err == nil {
+		return list.Results
+	}
+
+	err := MakeRequest(APIRequest{
+		URL: fmt.Sprintf("%s/watch/providers/%s", tmdbEndpoint, endpoint),
+		Params: napping.Params{
+			"watch_region": country,
+		}.AsUrlValues(),
+		Result:      &list,
+		Description: endpoint + " watch providers list",
+	})
+	if err != nil || len(list.Results) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(list.Results, func(i, j int) bool {
+		return list.Results[i].DisplayPriority < list.Results[j].DisplayPriority
+	})
+	cacheStore.Set(key, list, cache.TMDBWatchProvidersListExpire)
+	return list.Results
+}
+
+// WatchProviderNames returns names of providers, without duplicates
+func WatchProviderNames(providers []*WatchProvider) []string {
+	ret := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+
+		found := false
+		for _, name := range ret {
+			if name == provider.ProviderName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, provider.ProviderName)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,149 @@
+package trakt
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+// Theatrical release types of TMDB release dates
+const (
+	tmdbReleaseTheatricalLimited = 2
+	tmdbReleaseTheatrical        = 3
+)
+
+// fillMoviesReleaseDates sets release dates of movies from TMDB, preferring theatrical release in user region
+func fillMoviesReleaseDates(movies []*Movies) {
+	language := config.Get().Language
+
+	var wg sync.WaitGroup
+	for _, m := range movies {
+		if m == nil || m.Movie == nil || m.Movie.IDs == nil || m.Movie.IDs.TMDB == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(m *Movies) {
+			defer wg.Done()
+
+			date := m.Movie.Released
+			if movie := tmdb.GetMovie(m.Movie.IDs.TMDB, language); movie != nil {
+				if regional := regionalReleaseDate(movie); regional != "" {
+					date = regional
+				} else if movie.ReleaseDate != "" {
+					date = movie.ReleaseDate
+				}
+			}
+			m.ReleaseDate = parseReleaseDate(date)
+		}(m)
+	}
+	wg.Wait()
+}
+
+// fillShowsReleaseDates sets release dates of shows from TMDB, which is the air date of an upcoming season,
+// or first air date for new shows
+func fillShowsReleaseDates(shows []*Shows) {
+	language := config.Get().Language
+	today := util.UTCBod()
+
+	var wg sync.WaitGroup
+	for _, s := range shows {
+		if s == nil || s.Show == nil || s.Show.IDs == nil || s.Show.IDs.TMDB == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *Shows) {
+			defer wg.Done()
+
+			date := parseReleaseDate(s.Show.FirstAired)
+			if show := tmdb.GetShow(s.Show.IDs.TMDB, language); show != nil {
+				date = parseReleaseDate(show.FirstAirDate)
+				for _, season := range show.Seasons {
+					if season == nil || season.Season == 0 {
+						continue
+					}
+					if aired := parseReleaseDate(season.AirDate); !aired.Before(today) {
+						date = aired
+						break
+					}
+				}
+			}
+			s.ReleaseDate = date
+		}(s)
+	}
+	wg.Wait()
+}
+
+func regionalReleaseDate(movie *tmdb.Movie) string {
+	if movie.ReleaseDates == nil {
+		return ""
+	}
+
+	region := config.Get().Region
+	ret := ""
+	for _, r := range movie.ReleaseDates.Results {
+		if r == nil || strings.ToUpper(r.Iso3166_1) != region {
+			continue
+		}
+		for _, rd := range r.ReleaseDates {
+			if rd == nil || (rd.Type != tmdbReleaseTheatricalLimited && rd.Type != tmdbReleaseTheatrical) {
+				continue
+			}
+			if ret == "" || rd.ReleaseDate < ret {
+				ret = rd.ReleaseDate
+			}
+		}
+	}
+	return ret
+}
+
+func parseReleaseDate(date string) time.Time {
+	if len(date) > 10 {
+		date = date[0:10]
+	}
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
+// SortMoviesByRelease sorts movies by days until release, movies without known release date go last
+func SortMoviesByRelease(movies []*Movies) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		return releasesBefore(movieReleaseDate(movies[i]), movieReleaseDate(movies[j]))
+	})
+}
+
+// SortShowsByRelease sorts shows by days until release, shows without known release date go last
+func SortShowsByRelease(shows []*Shows) {
+	sort.SliceStable(shows, func(i, j int) bool {
+		return releasesBefore(showReleaseDate(shows[i]), showReleaseDate(shows[j]))
+	})
+}
+
+func releasesBefore(a, b time.Time) bool {
+	if a.IsZero() {
+		return false
+	} else if b.IsZero() {
+		return true
+	}
+	return a.Before(b)
+}
+
+func movieReleaseDate(m *Movies) time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return m.ReleaseDate
+}
+
+func showReleaseDate(s *Shows) time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.ReleaseDate
+}
@@ -0,0 +1,107 @@
+package trakt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/jmcvetta/napping"
+)
+
+// Trakt allows up to 33 days in a single calendar request,
+// longer ranges are fetched in several requests
+const calendarMaxDays = 31
+
+// CalendarShowsRange returns shows calendar for a number of days, starting with start date, sorted by air date
+func CalendarShowsRange(endPoint string, start time.Time, days int) (shows []*CalendarShow, err error) {
+	endPointKey := strings.Replace(endPoint, "/", ".", -1)
+	cacheStore := cache.NewDBStore()
+
+	for offset := 0; offset < days; offset += calendarMaxDays {
+		chunkStart, chunkDays := calendarChunk(start, days, offset)
+		key := fmt.Sprintf(cache.TraktShowsCalendarKey, endPointKey, chunkStart+"."+fmt.Sprint(chunkDays))
+
+		var chunk []*CalendarShow
+		if err := cacheStore.Get(key, &chunk); err != nil {
+			if err := getCalendarRange(endPoint, chunkStart, chunkDays, &chunk); err != nil {
+				return filterHiddenCalendarShows(shows), err
+			}
+			cacheStore.Set(key, &chunk, cache.TraktShowsCalendarExpire)
+		}
+		shows = append(shows, chunk...)
+	}
+
+	sort.SliceStable(shows, func(i, j int) bool {
+		return calendarShowAired(shows[i]) < calendarShowAired(shows[j])
+	})
+	return filterHiddenCalendarShows(shows), nil
+}
+
+// CalendarMoviesRange returns movies calendar for a number of days, starting with start date, sorted by release date
+func CalendarMoviesRange(endPoint string, start time.Time, days int) (movies []*CalendarMovie, err error) {
+	endPointKey := strings.Replace(endPoint, "/", ".", -1)
+	cacheStore := cache.NewDBStore()
+
+	for offset := 0; offset < days; offset += calendarMaxDays {
+		chunkStart, chunkDays := calendarChunk(start, days, offset)
+		key := fmt.Sprintf(cache.TraktMoviesCalendarKey, endPointKey, chunkStart+"."+fmt.Sprint(chunkDays))
+
+		var chunk []*CalendarMovie
+		if err := cacheStore.Get(key, &chunk); err != nil {
+			if err := getCalendarRange(endPoint, chunkStart, chunkDays, &chunk); err != nil {
+				return filterHiddenCalendarMovies(movies), err
+			}
+			cacheStore.Set(key, &chunk, cache.TraktMoviesCalendarExpire)
+		}
+		movies = append(movies, chunk...)
+	}
+
+	sort.SliceStable(movies, func(i, j int) bool {
+		return calendarMovieReleased(movies[i]) < calendarMovieReleased(movies[j])
+	})
+	return filterHiddenCalendarMovies(movies), nil
+}
+
+// calendarChunk returns start date and length of a single request, beginning offset days after start
+func calendarChunk(start time.Time, days int, offset int) (string, int) {
+	chunkDays := days - offset
+	if chunkDays > calendarMaxDays {
+		chunkDays = calendarMaxDays
+	}
+	return start.AddDate(0, 0, offset).Format("2006-01-02"), chunkDays
+}
+
+func getCalendarRange(endPoint string, start string, days int, ret interface{}) error {
+	params := napping.Params{
+		"extended": "full,images",
+	}.AsUrlValues()
+
+	resp, err := GetCalendar(fmt.Sprintf("%s/%s/%d", endPoint, start, days), params)
+	if err != nil {
+		return err
+	} else if resp.Status() != 200 {
+		return fmt.Errorf("Bad status getting %s Trakt calendar: %d", endPoint, resp.Status())
+	}
+
+	return resp.Unmarshal(ret)
+}
+
+func calendarShowAired(s *CalendarShow) string {
+	if s == nil {
+		return ""
+	} else if s.FirstAired == "" && s.Episode != nil {
+		return s.Episode.FirstAired
+	}
+	return s.FirstAired
+}
+
+func calendarMovieReleased(m *CalendarMovie) string {
+	if m == nil {
+		return ""
+	} else if m.Released == "" && m.Movie != nil {
+		return m.Movie.Released
+	}
+	return m.Released
+}
@@ -0,0 +1,57 @@
+package trakt
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CollectionMetadata describes quality of a collected item, values follow Trakt naming,
+// like "hd_1080p" for resolution or "dolby_digital" for audio.
+type CollectionMetadata struct {
+	MediaType     string `json:"media_type,omitempty"`
+	Resolution    string `json:"resolution,omitempty"`
+	HDR           string `json:"hdr,omitempty"`
+	Audio         string `json:"audio,omitempty"`
+	AudioChannels string `json:"audio_channels,omitempty"`
+	ThreeD        bool   `json:"3d,omitempty"`
+}
+
+type collectionItem struct {
+	CollectedAt string `json:"collected_at"`
+	IDs         struct {
+		TMDB int `json:"tmdb"`
+	} `json:"ids"`
+	*CollectionMetadata
+}
+
+// CollectItem adds movie or episode, identified by TMDB ID, to user collection with quality metadata.
+// itemType is one of "movies" or "episodes", showID is a TMDB ID of episode's show.
+func CollectItem(itemType string, tmdbID int, showID int, meta *CollectionMetadata) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+	if itemType != "movies" && itemType != "episodes" {
+		return fmt.Errorf("Unknown item type for collection: %s", itemType)
+	}
+
+	item := collectionItem{
+		CollectedAt:        time.Now().UTC().Format(time.RFC3339),
+		CollectionMetadata: meta,
+	}
+	item.IDs.TMDB = tmdbID
+
+	resp, err := PostJSON("sync/collection", map[string][]collectionItem{itemType: {item}})
+	if err != nil {
+		return err
+	} else if resp.Status() != 201 {
+		return fmt.Errorf("Bad status adding to Trakt collection: %d", resp.Status())
+	}
+
+	if itemType == "movies" {
+		updateCachedCollection("movies", strconv.Itoa(tmdbID), true)
+	} else if showID != 0 {
+		updateCachedCollection("shows", strconv.Itoa(showID), true)
+	}
+	return nil
+}
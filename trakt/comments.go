@@ -0,0 +1,93 @@
+package trakt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/jmcvetta/napping"
+)
+
+// Comment is a Trakt comment or review, left for a movie, show or episode
+type Comment struct {
+	ID        int       `json:"id"`
+	ParentID  int       `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Comment   string    `json:"comment"`
+	Spoiler   bool      `json:"spoiler"`
+	Review    bool      `json:"review"`
+	Replies   int       `json:"replies"`
+	Likes     int       `json:"likes"`
+	UserStats struct {
+		Rating             int `json:"rating"`
+		PlayCount          int `json:"play_count"`
+		CompletedPlayCount int `json:"completed_play_count"`
+	} `json:"user_stats"`
+	User *User `json:"user"`
+}
+
+// CommentsPage is a cached page of comments
+type CommentsPage struct {
+	Comments []*Comment
+	HasNext  bool
+}
+
+// GetComments returns a page of comments, most liked first. mediaType is one of "movie", "show" or "episode",
+// id is a Trakt ID or slug of a movie or show, for episodes it is in form of "<show>/seasons/<season>/episodes/<episode>".
+func GetComments(mediaType string, id string, page int) (comments []*Comment, hasNext bool, err error) {
+	var endPoint string
+	switch mediaType {
+	case "movie":
+		endPoint = fmt.Sprintf("movies/%s/comments/likes", id)
+	case "show", "episode":
+		endPoint = fmt.Sprintf("shows/%s/comments/likes", id)
+	default:
+		return nil, false, fmt.Errorf("Unknown media type for comments: %s", mediaType)
+	}
+
+	ret := CommentsPage{}
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TraktCommentsKey, mediaType, strings.Replace(id, "/", ".", -1), page)
+	if err := cacheStore.Get(key, &ret); err == nil {
+		return ret.Comments, ret.HasNext, nil
+	}
+
+	params := napping.Params{
+		"page":  strconv.Itoa(page),
+		"limit": strconv.Itoa(config.Get().ResultsPerPage),
+	}.AsUrlValues()
+
+	resp, err := Get(endPoint, params)
+	if err != nil {
+		return
+	} else if resp.Status() != 200 {
+		return nil, false, fmt.Errorf("Bad status getting Trakt comments: %d", resp.Status())
+	}
+
+	if err = resp.Unmarshal(&ret.Comments); err != nil {
+		return
+	}
+
+	p := getPagination(resp.HttpResponse().Header)
+	ret.HasNext = p.PageCount > page
+
+	cacheStore.Set(key, ret, cache.TraktCommentsExpire)
+	return ret.Comments, ret.HasNext, nil
+}
+
+// GetComment returns single comment by its ID
+func GetComment(commentID int) (comment *Comment, err error) {
+	resp, err := Get(fmt.Sprintf("comments/%d", commentID), napping.Params{}.AsUrlValues())
+	if err != nil {
+		return
+	} else if resp.Status() != 200 {
+		return nil, fmt.Errorf("Bad status getting Trakt comment: %d", resp.Status())
+	}
+
+	err = resp.Unmarshal(&comment)
+	return
+}
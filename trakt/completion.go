@@ -0,0 +1,70 @@
+package trakt
+
+import (
+	"sort"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// ShowCompletion is a watched progress of a show, except specials
+type ShowCompletion struct {
+	Watched int
+	Aired   int
+}
+
+// Percent returns watched part of aired episodes
+func (c *ShowCompletion) Percent() int {
+	if c == nil || c.Aired == 0 {
+		return 0
+	}
+	if c.Watched >= c.Aired {
+		return 100
+	}
+	return c.Watched * 100 / c.Aired
+}
+
+// ShowsCompletion returns watched progress of shows by Trakt id.
+// Progress is taken from cached watched shows, so it makes no requests.
+func ShowsCompletion() map[int]*ShowCompletion {
+	ret := map[int]*ShowCompletion{}
+	if config.Get().TraktToken == "" {
+		return ret
+	}
+
+	watched, err := PreviousWatchedShows()
+	if err != nil {
+		return ret
+	}
+	for _, s := range watched {
+		if s == nil || s.Show == nil || s.Show.IDs == nil || s.Show.AiredEpisodes == 0 {
+			continue
+		}
+		ret[s.Show.IDs.Trakt] = &ShowCompletion{
+			Watched: watchedEpisodesCount(s),
+			Aired:   s.Show.AiredEpisodes,
+		}
+	}
+	return ret
+}
+
+// SortShowsByCompletion puts nearly finished shows first,
+// fully watched shows follow them and shows, not started yet, go last
+func SortShowsByCompletion(shows []*Shows) {
+	completion := ShowsCompletion()
+	rank := func(s *Shows) int {
+		if s == nil || s.Show == nil || s.Show.IDs == nil {
+			return -1
+		}
+		c, ok := completion[s.Show.IDs.Trakt]
+		if !ok || c.Watched == 0 {
+			return -1
+		} else if p := c.Percent(); p < 100 {
+			return 100 + p
+		}
+		return 0
+	}
+
+	sort.SliceStable(shows, func(i, j int) bool {
+		return rank(shows[i]) > rank(shows[j])
+	})
+}
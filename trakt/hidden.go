@@ -0,0 +1,215 @@
+package trakt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/jmcvetta/napping"
+)
+
+// Sections of Trakt hidden items
+const (
+	HiddenProgressWatched   = "progress_watched"
+	HiddenProgressCollected = "progress_collected"
+	HiddenCalendar          = "calendar"
+	HiddenRecommendations   = "recommendations"
+)
+
+const hiddenPageLimit = 100
+
+// HiddenItem is an entry of users/hidden/* listing
+type HiddenItem struct {
+	HiddenAt time.Time `json:"hidden_at"`
+	Type     string    `json:"type"`
+	Movie    *Movie    `json:"movie"`
+	Show     *Show     `json:"show"`
+}
+
+// HiddenShows returns shows, hidden by user in specified section
+func HiddenShows(section string) ([]*HiddenItem, error) {
+	return hiddenItems(section, "show")
+}
+
+// HiddenMovies returns movies, hidden by user in specified section
+func HiddenMovies(section string) ([]*HiddenItem, error) {
+	return hiddenItems(section, "movie")
+}
+
+// IsShowHidden checks whether show, identified by TMDB id, is hidden in specified section
+func IsShowHidden(section string, tmdbID int) bool {
+	items, _ := HiddenShows(section)
+	for _, i := range items {
+		if i.Show != nil && i.Show.IDs != nil && i.Show.IDs.TMDB == tmdbID {
+			return true
+		}
+	}
+	return false
+}
+
+// HideShow hides show, identified by TMDB id, in specified section
+func HideShow(section string, tmdbID int) error {
+	return setHidden(section, "show", tmdbID, true)
+}
+
+// UnhideShow returns show, identified by TMDB id, back to specified section
+func UnhideShow(section string, tmdbID int) error {
+	return setHidden(section, "show", tmdbID, false)
+}
+
+func hiddenItems(section string, itemType string) (items []*HiddenItem, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TraktHiddenKey, section, itemType)
+	if err := cacheStore.Get(key, &items); err == nil {
+		return items, nil
+	}
+
+	endPoint := "users/hidden/" + section
+	for page := 1; ; page++ {
+		params := napping.Params{
+			"type":  itemType,
+			"page":  strconv.Itoa(page),
+			"limit": strconv.Itoa(hiddenPageLimit),
+		}.AsUrlValues()
+
+		resp, err := GetWithAuth(endPoint, params)
+		if err != nil {
+			return nil, err
+		} else if resp.Status() != 200 {
+			return nil, fmt.Errorf("Bad status getting Trakt hidden %ss: %d", itemType, resp.Status())
+		}
+
+		var pageItems []*HiddenItem
+		if err := resp.Unmarshal(&pageItems); err != nil {
+			log.Warning(err)
+		}
+		items = append(items, pageItems...)
+
+		if p := getPagination(resp.HttpResponse().Header); page >= p.PageCount {
+			break
+		}
+	}
+
+	cacheStore.Set(key, &items, cache.TraktHiddenExpire)
+	return items, nil
+}
+
+func setHidden(section string, itemType string, tmdbID int, hide bool) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	endPoint := "users/hidden/" + section
+	if !hide {
+		endPoint += "/remove"
+	}
+
+	resp, err := Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%ss": [{"ids": {"tmdb": %d}}]}`, itemType, tmdbID)))
+	if err != nil {
+		return err
+	} else if resp.Status() != 200 && resp.Status() != 201 {
+		return fmt.Errorf("Bad status changing Trakt hidden %s: %d", itemType, resp.Status())
+	}
+
+	cache.NewDBStore().Delete(fmt.Sprintf(cache.TraktHiddenKey, section, itemType))
+	return nil
+}
+
+// hiddenShowIDs returns Trakt ids of shows, hidden in specified section
+func hiddenShowIDs(section string) map[int]bool {
+	ret := map[int]bool{}
+	if config.Get().TraktToken == "" {
+		return ret
+	}
+
+	items, err := HiddenShows(section)
+	if err != nil {
+		log.Warningf("Could not get hidden shows: %s", err)
+		return ret
+	}
+	for _, i := range items {
+		if i.Show != nil && i.Show.IDs != nil {
+			ret[i.Show.IDs.Trakt] = true
+		}
+	}
+	return ret
+}
+
+// hiddenMovieIDs returns Trakt ids of movies, hidden in specified section
+func hiddenMovieIDs(section string) map[int]bool {
+	ret := map[int]bool{}
+	if config.Get().TraktToken == "" {
+		return ret
+	}
+
+	items, err := HiddenMovies(section)
+	if err != nil {
+		log.Warningf("Could not get hidden movies: %s", err)
+		return ret
+	}
+	for _, i := range items {
+		if i.Movie != nil && i.Movie.IDs != nil {
+			ret[i.Movie.IDs.Trakt] = true
+		}
+	}
+	return ret
+}
+
+func isHiddenObject(hidden map[int]bool, o *Object) bool {
+	return len(hidden) > 0 && o != nil && o.IDs != nil && hidden[o.IDs.Trakt]
+}
+
+func filterHiddenShows(shows []*Shows, section string) []*Shows {
+	hidden := hiddenShowIDs(section)
+	ret := make([]*Shows, 0, len(shows))
+	for _, s := range shows {
+		if s != nil && s.Show != nil && isHiddenObject(hidden, &s.Show.Object) {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+func filterHiddenMovies(movies []*Movies, section string) []*Movies {
+	hidden := hiddenMovieIDs(section)
+	ret := make([]*Movies, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && isHiddenObject(hidden, &m.Movie.Object) {
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+func filterHiddenCalendarShows(shows []*CalendarShow) []*CalendarShow {
+	hidden := hiddenShowIDs(HiddenCalendar)
+	ret := make([]*CalendarShow, 0, len(shows))
+	for _, s := range shows {
+		if s != nil && s.Show != nil && isHiddenObject(hidden, &s.Show.Object) {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
+
+func filterHiddenCalendarMovies(movies []*CalendarMovie) []*CalendarMovie {
+	hidden := hiddenMovieIDs(HiddenCalendar)
+	ret := make([]*CalendarMovie, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && isHiddenObject(hidden, &m.Movie.Object) {
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
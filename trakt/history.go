@@ -0,0 +1,64 @@
+package trakt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/jmcvetta/napping"
+)
+
+// History returns page of user's watch history, both movies and episodes
+func History(page string) (items []*HistoryItem, hasNext bool, err error) {
+	if err := Authorized(); err != nil {
+		return items, false, err
+	}
+
+	pageInt, _ := strconv.Atoi(page)
+
+	endPoint := "sync/history"
+	params := napping.Params{
+		"page":     page,
+		"limit":    strconv.Itoa(config.Get().ResultsPerPage),
+		"extended": "full",
+	}.AsUrlValues()
+
+	resp, err := GetWithAuth(endPoint, params)
+	if err != nil {
+		return items, false, err
+	} else if resp.Status() != 200 {
+		return items, false, fmt.Errorf("Bad status getting Trakt history: %d", resp.Status())
+	}
+
+	if err := resp.Unmarshal(&items); err != nil {
+		log.Warning(err)
+	}
+
+	p := getPagination(resp.HttpResponse().Header)
+	hasNext = p.PageCount > pageInt
+
+	return items, hasNext, nil
+}
+
+// RemoveFromHistory removes single history entry by it's history ID
+func RemoveFromHistory(historyID int64) (*HistoryResponse, error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	endPoint := "sync/history/remove"
+	resp, err := Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"ids": [%d]}`, historyID)))
+	if err != nil {
+		return nil, err
+	} else if resp.Status() != 200 {
+		return nil, fmt.Errorf("Bad status removing Trakt history entry: %d", resp.Status())
+	}
+
+	stats := HistoryResponse{}
+	if err = resp.Unmarshal(&stats); err != nil {
+		log.Warning(err)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,155 @@
+package trakt
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+)
+
+// updateCachedWatchlist adds or removes item in cached watchlist, so that listings
+// reflect the change without fetching whole watchlist again.
+func updateCachedWatchlist(itemType string, tmdbID string, add bool) {
+	switch itemType {
+	case "movies":
+		updateCachedMovies(cache.TraktMoviesWatchlistKey, cache.TraktMoviesWatchlistExpire, cache.TraktMoviesWatchlistPendingKey, tmdbID, add)
+	case "shows":
+		updateCachedShows(cache.TraktShowsWatchlistKey, cache.TraktShowsWatchlistExpire, cache.TraktShowsWatchlistPendingKey, tmdbID, add)
+	}
+}
+
+// updateCachedCollection adds or removes item in cached collection
+func updateCachedCollection(itemType string, tmdbID string, add bool) {
+	switch itemType {
+	case "movies":
+		updateCachedMovies(cache.TraktMoviesCollectionKey, cache.TraktMoviesCollectionExpire, cache.TraktMoviesCollectionPendingKey, tmdbID, add)
+	case "shows":
+		updateCachedShows(cache.TraktShowsCollectionKey, cache.TraktShowsCollectionExpire, cache.TraktShowsCollectionPendingKey, tmdbID, add)
+	}
+}
+
+// updateCachedMovies adds or removes movie in cached listing.
+// If listing is not cached, nothing is done, as it will be fetched on next listing anyway.
+// Listing keeps its original expiration, so that it is still refreshed from Trakt in time.
+func updateCachedMovies(key string, expire time.Duration, pendingKey string, tmdbID string, add bool) {
+	id, _ := strconv.Atoi(tmdbID)
+	cacheStore := cache.NewDBStore()
+
+	var movies []*Movies
+	if err := cacheStore.Get(key, &movies); err != nil {
+		return
+	}
+
+	ret := make([]*Movies, 0, len(movies)+1)
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && m.Movie.IDs != nil && m.Movie.IDs.TMDB == id {
+			continue
+		}
+		ret = append(ret, m)
+	}
+	if add {
+		movie := GetMovieByTMDB(tmdbID)
+		if movie == nil || movie.IDs == nil {
+			cacheStore.Delete(key)
+			return
+		}
+		ret = append(ret, &Movies{Movie: movie})
+		addPending(pendingKey, expire, movie.IDs.Trakt)
+	}
+
+	if err := cacheStore.Update(key, &ret); err != nil {
+		cacheStore.Delete(key)
+	}
+}
+
+// updateCachedShows adds or removes show in cached listing.
+// Added shows are remembered as pending, because library sync compares previous listing
+// with the fresh one, and should not consider optimistically added show as already known.
+func updateCachedShows(key string, expire time.Duration, pendingKey string, tmdbID string, add bool) {
+	id, _ := strconv.Atoi(tmdbID)
+	cacheStore := cache.NewDBStore()
+
+	var shows []*Shows
+	if err := cacheStore.Get(key, &shows); err != nil {
+		return
+	}
+
+	ret := make([]*Shows, 0, len(shows)+1)
+	for _, s := range shows {
+		if s != nil && s.Show != nil && s.Show.IDs != nil && s.Show.IDs.TMDB == id {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	if add {
+		show := GetShowByTMDB(tmdbID)
+		if show == nil || show.IDs == nil {
+			cacheStore.Delete(key)
+			return
+		}
+		ret = append(ret, &Shows{Show: show})
+		addPending(pendingKey, expire, show.IDs.Trakt)
+	}
+
+	if err := cacheStore.Update(key, &ret); err != nil {
+		cacheStore.Delete(key)
+	}
+}
+
+// addPending remembers Trakt ID of optimistically added item
+func addPending(pendingKey string, expire time.Duration, traktID int) {
+	cacheStore := cache.NewDBStore()
+
+	var pending []int
+	cacheStore.Get(pendingKey, &pending)
+	pending = append(pending, traktID)
+	cacheStore.Set(pendingKey, pending, expire)
+}
+
+// pendingIDs returns Trakt IDs of optimistically added items
+func pendingIDs(pendingKey string) map[int]bool {
+	var pending []int
+	if err := cache.NewDBStore().Get(pendingKey, &pending); err != nil || len(pending) == 0 {
+		return nil
+	}
+
+	isPending := map[int]bool{}
+	for _, id := range pending {
+		isPending[id] = true
+	}
+	return isPending
+}
+
+// withoutPendingMovies removes optimistically added movies from cached listing
+func withoutPendingMovies(movies []*Movies, pendingKey string) []*Movies {
+	isPending := pendingIDs(pendingKey)
+	if len(isPending) == 0 {
+		return movies
+	}
+
+	ret := make([]*Movies, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && m.Movie.IDs != nil && isPending[m.Movie.IDs.Trakt] {
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+// withoutPendingShows removes optimistically added shows from cached listing
+func withoutPendingShows(shows []*Shows, pendingKey string) []*Shows {
+	isPending := pendingIDs(pendingKey)
+	if len(isPending) == 0 {
+		return shows
+	}
+
+	ret := make([]*Shows, 0, len(shows))
+	for _, s := range shows {
+		if s != nil && s.Show != nil && s.Show.IDs != nil && isPending[s.Show.IDs.Trakt] {
+			continue
+		}
+		ret = append(ret, s)
+	}
+	return ret
+}
@@ -0,0 +1,141 @@
+package trakt
+
+import (
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+)
+
+// listFilter keeps Trakt ids of movies or shows, that should be removed from listings.
+// Ids are taken from cached watched and collection sets, so filtering makes no requests.
+// Collection is read as cached, including items, that were just added to it.
+type listFilter map[int]bool
+
+func (f listFilter) has(o *Object) bool {
+	return len(f) > 0 && o != nil && o.IDs != nil && f[o.IDs.Trakt]
+}
+
+func isListFilterEnabled() bool {
+	conf := config.Get()
+	return conf.TraktToken != "" && (conf.TraktFilterWatched || conf.TraktFilterCollected || conf.TraktFilterHidden)
+}
+
+func moviesListFilter() listFilter {
+	conf := config.Get()
+	ret := listFilter{}
+
+	if conf.TraktFilterWatched {
+		if watched, err := PreviousWatchedMovies(); err == nil {
+			for _, m := range watched {
+				if m != nil && m.Movie != nil && m.Movie.IDs != nil {
+					ret[m.Movie.IDs.Trakt] = true
+				}
+			}
+		}
+	}
+	if conf.TraktFilterCollected {
+		var collected []*Movies
+		if err := cache.NewDBStore().Get(cache.TraktMoviesCollectionKey, &collected); err == nil {
+			for _, m := range collected {
+				if m != nil && m.Movie != nil && m.Movie.IDs != nil {
+					ret[m.Movie.IDs.Trakt] = true
+				}
+			}
+		}
+	}
+	if conf.TraktFilterHidden {
+		for id := range hiddenMovieIDs(HiddenRecommendations) {
+			ret[id] = true
+		}
+	}
+
+	return ret
+}
+
+func showsListFilter() listFilter {
+	conf := config.Get()
+	ret := listFilter{}
+
+	if conf.TraktFilterWatched {
+		if watched, err := PreviousWatchedShows(); err == nil {
+			for _, s := range watched {
+				if isShowFullyWatched(s) {
+					ret[s.Show.IDs.Trakt] = true
+				}
+			}
+		}
+	}
+	if conf.TraktFilterCollected {
+		var collected []*Shows
+		if err := cache.NewDBStore().Get(cache.TraktShowsCollectionKey, &collected); err == nil {
+			for _, s := range collected {
+				if s != nil && s.Show != nil && s.Show.IDs != nil {
+					ret[s.Show.IDs.Trakt] = true
+				}
+			}
+		}
+	}
+	if conf.TraktFilterHidden {
+		for id := range hiddenShowIDs(HiddenRecommendations) {
+			ret[id] = true
+		}
+	}
+
+	return ret
+}
+
+// isShowFullyWatched checks that all aired episodes of a show, except specials, are watched
+func isShowFullyWatched(s *WatchedShow) bool {
+	if s == nil || s.Show == nil || s.Show.IDs == nil || s.Show.AiredEpisodes == 0 {
+		return false
+	}
+
+	return watchedEpisodesCount(s) >= s.Show.AiredEpisodes
+}
+
+// watchedEpisodesCount returns number of watched episodes of a show, except specials
+func watchedEpisodesCount(s *WatchedShow) (watched int) {
+	for _, season := range s.Seasons {
+		if season != nil && season.Number > 0 {
+			watched += len(season.Episodes)
+		}
+	}
+	return
+}
+
+// FilterMovies removes watched, collected or hidden movies from a listing, according to settings
+func FilterMovies(movies []*Movies) []*Movies {
+	if !isListFilterEnabled() {
+		return movies
+	}
+
+	filter := moviesListFilter()
+	ret := make([]*Movies, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && m.Movie != nil && filter.has(&m.Movie.Object) {
+			continue
+		}
+		ret = append(ret, m)
+	}
+
+	log.Debugf("Filtered %d out of %d movies", len(movies)-len(ret), len(movies))
+	return ret
+}
+
+// FilterShows removes fully watched, collected or hidden shows from a listing, according to settings
+func FilterShows(shows []*Shows) []*Shows {
+	if !isListFilterEnabled() {
+		return shows
+	}
+
+	filter := showsListFilter()
+	ret := make([]*Shows, 0, len(shows))
+	for _, s := range shows {
+		if s != nil && s.Show != nil && filter.has(&s.Show.Object) {
+			continue
+		}
+		ret = append(ret, s)
+	}
+
+	log.Debugf("Filtered %d out of %d shows", len(shows)-len(ret), len(shows))
+	return ret
+}
@@ -0,0 +1,199 @@
+package trakt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/jmcvetta/napping"
+)
+
+// List privacy values
+const (
+	ListPrivate = "private"
+	ListFriends = "friends"
+	ListPublic  = "public"
+)
+
+// ListPayload is used to create or update user list
+type ListPayload struct {
+	Name    string `json:"name,omitempty"`
+	Privacy string `json:"privacy,omitempty"`
+}
+
+// ListReorderPayload sets new order of list items
+type ListReorderPayload struct {
+	Rank []int `json:"rank"`
+}
+
+// CreateList creates new user list
+func CreateList(name string, privacy string) (list *List, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	endPoint := fmt.Sprintf("users/%s/lists", config.Get().TraktUsername)
+	resp, err := PostJSON(endPoint, ListPayload{Name: name, Privacy: privacy})
+	if err != nil {
+		return nil, err
+	} else if resp.Status() != 201 {
+		return nil, fmt.Errorf("Bad status creating list: %d", resp.Status())
+	}
+
+	list = &List{}
+	err = resp.Unmarshal(list)
+	return
+}
+
+// UpdateList changes name or privacy of user list, empty values are kept unchanged
+func UpdateList(listID int, name string, privacy string) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(ListPayload{Name: name, Privacy: privacy})
+	if err != nil {
+		return err
+	}
+
+	endPoint := fmt.Sprintf("users/%s/lists/%d", config.Get().TraktUsername, listID)
+	resp, err := Put(endPoint, bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	} else if resp.Status() != 200 {
+		return fmt.Errorf("Bad status updating list: %d", resp.Status())
+	}
+
+	return nil
+}
+
+// DeleteList removes user list with all its items
+func DeleteList(listID int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	endPoint := fmt.Sprintf("users/%s/lists/%d", config.Get().TraktUsername, listID)
+	resp, err := Delete(endPoint)
+	if err != nil {
+		return err
+	} else if resp.Status() != 204 {
+		return fmt.Errorf("Bad status deleting list: %d", resp.Status())
+	}
+
+	ClearListCache(listID)
+	return nil
+}
+
+// ListItems returns all items of user list, in list order
+func ListItems(listID int) (items []*ListItem, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	endPoint := fmt.Sprintf("users/%s/lists/%d/items", config.Get().TraktUsername, listID)
+	resp, err := GetWithAuth(endPoint, napping.Params{}.AsUrlValues())
+	if err != nil {
+		return nil, err
+	} else if resp.Status() != 200 {
+		return nil, fmt.Errorf("Bad status getting list items: %d", resp.Status())
+	}
+
+	err = resp.Unmarshal(&items)
+	return
+}
+
+// ReorderListItems sets new order of list items, rank contains list item IDs
+func ReorderListItems(listID int, rank []int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	endPoint := fmt.Sprintf("users/%s/lists/%d/items/reorder", config.Get().TraktUsername, listID)
+	resp, err := PostJSON(endPoint, ListReorderPayload{Rank: rank})
+	if err != nil {
+		return err
+	} else if resp.Status() != 200 {
+		return fmt.Errorf("Bad status reordering list: %d", resp.Status())
+	}
+
+	ClearListCache(listID)
+	return nil
+}
+
+// ClearListCache removes cached items of user list
+func ClearListCache(listID int) {
+	cacheStore := cache.NewDBStore()
+	cacheStore.Delete(fmt.Sprintf(cache.TraktMoviesListKey, strconv.Itoa(listID)))
+	cacheStore.Delete(fmt.Sprintf(cache.TraktShowsListKey, strconv.Itoa(listID)))
+}
+
+// SearchLists searches public lists by name and description
+func SearchLists(query string, page string) (lists []*ListContainer, hasNext bool, err error) {
+	pageInt, _ := strconv.Atoi(page)
+
+	endPoint := "search/list"
+	params := napping.Params{
+		"query":    query,
+		"page":     page,
+		"limit":    strconv.Itoa(config.Get().ResultsPerPage),
+		"extended": "full",
+	}.AsUrlValues()
+
+	var resp *napping.Response
+	if !config.Get().TraktAuthorized {
+		resp, err = Get(endPoint, params)
+	} else {
+		resp, err = GetWithAuth(endPoint, params)
+	}
+
+	if err != nil {
+		return
+	} else if resp.Status() != 200 {
+		return lists, hasNext, fmt.Errorf("Bad status searching lists: %d", resp.Status())
+	}
+
+	if err = resp.Unmarshal(&lists); err != nil {
+		return
+	}
+
+	p := getPagination(resp.HttpResponse().Header)
+	hasNext = p.PageCount > pageInt
+
+	return
+}
+
+// LikeList adds a like to a list of any user
+func LikeList(user string, listID int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	resp, err := Post(fmt.Sprintf("users/%s/lists/%d/like", user, listID), bytes.NewBuffer(nil))
+	if err != nil {
+		return err
+	} else if resp.Status() != 204 {
+		return fmt.Errorf("Bad status liking list: %d", resp.Status())
+	}
+
+	return nil
+}
+
+// UnlikeList removes a like from a list of any user
+func UnlikeList(user string, listID int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	resp, err := Delete(fmt.Sprintf("users/%s/lists/%d/like", user, listID))
+	if err != nil {
+		return err
+	} else if resp.Status() != 204 {
+		return fmt.Errorf("Bad status unliking list: %d", resp.Status())
+	}
+
+	return nil
+}
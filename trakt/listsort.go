@@ -0,0 +1,144 @@
+package trakt
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/jmcvetta/napping"
+)
+
+// List sort fields, supported for sorting list items
+const (
+	ListSortRank       = "rank"
+	ListSortAdded      = "added"
+	ListSortTitle      = "title"
+	ListSortReleased   = "released"
+	ListSortRuntime    = "runtime"
+	ListSortPercentage = "percentage"
+	ListSortVotes      = "votes"
+)
+
+// ListSortFields lists supported sort fields, in the order, shown to user
+var ListSortFields = []string{ListSortRank, ListSortAdded, ListSortTitle, ListSortReleased, ListSortRuntime, ListSortPercentage, ListSortVotes}
+
+// GetList returns list details, including its configured sort order
+func GetList(user string, listID string) (list *List, err error) {
+	endPoint := fmt.Sprintf("users/%s/lists/%s", user, listID)
+	params := napping.Params{}.AsUrlValues()
+
+	var resp *napping.Response
+	if config.Get().TraktAuthorized {
+		resp, err = GetWithAuth(endPoint, params)
+	} else {
+		resp, err = Get(endPoint, params)
+	}
+
+	if err != nil {
+		return nil, err
+	} else if resp.Status() != 200 {
+		return nil, fmt.Errorf("Bad status getting Trakt list: %d", resp.Status())
+	}
+
+	err = resp.Unmarshal(&list)
+	return
+}
+
+// listSortOrder returns sort order of the list, using local override,
+// then sort headers of items response, then list details.
+func listSortOrder(user string, listID string, header http.Header) (sortBy string, sortHow string) {
+	if id, err := strconv.Atoi(listID); err == nil {
+		if o := database.GetStorm().GetListSort(id); o != nil {
+			return o.SortBy, o.SortHow
+		}
+	}
+
+	if sortBy = header.Get("X-Sort-By"); sortBy != "" {
+		return sortBy, header.Get("X-Sort-How")
+	}
+
+	if list, err := GetList(user, listID); err == nil && list != nil {
+		return list.SortBy, list.SortHow
+	}
+	return ListSortRank, "asc"
+}
+
+// sortListItems sorts items by one of list sort fields, unknown fields keep list rank
+func sortListItems(items []*ListItem, sortBy string, sortHow string) {
+	var less func(a, b *ListItem) bool
+	switch sortBy {
+	case ListSortAdded:
+		less = func(a, b *ListItem) bool { return a.ListedAt < b.ListedAt }
+	case ListSortTitle:
+		less = func(a, b *ListItem) bool {
+			return strings.ToLower(listItemObject(a).Title) < strings.ToLower(listItemObject(b).Title)
+		}
+	case ListSortReleased:
+		less = func(a, b *ListItem) bool { return listItemReleased(a) < listItemReleased(b) }
+	case ListSortRuntime:
+		less = func(a, b *ListItem) bool { return listItemRuntime(a) < listItemRuntime(b) }
+	case ListSortPercentage:
+		less = func(a, b *ListItem) bool { return listItemRating(a) < listItemRating(b) }
+	case ListSortVotes:
+		less = func(a, b *ListItem) bool { return listItemVotes(a) < listItemVotes(b) }
+	default:
+		less = func(a, b *ListItem) bool { return a.Rank < b.Rank }
+	}
+
+	desc := sortHow == "desc"
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+}
+
+func listItemObject(i *ListItem) *Object {
+	if i.Movie != nil {
+		return &i.Movie.Object
+	} else if i.Show != nil {
+		return &i.Show.Object
+	}
+	return &Object{}
+}
+
+func listItemReleased(i *ListItem) string {
+	if i.Movie != nil {
+		return i.Movie.Released
+	} else if i.Show != nil {
+		return i.Show.FirstAired
+	}
+	return ""
+}
+
+func listItemRuntime(i *ListItem) int {
+	if i.Movie != nil {
+		return i.Movie.Runtime
+	} else if i.Show != nil {
+		return i.Show.Runtime
+	}
+	return 0
+}
+
+func listItemRating(i *ListItem) float32 {
+	if i.Movie != nil {
+		return i.Movie.Rating
+	} else if i.Show != nil {
+		return i.Show.Rating
+	}
+	return 0
+}
+
+func listItemVotes(i *ListItem) int {
+	if i.Movie != nil {
+		return i.Movie.Votes
+	} else if i.Show != nil {
+		return i.Show.Votes
+	}
+	return 0
+}
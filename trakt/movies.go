@@ -2,15 +2,15 @@ package trakt
 
 import (
 	"fmt"
-	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/metadata"
+	"github.com/elgatito/elementum/placeholder"
 	"github.com/elgatito/elementum/playcount"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/util"
@@ -20,6 +20,14 @@ import (
 
 // Fill fanart from TMDB
 func setFanart(movie *Movie) *Movie {
+	if movie.IDs == nil || movie.IDs.TMDB == 0 {
+		return setProjectionFanart(movie, nil)
+	}
+
+	return setProjectionFanart(movie, tmdb.GetMovieProjection(movie.IDs.TMDB, config.Get().Language))
+}
+
+func setProjectionFanart(movie *Movie, p *tmdb.Projection) *Movie {
 	if movie.Images == nil {
 		movie.Images = &Images{}
 	}
@@ -39,62 +47,59 @@ func setFanart(movie *Movie) *Movie {
 		movie.Images.ClearArt = &Sizes{}
 	}
 
-	if movie.IDs == nil || movie.IDs.TMDB == 0 {
+	if p == nil {
 		return movie
 	}
 
-	tmdbImages := tmdb.GetImages(movie.IDs.TMDB)
-	if tmdbImages == nil {
-		return movie
+	if poster := p.PosterURL(); poster != "" {
+		movie.Images.Poster.Full = poster
+		movie.Images.Thumbnail.Full = poster
+	}
+	if backdrop := p.BackdropURL(); backdrop != "" {
+		movie.Images.FanArt.Full = backdrop
+		movie.Images.Banner.Full = backdrop
 	}
+	return movie
+}
 
-	if len(tmdbImages.Posters) > 0 {
-		posterImage := tmdb.ImageURL(tmdbImages.Posters[0].FilePath, "w1280")
-		for _, image := range tmdbImages.Posters {
-			if image.Iso639_1 == config.Get().Language {
-				posterImage = tmdb.ImageURL(image.FilePath, "w1280")
-			}
+// setMoviesFanart fills fanart of all movies, projections are requested at once,
+// so each movie is requested from TMDB only once, with images appended to the same request
+func setMoviesFanart(movies []*Movie) {
+	ids := make([]int, 0, len(movies))
+	for _, movie := range movies {
+		if movie != nil && movie.IDs != nil {
+			ids = append(ids, movie.IDs.TMDB)
 		}
-		movie.Images.Poster.Full = posterImage
-		movie.Images.Thumbnail.Full = posterImage
-	}
-	if len(tmdbImages.Backdrops) > 0 {
-		backdropImage := tmdb.ImageURL(tmdbImages.Backdrops[0].FilePath, "w1280")
-		for _, image := range tmdbImages.Backdrops {
-			if image.Iso639_1 == config.Get().Language {
-				backdropImage = tmdb.ImageURL(image.FilePath, "w1280")
-			}
+	}
+	projections := tmdb.GetMovieProjections(ids, config.Get().Language)
+
+	for _, movie := range movies {
+		if movie == nil {
+			continue
+		} else if movie.IDs != nil {
+			setProjectionFanart(movie, projections[movie.IDs.TMDB])
+		} else {
+			setProjectionFanart(movie, nil)
 		}
-		movie.Images.FanArt.Full = backdropImage
-		movie.Images.Banner.Full = backdropImage
 	}
-	return movie
 }
 
 func setFanarts(movies []*Movies) []*Movies {
-	wg := sync.WaitGroup{}
-	for i, movie := range movies {
-		wg.Add(1)
-		go func(idx int, m *Movies) {
-			defer wg.Done()
-			movies[idx].Movie = setFanart(m.Movie)
-		}(i, movie)
+	list := make([]*Movie, 0, len(movies))
+	for _, m := range movies {
+		list = append(list, m.Movie)
 	}
-	wg.Wait()
+	setMoviesFanart(list)
 
 	return movies
 }
 
 func setCalendarFanarts(movies []*CalendarMovie) []*CalendarMovie {
-	wg := sync.WaitGroup{}
-	for i, movie := range movies {
-		wg.Add(1)
-		go func(idx int, m *CalendarMovie) {
-			defer wg.Done()
-			movies[idx].Movie = setFanart(m.Movie)
-		}(i, movie)
+	list := make([]*Movie, 0, len(movies))
+	for _, m := range movies {
+		list = append(list, m.Movie)
 	}
-	wg.Wait()
+	setMoviesFanart(list)
 
 	return movies
 }
@@ -187,6 +192,20 @@ func SearchMovies(query string, page string) (movies []*Movies, err error) {
 
 // TopMovies ...
 func TopMovies(topCategory string, page string) (movies []*Movies, total int, err error) {
+	return TopMoviesByGenre(topCategory, "", page)
+}
+
+// TopMoviesByGenre returns top category listing, filtered by Trakt genre slug
+func TopMoviesByGenre(topCategory string, genre string, page string) (movies []*Movies, total int, err error) {
+	return topMovies(topCategory, genre, "", page)
+}
+
+// TopMoviesByCountry returns top category listing, filtered by country code
+func TopMoviesByCountry(topCategory string, country string, page string) (movies []*Movies, total int, err error) {
+	return topMovies(topCategory, "", strings.ToLower(country), page)
+}
+
+func topMovies(topCategory string, genre string, country string, page string) (movies []*Movies, total int, err error) {
 	endPoint := "movies/" + topCategory
 	if topCategory == "recommendations" {
 		endPoint = topCategory + "/movies"
@@ -204,10 +223,23 @@ func TopMovies(topCategory string, page string) (movies []*Movies, total int, er
 		"limit":    strconv.Itoa(limit),
 		"extended": "full,images",
 	}.AsUrlValues()
+	if genre != "" {
+		params.Set("genres", genre)
+	}
+	if country != "" {
+		params.Set("countries", country)
+	}
 
 	cacheStore := cache.NewDBStore()
-	key := fmt.Sprintf(cache.TraktMoviesByCategoryKey, topCategory, page)
-	totalKey := fmt.Sprintf(cache.TraktMoviesByCategoryTotalKey, topCategory)
+	categoryKey := strings.Replace(topCategory, "/", ".", -1)
+	if genre != "" {
+		categoryKey += ".genre." + genre
+	}
+	if country != "" {
+		categoryKey += ".country." + country
+	}
+	key := fmt.Sprintf(cache.TraktMoviesByCategoryKey, categoryKey, page)
+	totalKey := fmt.Sprintf(cache.TraktMoviesByCategoryTotalKey, categoryKey)
 	if err := cacheStore.Get(key, &movies); err != nil || len(movies) == 0 {
 		var resp *napping.Response
 		var err error
@@ -259,6 +291,13 @@ func TopMovies(topCategory string, page string) (movies []*Movies, total int, er
 		}
 	}
 
+	if topCategory == "recommendations" {
+		movies = filterHiddenMovies(movies, HiddenRecommendations)
+	} else if topCategory == "anticipated" {
+		fillMoviesReleaseDates(movies)
+	}
+	movies = FilterMovies(movies)
+
 	return
 }
 
@@ -305,9 +344,19 @@ func WatchlistMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
 	movies = movieListing
 
 	cacheStore.Set(cache.TraktMoviesWatchlistKey, &movies, cache.TraktMoviesWatchlistExpire)
+	cacheStore.Delete(cache.TraktMoviesWatchlistPendingKey)
 	return
 }
 
+// PreviousWatchlistMovies returns cached watchlist, without movies added after it was fetched
+func PreviousWatchlistMovies() (movies []*Movies, err error) {
+	err = cache.
+		NewDBStore().
+		Get(cache.TraktMoviesWatchlistKey, &movies)
+
+	return withoutPendingMovies(movies, cache.TraktMoviesWatchlistPendingKey), err
+}
+
 // CollectionMovies ...
 func CollectionMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
 	if errAuth := Authorized(); errAuth != nil {
@@ -349,9 +398,19 @@ func CollectionMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
 	movies = movieListing
 
 	cacheStore.Set(cache.TraktMoviesCollectionKey, &movies, cache.TraktMoviesCollectionExpire)
+	cacheStore.Delete(cache.TraktMoviesCollectionPendingKey)
 	return movies, err
 }
 
+// PreviousCollectionMovies returns cached collection, without movies added after it was fetched
+func PreviousCollectionMovies() (movies []*Movies, err error) {
+	err = cache.
+		NewDBStore().
+		Get(cache.TraktMoviesCollectionKey, &movies)
+
+	return withoutPendingMovies(movies, cache.TraktMoviesCollectionPendingKey), err
+}
+
 // Userlists ...
 func Userlists() (lists []*List) {
 	traktUsername := config.Get().TraktUsername
@@ -495,7 +554,9 @@ func ListItemsMovies(user string, listID string, isUpdateNeeded bool) (movies []
 
 	endPoint := fmt.Sprintf("users/%s/lists/%s/items/movies", user, listID)
 
-	params := napping.Params{}.AsUrlValues()
+	params := napping.Params{
+		"extended": "full",
+	}.AsUrlValues()
 
 	var resp *napping.Response
 
@@ -523,6 +584,8 @@ func ListItemsMovies(user string, listID string, isUpdateNeeded bool) (movies []
 	if err = resp.Unmarshal(&list); err != nil {
 		log.Warning(err)
 	}
+	sortBy, sortHow := listSortOrder(user, listID, resp.HttpResponse().Header)
+	sortListItems(list, sortBy, sortHow)
 
 	movieListing := make([]*Movies, 0)
 	for _, movie := range list {
@@ -560,7 +623,7 @@ func CalendarMovies(endPoint string, page string) (movies []*CalendarMovie, tota
 	key := fmt.Sprintf(cache.TraktMoviesCalendarKey, endPointKey, page)
 	totalKey := fmt.Sprintf(cache.TraktMoviesCalendarTotalKey, endPointKey)
 	if err := cacheStore.Get(key, &movies); err != nil {
-		resp, err := GetWithAuth("calendars/"+endPoint, params)
+		resp, err := GetCalendar(endPoint, params)
 
 		if err != nil {
 			log.Error(err)
@@ -589,6 +652,8 @@ func CalendarMovies(endPoint string, page string) (movies []*CalendarMovie, tota
 		}
 	}
 
+	movies = filterHiddenCalendarMovies(movies)
+
 	return
 }
 
@@ -647,50 +712,79 @@ func PausedMovies(isUpdateNeeded bool) ([]*PausedMovie, error) {
 
 // ToListItem ...
 func (movie *Movie) ToListItem() (item *xbmc.ListItem) {
+	var tmdbSource *metadata.Source
 	if !config.Get().ForceUseTrakt && movie.IDs.TMDB != 0 {
-		tmdbID := strconv.Itoa(movie.IDs.TMDB)
-		if tmdbMovie := tmdb.GetMovieByID(tmdbID, config.Get().Language); tmdbMovie != nil {
-			item = tmdbMovie.ToListItem()
-		}
+		tmdbSource = metadata.TMDBSource
 	}
-	if item == nil {
-		movie = setFanart(movie)
-		item = &xbmc.ListItem{
-			Label: movie.Title,
-			Info: &xbmc.ListItemInfo{
-				Count:         rand.Int(),
-				Title:         movie.Title,
-				OriginalTitle: movie.Title,
-				Year:          movie.Year,
-				Genre:         strings.Title(strings.Join(movie.Genres, " / ")),
-				Plot:          movie.Overview,
-				PlotOutline:   movie.Overview,
-				TagLine:       movie.TagLine,
-				Rating:        movie.Rating,
-				Votes:         strconv.Itoa(movie.Votes),
-				Duration:      movie.Runtime * 60,
-				MPAA:          movie.Certification,
-				Code:          movie.IDs.IMDB,
-				IMDBNumber:    movie.IDs.IMDB,
-				Trailer:       util.TrailerURL(movie.Trailer),
-				PlayCount:     playcount.GetWatchedMovieByTMDB(movie.IDs.TMDB).Int(),
-				DBTYPE:        "movie",
-				Mediatype:     "movie",
-			},
-			Art: &xbmc.ListItemArt{
-				Poster:    movie.Images.Poster.Full,
-				FanArt:    movie.Images.FanArt.Full,
-				Banner:    movie.Images.Banner.Full,
-				Thumbnail: movie.Images.Thumbnail.Full,
-				ClearArt:  movie.Images.ClearArt.Full,
-			},
-			Thumbnail: movie.Images.Poster.Full,
-		}
+
+	ids := metadata.IDs{TMDB: movie.IDs.TMDB, TVDB: movie.IDs.TVDB, IMDB: movie.IDs.IMDB}
+	resolved := metadata.NewResolver(tmdbSource, movie.metadataSource(), metadata.TVDBSource, metadata.OMDbSource).Resolve(metadata.MovieType, ids)
+	if resolved != nil {
+		item = resolved.ToListItem()
+	} else {
+		item = movie.traktListItem()
 	}
 
 	if len(item.Info.Trailer) == 0 {
 		item.Info.Trailer = util.TrailerURL(movie.Trailer)
 	}
 
+	placeholder.Fill(item)
+	return
+}
+
+// metadataSource returns metadata source, which builds list item from Trakt data
+func (movie *Movie) metadataSource() *metadata.Source {
+	return &metadata.Source{
+		Name: "Trakt",
+		Resolve: func(mediaType string, ids metadata.IDs) *metadata.Item {
+			if movie.Title == "" {
+				return nil
+			}
+
+			return metadata.NewItem(movie.traktListItem())
+		},
+	}
+}
+
+// traktListItem builds list item from Trakt data only
+func (movie *Movie) traktListItem() (item *xbmc.ListItem) {
+	movie = setFanart(movie)
+	title := tmdb.DisplayTitle(movie.Title, movie.OriginalTitle)
+	originalTitle := movie.OriginalTitle
+	if originalTitle == "" {
+		originalTitle = movie.Title
+	}
+	item = &xbmc.ListItem{
+		Label: title,
+		Info: &xbmc.ListItemInfo{
+			Count:         xbmc.ItemCount(xbmc.CountMovie, movie.IDs.CountID(), 0),
+			Title:         title,
+			OriginalTitle: originalTitle,
+			Year:          movie.Year,
+			Genre:         strings.Title(strings.Join(movie.Genres, " / ")),
+			Plot:          movie.Overview,
+			PlotOutline:   movie.Overview,
+			TagLine:       movie.TagLine,
+			Rating:        movie.Rating,
+			Votes:         strconv.Itoa(movie.Votes),
+			Duration:      movie.Runtime * 60,
+			MPAA:          movie.Certification,
+			Code:          movie.IDs.IMDB,
+			IMDBNumber:    movie.IDs.IMDB,
+			Trailer:       util.TrailerURL(movie.Trailer),
+			PlayCount:     playcount.GetWatchedMovieByTMDB(movie.IDs.TMDB).Int(),
+			DBTYPE:        "movie",
+			Mediatype:     "movie",
+		},
+		Art: &xbmc.ListItemArt{
+			Poster:    movie.Images.Poster.Full,
+			FanArt:    movie.Images.FanArt.Full,
+			Banner:    movie.Images.Banner.Full,
+			Thumbnail: movie.Images.Thumbnail.Full,
+			ClearArt:  movie.Images.ClearArt.Full,
+		},
+		Thumbnail: movie.Images.Poster.Full,
+	}
 	return
 }
@@ -0,0 +1,172 @@
+package trakt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/jmcvetta/napping"
+)
+
+// Trakt responds with this status to VIP only methods, called by regular accounts
+const statusVIPRequired = 426
+
+// Maximum length of a note, accepted by Trakt
+const noteMaxLength = 500
+
+// ErrVIPRequired is returned when notes are used by an account without Trakt VIP
+var ErrVIPRequired = errors.New("Trakt notes require Trakt VIP")
+
+// Note is a user note, attached to a Trakt item
+type Note struct {
+	ID        int64     `json:"id"`
+	Notes     string    `json:"notes"`
+	Privacy   string    `json:"privacy"`
+	Spoiler   bool      `json:"spoiler"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NoteAttachment identifies what a note is attached to
+type NoteAttachment struct {
+	Type string `json:"type"`
+	ID   int64  `json:"id"`
+}
+
+// NoteItem is an entry of user's notes listing
+type NoteItem struct {
+	AttachedTo *NoteAttachment `json:"attached_to"`
+	Type       string          `json:"type"`
+	Note       *Note           `json:"note"`
+}
+
+// notesVIPRequired checks if Trakt has already refused notes for this account
+func notesVIPRequired() bool {
+	required := false
+	return cache.NewDBStore().Get(cache.TraktNotesVIPRequiredKey, &required) == nil && required
+}
+
+func setNotesVIPRequired() {
+	cache.NewDBStore().Set(cache.TraktNotesVIPRequiredKey, true, cache.TraktNotesVIPRequiredExpire)
+}
+
+// HistoryNotes returns notes, attached to history entries, by history ID
+func HistoryNotes() (notes map[int64]*Note, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	} else if notesVIPRequired() {
+		return nil, ErrVIPRequired
+	}
+
+	cacheStore := cache.NewDBStore()
+	if err := cacheStore.Get(cache.TraktHistoryNotesKey, &notes); err == nil {
+		return notes, nil
+	}
+
+	notes = map[int64]*Note{}
+	for page, pageCount := 1, 1; page <= pageCount; page++ {
+		params := napping.Params{
+			"page":  strconv.Itoa(page),
+			"limit": "100",
+		}.AsUrlValues()
+
+		resp, err := GetWithAuth("users/me/notes/all", params)
+		if err != nil {
+			return nil, err
+		} else if resp.Status() == statusVIPRequired {
+			setNotesVIPRequired()
+			return nil, ErrVIPRequired
+		} else if resp.Status() != 200 {
+			return nil, fmt.Errorf("Bad status getting Trakt notes: %d", resp.Status())
+		}
+
+		var items []*NoteItem
+		if err := resp.Unmarshal(&items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item == nil || item.Note == nil || item.AttachedTo == nil || item.AttachedTo.Type != "history" {
+				continue
+			}
+			notes[item.AttachedTo.ID] = item.Note
+		}
+
+		pageCount = getPagination(resp.HttpResponse().Header).PageCount
+	}
+
+	cacheStore.Set(cache.TraktHistoryNotesKey, notes, cache.TraktHistoryNotesExpire)
+	return notes, nil
+}
+
+// SetHistoryNote attaches a private note to a history entry, or updates the note, that is already attached
+func SetHistoryNote(historyID int64, text string) error {
+	if err := Authorized(); err != nil {
+		return err
+	} else if notesVIPRequired() {
+		return ErrVIPRequired
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("Note is empty")
+	} else if len([]rune(text)) > noteMaxLength {
+		text = string([]rune(text)[:noteMaxLength])
+	}
+
+	notes, err := HistoryNotes()
+	if err != nil {
+		return err
+	}
+
+	var resp *napping.Response
+	if note, ok := notes[historyID]; ok && note != nil {
+		b, _ := json.Marshal(map[string]interface{}{
+			"notes": text,
+		})
+		resp, err = Put(fmt.Sprintf("notes/%d", note.ID), bytes.NewBuffer(b))
+	} else {
+		resp, err = PostJSON("notes", map[string]interface{}{
+			"attached_to": NoteAttachment{Type: "history", ID: historyID},
+			"notes":       text,
+			"privacy":     "private",
+			"spoiler":     false,
+		})
+	}
+	if err != nil {
+		return err
+	} else if resp.Status() == statusVIPRequired {
+		setNotesVIPRequired()
+		return ErrVIPRequired
+	} else if resp.Status() != 200 && resp.Status() != 201 {
+		return fmt.Errorf("Bad status saving Trakt note: %d", resp.Status())
+	}
+
+	cache.NewDBStore().Delete(cache.TraktHistoryNotesKey)
+	return nil
+}
+
+// LastHistoryID returns ID of the latest history entry for a movie or an episode, identified by TMDB ids
+func LastHistoryID(item *WatchedItem) (int64, error) {
+	history, _, err := History("1")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, h := range history {
+		if h == nil {
+			continue
+		}
+		if item.Movie != 0 && h.Movie != nil && h.Movie.IDs != nil && h.Movie.IDs.TMDB == item.Movie {
+			return h.ID, nil
+		} else if item.Movie == 0 && h.Episode != nil && h.Show != nil && h.Show.IDs != nil &&
+			h.Show.IDs.TMDB == item.Show && h.Episode.Season == item.Season && h.Episode.Number == item.Episode {
+			return h.ID, nil
+		}
+	}
+	return 0, errors.New("Watched entry not found in Trakt history")
+}
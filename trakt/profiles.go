@@ -0,0 +1,99 @@
+package trakt
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var (
+	profileMu     sync.Mutex
+	activeProfile string
+)
+
+// SwitchProfile stores Trakt authorization of the active Kodi profile and restores authorization,
+// stored for the new one. Empty profile means current Kodi profile.
+// Cache keys follow Trakt username, so lists of the new account are used right away.
+func SwitchProfile(profile string) error {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+
+	if profile == "" {
+		profile = xbmc.GetCurrentProfile()
+	}
+	if activeProfile == "" {
+		// On startup authorization is restored, if settings have lost it
+		activeProfile = profile
+		if stored := database.GetStorm().GetTraktProfile(profile); config.Get().TraktToken == "" && stored != nil && stored.Token != "" {
+			applyProfile(stored)
+			return nil
+		}
+	}
+	if profile == activeProfile {
+		return saveProfile(profile)
+	}
+
+	if err := saveProfile(activeProfile); err != nil {
+		log.Warningf("Could not store Trakt authorization of profile '%s': %s", activeProfile, err)
+	}
+
+	stored := database.GetStorm().GetTraktProfile(profile)
+	if stored == nil {
+		stored = &database.TraktProfile{Profile: profile}
+	}
+
+	log.Noticef("Switching Trakt profile from '%s' to '%s', user: '%s'", activeProfile, profile, stored.Username)
+	activeProfile = profile
+	applyProfile(stored)
+
+	return nil
+}
+
+// ActiveProfile returns Kodi profile, Trakt authorization is currently used for
+func ActiveProfile() string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+
+	return activeProfile
+}
+
+// applyProfile sets stored Trakt authorization to current configuration and to settings
+func applyProfile(stored *database.TraktProfile) {
+	config.SetTraktAccount(stored.Username, stored.Token, stored.RefreshToken, stored.TokenExpiry)
+
+	expiry := ""
+	if stored.TokenExpiry != 0 {
+		expiry = strconv.Itoa(stored.TokenExpiry)
+	}
+	xbmc.SetSetting("trakt_token_expiry", expiry)
+	xbmc.SetSetting("trakt_token", stored.Token)
+	xbmc.SetSetting("trakt_refresh_token", stored.RefreshToken)
+	xbmc.SetSetting("trakt_username", stored.Username)
+}
+
+// updateProfileToken sets new Trakt token to current configuration and stores it for the active profile,
+// so that switching back to the profile does not restore an outdated token
+func updateProfileToken(username string, token *Token, expiry int64) {
+	config.SetTraktAccount(username, token.AccessToken, token.RefreshToken, int(expiry))
+
+	if profile := ActiveProfile(); profile != "" {
+		if err := saveProfile(profile); err != nil {
+			log.Warningf("Could not store Trakt authorization of profile '%s': %s", profile, err)
+		}
+	}
+}
+
+// saveProfile stores current Trakt authorization for a Kodi profile
+func saveProfile(profile string) error {
+	conf := config.Get()
+	return database.GetStorm().SaveTraktProfile(&database.TraktProfile{
+		Profile:      profile,
+		Username:     conf.TraktUsername,
+		Token:        conf.TraktToken,
+		RefreshToken: conf.TraktRefreshToken,
+		TokenExpiry:  conf.TraktTokenExpiry,
+	})
+}
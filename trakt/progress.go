@@ -0,0 +1,203 @@
+package trakt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/jmcvetta/napping"
+)
+
+// Number of parallel requests, made for progress of watched shows
+const progressWorkers = 4
+
+// Trakt genres of daily shows, which are not watched episode by episode
+var newsTalkGenres = []string{"news", "talk-show"}
+
+// progressCall is a progress request in flight, shared by concurrent callers for the same show
+type progressCall struct {
+	wg       sync.WaitGroup
+	progress *WatchedProgressShow
+}
+
+var (
+	progressCallsMu sync.Mutex
+	progressCalls   = map[int]*progressCall{}
+)
+
+// showsProgress returns watched progress of shows. Stored progress is used for shows without activity
+// since it was fetched, other shows are requested with a bounded number of workers.
+func showsProgress(shows []*WatchedShow) []*WatchedProgressShow {
+	ret := make([]*WatchedProgressShow, len(shows))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < progressWorkers && w < len(shows); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				ret[idx] = fetchShowProgress(shows[idx])
+			}
+		}()
+	}
+
+	changed := 0
+	for i, show := range shows {
+		if show == nil || show.Show == nil || show.Show.IDs == nil {
+			continue
+		}
+
+		item := database.GetStorm().GetShowProgress(show.Show.IDs.Trakt)
+		if isShowProgressFresh(item, show) {
+			if ret[i] = decodeShowProgress(item); ret[i] != nil {
+				continue
+			}
+		}
+
+		changed++
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	log.Debugf("Fetched progress of %d out of %d watched shows", changed, len(shows))
+	return ret
+}
+
+// isShowProgressFresh checks that show had no watch activity since progress was fetched,
+// and that progress is not too old to miss newly aired episodes
+func isShowProgressFresh(item *database.ShowProgress, show *WatchedShow) bool {
+	return item != nil &&
+		item.LastWatchedAt.Equal(show.LastWatchedAt) &&
+		item.LastUpdatedAt.Equal(show.LastUpdatedAt) &&
+		time.Since(item.Fetched) < cache.TraktWatchedShowsProgressExpire
+}
+
+func decodeShowProgress(item *database.ShowProgress) (progress *WatchedProgressShow) {
+	if item == nil {
+		return nil
+	}
+	if err := json.Unmarshal(item.Progress, &progress); err != nil {
+		log.Warningf("Can't decode stored progress of show '%d': %s", item.TraktID, err)
+		return nil
+	}
+	return
+}
+
+// fetchShowProgress requests progress of a show, joining a request already in flight for the same show
+func fetchShowProgress(show *WatchedShow) *WatchedProgressShow {
+	traktID := show.Show.IDs.Trakt
+
+	progressCallsMu.Lock()
+	if c, ok := progressCalls[traktID]; ok {
+		progressCallsMu.Unlock()
+		c.wg.Wait()
+		return c.progress
+	}
+	c := &progressCall{}
+	c.wg.Add(1)
+	progressCalls[traktID] = c
+	progressCallsMu.Unlock()
+
+	c.progress = requestShowProgress(show)
+	c.wg.Done()
+
+	progressCallsMu.Lock()
+	delete(progressCalls, traktID)
+	progressCallsMu.Unlock()
+
+	return c.progress
+}
+
+// requestShowProgress gets progress of a show from Trakt and stores it,
+// falling back to previously stored progress if request fails
+func requestShowProgress(show *WatchedShow) (progress *WatchedProgressShow) {
+	traktID := show.Show.IDs.Trakt
+
+	params := napping.Params{
+		"hidden":         "false",
+		"specials":       "false",
+		"count_specials": "false",
+	}.AsUrlValues()
+
+	endPoint := fmt.Sprintf("shows/%d/progress/watched", traktID)
+	resp, err := GetWithAuth(endPoint, params)
+	if err != nil {
+		log.Errorf("Error getting endpoint %s for show '%d': %#v", endPoint, traktID, err)
+		return decodeShowProgress(database.GetStorm().GetShowProgress(traktID))
+	} else if resp.Status() != 200 {
+		log.Errorf("Got %d response status getting endpoint %s for show '%d'", resp.Status(), endPoint, traktID)
+		return decodeShowProgress(database.GetStorm().GetShowProgress(traktID))
+	}
+	if err := resp.Unmarshal(&progress); err != nil {
+		log.Warningf("Can't unmarshal response: %#v", err)
+		return nil
+	}
+
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return
+	}
+	database.GetStorm().SaveShowProgress(&database.ShowProgress{
+		TraktID:       traktID,
+		LastWatchedAt: show.LastWatchedAt,
+		LastUpdatedAt: show.LastUpdatedAt,
+		Fetched:       time.Now(),
+		Progress:      b,
+	})
+	return
+}
+
+// progressExcludedGenres returns Trakt genre slugs of shows, that are not shown in continue watching
+func progressExcludedGenres() map[string]bool {
+	ret := map[string]bool{}
+	if config.Get().TraktProgressExcludeNewsTalk {
+		for _, genre := range newsTalkGenres {
+			ret[genre] = true
+		}
+	}
+	for _, genre := range strings.Split(config.Get().TraktProgressExcludeGenres, ",") {
+		if genre = strings.ToLower(strings.TrimSpace(genre)); genre != "" {
+			ret[strings.Replace(genre, " ", "-", -1)] = true
+		}
+	}
+	return ret
+}
+
+// isProgressExcluded checks if show has any of excluded genres
+func isProgressExcluded(show *Show, excluded map[string]bool) bool {
+	if len(excluded) == 0 || show == nil {
+		return false
+	}
+
+	for _, genre := range show.Genres {
+		if excluded[genre] {
+			return true
+		}
+	}
+	return false
+}
+
+// isProgressDismissed checks if show was dismissed from continue watching on the same next episode.
+// Dismissal is removed, once next episode changes, so show comes back with new progress.
+func isProgressDismissed(dismissed map[int]*database.ProgressDismissedItem, s *ProgressShow) bool {
+	if s.Show.IDs == nil || s.Episode == nil {
+		return false
+	}
+
+	item, ok := dismissed[s.Show.IDs.TMDB]
+	if !ok {
+		return false
+	} else if item.Season == s.Episode.Season && item.Episode == s.Episode.Number {
+		return true
+	}
+
+	database.GetStorm().UndismissProgress(item.ShowID)
+	return false
+}
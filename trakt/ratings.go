@@ -0,0 +1,97 @@
+package trakt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/jmcvetta/napping"
+)
+
+// RatedItem is an entry of sync/ratings listing
+type RatedItem struct {
+	RatedAt time.Time `json:"rated_at"`
+	Rating  int       `json:"rating"`
+	Type    string    `json:"type"`
+	Movie   *Movie    `json:"movie"`
+	Show    *Show     `json:"show"`
+	Episode *Episode  `json:"episode"`
+}
+
+// RatedMovies returns movies, rated by user, with higher rated first
+func RatedMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
+	var rated []*RatedItem
+	if err = ratings("movies", isUpdateNeeded, cache.TraktMoviesRatingsKey, cache.TraktMoviesRatingsExpire, &rated); err != nil {
+		return
+	}
+
+	for _, r := range rated {
+		if r.Movie != nil {
+			movies = append(movies, &Movies{Movie: r.Movie})
+		}
+	}
+	return
+}
+
+// RatedShows returns shows, rated by user, with higher rated first
+func RatedShows(isUpdateNeeded bool) (shows []*Shows, err error) {
+	var rated []*RatedItem
+	if err = ratings("shows", isUpdateNeeded, cache.TraktShowsRatingsKey, cache.TraktShowsRatingsExpire, &rated); err != nil {
+		return
+	}
+
+	for _, r := range rated {
+		if r.Show != nil {
+			shows = append(shows, &Shows{Show: r.Show})
+		}
+	}
+	return
+}
+
+// RateItem sets user rating, from 1 to 10, of movie, show or episode, identified by TMDB id.
+// Zero rating removes existing rating.
+func RateItem(itemType string, tmdbID int, rating int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	endPoint := "sync/ratings"
+	payload := fmt.Sprintf(`{"%s": [{"rating": %d, "ids": {"tmdb": %d}}]}`, itemType, rating, tmdbID)
+	if rating == 0 {
+		endPoint = "sync/ratings/remove"
+		payload = fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %d}}]}`, itemType, tmdbID)
+	} else if rating < 1 || rating > 10 {
+		return fmt.Errorf("Rating should be from 1 to 10, got %d", rating)
+	}
+
+	resp, err := Post(endPoint, bytes.NewBufferString(payload))
+	if err != nil {
+		return err
+	} else if resp.Status() != 200 && resp.Status() != 201 {
+		return fmt.Errorf("Bad status rating Trakt %s: %d", itemType, resp.Status())
+	}
+
+	cacheStore := cache.NewDBStore()
+	cacheStore.Delete(cache.TraktMoviesRatingsKey)
+	cacheStore.Delete(cache.TraktShowsRatingsKey)
+	return nil
+}
+
+func ratings(itemType string, isUpdateNeeded bool, cacheKey string, cacheExpire time.Duration, rated *[]*RatedItem) error {
+	err := Request(
+		"sync/ratings/"+itemType,
+		napping.Params{"extended": "full,images"},
+		true,
+		isUpdateNeeded,
+		cacheKey,
+		cacheExpire,
+		rated,
+	)
+
+	sort.SliceStable(*rated, func(i, j int) bool {
+		return (*rated)[i].Rating > (*rated)[j].Rating
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package trakt
+
+import (
+	"fmt"
+
+	"github.com/elgatito/elementum/database"
+)
+
+// MergeDuplicateShows merges entries of the same show, listed twice with differing IDs,
+// e.g. Trakt entry without TMDB ID and TMDB-resolved one.
+// Resolved TMDB IDs are recorded for future lookups of Trakt entries.
+func MergeDuplicateShows(shows []*Shows) []*Shows {
+	ret := make([]*Shows, 0, len(shows))
+	seen := map[string]*Show{}
+
+	for _, s := range shows {
+		if s == nil || s.Show == nil || s.Show.IDs == nil {
+			ret = append(ret, s)
+			continue
+		}
+
+		ids := s.Show.IDs
+		if ids.TMDB == 0 && ids.Trakt != 0 {
+			ids.TMDB = database.GetStorm().GetShowTMDBMapping(ids.Trakt)
+		}
+
+		var existing *Show
+		for _, key := range showIDKeys(ids) {
+			if show, ok := seen[key]; ok {
+				existing = show
+				break
+			}
+		}
+
+		if existing == nil {
+			for _, key := range showIDKeys(ids) {
+				seen[key] = s.Show
+			}
+			ret = append(ret, s)
+			continue
+		}
+
+		log.Debugf("Merging duplicate show entries of %s: %#v and %#v", existing.Title, existing.IDs, ids)
+		mergeShowIDs(existing.IDs, ids)
+		for _, key := range showIDKeys(existing.IDs) {
+			seen[key] = existing
+		}
+
+		if existing.IDs.Trakt != 0 && existing.IDs.TMDB != 0 {
+			database.GetStorm().SetShowTMDBMapping(existing.IDs.Trakt, existing.IDs.TMDB)
+		}
+	}
+
+	return ret
+}
+
+// showIDKeys returns keys of all known IDs of a show
+func showIDKeys(ids *IDs) []string {
+	ret := []string{}
+	if ids.Trakt != 0 {
+		ret = append(ret, fmt.Sprintf("trakt.%d", ids.Trakt))
+	}
+	if ids.TMDB != 0 {
+		ret = append(ret, fmt.Sprintf("tmdb.%d", ids.TMDB))
+	}
+	if ids.TVDB != 0 {
+		ret = append(ret, fmt.Sprintf("tvdb.%d", ids.TVDB))
+	}
+	if ids.IMDB != "" {
+		ret = append(ret, "imdb."+ids.IMDB)
+	}
+	return ret
+}
+
+// mergeShowIDs fills missing IDs with IDs of a duplicate entry
+func mergeShowIDs(to *IDs, from *IDs) {
+	if to.Trakt == 0 {
+		to.Trakt = from.Trakt
+	}
+	if to.TMDB == 0 {
+		to.TMDB = from.TMDB
+	}
+	if to.TVDB == 0 {
+		to.TVDB = from.TVDB
+	}
+	if to.TVRage == 0 {
+		to.TVRage = from.TVRage
+	}
+	if to.IMDB == "" {
+		to.IMDB = from.IMDB
+	}
+	if to.Slug == "" {
+		to.Slug = from.Slug
+	}
+}
@@ -0,0 +1,133 @@
+package trakt
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/util"
+)
+
+const (
+	// Playback progress, starting from which stopped item is sent as watched
+	scrobbleWatchedProgress = 80
+	// Queued events are retried with exponential backoff, up to this delay
+	scrobbleMaxBackoff = 6 * time.Hour
+	// Queued events, older than this, are dropped
+	scrobbleMaxAge = 14 * 24 * time.Hour
+	// How often queue is checked for due events
+	scrobbleFlushInterval = 5 * time.Minute
+)
+
+var scrobbleFlushMu sync.Mutex
+
+// isRetryable reports whether failed request should be retried later,
+// which is the case for network errors, rate limiting and server errors.
+func isRetryable(status int, err error) bool {
+	return err != nil || status == 429 || status >= 500
+}
+
+// queueScrobble saves playback event, that could not be sent to Trakt, for later retry.
+// Only pause and stop events are queued, since start is superseded by them anyway.
+func queueScrobble(action string, contentType string, tmdbID int, progress float64) {
+	if action == "start" {
+		return
+	}
+
+	item := &database.ScrobbleItem{
+		Action:      action,
+		ContentType: contentType,
+		TMDBID:      tmdbID,
+		Progress:    progress,
+		Created:     time.Now(),
+		NextAttempt: time.Now().Add(scrobbleBackoff(0)),
+	}
+	if err := database.GetStorm().AddScrobbleItem(item); err != nil {
+		log.Warningf("Could not queue scrobble of %s #%d: %s", contentType, tmdbID, err)
+		return
+	}
+	log.Noticef("Queued %s of %s #%d at %f%% for retry", action, contentType, tmdbID, progress)
+}
+
+// scrobbleBackoff returns delay before next attempt of queued event
+func scrobbleBackoff(attempts int) time.Duration {
+	if attempts > 10 {
+		return scrobbleMaxBackoff
+	}
+	if delay := time.Duration(1<<uint(attempts)) * time.Minute; delay < scrobbleMaxBackoff {
+		return delay
+	}
+	return scrobbleMaxBackoff
+}
+
+// FlushScrobbleQueue sends queued playback events, which are due for retry.
+// Stopped items with enough progress are added to history with the time they were watched,
+// others are sent as paused, so that Trakt keeps the playback progress.
+func FlushScrobbleQueue() {
+	if err := Authorized(); err != nil {
+		return
+	}
+
+	scrobbleFlushMu.Lock()
+	defer scrobbleFlushMu.Unlock()
+
+	now := time.Now()
+	for _, item := range database.GetStorm().GetScrobbleItems() {
+		item := item
+		if now.Sub(item.Created) > scrobbleMaxAge {
+			log.Warningf("Dropping queued %s of %s #%d, it is too old", item.Action, item.ContentType, item.TMDBID)
+			database.GetStorm().DeleteScrobbleItem(&item)
+			continue
+		} else if now.Before(item.NextAttempt) {
+			continue
+		}
+
+		status, err := sendQueuedScrobble(&item)
+		if err == nil && status >= 200 && status < 300 {
+			log.Noticef("Sent queued %s of %s #%d", item.Action, item.ContentType, item.TMDBID)
+			database.GetStorm().DeleteScrobbleItem(&item)
+			continue
+		} else if !isRetryable(status, err) {
+			log.Warningf("Dropping queued %s of %s #%d, rejected with status %d", item.Action, item.ContentType, item.TMDBID, status)
+			database.GetStorm().DeleteScrobbleItem(&item)
+			continue
+		}
+
+		item.Attempts++
+		item.NextAttempt = now.Add(scrobbleBackoff(item.Attempts))
+		database.GetStorm().UpdateScrobbleItem(&item)
+
+		// Trakt is still unavailable, no reason to try other items now
+		break
+	}
+}
+
+// ScrobbleQueueHandler periodically flushes queued playback events
+func ScrobbleQueueHandler() {
+	FlushScrobbleQueue()
+
+	ticker := time.NewTicker(scrobbleFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		FlushScrobbleQueue()
+	}
+}
+
+func sendQueuedScrobble(item *database.ScrobbleItem) (int, error) {
+	var endPoint, payload string
+	if item.Action == "stop" && item.Progress >= scrobbleWatchedProgress {
+		endPoint = "sync/history"
+		payload = fmt.Sprintf(`{"%ss": [{"ids": {"tmdb": %d}, "watched_at": "%s"}]}`,
+			item.ContentType, item.TMDBID, item.Created.UTC().Format(time.RFC3339))
+	} else {
+		endPoint = "scrobble/pause"
+		payload = fmt.Sprintf(`{"%s": {"ids": {"tmdb": %d}}, "progress": %f, "app_version": "%s"}`,
+			item.ContentType, item.TMDBID, item.Progress, util.GetVersion())
+	}
+
+	resp, err := Post(endPoint, bytes.NewBufferString(payload))
+	return util.ResponseStatus(resp), err
+}
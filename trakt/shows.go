@@ -2,15 +2,16 @@ package trakt
 
 import (
 	"fmt"
-	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
 	"github.com/elgatito/elementum/fanart"
+	"github.com/elgatito/elementum/metadata"
+	"github.com/elgatito/elementum/placeholder"
 	"github.com/elgatito/elementum/playcount"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/util"
@@ -20,6 +21,14 @@ import (
 
 // Fill fanart from TMDB
 func setShowFanart(show *Show) *Show {
+	if show.IDs == nil || show.IDs.TMDB == 0 {
+		return setShowProjectionFanart(show, nil)
+	}
+
+	return setShowProjectionFanart(show, tmdb.GetShowProjection(show.IDs.TMDB, config.Get().Language))
+}
+
+func setShowProjectionFanart(show *Show, p *tmdb.Projection) *Show {
 	if show.Images == nil {
 		show.Images = &Images{}
 	}
@@ -39,77 +48,71 @@ func setShowFanart(show *Show) *Show {
 		show.Images.ClearArt = &Sizes{}
 	}
 
-	if show.IDs == nil || show.IDs.TMDB == 0 {
+	if p == nil {
 		return show
 	}
 
-	tmdbImages := tmdb.GetShowImages(show.IDs.TMDB)
-	if tmdbImages == nil {
-		return show
+	if poster := p.PosterURL(); poster != "" {
+		show.Images.Poster.Full = poster
+		show.Images.Thumbnail.Full = poster
 	}
+	if backdrop := p.BackdropURL(); backdrop != "" {
+		show.Images.FanArt.Full = backdrop
+		show.Images.Banner.Full = backdrop
+	}
+	return show
+}
 
-	if len(tmdbImages.Posters) > 0 {
-		posterImage := tmdb.ImageURL(tmdbImages.Posters[0].FilePath, "w1280")
-		for _, image := range tmdbImages.Posters {
-			if image.Iso639_1 == config.Get().Language {
-				posterImage = tmdb.ImageURL(image.FilePath, "w1280")
-			}
+// setShowsProjectionFanart fills fanart of all shows, projections are requested at once,
+// so each show is requested from TMDB only once, with images appended to the same request
+func setShowsProjectionFanart(shows []*Show) {
+	ids := make([]int, 0, len(shows))
+	for _, show := range shows {
+		if show != nil && show.IDs != nil {
+			ids = append(ids, show.IDs.TMDB)
 		}
-		show.Images.Poster.Full = posterImage
-		show.Images.Thumbnail.Full = posterImage
 	}
-	if len(tmdbImages.Backdrops) > 0 {
-		backdropImage := tmdb.ImageURL(tmdbImages.Backdrops[0].FilePath, "w1280")
-		for _, image := range tmdbImages.Backdrops {
-			if image.Iso639_1 == config.Get().Language {
-				backdropImage = tmdb.ImageURL(image.FilePath, "w1280")
-			}
+	projections := tmdb.GetShowProjections(ids, config.Get().Language)
+
+	for _, show := range shows {
+		if show == nil {
+			continue
+		} else if show.IDs != nil {
+			setShowProjectionFanart(show, projections[show.IDs.TMDB])
+		} else {
+			setShowProjectionFanart(show, nil)
 		}
-		show.Images.FanArt.Full = backdropImage
-		show.Images.Banner.Full = backdropImage
 	}
-	return show
 }
 
 func setShowsFanart(shows []*Shows) []*Shows {
-	wg := sync.WaitGroup{}
-	for i, show := range shows {
-		wg.Add(1)
-		go func(idx int, s *Shows) {
-			defer wg.Done()
-			shows[idx].Show = setShowFanart(s.Show)
-		}(i, show)
+	list := make([]*Show, 0, len(shows))
+	for _, s := range shows {
+		list = append(list, s.Show)
 	}
-	wg.Wait()
+	setShowsProjectionFanart(list)
 
 	return shows
 }
 
 func setProgressShowsFanart(shows []*ProgressShow) []*ProgressShow {
-	wg := sync.WaitGroup{}
-	wg.Add(len(shows))
-	for i, show := range shows {
-		go func(idx int, s *ProgressShow) {
-			defer wg.Done()
-			if s != nil && s.Show != nil {
-				shows[idx].Show = setShowFanart(s.Show)
-			}
-		}(i, show)
+	list := make([]*Show, 0, len(shows))
+	for _, s := range shows {
+		if s != nil {
+			list = append(list, s.Show)
+		}
 	}
-	wg.Wait()
+	setShowsProjectionFanart(list)
+
 	return shows
 }
 
 func setCalendarShowsFanart(shows []*CalendarShow) []*CalendarShow {
-	wg := sync.WaitGroup{}
-	for i, show := range shows {
-		wg.Add(1)
-		go func(idx int, s *CalendarShow) {
-			defer wg.Done()
-			shows[idx].Show = setShowFanart(s.Show)
-		}(i, show)
+	list := make([]*Show, 0, len(shows))
+	for _, s := range shows {
+		list = append(list, s.Show)
 	}
-	wg.Wait()
+	setShowsProjectionFanart(list)
 
 	return shows
 }
@@ -338,6 +341,20 @@ func SearchShows(query string, page string) (shows []*Shows, err error) {
 
 // TopShows ...
 func TopShows(topCategory string, page string) (shows []*Shows, total int, err error) {
+	return TopShowsByGenre(topCategory, "", page)
+}
+
+// TopShowsByGenre returns top category listing, filtered by Trakt genre slug
+func TopShowsByGenre(topCategory string, genre string, page string) (shows []*Shows, total int, err error) {
+	return topShows(topCategory, genre, "", page)
+}
+
+// TopShowsByCountry returns top category listing, filtered by country code
+func TopShowsByCountry(topCategory string, country string, page string) (shows []*Shows, total int, err error) {
+	return topShows(topCategory, "", strings.ToLower(country), page)
+}
+
+func topShows(topCategory string, genre string, country string, page string) (shows []*Shows, total int, err error) {
 	endPoint := "shows/" + topCategory
 	if topCategory == "recommendations" {
 		endPoint = topCategory + "/shows"
@@ -355,10 +372,23 @@ func TopShows(topCategory string, page string) (shows []*Shows, total int, err e
 		"limit":    strconv.Itoa(limit),
 		"extended": "full,images",
 	}.AsUrlValues()
+	if genre != "" {
+		params.Set("genres", genre)
+	}
+	if country != "" {
+		params.Set("countries", country)
+	}
 
 	cacheStore := cache.NewDBStore()
-	key := fmt.Sprintf(cache.TraktShowsByCategoryKey, topCategory, page)
-	totalKey := fmt.Sprintf(cache.TraktShowsByCategoryTotalKey, topCategory)
+	categoryKey := strings.Replace(topCategory, "/", ".", -1)
+	if genre != "" {
+		categoryKey += ".genre." + genre
+	}
+	if country != "" {
+		categoryKey += ".country." + country
+	}
+	key := fmt.Sprintf(cache.TraktShowsByCategoryKey, categoryKey, page)
+	totalKey := fmt.Sprintf(cache.TraktShowsByCategoryTotalKey, categoryKey)
 	if err := cacheStore.Get(key, &shows); err != nil || len(shows) == 0 {
 		var resp *napping.Response
 		var err error
@@ -410,6 +440,13 @@ func TopShows(topCategory string, page string) (shows []*Shows, total int, err e
 		}
 	}
 
+	if topCategory == "recommendations" {
+		shows = filterHiddenShows(shows, HiddenRecommendations)
+	} else if topCategory == "anticipated" {
+		fillShowsReleaseDates(shows)
+	}
+	shows = FilterShows(shows)
+
 	return
 }
 
@@ -457,16 +494,17 @@ func WatchlistShows(isUpdateNeeded bool) (shows []*Shows, err error) {
 	shows = showListing
 
 	cacheStore.Set(cache.TraktShowsWatchlistKey, &shows, cache.TraktShowsWatchlistExpire)
+	cacheStore.Delete(cache.TraktShowsWatchlistPendingKey)
 	return
 }
 
-// PreviousWatchlistShows ...
+// PreviousWatchlistShows returns cached watchlist, without shows added after it was fetched
 func PreviousWatchlistShows() (shows []*Shows, err error) {
 	err = cache.
 		NewDBStore().
 		Get(cache.TraktShowsWatchlistKey, &shows)
 
-	return shows, err
+	return withoutPendingShows(shows, cache.TraktShowsWatchlistPendingKey), err
 }
 
 // CollectionShows ...
@@ -511,16 +549,17 @@ func CollectionShows(isUpdateNeeded bool) (shows []*Shows, err error) {
 	}
 
 	cacheStore.Set(cache.TraktShowsCollectionKey, &showListing, cache.TraktShowsCollectionExpire)
+	cacheStore.Delete(cache.TraktShowsCollectionPendingKey)
 	return showListing, err
 }
 
-// PreviousCollectionShows ...
+// PreviousCollectionShows returns cached collection, without shows added after it was fetched
 func PreviousCollectionShows() (shows []*Shows, err error) {
 	err = cache.
 		NewDBStore().
 		Get(cache.TraktShowsCollectionKey, &shows)
 
-	return shows, err
+	return withoutPendingShows(shows, cache.TraktShowsCollectionPendingKey), err
 }
 
 // ListItemsShows ...
@@ -531,7 +570,9 @@ func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*S
 
 	endPoint := fmt.Sprintf("users/%s/lists/%s/items/shows", user, listID)
 
-	params := napping.Params{}.AsUrlValues()
+	params := napping.Params{
+		"extended": "full",
+	}.AsUrlValues()
 
 	var resp *napping.Response
 
@@ -559,6 +600,8 @@ func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*S
 	if err = resp.Unmarshal(&list); err != nil {
 		log.Warning(err)
 	}
+	sortBy, sortHow := listSortOrder(user, listID, resp.HttpResponse().Header)
+	sortListItems(list, sortBy, sortHow)
 
 	showListing := make([]*Shows, 0)
 	for _, show := range list {
@@ -605,7 +648,7 @@ func CalendarShows(endPoint string, page string) (shows []*CalendarShow, total i
 	key := fmt.Sprintf(cache.TraktShowsCalendarKey, endPointKey, page)
 	totalKey := fmt.Sprintf(cache.TraktShowsCalendarTotalKey, endPointKey)
 	if err := cacheStore.Get(key, &shows); err != nil {
-		resp, err := GetWithAuth("calendars/"+endPoint, params)
+		resp, err := GetCalendar(endPoint, params)
 
 		if err != nil {
 			return shows, 0, err
@@ -632,6 +675,8 @@ func CalendarShows(endPoint string, page string) (shows []*CalendarShow, total i
 		}
 	}
 
+	shows = filterHiddenCalendarShows(shows)
+
 	return
 }
 
@@ -708,110 +753,112 @@ func WatchedShowsProgress() (shows []*ProgressShow, err error) {
 		return nil, errWatched
 	}
 
-	params := napping.Params{
-		"hidden":         "false",
-		"specials":       "false",
-		"count_specials": "false",
-	}.AsUrlValues()
-
+	watchedProgressShows := showsProgress(watchedShows)
 	showsList := make([]*ProgressShow, len(watchedShows))
-	watchedProgressShows := make([]*WatchedProgressShow, len(watchedShows))
-
-	var wg sync.WaitGroup
-	wg.Add(len(watchedShows))
-	for i, show := range watchedShows {
-		go func(idx int, show *WatchedShow) {
-			var watchedProgressShow *WatchedProgressShow
-			var cachedWatchedAt time.Time
-
-			defer func() {
-				cacheStore.Set(fmt.Sprintf(cache.TraktWatchedShowsProgressWatchedKey, show.Show.IDs.Trakt), show.LastWatchedAt, cache.TraktWatchedShowsProgressWatchedExpire)
-
-				watchedProgressShows[idx] = watchedProgressShow
 
-				if watchedProgressShow != nil && watchedProgressShow.NextEpisode != nil && watchedProgressShow.NextEpisode.Number != 0 && watchedProgressShow.NextEpisode.Season != 0 {
-					showsList[idx] = &ProgressShow{
-						Show:    show.Show,
-						Episode: watchedProgressShow.NextEpisode,
-					}
-				}
-				wg.Done()
-			}()
+	for idx, show := range watchedShows {
+		if show == nil || show.Show == nil || show.Show.IDs == nil {
+			continue
+		}
+		watchedProgressShow := watchedProgressShows[idx]
 
-			if err := cacheStore.Get(fmt.Sprintf(cache.TraktWatchedShowsProgressWatchedKey, show.Show.IDs.Trakt), &cachedWatchedAt); err == nil && show.LastWatchedAt.Equal(cachedWatchedAt) {
-				if err := cacheStore.Get(fmt.Sprintf(cache.TraktWatchedShowsProgressKey, show.Show.IDs.Trakt), &watchedProgressShow); err == nil {
-					return
-				}
+		var rewatchEpisode *Episode
+		if rewatch := database.GetStorm().GetRewatch(show.Show.IDs.Trakt); rewatch != nil {
+			if rewatchEpisode = rewatchNextEpisode(show, rewatch); rewatchEpisode == nil {
+				// Every episode was watched again, so rewatch is finished
+				database.GetStorm().StopRewatch(show.Show.IDs.Trakt)
 			}
+		}
 
-			endPoint := fmt.Sprintf("shows/%d/progress/watched", show.Show.IDs.Trakt)
-			resp, err := GetWithAuth(endPoint, params)
-			if err != nil {
-				log.Errorf("Error getting endpoint %s for show '%d': %#v", endPoint, show.Show.IDs.Trakt, err)
-				return
-			} else if resp.Status() != 200 {
-				log.Errorf("Got %d response status getting endpoint %s for show '%d'", resp.Status(), endPoint, show.Show.IDs.Trakt)
-				return
+		if rewatchEpisode != nil {
+			showsList[idx] = &ProgressShow{
+				Show:    show.Show,
+				Episode: rewatchEpisode,
 			}
-			if err := resp.Unmarshal(&watchedProgressShow); err != nil {
-				log.Warningf("Can't unmarshal response: %#v", err)
+		} else if watchedProgressShow != nil && watchedProgressShow.NextEpisode != nil && watchedProgressShow.NextEpisode.Number != 0 && watchedProgressShow.NextEpisode.Season != 0 {
+			showsList[idx] = &ProgressShow{
+				Show:    show.Show,
+				Episode: watchedProgressShow.NextEpisode,
 			}
-
-			cacheStore.Set(fmt.Sprintf(cache.TraktWatchedShowsProgressKey, show.Show.IDs.Trakt), &watchedProgressShow, cache.TraktWatchedShowsProgressExpire)
-		}(i, show)
+		}
 	}
-	wg.Wait()
 
+	hidden := hiddenShowIDs(HiddenProgressWatched)
+	excluded := progressExcludedGenres()
+	dismissed := database.GetStorm().GetProgressDismissed()
 	for _, s := range showsList {
-		if s != nil {
-			shows = append(shows, s)
+		if s == nil || isHiddenObject(hidden, &s.Show.Object) {
+			continue
+		} else if isProgressExcluded(s.Show, excluded) || isProgressDismissed(dismissed, s) {
+			continue
 		}
+
+		shows = append(shows, s)
 	}
 
 	return
 }
 
+// rewatchNextEpisode finds next episode to rewatch, starting from stored rewatch cursor.
+// Episodes, watched since rewatch start, move the cursor forward, prior watch history is ignored.
+func rewatchNextEpisode(show *WatchedShow, rewatch *database.RewatchItem) *Episode {
+	seasons := make([]*WatchedSeason, 0, len(show.Seasons))
+	for _, season := range show.Seasons {
+		if season != nil && season.Number >= rewatch.Season {
+			seasons = append(seasons, season)
+		}
+	}
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].Number < seasons[j].Number
+	})
+
+	for _, season := range seasons {
+		episodes := make([]*WatchedEpisode, 0, len(season.Episodes))
+		for _, episode := range season.Episodes {
+			if episode != nil && (season.Number > rewatch.Season || episode.Number >= rewatch.Episode) {
+				episodes = append(episodes, episode)
+			}
+		}
+		sort.Slice(episodes, func(i, j int) bool {
+			return episodes[i].Number < episodes[j].Number
+		})
+
+		for _, episode := range episodes {
+			if !episode.LastWatchedAt.Before(rewatch.StartedAt) {
+				continue
+			}
+
+			if season.Number != rewatch.Season || episode.Number != rewatch.Episode {
+				database.GetStorm().UpdateRewatchCursor(rewatch.ShowID, season.Number, episode.Number)
+			}
+
+			next := GetEpisode(show.Show.IDs.Trakt, season.Number, episode.Number)
+			if next == nil {
+				next = &Episode{
+					Season: season.Number,
+					Number: episode.Number,
+				}
+			}
+			return next
+		}
+	}
+
+	return nil
+}
+
 // ToListItem ...
 func (show *Show) ToListItem() (item *xbmc.ListItem) {
+	var tmdbSource *metadata.Source
 	if !config.Get().ForceUseTrakt && show.IDs.TMDB != 0 {
-		tmdbID := strconv.Itoa(show.IDs.TMDB)
-		if tmdbShow := tmdb.GetShowByID(tmdbID, config.Get().Language); tmdbShow != nil {
-			item = tmdbShow.ToListItem()
-		}
-	}
-	if item == nil {
-		show = setShowFanart(show)
-		item = &xbmc.ListItem{
-			Label: show.Title,
-			Info: &xbmc.ListItemInfo{
-				Count:         rand.Int(),
-				Title:         show.Title,
-				OriginalTitle: show.Title,
-				Year:          show.Year,
-				Genre:         strings.Title(strings.Join(show.Genres, " / ")),
-				Plot:          show.Overview,
-				PlotOutline:   show.Overview,
-				Rating:        show.Rating,
-				Votes:         strconv.Itoa(show.Votes),
-				Duration:      show.Runtime * 60,
-				MPAA:          show.Certification,
-				Code:          show.IDs.IMDB,
-				IMDBNumber:    show.IDs.IMDB,
-				Trailer:       util.TrailerURL(show.Trailer),
-				PlayCount:     playcount.GetWatchedShowByTMDB(show.IDs.TMDB).Int(),
-				DBTYPE:        "tvshow",
-				Mediatype:     "tvshow",
-			},
-			Art: &xbmc.ListItemArt{
-				TvShowPoster: show.Images.Poster.Full,
-				Poster:       show.Images.Poster.Full,
-				FanArt:       show.Images.FanArt.Full,
-				Banner:       show.Images.Banner.Full,
-				Thumbnail:    show.Images.Thumbnail.Full,
-				ClearArt:     show.Images.ClearArt.Full,
-			},
-			Thumbnail: show.Images.Poster.Full,
-		}
+		tmdbSource = metadata.TMDBSource
+	}
+
+	ids := metadata.IDs{TMDB: show.IDs.TMDB, TVDB: show.IDs.TVDB, IMDB: show.IDs.IMDB}
+	resolved := metadata.NewResolver(tmdbSource, show.metadataSource(), metadata.TVDBSource, metadata.OMDbSource).Resolve(metadata.ShowType, ids)
+	if resolved != nil {
+		item = resolved.ToListItem()
+	} else {
+		item = show.traktListItem()
 	}
 
 	item.Thumbnail = item.Art.Poster
@@ -826,14 +873,81 @@ func (show *Show) ToListItem() (item *xbmc.ListItem) {
 		item.Info.Trailer = util.TrailerURL(show.Trailer)
 	}
 
+	placeholder.Fill(item)
+	return
+}
+
+// metadataSource returns metadata source, which builds list item from Trakt data
+func (show *Show) metadataSource() *metadata.Source {
+	return &metadata.Source{
+		Name: "Trakt",
+		Resolve: func(mediaType string, ids metadata.IDs) *metadata.Item {
+			if show.Title == "" {
+				return nil
+			}
+
+			return metadata.NewItem(show.traktListItem())
+		},
+	}
+}
+
+// traktListItem builds list item from Trakt data only
+func (show *Show) traktListItem() (item *xbmc.ListItem) {
+	show = setShowFanart(show)
+	title := tmdb.DisplayTitle(show.Title, show.OriginalTitle)
+	originalTitle := show.OriginalTitle
+	if originalTitle == "" {
+		originalTitle = show.Title
+	}
+	item = &xbmc.ListItem{
+		Label: title,
+		Info: &xbmc.ListItemInfo{
+			Count:         xbmc.ItemCount(xbmc.CountShow, show.IDs.CountID(), 0),
+			Title:         title,
+			OriginalTitle: originalTitle,
+			Year:          show.Year,
+			Genre:         strings.Title(strings.Join(show.Genres, " / ")),
+			Plot:          show.Overview,
+			PlotOutline:   show.Overview,
+			Rating:        show.Rating,
+			Votes:         strconv.Itoa(show.Votes),
+			Duration:      show.Runtime * 60,
+			MPAA:          show.Certification,
+			Code:          show.IDs.IMDB,
+			IMDBNumber:    show.IDs.IMDB,
+			Trailer:       util.TrailerURL(show.Trailer),
+			PlayCount:     playcount.GetWatchedShowByTMDB(show.IDs.TMDB).Int(),
+			DBTYPE:        "tvshow",
+			Mediatype:     "tvshow",
+		},
+		Art: &xbmc.ListItemArt{
+			TvShowPoster: show.Images.Poster.Full,
+			Poster:       show.Images.Poster.Full,
+			FanArt:       show.Images.FanArt.Full,
+			Banner:       show.Images.Banner.Full,
+			Thumbnail:    show.Images.Thumbnail.Full,
+			ClearArt:     show.Images.ClearArt.Full,
+		},
+		Thumbnail: show.Images.Poster.Full,
+	}
 	return
 }
 
 // ToListItem ...
 func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
-	episodeLabel := episode.Title
+	playCount := playcount.GetWatchedEpisodeByTMDB(show.IDs.TMDB, episode.Season, episode.Number).Int()
+	hideSpoilers := playCount == 0 && database.GetStorm().IsSpoilerProtected(show.IDs.TMDB)
+
+	title := episode.Title
+	overview := episode.Overview
+	if hideSpoilers {
+		title = fmt.Sprintf("Episode %d", episode.Number)
+		overview = ""
+	}
+
+	episodeLabel := title
 	if config.Get().AddEpisodeNumbers {
-		episodeLabel = fmt.Sprintf("%dx%02d %s", episode.Season, episode.Number, episode.Title)
+		episodeLabel = fmt.Sprintf("%dx%02d %s", episode.Season, episode.Number, title)
 	}
 
 	runtime := 1800
@@ -846,20 +960,20 @@ func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
 		Label:  episodeLabel,
 		Label2: fmt.Sprintf("%f", episode.Rating),
 		Info: &xbmc.ListItemInfo{
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountEpisode, show.IDs.CountID(), episode.Season*1000+episode.Number),
 			Title:         episodeLabel,
-			OriginalTitle: episode.Title,
+			OriginalTitle: title,
 			Season:        episode.Season,
 			Episode:       episode.Number,
 			TVShowTitle:   show.Title,
-			Plot:          episode.Overview,
-			PlotOutline:   episode.Overview,
+			Plot:          overview,
+			PlotOutline:   overview,
 			Rating:        episode.Rating,
 			Aired:         episode.FirstAired,
 			Duration:      runtime,
 			Code:          show.IDs.IMDB,
 			IMDBNumber:    show.IDs.IMDB,
-			PlayCount:     playcount.GetWatchedEpisodeByTMDB(show.IDs.TMDB, episode.Season, episode.Number).Int(),
+			PlayCount:     playCount,
 			DBTYPE:        "episode",
 			Mediatype:     "episode",
 		},
@@ -876,22 +990,33 @@ func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
 
 	item.Info.Genre = strings.Join(show.Genres, " / ")
 
+	var fa *fanart.Show
 	if config.Get().UseFanartTv {
-		if fa := fanart.GetShow(util.StrInterfaceToInt(show.IDs.TVDB)); fa != nil {
+		if fa = fanart.GetShow(util.StrInterfaceToInt(show.IDs.TVDB)); fa != nil {
 			item.Art = fa.ToEpisodeListItemArt(episode.Season, item.Art)
 		}
 	}
 
-	if episode.Images != nil && episode.Images.ScreenShot.Full != "" {
-		item.Art.FanArt = episode.Images.ScreenShot.Full
-		item.Art.Thumbnail = episode.Images.ScreenShot.Full
-		item.Art.Poster = episode.Images.ScreenShot.Full
-		item.Thumbnail = episode.Images.ScreenShot.Full
-	} else if epi := tmdb.GetEpisode(show.IDs.TMDB, episode.Season, episode.Number, config.Get().Language); epi != nil && epi.StillPath != "" {
-		item.Art.FanArt = tmdb.ImageURL(epi.StillPath, "w1280")
-		item.Art.Thumbnail = tmdb.ImageURL(epi.StillPath, "w1280")
-		item.Art.Poster = tmdb.ImageURL(epi.StillPath, "w1280")
-		item.Thumbnail = tmdb.ImageURL(epi.StillPath, "w1280")
+	// Episode thumbnail is taken, in order, from: Trakt screenshot, TMDB still,
+	// fanart.tv season thumb, show fanart. Stills are skipped if user hides them,
+	// or if the episode is not watched yet and the show is spoiler protected.
+	still := ""
+	if !config.Get().HideEpisodeStills && !hideSpoilers {
+		if episode.Images != nil && episode.Images.ScreenShot.Full != "" {
+			still = episode.Images.ScreenShot.Full
+		} else if epi := tmdb.GetEpisode(show.IDs.TMDB, episode.Season, episode.Number, config.Get().Language); epi != nil && epi.StillPath != "" {
+			still = tmdb.ImageURL(epi.StillPath, "w1280")
+		}
+	}
+
+	if still != "" {
+		item.Art.FanArt = still
+		item.Art.Thumbnail = still
+		item.Art.Poster = still
+		item.Thumbnail = still
+	} else if thumb := fanart.EpisodeFallbackThumbnail(fa, episode.Season, item.Art.FanArt); thumb != "" {
+		item.Art.Thumbnail = thumb
+		item.Thumbnail = thumb
 	}
 
 	return item
@@ -14,6 +14,8 @@ import (
 	"github.com/elgatito/elementum/broadcast"
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/notifications"
 	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 	"github.com/jmcvetta/napping"
@@ -58,19 +60,34 @@ const (
 	ProgressSortAiredOlder
 )
 
+// TopPeriods lists periods, supported by played/watched/collected charts
+var TopPeriods = []string{"weekly", "monthly", "yearly", "all"}
+
 var (
 	// ErrLocked reflects Trakt account locked status
 	ErrLocked = errors.New("Account is locked")
+	// ErrNotAuthorized is returned for personal endpoints, when Trakt is not authorized
+	ErrNotAuthorized = errors.New("Trakt authorization is required")
+	// ErrAuthExpired is returned when Trakt rejects stored access token
+	ErrAuthExpired = errors.New("Trakt access token is not valid, please, re-authorize Trakt")
 )
 
 var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
 
+var breaker = util.NewCircuitBreaker("Trakt", func(open bool) {
+	cache.AllowStale(cache.TraktKey, open)
+	if open {
+		notifications.Notify(notifications.ServiceUnavailable, "LOCALIZE[30858];;Trakt")
+	}
+})
+
 // Object ...
 type Object struct {
-	Title     string    `json:"title"`
-	Year      int       `json:"year"`
-	IDs       *IDs      `json:"ids"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Title         string    `json:"title"`
+	OriginalTitle string    `json:"original_title"`
+	Year          int       `json:"year"`
+	IDs           *IDs      `json:"ids"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // MovieSearchResults ...
@@ -184,12 +201,18 @@ type Airs struct {
 type Movies struct {
 	Watchers int    `json:"watchers"`
 	Movie    *Movie `json:"movie"`
+
+	// ReleaseDate is set for anticipated movies from TMDB
+	ReleaseDate time.Time `json:"-"`
 }
 
 // Shows ...
 type Shows struct {
 	Watchers int   `json:"watchers"`
 	Show     *Show `json:"show"`
+
+	// ReleaseDate is set for anticipated shows from TMDB
+	ReleaseDate time.Time `json:"-"`
 }
 
 // Watchlist ...
@@ -284,6 +307,17 @@ type IDs struct {
 	Slug   string `json:"slug"`
 }
 
+// CountID returns ID, used to build stable ListItem count: TMDB ID, same as for TMDB items, or Trakt ID
+func (ids *IDs) CountID() int {
+	if ids == nil {
+		return 0
+	}
+	if ids.TMDB != 0 {
+		return ids.TMDB
+	}
+	return ids.Trakt
+}
+
 // Code ...
 type Code struct {
 	DeviceCode      string `json:"device_code"`
@@ -340,6 +374,7 @@ type List struct {
 
 // ListItem ...
 type ListItem struct {
+	ID       int    `json:"id"`
 	Rank     int    `json:"rank"`
 	ListedAt string `json:"listed_at"`
 	Type     string `json:"type"`
@@ -362,6 +397,12 @@ type CalendarMovie struct {
 	Movie    *Movie `json:"movie"`
 }
 
+// Genre ...
+type Genre struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
 // User ...
 type User struct {
 	Username string `json:"username"`
@@ -424,6 +465,7 @@ type WatchedShow struct {
 	Plays         int `json:"plays"`
 	Watched       bool
 	LastWatchedAt time.Time        `json:"last_watched_at"`
+	LastUpdatedAt time.Time        `json:"last_updated_at"`
 	Show          *Show            `json:"show"`
 	Seasons       []*WatchedSeason `json:"seasons"`
 }
@@ -459,6 +501,17 @@ type ProgressShow struct {
 	Show    *Show    `json:"show"`
 }
 
+// HistoryItem represents single entry of sync/history
+type HistoryItem struct {
+	ID        int64     `json:"id"`
+	WatchedAt time.Time `json:"watched_at"`
+	Action    string    `json:"action"`
+	Type      string    `json:"type"`
+	Movie     *Movie    `json:"movie"`
+	Episode   *Episode  `json:"episode"`
+	Show      *Show     `json:"show"`
+}
+
 // Pagination ...
 type Pagination struct {
 	ItemCount int `json:"x_pagination_item_count"`
@@ -582,6 +635,10 @@ func getIntFromHeader(headers http.Header, key string) (res int) {
 
 // Get ...
 func Get(endPoint string, params url.Values) (resp *napping.Response, err error) {
+	if !breaker.Allow() {
+		return nil, util.ErrCircuitOpen
+	}
+
 	header := http.Header{
 		"Content-type":      []string{"application/json"},
 		"trakt-api-key":     []string{config.TraktReadClientID},
@@ -597,26 +654,21 @@ func Get(endPoint string, params url.Values) (resp *napping.Response, err error)
 		Header: &header,
 	}
 
-	rl.Call(func() error {
-		resp, err = napping.Send(&req)
-		if err != nil {
-			return err
-		} else if resp.Status() == 429 {
-			log.Warningf("Rate limit exceeded getting %s, cooling down...", endPoint)
-			rl.CoolDown(resp.HttpResponse().Header)
-			return util.ErrExceeded
-		} else if resp.Status() == 403 && retriesLeft > 0 {
-			retriesLeft--
-			resp, err = Get(endPoint, params)
-		}
-
-		return nil
-	})
+	resp, err = sendLimited(&req, endPoint)
+	if err == nil && resp.Status() == 403 && retriesLeft > 0 {
+		retriesLeft--
+		resp, err = Get(endPoint, params)
+	}
+	breaker.Report(util.ResponseStatus(resp), err)
 	return
 }
 
 // GetWithAuth ...
 func GetWithAuth(endPoint string, params url.Values) (resp *napping.Response, err error) {
+	if !breaker.Allow() {
+		return nil, util.ErrCircuitOpen
+	}
+
 	header := http.Header{
 		"Content-type":      []string{"application/json"},
 		"Authorization":     []string{fmt.Sprintf("Bearer %s", config.Get().TraktToken)},
@@ -633,28 +685,29 @@ func GetWithAuth(endPoint string, params url.Values) (resp *napping.Response, er
 		Header: &header,
 	}
 
-	rl.Call(func() error {
-		resp, err = napping.Send(&req)
+	resp, err = sendLimited(&req, endPoint)
+	if err == nil && resp.Status() == 401 {
+		err = ErrAuthExpired
+		log.Warningf("Request: %s, Error: %s", endPoint, err)
+		notifications.Notify(notifications.AuthExpired, "LOCALIZE[30576]")
+	} else if err == nil && resp.Status() == 403 && retriesLeft > 0 {
+		retriesLeft--
+		resp, err = GetWithAuth(endPoint, params)
+	}
+	breaker.Report(util.ResponseStatus(resp), err)
+	return
+}
 
-		if err != nil {
-			return err
-		} else if resp.Status() == 401 {
-			err = fmt.Errorf("Trakt access token is not valid, please, re-authorize Trakt")
-			log.Warningf("Request: %s, Error: %s", endPoint, err)
-			xbmc.Notify("Elementum", "LOCALIZE[30576]", config.AddonIcon())
-			return err
-		} else if resp.Status() == 429 {
-			log.Warningf("Rate limit exceeded getting %s, cooling down...", endPoint)
-			rl.CoolDown(resp.HttpResponse().Header)
-			return util.ErrExceeded
-		} else if resp.Status() == 403 && retriesLeft > 0 {
-			retriesLeft--
-			resp, err = GetWithAuth(endPoint, params)
-		}
+// GetCalendar requests calendar endpoint. Personal "my/" calendars require authorization,
+// while "all/" calendars are public and are available without it.
+func GetCalendar(endPoint string, params url.Values) (resp *napping.Response, err error) {
+	if config.Get().TraktAuthorized {
+		return GetWithAuth("calendars/"+endPoint, params)
+	} else if strings.HasPrefix(endPoint, "my/") {
+		return nil, ErrNotAuthorized
+	}
 
-		return nil
-	})
-	return
+	return Get("calendars/"+endPoint, params)
 }
 
 // PostJSON ...
@@ -670,6 +723,25 @@ func PostJSON(endPoint string, obj interface{}) (resp *napping.Response, err err
 
 // Post ...
 func Post(endPoint string, payload *bytes.Buffer) (resp *napping.Response, err error) {
+	return write("POST", endPoint, payload)
+}
+
+// Put ...
+func Put(endPoint string, payload *bytes.Buffer) (resp *napping.Response, err error) {
+	return write("PUT", endPoint, payload)
+}
+
+// Delete ...
+func Delete(endPoint string) (resp *napping.Response, err error) {
+	return write("DELETE", endPoint, bytes.NewBuffer(nil))
+}
+
+// write makes authorized request, modifying user data
+func write(method string, endPoint string, payload *bytes.Buffer) (resp *napping.Response, err error) {
+	if !breaker.Allow() {
+		return nil, util.ErrCircuitOpen
+	}
+
 	header := http.Header{
 		"Content-type":      []string{"application/json"},
 		"Authorization":     []string{fmt.Sprintf("Bearer %s", config.Get().TraktToken)},
@@ -681,30 +753,51 @@ func Post(endPoint string, payload *bytes.Buffer) (resp *napping.Response, err e
 
 	req := napping.Request{
 		Url:        fmt.Sprintf("%s/%s", APIURL, endPoint),
-		Method:     "POST",
+		Method:     method,
 		RawPayload: true,
 		Payload:    payload,
 		Header:     &header,
 	}
 
-	rl.Call(func() error {
-		resp, err = napping.Send(&req)
-		if err != nil {
-			return err
-		} else if resp.Status() == 429 {
-			log.Warningf("Rate limit exceeded getting %s, cooling down...", endPoint)
-			rl.CoolDown(resp.HttpResponse().Header)
-			return util.ErrExceeded
-		} else if resp.Status() == 403 && retriesLeft > 0 {
-			retriesLeft--
-			resp, err = Post(endPoint, payload)
-		}
+	resp, err = sendLimited(&req, endPoint)
+	if err == nil && resp.Status() == 403 && retriesLeft > 0 {
+		retriesLeft--
+		resp, err = write(method, endPoint, payload)
+	}
+	breaker.Report(util.ResponseStatus(resp), err)
+	return
+}
 
-		return nil
+// sendLimited makes request through rate limiter, transient failures are retried outside of it,
+// so that waiting for next attempt does not hold a rate limiter slot
+func sendLimited(req *napping.Request, endPoint string) (resp *napping.Response, err error) {
+	err = util.DefaultRetryPolicy.Do(req.Method, func() (int, error) {
+		rl.Call(func() error {
+			if _, err = send(req, &resp); err != nil {
+				return err
+			} else if resp.Status() == 429 {
+				log.Warningf("Rate limit exceeded getting %s, cooling down...", endPoint)
+				rl.CoolDown(resp.HttpResponse().Header)
+				return util.ErrExceeded
+			}
+
+			return nil
+		})
+		return util.ResponseStatus(resp), err
 	})
 	return
 }
 
+// send makes a single request attempt and returns response status for retry policy
+func send(req *napping.Request, resp **napping.Response) (int, error) {
+	r, err := napping.Send(req)
+	*resp = r
+	if err != nil || r == nil {
+		return 0, err
+	}
+	return r.Status(), nil
+}
+
 // GetCode ...
 func GetCode() (code *Code, err error) {
 	endPoint := "oauth/device/code"
@@ -895,6 +988,7 @@ func TokenRefreshHandler() {
 						xbmc.SetSetting("trakt_token_expiry", strconv.Itoa(int(expiry)))
 						xbmc.SetSetting("trakt_token", token.AccessToken)
 						xbmc.SetSetting("trakt_refresh_token", token.RefreshToken)
+						updateProfileToken(config.Get().TraktUsername, token, expiry)
 						log.Noticef("Token refreshed for Trakt authorization, next refresh in %s", time.Duration(token.ExpiresIn-259200)*time.Second)
 					}
 				} else {
@@ -945,33 +1039,25 @@ func Authorize(fromSettings bool) error {
 					continue
 				}
 
-				// Cleanup last activities to force requesting again
-				cacheStore := cache.NewDBStore()
-				_ = cacheStore.Set(cache.TraktActivitiesKey, "", 1)
-
 				expiry := time.Now().Unix() + int64(token.ExpiresIn)
 				xbmc.SetSetting("trakt_token_expiry", strconv.Itoa(int(expiry)))
 				xbmc.SetSetting("trakt_token", token.AccessToken)
 				xbmc.SetSetting("trakt_refresh_token", token.RefreshToken)
 
-				config.Get().TraktToken = token.AccessToken
-
-				// Getting username for currently authorized user
-				params := napping.Params{}.AsUrlValues()
-				resp, err := GetWithAuth("users/settings", params)
-				if resp.Status() == 200 {
-					user := &UserSettings{}
-					errJSON := resp.Unmarshal(user)
-					if errJSON != nil {
-						return
-					}
-
-					if user != nil && user.User.Ids.Slug != "" {
-						log.Debugf("Setting Trakt Username as %s", user.User.Ids.Slug)
-						xbmc.SetSetting("trakt_username", user.User.Ids.Slug)
-					}
+				// Getting username for currently authorized user. Account is changed only
+				// when username is known, so that cache keys do not fall back to no account.
+				if user, err := GetUserSettings(token.AccessToken); err == nil && user.User.Ids.Slug != "" {
+					log.Debugf("Setting Trakt Username as %s", user.User.Ids.Slug)
+					xbmc.SetSetting("trakt_username", user.User.Ids.Slug)
+					updateProfileToken(user.User.Ids.Slug, token, expiry)
+				} else if err != nil {
+					log.Warningf("Could not get Trakt username: %s", err)
 				}
 
+				// Cleanup last activities to force requesting again
+				cacheStore := cache.NewDBStore()
+				_ = cacheStore.Set(cache.TraktActivitiesKey, "", 1)
+
 				config.Reload()
 
 				xbmc.Notify("Elementum", "LOCALIZE[30650]", config.AddonIcon())
@@ -987,6 +1073,51 @@ func Authorize(fromSettings bool) error {
 	return nil
 }
 
+// GetUserSettings returns settings of the user, authorized with a token,
+// that is not yet set to current configuration
+func GetUserSettings(token string) (user *UserSettings, err error) {
+	endPoint := "users/settings"
+	header := http.Header{
+		"Content-type":      []string{"application/json"},
+		"Authorization":     []string{fmt.Sprintf("Bearer %s", token)},
+		"trakt-api-key":     []string{config.TraktWriteClientID},
+		"trakt-api-version": []string{APIVersion},
+		"User-Agent":        []string{UserAgent},
+		"Cookie":            []string{Cookies},
+	}
+	params := napping.Params{}.AsUrlValues()
+
+	req := napping.Request{
+		Url:    fmt.Sprintf("%s/%s", APIURL, endPoint),
+		Method: "GET",
+		Params: &params,
+		Header: &header,
+	}
+
+	var resp *napping.Response
+	rl.Call(func() error {
+		resp, err = napping.Send(&req)
+		if err != nil {
+			return err
+		} else if resp.Status() == 429 {
+			log.Warningf("Rate limit exceeded getting Trakt user settings, cooling down...")
+			rl.CoolDown(resp.HttpResponse().Header)
+			return util.ErrExceeded
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	} else if resp.Status() != 200 {
+		return nil, fmt.Errorf("Bad status getting Trakt user settings: %d", resp.Status())
+	}
+
+	user = &UserSettings{}
+	err = resp.Unmarshal(user)
+	return
+}
+
 // Deauthorize ...
 func Deauthorize(fromSettings bool) error {
 	// Cleanup last activities to force requesting again
@@ -998,6 +1129,9 @@ func Deauthorize(fromSettings bool) error {
 	xbmc.SetSetting("trakt_refresh_token", "")
 	xbmc.SetSetting("trakt_username", "")
 
+	// Forget stored authorization, so that it is not restored for this profile
+	updateProfileToken("", &Token{}, 0)
+
 	xbmc.Notify("Elementum", "LOCALIZE[30652]", config.AddonIcon())
 
 	return nil
@@ -1038,7 +1172,13 @@ func Request(endPoint string, params napping.Params, isWithAuth bool, isUpdateNe
 		resp, err = Get(endPoint, params.AsUrlValues())
 	}
 
-	if err != nil {
+	if err == util.ErrCircuitOpen {
+		// Serving stale cached data while Trakt is not available
+		if errCache := cacheStore.Get(cacheKey, &ret); errCache == nil {
+			return nil
+		}
+		return err
+	} else if err != nil {
 		return err
 	} else if resp.Status() != 200 {
 		return fmt.Errorf("Bad status getting %s: %d", endPoint, resp.Status())
@@ -1053,6 +1193,22 @@ func Request(endPoint string, params napping.Params, isWithAuth bool, isUpdateNe
 	return nil
 }
 
+// GetGenres returns list of Trakt genres for movies or shows
+func GetGenres(itemType string) ([]*Genre, error) {
+	var genres []*Genre
+	err := Request(
+		"genres/"+itemType,
+		napping.Params{},
+		false,
+		false,
+		fmt.Sprintf(cache.TraktGenresKey, itemType),
+		cache.TraktGenresExpire,
+		&genres,
+	)
+
+	return genres, err
+}
+
 // SyncAddedItem adds item (movie/show) to watchlist or collection
 func SyncAddedItem(itemType string, tmdbID string, location int) (resp *napping.Response, err error) {
 	list := config.Get().TraktSyncAddedMoviesList
@@ -1071,6 +1227,24 @@ func SyncAddedItem(itemType string, tmdbID string, location int) (resp *napping.
 	return
 }
 
+// SyncAddedItems adds multiple items (movies/shows) to watchlist or collection with a single request
+func SyncAddedItems(itemType string, tmdbIDs []int, location int) (resp *napping.Response, err error) {
+	list := config.Get().TraktSyncAddedMoviesList
+	if itemType == "shows" {
+		list = config.Get().TraktSyncAddedShowsList
+	}
+
+	if location == 0 {
+		return AddMultipleToCollection(itemType, tmdbIDs)
+	} else if location == 1 {
+		return AddMultipleToWatchlist(itemType, tmdbIDs)
+	} else if location == 2 && list != 0 {
+		return AddMultipleToUserlist(list, itemType, tmdbIDs)
+	}
+
+	return
+}
+
 // SyncRemovedItem removes item (movie/show) from watchlist or collection
 func SyncRemovedItem(itemType string, tmdbID string, location int) (resp *napping.Response, err error) {
 	list := config.Get().TraktSyncRemovedMoviesList
@@ -1089,14 +1263,18 @@ func SyncRemovedItem(itemType string, tmdbID string, location int) (resp *nappin
 	return
 }
 
-// AddToWatchlist ...
+// AddToWatchlist adds item to watchlist and updates cached watchlist in place
 func AddToWatchlist(itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
 		return nil, err
 	}
 
 	endPoint := "sync/watchlist"
-	return Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	resp, err = Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	if err == nil && resp.Status() == 201 {
+		updateCachedWatchlist(itemType, tmdbID, true)
+	}
+	return
 }
 
 // AddToUserlist ...
@@ -1121,6 +1299,50 @@ func AddToUserlist(listID int, itemType string, tmdbID string) (resp *napping.Re
 	return PostJSON(endPoint, payload)
 }
 
+// AddMultipleToWatchlist adds multiple items to watchlist with a single request
+func AddMultipleToWatchlist(itemType string, tmdbIDs []int) (resp *napping.Response, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	return PostJSON("sync/watchlist", newItemsPayload(itemType, tmdbIDs))
+}
+
+// AddMultipleToCollection adds multiple items to collection with a single request
+func AddMultipleToCollection(itemType string, tmdbIDs []int) (resp *napping.Response, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	return PostJSON("sync/collection", newItemsPayload(itemType, tmdbIDs))
+}
+
+// AddMultipleToUserlist adds multiple items to user list with a single request
+func AddMultipleToUserlist(listID int, itemType string, tmdbIDs []int) (resp *napping.Response, err error) {
+	if err := Authorized(); err != nil {
+		return nil, err
+	}
+
+	endPoint := fmt.Sprintf("/users/%s/lists/%s/items", config.Get().TraktUsername, strconv.Itoa(listID))
+	return PostJSON(endPoint, newItemsPayload(itemType, tmdbIDs))
+}
+
+func newItemsPayload(itemType string, tmdbIDs []int) ListItemsPayload {
+	payload := ListItemsPayload{}
+	for _, id := range tmdbIDs {
+		if itemType == "movies" {
+			i := &Movie{}
+			i.IDs = &IDs{TMDB: id}
+			payload.Movies = append(payload.Movies, i)
+		} else if itemType == "shows" {
+			i := &Show{}
+			i.IDs = &IDs{TMDB: id}
+			payload.Shows = append(payload.Shows, i)
+		}
+	}
+	return payload
+}
+
 // RemoveFromUserlist ...
 func RemoveFromUserlist(listID int, itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
@@ -1143,34 +1365,64 @@ func RemoveFromUserlist(listID int, itemType string, tmdbID string) (resp *nappi
 	return PostJSON(endPoint, payload)
 }
 
-// RemoveFromWatchlist ...
+// RemoveFromWatchlist removes item from watchlist and updates cached watchlist in place
 func RemoveFromWatchlist(itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
 		return nil, err
 	}
 
 	endPoint := "sync/watchlist/remove"
-	return Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	resp, err = Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	if err == nil && resp.Status() == 200 {
+		updateCachedWatchlist(itemType, tmdbID, false)
+	}
+	return
+}
+
+// DismissRecommendation hides movie or show, identified by Trakt id, from user recommendations
+// and removes cached recommendation pages, so that item disappears immediately
+func DismissRecommendation(itemType string, traktID int) error {
+	if err := Authorized(); err != nil {
+		return err
+	}
+
+	resp, err := Delete(fmt.Sprintf("recommendations/%s/%d", itemType, traktID))
+	if err != nil {
+		return err
+	} else if resp.Status() != 204 {
+		return fmt.Errorf("Bad status dismissing Trakt recommendation: %d", resp.Status())
+	}
+
+	database.GetCache().DeleteWithPrefix(database.CommonBucket, []byte(fmt.Sprintf(cache.TraktKey+"%s.recommendations", itemType)))
+	return nil
 }
 
-// AddToCollection ...
+// AddToCollection adds item to collection and updates cached collection in place
 func AddToCollection(itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
 		return nil, err
 	}
 
 	endPoint := "sync/collection"
-	return Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	resp, err = Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	if err == nil && resp.Status() == 201 {
+		updateCachedCollection(itemType, tmdbID, true)
+	}
+	return
 }
 
-// RemoveFromCollection ...
+// RemoveFromCollection removes item from collection and updates cached collection in place
 func RemoveFromCollection(itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
 		return nil, err
 	}
 
 	endPoint := "sync/collection/remove"
-	return Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	resp, err = Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
+	if err == nil && resp.Status() == 200 {
+		updateCachedCollection(itemType, tmdbID, false)
+	}
+	return
 }
 
 // SetWatched addes and removes from watched history
@@ -1284,7 +1536,7 @@ func (item *WatchedItem) String() (query string) {
 // 	return Post(endPoint, buf)
 // }
 
-// Scrobble ...
+// Scrobble sends playback event to Trakt, queueing it for retry if Trakt is not available
 func Scrobble(action string, contentType string, tmdbID int, watched float64, runtime float64) {
 	if err := Authorized(); err != nil {
 		return
@@ -1302,11 +1554,13 @@ func Scrobble(action string, contentType string, tmdbID int, watched float64, ru
 	payload := fmt.Sprintf(`{"%s": {"ids": {"tmdb": %d}}, "progress": %f, "app_version": "%s"}`,
 		contentType, tmdbID, progress, util.GetVersion())
 	resp, err := Post(endPoint, bytes.NewBufferString(payload))
-	if err != nil {
-		log.Error(err.Error())
-		xbmc.Notify("Elementum", "Scrobble failed, check your logs.", config.AddonIcon())
-	} else if resp.Status() != 201 {
-		log.Errorf("Failed to scrobble %s #%d to %s at %f: %d", contentType, tmdbID, action, progress, resp.Status())
+	if status := util.ResponseStatus(resp); isRetryable(status, err) {
+		log.Errorf("Failed to scrobble %s #%d to %s at %f: %d, %v", contentType, tmdbID, action, progress, status, err)
+		queueScrobble(action, contentType, tmdbID, progress)
+	} else if status != 201 {
+		log.Errorf("Failed to scrobble %s #%d to %s at %f: %d", contentType, tmdbID, action, progress, status)
+	} else {
+		go FlushScrobbleQueue()
 	}
 }
 
@@ -230,18 +230,26 @@ func (s *Season) GetEpisode(number int) *Episode {
 	return nil
 }
 
-// GetShow ...
+// GetShow returns show with all episodes, using TVDB v4 API, if API key is configured, or legacy API
 func GetShow(tvdbID int, language string) (*Show, error) {
 	var show *Show
 	cacheStore := cache.NewDBStore()
 	key := fmt.Sprintf(cache.TVDBShowByIDKey, tvdbID, language)
+	expire := cache.TVDBShowByIDExpire
+	get := getShow
+	if IsV4Enabled() {
+		key = fmt.Sprintf(cache.TVDBShowV4ByIDKey, tvdbID, language)
+		expire = cache.TVDBShowV4ByIDExpire
+		get = getShowV4
+	}
+
 	if err := cacheStore.Get(key, &show); err != nil {
-		newShow, err := getShow(tvdbID, language)
+		newShow, err := get(tvdbID, language)
 		if err != nil {
 			return nil, err
 		}
 		if newShow != nil {
-			cacheStore.Set(key, newShow, cache.TVDBShowByIDExpire)
+			cacheStore.Set(key, newShow, expire)
 		}
 		show = newShow
 	}
@@ -0,0 +1,550 @@
+package tvdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jmcvetta/napping"
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+)
+
+const (
+	v4URL = "https://api4.thetvdb.com/v4"
+	// Episodes are returned in pages of 500, limit is just a guard against endless paging
+	v4MaxPages = 20
+)
+
+// TVDB v4 artwork types
+const (
+	ArtworkSeriesBanner     = 1
+	ArtworkSeriesPoster     = 2
+	ArtworkSeriesBackground = 3
+	ArtworkSeasonBanner     = 6
+	ArtworkSeasonPoster     = 7
+	ArtworkSeasonBackground = 8
+)
+
+var (
+	log = logging.MustGetLogger("tvdb")
+
+	errUnauthorized = errors.New("TVDB token is not valid")
+
+	v4Token   string
+	v4TokenMu sync.Mutex
+
+	// TVDB v4 uses ISO 639-2 language codes, while Kodi uses ISO 639-1
+	v4Languages = map[string]string{
+		"ar": "ara",
+		"bg": "bul",
+		"cs": "ces",
+		"da": "dan",
+		"de": "deu",
+		"el": "ell",
+		"en": "eng",
+		"es": "spa",
+		"fi": "fin",
+		"fr": "fra",
+		"he": "heb",
+		"hu": "hun",
+		"it": "ita",
+		"ja": "jpn",
+		"ko": "kor",
+		"nl": "nld",
+		"no": "nor",
+		"pl": "pol",
+		"pt": "por",
+		"ro": "ron",
+		"ru": "rus",
+		"sk": "slk",
+		"sv": "swe",
+		"tr": "tur",
+		"uk": "ukr",
+		"zh": "zho",
+	}
+)
+
+// SeriesExtended is an extended series record of TVDB v4 API
+type SeriesExtended struct {
+	ID               int           `json:"id"`
+	Name             string        `json:"name"`
+	Slug             string        `json:"slug"`
+	Image            string        `json:"image"`
+	FirstAired       string        `json:"firstAired"`
+	OriginalLanguage string        `json:"originalLanguage"`
+	Overview         string        `json:"overview"`
+	AverageRuntime   int           `json:"averageRuntime"`
+	Score            float64       `json:"score"`
+	Status           *NameRecord   `json:"status"`
+	Genres           []*NameRecord `json:"genres"`
+	Artworks         []*Artwork    `json:"artworks"`
+	Seasons          []*V4Season   `json:"seasons"`
+	RemoteIDs        []*RemoteID   `json:"remoteIds"`
+	Translations     *struct {
+		NameTranslations     []*Translation `json:"nameTranslations"`
+		OverviewTranslations []*Translation `json:"overviewTranslations"`
+	} `json:"translations"`
+}
+
+// NameRecord is any TVDB v4 record, that is used only by its name
+type NameRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Artwork is an image of a series or a season
+type Artwork struct {
+	ID        int     `json:"id"`
+	Image     string  `json:"image"`
+	Thumbnail string  `json:"thumbnail"`
+	Language  string  `json:"language"`
+	Type      int     `json:"type"`
+	Score     float64 `json:"score"`
+}
+
+// V4Season is a season record of TVDB v4 API, same season number exists for each of season types
+type V4Season struct {
+	ID     int    `json:"id"`
+	Number int    `json:"number"`
+	Image  string `json:"image"`
+	Type   *struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"type"`
+}
+
+// V4Episode is an episode record of TVDB v4 API, numbered by default season type
+type V4Episode struct {
+	ID             int    `json:"id"`
+	SeasonNumber   int    `json:"seasonNumber"`
+	Number         int    `json:"number"`
+	AbsoluteNumber int    `json:"absoluteNumber"`
+	Name           string `json:"name"`
+	Aired          string `json:"aired"`
+	Runtime        int    `json:"runtime"`
+	Overview       string `json:"overview"`
+	Image          string `json:"image"`
+}
+
+// RemoteID is an ID of a series in other sources
+type RemoteID struct {
+	ID         string `json:"id"`
+	Type       int    `json:"type"`
+	SourceName string `json:"sourceName"`
+}
+
+// Translation is a translated name or overview
+type Translation struct {
+	Name      string `json:"name"`
+	Overview  string `json:"overview"`
+	Language  string `json:"language"`
+	IsAlias   bool   `json:"isAlias"`
+	IsPrimary bool   `json:"isPrimary"`
+}
+
+// IsV4Enabled returns whether TVDB v4 API key is configured, otherwise legacy API is used
+func IsV4Enabled() bool {
+	return config.Get().TVDBAPIKey != ""
+}
+
+// V4Language returns TVDB v4 language code for Kodi language code
+func V4Language(language string) string {
+	if l, ok := v4Languages[strings.ToLower(language)]; ok {
+		return l
+	}
+	return "eng"
+}
+
+// GetSeriesExtended returns extended series record with artworks, seasons and translations
+func GetSeriesExtended(tvdbID int) (series *SeriesExtended) {
+	if tvdbID == 0 || !IsV4Enabled() {
+		return nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TVDBSeriesExtendedKey, tvdbID)
+	if err := cacheStore.Get(key, &series); err != nil {
+		if config.Get().MeteredMode {
+			return nil
+		}
+
+		var result struct {
+			Data *SeriesExtended `json:"data"`
+		}
+		params := url.Values{"meta": []string{"translations"}}
+		if err := v4Get(fmt.Sprintf("series/%d/extended", tvdbID), params, &result); err != nil {
+			log.Warningf("Could not get TVDB series %d: %s", tvdbID, err)
+			return nil
+		}
+
+		series = result.Data
+		if series != nil {
+			cacheStore.Set(key, series, cache.TVDBSeriesExtendedExpire)
+		}
+	}
+	return
+}
+
+// GetSeriesEpisodes returns all episodes of a series, with absolute numbers
+func GetSeriesEpisodes(tvdbID int) (episodes []*V4Episode) {
+	if tvdbID == 0 || !IsV4Enabled() {
+		return nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TVDBSeriesEpisodesKey, tvdbID)
+	if err := cacheStore.Get(key, &episodes); err != nil {
+		if config.Get().MeteredMode {
+			return nil
+		}
+
+		for page := 0; page < v4MaxPages; page++ {
+			var result struct {
+				Data *struct {
+					Episodes []*V4Episode `json:"episodes"`
+				} `json:"data"`
+				Links *struct {
+					Next *string `json:"next"`
+				} `json:"links"`
+			}
+			params := url.Values{"page": []string{strconv.Itoa(page)}}
+			if err := v4Get(fmt.Sprintf("series/%d/episodes/default", tvdbID), params, &result); err != nil {
+				log.Warningf("Could not get TVDB episodes of %d: %s", tvdbID, err)
+				return nil
+			}
+			if result.Data == nil {
+				break
+			}
+
+			episodes = append(episodes, result.Data.Episodes...)
+			if result.Links == nil || result.Links.Next == nil || *result.Links.Next == "" {
+				break
+			}
+		}
+
+		cacheStore.Set(key, episodes, cache.TVDBSeriesEpisodesExpire)
+	}
+	return
+}
+
+// GetSeasonArtwork returns artwork of a season, sorted by score
+func GetSeasonArtwork(seasonID int) (artworks []*Artwork) {
+	if seasonID == 0 || !IsV4Enabled() {
+		return nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TVDBSeasonArtworkKey, seasonID)
+	if err := cacheStore.Get(key, &artworks); err != nil {
+		if config.Get().MeteredMode {
+			return nil
+		}
+
+		var result struct {
+			Data *struct {
+				Artwork []*Artwork `json:"artwork"`
+			} `json:"data"`
+		}
+		if err := v4Get(fmt.Sprintf("seasons/%d/extended", seasonID), url.Values{}, &result); err != nil {
+			log.Warningf("Could not get TVDB season %d: %s", seasonID, err)
+			return nil
+		}
+		if result.Data != nil {
+			artworks = result.Data.Artwork
+		}
+
+		sort.Slice(artworks, func(i, j int) bool { return artworks[i].Score > artworks[j].Score })
+		cacheStore.Set(key, artworks, cache.TVDBSeasonArtworkExpire)
+	}
+	return
+}
+
+// GetTitles returns translated names of a series, by Kodi language code
+func GetTitles(tvdbID int) map[string]string {
+	ret := map[string]string{}
+	series := GetSeriesExtended(tvdbID)
+	if series == nil || series.Translations == nil {
+		return ret
+	}
+
+	for short, long := range v4Languages {
+		if name := series.Translation(long); name != "" {
+			ret[short] = name
+		}
+	}
+	return ret
+}
+
+// Translation returns name of a series in TVDB v4 language, or empty string, if it is not translated
+func (s *SeriesExtended) Translation(language string) string {
+	if s.Translations == nil {
+		return ""
+	}
+
+	for _, t := range s.Translations.NameTranslations {
+		if t.Language == language && !t.IsAlias && t.Name != "" {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// OverviewTranslation returns overview of a series in TVDB v4 language, or empty string
+func (s *SeriesExtended) OverviewTranslation(language string) string {
+	if s.Translations == nil {
+		return ""
+	}
+
+	for _, t := range s.Translations.OverviewTranslations {
+		if t.Language == language && t.Overview != "" {
+			return t.Overview
+		}
+	}
+	return ""
+}
+
+// BestArtwork returns image of given type with the highest score, preferring given language
+func (s *SeriesExtended) BestArtwork(artworkType int, language string) string {
+	var best *Artwork
+	for _, a := range s.Artworks {
+		if a.Type != artworkType {
+			continue
+		}
+
+		if best == nil ||
+			(a.Language == language && best.Language != language) ||
+			((a.Language == language) == (best.Language == language) && a.Score > best.Score) {
+			best = a
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return best.Image
+}
+
+// getShowV4 builds a show from TVDB v4 records, so it can be used the same way, as legacy API show
+func getShowV4(tvdbID int, language string) (*Show, error) {
+	series := GetSeriesExtended(tvdbID)
+	if series == nil {
+		return nil, fmt.Errorf("Could not get TVDB series %d", tvdbID)
+	}
+
+	lang := V4Language(language)
+	show := &Show{
+		ID:            series.ID,
+		SeriesName:    series.Name,
+		Overview:      series.Overview,
+		FirstAired:    series.FirstAired,
+		Language:      language,
+		Rating:        strconv.FormatFloat(series.Score, 'f', -1, 64),
+		RuntimeString: strconv.Itoa(series.AverageRuntime),
+		Runtime:       series.AverageRuntime,
+		Poster:        series.BestArtwork(ArtworkSeriesPoster, lang),
+		FanArt:        series.BestArtwork(ArtworkSeriesBackground, lang),
+		Banner:        series.BestArtwork(ArtworkSeriesBanner, lang),
+		Seasons:       SeasonList{},
+		Banners:       []*Banner{},
+	}
+	if name := series.Translation(lang); name != "" {
+		show.SeriesName = name
+	}
+	if overview := series.OverviewTranslation(lang); overview != "" {
+		show.Overview = overview
+	}
+	if show.Poster == "" {
+		show.Poster = series.Image
+	}
+	if series.Status != nil {
+		show.Status = series.Status.Name
+	}
+	for _, remote := range series.RemoteIDs {
+		if remote.SourceName == "IMDB" {
+			show.ImdbID = remote.ID
+		}
+	}
+
+	genres := make([]string, 0, len(series.Genres))
+	for _, g := range series.Genres {
+		genres = append(genres, g.Name)
+	}
+	if len(genres) > 0 {
+		show.Genre = "|" + strings.Join(genres, "|") + "|"
+	}
+
+	for _, a := range series.Artworks {
+		bannerType := ""
+		switch a.Type {
+		case ArtworkSeriesBanner:
+			bannerType = "series"
+		case ArtworkSeriesPoster:
+			bannerType = "poster"
+		case ArtworkSeriesBackground:
+			bannerType = "fanart"
+		default:
+			continue
+		}
+
+		show.Banners = append(show.Banners, &Banner{
+			ID:            strconv.Itoa(a.ID),
+			BannerPath:    a.Image,
+			BannerType:    bannerType,
+			Language:      a.Language,
+			Rating:        strconv.FormatFloat(a.Score, 'f', -1, 64),
+			ThumbnailPath: a.Thumbnail,
+		})
+	}
+	for _, s := range series.Seasons {
+		if s.Type == nil || s.Type.Type != "official" {
+			continue
+		}
+
+		image := s.Image
+		if image == "" {
+			for _, a := range GetSeasonArtwork(s.ID) {
+				if a.Type == ArtworkSeasonPoster {
+					image = a.Image
+					break
+				}
+			}
+		}
+		if image == "" {
+			continue
+		}
+
+		show.Banners = append(show.Banners, &Banner{
+			ID:          strconv.Itoa(s.ID),
+			BannerPath:  image,
+			BannerType:  "season",
+			BannerType2: "season",
+			Language:    language,
+			Season:      s.Number,
+		})
+	}
+
+	sort.Sort(sort.Reverse(BannersByRating(show.Banners)))
+
+	episodes := GetSeriesEpisodes(tvdbID)
+	sort.Slice(episodes, func(i, j int) bool {
+		return episodes[i].SeasonNumber*1000+episodes[i].Number < episodes[j].SeasonNumber*1000+episodes[j].Number
+	})
+	for _, e := range episodes {
+		season := show.GetSeason(e.SeasonNumber)
+		if season == nil {
+			season = &Season{
+				Season:   e.SeasonNumber,
+				Episodes: EpisodeList{},
+			}
+			show.Seasons = append(show.Seasons, season)
+		}
+
+		season.Episodes = append(season.Episodes, &Episode{
+			ID:                   strconv.Itoa(e.ID),
+			EpisodeName:          e.Name,
+			EpisodeNumber:        e.Number,
+			FirstAired:           e.Aired,
+			Overview:             e.Overview,
+			SeasonNumber:         e.SeasonNumber,
+			FileName:             e.Image,
+			SeriesID:             strconv.Itoa(tvdbID),
+			AbsoluteNumber:       e.AbsoluteNumber,
+			AbsoluteNumberString: strconv.Itoa(e.AbsoluteNumber),
+		})
+	}
+
+	return show, nil
+}
+
+// v4Get makes authorized request to TVDB v4 API, logging in again, if token has expired
+func v4Get(endPoint string, params url.Values, result interface{}) error {
+	err := v4Request(endPoint, params, result)
+	if err == errUnauthorized {
+		resetV4Token()
+		err = v4Request(endPoint, params, result)
+	}
+	return err
+}
+
+func v4Request(endPoint string, params url.Values, result interface{}) error {
+	token, err := getV4Token()
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{
+		"Content-type":  []string{"application/json"},
+		"Authorization": []string{"Bearer " + token},
+	}
+	req := napping.Request{
+		Url:    fmt.Sprintf("%s/%s", v4URL, endPoint),
+		Method: "GET",
+		Params: &params,
+		Header: &header,
+	}
+
+	resp, err := napping.Send(&req)
+	if err != nil {
+		return err
+	} else if resp.Status() == 401 {
+		return errUnauthorized
+	} else if resp.Status() != 200 {
+		return fmt.Errorf("Bad status getting %s: %d", endPoint, resp.Status())
+	}
+
+	return resp.Unmarshal(result)
+}
+
+// getV4Token returns token, that is valid for a month, logging in, if there is no token yet
+func getV4Token() (string, error) {
+	v4TokenMu.Lock()
+	defer v4TokenMu.Unlock()
+
+	if v4Token != "" {
+		return v4Token, nil
+	}
+
+	cacheStore := cache.NewDBStore()
+	key := cache.TVDBTokenKey
+	if err := cacheStore.Get(key, &v4Token); err == nil && v4Token != "" {
+		return v4Token, nil
+	}
+
+	payload := map[string]string{"apikey": config.Get().TVDBAPIKey}
+	if config.Get().TVDBPin != "" {
+		payload["pin"] = config.Get().TVDBPin
+	}
+	var result struct {
+		Data *struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+
+	resp, err := napping.Post(v4URL+"/login", &payload, &result, nil)
+	if err != nil {
+		return "", err
+	} else if resp.Status() != 200 || result.Data == nil || result.Data.Token == "" {
+		return "", fmt.Errorf("Could not login to TVDB, status: %d", resp.Status())
+	}
+
+	v4Token = result.Data.Token
+	cacheStore.Set(key, v4Token, cache.TVDBTokenExpire)
+	return v4Token, nil
+}
+
+func resetV4Token() {
+	v4TokenMu.Lock()
+	defer v4TokenMu.Unlock()
+
+	v4Token = ""
+	cache.NewDBStore().Delete(cache.TVDBTokenKey)
+}
@@ -11,7 +11,12 @@ import (
 	"github.com/elgatito/elementum/xbmc"
 )
 
-func imageURL(path string) string {
+// ImageURL returns full URL of a banner
+func ImageURL(path string) string {
+	// TVDB v4 returns full URLs
+	if strings.HasPrefix(path, "http") {
+		return path
+	}
 	return tvdbURL + "/banners/" + path
 }
 
@@ -22,7 +27,7 @@ func (seasons SeasonList) ToListItems(show *Show) []*xbmc.ListItem {
 	fanarts := make([]string, 0)
 	for _, banner := range show.Banners {
 		if banner.BannerType == "fanart" {
-			fanarts = append(fanarts, imageURL(banner.BannerPath))
+			fanarts = append(fanarts, ImageURL(banner.BannerPath))
 		}
 	}
 
@@ -55,7 +60,7 @@ func (episodes EpisodeList) ToListItems(show *Show) []*xbmc.ListItem {
 	fanarts := make([]string, 0)
 	for _, banner := range show.Banners {
 		if banner.BannerType == "fanart" {
-			fanarts = append(fanarts, imageURL(banner.BannerPath))
+			fanarts = append(fanarts, ImageURL(banner.BannerPath))
 		}
 	}
 
@@ -88,7 +93,7 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 	item := &xbmc.ListItem{
 		Label: name,
 		Info: &xbmc.ListItemInfo{
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountSeason, show.ID, season.Season),
 			Title:         name,
 			OriginalTitle: name,
 			Season:        season.Season,
@@ -102,7 +107,7 @@ func (season *Season) ToListItem(show *Show) *xbmc.ListItem {
 			banner.Season == season.Season &&
 			banner.Language == show.Language &&
 			item.Art.Poster == "" {
-			item.Art.Poster = imageURL(banner.BannerPath)
+			item.Art.Poster = ImageURL(banner.BannerPath)
 			item.Art.Thumbnail = item.Art.Poster
 			item.Thumbnail = item.Art.Poster
 			break
@@ -124,7 +129,7 @@ func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
 	item := &xbmc.ListItem{
 		Label: episodeLabel,
 		Info: &xbmc.ListItemInfo{
-			Count:         rand.Int(),
+			Count:         xbmc.ItemCount(xbmc.CountEpisode, show.ID, episode.SeasonNumber*1000+episode.EpisodeNumber),
 			Title:         episodeLabel,
 			OriginalTitle: episode.EpisodeName,
 			Season:        episode.SeasonNumber,
@@ -135,8 +140,8 @@ func (episode *Episode) ToListItem(show *Show) *xbmc.ListItem {
 			Aired:         episode.FirstAired,
 		},
 		Art: &xbmc.ListItemArt{
-			Thumbnail: imageURL(episode.FileName),
-			Poster:    imageURL(show.Poster),
+			Thumbnail: ImageURL(episode.FileName),
+			Poster:    ImageURL(show.Poster),
 		},
 	}
 
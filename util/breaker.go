@@ -0,0 +1,99 @@
+package util
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while upstream service is considered unavailable
+var ErrCircuitOpen = errors.New("Service is temporarily unavailable")
+
+// CircuitBreaker stops calling failing upstream service for a cooldown period,
+// after which a single probe request is allowed to check if service is back.
+type CircuitBreaker struct {
+	Name      string
+	Threshold int
+	Cooldown  time.Duration
+
+	// OnChange is called when breaker is opened or closed
+	OnChange func(open bool)
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	probing  bool
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates circuit breaker with default threshold and cooldown
+func NewCircuitBreaker(name string, onChange func(open bool)) *CircuitBreaker {
+	return &CircuitBreaker{
+		Name:      name,
+		Threshold: 5,
+		Cooldown:  time.Minute,
+		OnChange:  onChange,
+	}
+}
+
+// Allow returns true if request to upstream service can be made
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.open {
+		return true
+	} else if cb.probing || time.Since(cb.openedAt) < cb.Cooldown {
+		return false
+	}
+
+	cb.probing = true
+	return true
+}
+
+// IsOpen returns true if upstream service is considered unavailable
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.open
+}
+
+// Report counts result of a request, made to upstream service
+func (cb *CircuitBreaker) Report(status int, err error) {
+	failed := IsTransientError(err) || IsTransientStatus(status)
+
+	cb.mu.Lock()
+	cb.probing = false
+	changed := false
+	if !failed {
+		changed = cb.open
+		cb.open = false
+		cb.failures = 0
+	} else {
+		cb.failures++
+		if cb.open {
+			// Probe request has failed, waiting for another cooldown
+			cb.openedAt = time.Now()
+		} else if cb.failures >= cb.Threshold {
+			changed = true
+			cb.open = true
+			cb.openedAt = time.Now()
+		}
+	}
+	open := cb.open
+	cb.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if open {
+		log.Warningf("%s is not responding, pausing requests for %s", cb.Name, cb.Cooldown)
+	} else {
+		log.Noticef("%s is available again", cb.Name)
+	}
+	if cb.OnChange != nil {
+		cb.OnChange(open)
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// IsContentAllowed returns false for items, excluded from listings
+// by original language or by minimal vote count settings
+func IsContentAllowed(language string, votes int) bool {
+	if votes < config.Get().MinVoteCount {
+		return false
+	}
+
+	allowed := config.Get().AllowedLanguages
+	if len(allowed) == 0 || language == "" {
+		return true
+	}
+
+	language = strings.ToLower(language)
+	for _, l := range allowed {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
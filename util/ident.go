@@ -115,5 +115,10 @@ func GetUserAndPeer() (peerID, userAgent string) {
 		}
 	}
 
+	// Custom user agent overrides spoofed one, peer ID is left as is
+	if c.CustomUserAgent != "" {
+		userAgent = c.CustomUserAgent
+	}
+
 	return
 }
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jmcvetta/napping"
+)
+
+// RetryPolicy describes how transient failures of HTTP requests are retried
+type RetryPolicy struct {
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+}
+
+// DefaultRetryPolicy is used for metadata API requests
+var DefaultRetryPolicy = &RetryPolicy{
+	Retries:    3,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+	Jitter:     0.3,
+}
+
+// Do runs request function and repeats it while it fails with transient errors.
+// Function should return HTTP status code, or an error if request was not completed.
+// Requests with non-idempotent methods are never repeated.
+func (p *RetryPolicy) Do(method string, f func() (int, error)) (err error) {
+	status := 0
+	for attempt := 0; ; attempt++ {
+		status, err = f()
+		if attempt >= p.Retries || !IsIdempotentMethod(method) || !(IsTransientError(err) || IsTransientStatus(status)) {
+			return
+		}
+
+		delay := p.delay(attempt)
+		log.Debugf("Transient failure (status: %d, error: %v), retrying in %s", status, err, delay)
+		time.Sleep(delay)
+	}
+}
+
+// ResponseStatus returns HTTP status of the response, or 0 if there is no response
+func ResponseStatus(resp *napping.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.Status()
+}
+
+// delay returns exponential backoff delay for an attempt with applied jitter
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff << uint(attempt)
+	if delay > p.MaxBackoff || delay <= 0 {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// IsIdempotentMethod returns true if request with this method can be safely repeated
+func IsIdempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// IsTransientStatus returns true for HTTP statuses, that are expected to go away on retry
+func IsTransientStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status >= 500
+}
+
+// IsTransientError returns true for network errors, like timeouts or connection resets
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,237 @@
+package watchdog
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+
+	"github.com/elgatito/elementum/util"
+)
+
+// Monitored subsystems
+const (
+	ProviderSearch = "provider_search"
+	LibrarySync    = "library_sync"
+	TraktSync      = "trakt_sync"
+)
+
+const (
+	checkInterval = 10 * time.Second
+	// Task, running longer than abortFactor times of its expected duration, is aborted
+	abortFactor = 3
+	// Goroutine dumps are not written more often, to keep logs readable
+	dumpInterval = 5 * time.Minute
+)
+
+var (
+	log = logging.MustGetLogger("watchdog")
+
+	mu         sync.Mutex
+	tasks      = map[uint64]*Task{}
+	lastID     uint64
+	lastDump   time.Time
+	restarters = map[string]func(){}
+)
+
+// Task is a single run of a monitored loop
+type Task struct {
+	ID       uint64
+	Name     string
+	Started  time.Time
+	Expected time.Duration
+	Overdue  bool
+
+	aborted util.Event
+	onAbort func()
+}
+
+// TaskInfo describes running task
+type TaskInfo struct {
+	Name     string  `json:"name"`
+	Started  string  `json:"started"`
+	Running  float64 `json:"running_seconds"`
+	Expected float64 `json:"expected_seconds"`
+	Overdue  bool    `json:"overdue"`
+}
+
+// Start registers a run of a subsystem, which is expected to finish within expected duration.
+// onAbort is called, when task is aborted, to release the subsystem, so that it can run again.
+func Start(name string, expected time.Duration, onAbort func()) *Task {
+	mu.Lock()
+	defer mu.Unlock()
+
+	lastID++
+	t := &Task{
+		ID:       lastID,
+		Name:     name,
+		Started:  time.Now(),
+		Expected: expected,
+		onAbort:  onAbort,
+	}
+	// Create abort channel beforehand, as it is read from several goroutines
+	t.aborted.C()
+	tasks[t.ID] = t
+	return t
+}
+
+// Done unregisters finished task
+func (t *Task) Done() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(tasks, t.ID)
+}
+
+// C returns a channel, that is closed when task is aborted
+func (t *Task) C() <-chan struct{} {
+	return t.aborted.C()
+}
+
+// IsAborted checks if task was aborted
+func (t *Task) IsAborted() bool {
+	return t.aborted.IsSet()
+}
+
+// abort signals loop to stop and releases the subsystem. Goroutines cannot be killed,
+// so a stuck goroutine stays, but it does not block further runs of the subsystem.
+func (t *Task) abort() {
+	if !t.aborted.Set() {
+		return
+	}
+	if t.onAbort != nil {
+		t.onAbort()
+	}
+}
+
+// RegisterRestart sets a function, that restarts subsystem without a daemon restart
+func RegisterRestart(name string, restart func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	restarters[name] = restart
+}
+
+// Restart aborts running tasks of a subsystem and starts it again
+func Restart(name string) error {
+	mu.Lock()
+	restart, ok := restarters[name]
+	var running []*Task
+	for _, t := range tasks {
+		if t.Name == name {
+			running = append(running, t)
+			delete(tasks, t.ID)
+		}
+	}
+	mu.Unlock()
+
+	if !ok && len(running) == 0 {
+		return fmt.Errorf("Unknown subsystem: %s", name)
+	}
+
+	for _, t := range running {
+		t.abort()
+	}
+	if restart != nil {
+		log.Noticef("Restarting %s", name)
+		restart()
+	}
+	return nil
+}
+
+// Running returns running tasks, oldest first
+func Running() []TaskInfo {
+	mu.Lock()
+	defer mu.Unlock()
+
+	running := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		running = append(running, t)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Started.Before(running[j].Started)
+	})
+
+	ret := make([]TaskInfo, 0, len(running))
+	for _, t := range running {
+		ret = append(ret, TaskInfo{
+			Name:     t.Name,
+			Started:  t.Started.Format(time.RFC3339),
+			Running:  time.Since(t.Started).Seconds(),
+			Expected: t.Expected.Seconds(),
+			Overdue:  t.Overdue,
+		})
+	}
+	return ret
+}
+
+// Run checks running tasks until closing is signaled
+func Run(closing <-chan struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+func check() {
+	var overdue, stuck []*Task
+
+	mu.Lock()
+	for _, t := range tasks {
+		took := time.Since(t.Started)
+		if took > t.Expected*abortFactor {
+			stuck = append(stuck, t)
+			delete(tasks, t.ID)
+		} else if took > t.Expected && !t.Overdue {
+			t.Overdue = true
+			overdue = append(overdue, t)
+		}
+	}
+	mu.Unlock()
+
+	for _, t := range overdue {
+		log.Warningf("%s is running for %s, while expected to finish in %s", t.Name, time.Since(t.Started), t.Expected)
+		DumpGoroutines(false)
+	}
+	for _, t := range stuck {
+		log.Errorf("%s is stuck for %s, aborting it", t.Name, time.Since(t.Started))
+		DumpGoroutines(false)
+		t.abort()
+	}
+}
+
+// DumpGoroutines writes stacks of all goroutines to the log.
+// Unless forced, dumps are written not more often than dumpInterval.
+func DumpGoroutines(force bool) {
+	mu.Lock()
+	if !force && time.Since(lastDump) < dumpInterval {
+		mu.Unlock()
+		return
+	}
+	lastDump = time.Now()
+	mu.Unlock()
+
+	log.Warningf("Goroutines dump:\n%s", Stacks())
+}
+
+// Stacks returns stacks of all goroutines
+func Stacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
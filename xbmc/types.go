@@ -290,6 +290,22 @@ type PlayerItemInfo struct {
 	} `json:"item"`
 }
 
+// PlayerStream describes audio or subtitles stream of playing item
+type PlayerStream struct {
+	Index    int    `json:"index"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+}
+
+// PlayerStreams lists audio and subtitles streams of playing item
+type PlayerStreams struct {
+	AudioStreams       []*PlayerStream `json:"audiostreams"`
+	Subtitles          []*PlayerStream `json:"subtitles"`
+	CurrentAudioStream *PlayerStream   `json:"currentaudiostream"`
+	CurrentSubtitle    *PlayerStream   `json:"currentsubtitle"`
+	SubtitleEnabled    bool            `json:"subtitleenabled"`
+}
+
 // ActivePlayers ...
 type ActivePlayers []struct {
 	ID   int    `json:"playerid"`
@@ -320,6 +336,11 @@ type SettingValue struct {
 	Value string `json:"value"`
 }
 
+// SettingIntValue ...
+type SettingIntValue struct {
+	Value int `json:"value"`
+}
+
 // KodiTime ...
 type KodiTime struct {
 	time.Time
@@ -31,8 +31,17 @@ func VideoLibraryScan() (retVal string) {
 	return
 }
 
-// VideoLibraryScanDirectory ...
+// VideoLibraryScanDirectory scans only given directory of a library source,
+// Kodi matches directories with trailing separator only
 func VideoLibraryScanDirectory(directory string, showDialogs bool) (retVal string) {
+	if !strings.HasSuffix(directory, "/") && !strings.HasSuffix(directory, "\\") {
+		if strings.Contains(directory, "\\") && !strings.Contains(directory, "/") {
+			directory += "\\"
+		} else {
+			directory += "/"
+		}
+	}
+
 	executeJSONRPC("VideoLibrary.Scan", &retVal, Args{directory, showDialogs})
 	return
 }
@@ -145,6 +154,48 @@ func PlayerGetItem(playerid int) (item *PlayerItemInfo) {
 	return
 }
 
+// PlayerGetStreams returns audio and subtitles streams of playing item
+func PlayerGetStreams(playerid int) (streams *PlayerStreams) {
+	params := map[string]interface{}{
+		"playerid":   playerid,
+		"properties": []string{"audiostreams", "subtitles", "currentaudiostream", "currentsubtitle", "subtitleenabled"},
+	}
+	executeJSONRPCO("Player.GetProperties", &streams, params)
+	return
+}
+
+// PlayerSetAudioStream switches playing item to audio stream with index
+func PlayerSetAudioStream(playerid int, index int) (ret string) {
+	params := map[string]interface{}{
+		"playerid": playerid,
+		"stream":   index,
+	}
+	executeJSONRPCO("Player.SetAudioStream", &ret, params)
+	return
+}
+
+// PlayerSetSubtitle switches playing item to subtitles stream with index, or disables subtitles
+func PlayerSetSubtitle(playerid int, index int, enable bool) (ret string) {
+	params := map[string]interface{}{
+		"playerid": playerid,
+		"subtitle": index,
+		"enable":   enable,
+	}
+	if index < 0 {
+		params["subtitle"] = "off"
+	}
+	executeJSONRPCO("Player.SetSubtitle", &ret, params)
+	return
+}
+
+// ShowPicture opens image from url in Kodi picture viewer
+func ShowPicture(url string) {
+	retVal := ""
+	executeJSONRPCO("Player.Open", &retVal, Object{
+		"item": Object{"file": url},
+	})
+}
+
 // VideoLibraryGetShows ...
 func VideoLibraryGetShows() (shows *VideoLibraryShows, err error) {
 	defer perf.ScopeTimer()()
@@ -619,3 +670,23 @@ func SettingsGetSettingValue(setting string) string {
 	executeJSONRPCO("Settings.GetSettingValue", &resp, params)
 	return resp.Value
 }
+
+// SettingsGetSettingInt returns value of integer Kodi setting
+func SettingsGetSettingInt(setting string) int {
+	params := map[string]interface{}{
+		"setting": setting,
+	}
+	resp := SettingIntValue{}
+
+	executeJSONRPCO("Settings.GetSettingValue", &resp, params)
+	return resp.Value
+}
+
+// GetCurrentProfile returns name of active Kodi profile
+func GetCurrentProfile() string {
+	var profile struct {
+		Label string `json:"label"`
+	}
+	executeJSONRPCO("Profiles.GetCurrentProfile", &profile, Object{})
+	return profile.Label
+}
@@ -249,6 +249,18 @@ func ListDialogLarge(title string, subject string, items ...string) int {
 	return retVal
 }
 
+// DialogCountdown shows skinnable countdown dialog with next item artwork,
+// returns true if countdown has finished or user has confirmed playback.
+func DialogCountdown(heading string, title string, plot string, thumbnail string, fanart string, delay int) bool {
+	retVal := 0
+	if err := executeJSONRPCEx("Dialog_Countdown", &retVal, Args{TranslateText(heading), title, plot, thumbnail, fanart, delay}); err != nil {
+		// Older add-on versions do not have countdown dialog, so plain confirmation is asked
+		log.Debugf("Countdown dialog is not available, falling back to confirmation: %s", err)
+		return DialogConfirm(heading, title)
+	}
+	return retVal != 0
+}
+
 // PlayerGetPlayingFile ...
 func PlayerGetPlayingFile() string {
 	retVal := ""
@@ -1,7 +1,28 @@
 package xbmc
 
+import (
+	"fmt"
+	"hash/fnv"
+)
+
 // SetResolvedURL ...
 func SetResolvedURL(url string) {
 	retVal := -1
 	executeJSONRPCEx("SetResolvedUrl", &retVal, Args{url})
 }
+
+// Kinds of items, used to build ListItem count
+const (
+	CountMovie = iota + 1
+	CountShow
+	CountSeason
+	CountEpisode
+)
+
+// ItemCount returns stable ListItem count, derived from media ID and position of an item inside it,
+// so Kodi keeps list position and sorting between refreshes.
+func ItemCount(kind int, id int, position int) int {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%d:%d", kind, id, position)
+	return int(h.Sum32() & 0x7fffffff)
+}